@@ -1,8 +1,15 @@
 package main
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"os"
+	"stickersbot/internal/config"
 	"stickersbot/internal/version"
 	"time"
 
@@ -14,8 +21,17 @@ const (
 	authHost  = "crypto.cmd-root.com"
 	appId     = "telegrambot"
 	authDelay = 20 * time.Second
+
+	authAttempts        = 4               // Startup authentication attempts while license server is unreachable
+	authRetryDelay      = 5 * time.Second // First retry delay, doubled after every failure
+	authRetryMaxDelay   = 5 * time.Minute // Longest retry delay
+	defaultLicenseGrace = 24 * time.Hour  // License stays valid this long after the last verification
+	licenseCacheFile    = "license_cache.json"
 )
 
+// errInvalidKey license server rejected the key. Other errors mean the server was not reachable
+var errInvalidKey = errors.New("invalid key")
+
 var verifyUrl = fmt.Sprintf("https://%s/api/app/auth/b/verify", authHost)
 var authenticateUrl = fmt.Sprintf("https://%s/api/app/auth/b/token", authHost)
 
@@ -45,12 +61,18 @@ func doPost(url, key string) error {
 	if err != nil {
 		return err
 	}
-
-	if resp.StatusCode != 200 {
-		return errors.New("invalid key")
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	warnRequiredVersion(resp)
+
+	switch {
+	case resp.StatusCode == 200:
+		return nil
+	case resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != 429:
+		return errInvalidKey
+	default:
+		return fmt.Errorf("license server returned status %d", resp.StatusCode)
 	}
-
-	return nil
 }
 
 func verify(key string) error {
@@ -61,16 +83,159 @@ func authenticate(key string) error {
 	return doPost(authenticateUrl, key)
 }
 
-func startVerifier(licenseKey string) {
+// licenseCache time of the last successful verification of license key on this machine
+type licenseCache struct {
+	Key        string    `json:"key"` // Hash of license key and machine ID
+	VerifiedAt time.Time `json:"verified_at"`
+	MAC        string    `json:"mac"` // Signature of the verification time, edited cache is ignored
+}
+
+// licenseCacheKey returns hash identifying license key on this machine
+func licenseCacheKey(key string) string {
+	sum := sha256.Sum256([]byte(key + ":" + hash))
+	return hex.EncodeToString(sum[:])
+}
+
+// licenseCacheMAC returns signature of verification time of license key on this machine
+func licenseCacheMAC(key string, verifiedAt time.Time) string {
+	mac := hmac.New(sha256.New, []byte(key+":"+hash))
+	mac.Write([]byte(verifiedAt.UTC().Format(time.RFC3339Nano)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// saveVerified remembers successful verification of license key
+func saveVerified(key string) {
+	now := time.Now().Round(0)
+	data, err := json.Marshal(licenseCache{Key: licenseCacheKey(key), VerifiedAt: now, MAC: licenseCacheMAC(key, now)})
+	if err == nil {
+		err = config.WriteFileAtomic(config.StatePath(licenseCacheFile), data, 0600)
+	}
+	if err != nil {
+		fmt.Printf("⚠️ Failed to save license verification: %v\n", err)
+	}
+}
+
+// lastVerified returns time of the last successful verification of license key, zero if unknown
+func lastVerified(key string) time.Time {
+	data, err := os.ReadFile(config.StatePath(licenseCacheFile))
+	if err != nil {
+		return time.Time{}
+	}
+
+	var cache licenseCache
+	if err := json.Unmarshal(data, &cache); err != nil || cache.Key != licenseCacheKey(key) {
+		return time.Time{}
+	}
+	if !hmac.Equal([]byte(cache.MAC), []byte(licenseCacheMAC(key, cache.VerifiedAt))) {
+		return time.Time{}
+	}
+	// Verification from the future means edited cache or clock moved back
+	if cache.VerifiedAt.After(time.Now()) {
+		return time.Time{}
+	}
+	return cache.VerifiedAt
+}
+
+// licenseGrace returns how long license stays valid while license server is unreachable
+func licenseGrace(cfg *config.Config) time.Duration {
+	switch {
+	case cfg.LicenseGraceHours < 0:
+		return 0
+	case cfg.LicenseGraceHours == 0:
+		return defaultLicenseGrace
+	default:
+		return time.Duration(cfg.LicenseGraceHours) * time.Hour
+	}
+}
+
+// graceLeft returns how long license key stays valid without verification, 0 if grace is over
+func graceLeft(key string, grace time.Duration) time.Duration {
+	verifiedAt := lastVerified(key)
+	if verifiedAt.IsZero() {
+		return 0
+	}
+	left := time.Until(verifiedAt.Add(grace))
+	switch {
+	case left <= 0:
+		return 0
+	case left > grace:
+		return grace
+	default:
+		return left
+	}
+}
+
+// retryDelay returns delay before retry after given number of consecutive failures
+func retryDelay(failures int) time.Duration {
+	delay := authRetryDelay
+	for i := 1; i < failures && delay < authRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, authRetryMaxDelay)
+}
+
+// authenticateWithRetry authenticates license key, retrying while license server is
+// unreachable. Key verified recently on this machine is accepted within grace period
+func authenticateWithRetry(key string, grace time.Duration) error {
+	for attempt := 1; ; attempt++ {
+		err := authenticate(key)
+		if err == nil {
+			saveVerified(key)
+			return nil
+		}
+		if errors.Is(err, errInvalidKey) {
+			return err
+		}
+
+		if attempt == authAttempts {
+			if left := graceLeft(key, grace); left > 0 {
+				fmt.Printf("⚠️ License server unreachable: %v. Using license verified earlier, valid offline for %s\n",
+					err, left.Round(time.Minute))
+				return nil
+			}
+			return fmt.Errorf("license server unreachable: %v", err)
+		}
+
+		delay := retryDelay(attempt)
+		fmt.Printf("⚠️ License server unreachable (attempt %d/%d): %v, retrying in %s\n", attempt, authAttempts, err, delay)
+		time.Sleep(delay)
+	}
+}
+
+// startVerifier verifies license key periodically. Outage of license server is retried
+// with backoff and tolerated within grace period after the last successful verification.
+// onFailure is called once verification fails for good
+func startVerifier(licenseKey string, grace time.Duration, onFailure func(err error)) {
 	go func() {
+		failures := 0
 		for {
 			err := verify(licenseKey)
-			if err != nil {
+			delay := authDelay
+			switch {
+			case err == nil:
+				if failures > 0 {
+					fmt.Println("✅ License server reachable again")
+				}
+				failures = 0
+				saveVerified(licenseKey)
+			case errors.Is(err, errInvalidKey):
 				fmt.Printf("❌ License verification failed: %v\n", err)
+				onFailure(err)
 				return
+			default:
+				left := graceLeft(licenseKey, grace)
+				if left == 0 {
+					fmt.Printf("❌ License verification failed: %v (offline grace period is over)\n", err)
+					onFailure(fmt.Errorf("%v (offline grace period is over)", err))
+					return
+				}
+				failures++
+				delay = retryDelay(failures)
+				fmt.Printf("⚠️ License server unreachable: %v, retrying in %s (offline grace ends in %s)\n",
+					err, delay, left.Round(time.Minute))
 			}
 
-			time.Sleep(authDelay)
+			time.Sleep(delay)
 		}
 	}()
 }
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"stickersbot/internal/config"
+	"stickersbot/internal/service"
+)
+
+// runBacktest replays recorded catalog through snipe filters and strategy of configuration
+func runBacktest(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("backtest", flag.ContinueOnError)
+	configPath := flags.String("config", "", "configuration with filters to test (default: config.json of state directory)")
+	asJSON := flags.Bool("json", false, "print report as JSON")
+	verbose := flags.Bool("verbose", false, "show decisions of every filter check")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("expected backtest [--config file] [--json] [--verbose] <recorded catalog file>")
+	}
+
+	if *configPath != "" {
+		loaded, err := config.Load(*configPath)
+		if err != nil {
+			return fmt.Errorf("configuration loading (%s): %w", *configPath, err)
+		}
+		cfg = loaded
+	}
+	if err := service.ValidateStrategy(cfg.SnipeStrategy); err != nil {
+		return err
+	}
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// Monitors log every filter decision, the report is enough by default
+	if !*verbose {
+		log.SetOutput(io.Discard)
+	}
+
+	report, err := service.RunBacktest(cfg, file)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+	printBacktestReport(report)
+	return nil
+}
+
+// printBacktestReport prints matched drops and simulated purchases of accounts
+func printBacktestReport(report *service.BacktestReport) {
+	fmt.Printf("🧪 Backtest: %d snapshots, %s - %s, strategy %s\n", report.Snapshots,
+		report.From.Format("2006-01-02 15:04:05"), report.Until.Format("2006-01-02 15:04:05"), report.Strategy)
+	fmt.Println(strings.Repeat("-", 80))
+
+	if len(report.Drops) == 0 {
+		fmt.Println("ℹ️  No drop matched the filters")
+	}
+	for _, drop := range report.Drops {
+		fmt.Printf("🎯 %s  %s (collection %d, character %d) price %.4f TON, supply %d\n",
+			drop.Time.Format("2006-01-02 15:04:05"), drop.Name, drop.CollectionID, drop.CharacterID,
+			float64(drop.Price)/1000000000, drop.Supply)
+		fmt.Printf("   Matched: %s\n", strings.Join(drop.MatchedBy, ", "))
+		if len(drop.Buyers) > 0 {
+			fmt.Printf("   Bought by: %s (%d orders)\n", strings.Join(drop.Buyers, ", "), drop.Orders)
+		}
+		for _, reason := range drop.Skipped {
+			fmt.Printf("   Skipped: %s\n", reason)
+		}
+	}
+
+	fmt.Println("\n👤 Per account:")
+	for _, account := range report.Accounts {
+		fmt.Printf("   %-30s Matches: %d | Orders: %d | Spent: %.4f TON\n",
+			account.Name, account.Matches, account.Orders, float64(account.SpentNano)/1000000000)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"stickersbot/internal/config"
+	"stickersbot/internal/service"
+)
+
+// BackupPasswordEnv environment variable with backup password, asked interactively if unset
+const BackupPasswordEnv = "STICKERSBOT_BACKUP_PASSWORD"
+
+// runCommand runs backup, restore, update or service subcommand given on command line
+func runCommand(args []string, useConfigStateDir bool) error {
+	// Service of the state directory may be installed while the bot runs in it
+	if args[0] == "service" {
+		return runServiceCommand(args[1:], useConfigStateDir)
+	}
+
+	cfgPath := config.StatePath(config.ConfigFile)
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		return fmt.Errorf("configuration loading (%s): %w", cfgPath, err)
+	}
+	if useConfigStateDir && cfg.StateDir != "" {
+		config.SetStateDir(cfg.StateDir)
+	}
+
+	// Backtest only reads recorded catalog, it may run next to a working bot
+	if args[0] == "backtest" {
+		return runBacktest(cfg, args[1:])
+	}
+
+	// Bench talks to its own mock shop and writes no state
+	if args[0] == "bench" {
+		return runBench(cfg, args[1:])
+	}
+
+	// Files must not change while they are copied
+	lock, err := service.AcquireInstanceLock(config.StatePath(service.LockFile))
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	switch args[0] {
+	case "backup":
+		return runBackup(cfg, args[1:])
+	case "restore":
+		return runRestore(cfg, args[1:])
+	case "update":
+		// Binary is not replaced under a running bot, the lock is held
+		return runUpdate(args[1:])
+	default:
+		return fmt.Errorf("unknown command %q, expected backup, restore, update, backtest, bench or service", args[0])
+	}
+}
+
+// runBackup writes encrypted archive of bot state
+func runBackup(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("backup", flag.ContinueOnError)
+	redact := flags.Bool("redact", false, "remove seed phrases, tokens and passwords from archived config")
+	out := flags.String("out", "", "archive file (default: stickersbot_backup_<date>_<time>.sbbackup in state directory)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	path := *out
+	if path == "" {
+		path = config.StatePath(service.BackupFileName(time.Now()))
+	}
+
+	password, err := readBackupPassword(true)
+	if err != nil {
+		return err
+	}
+
+	files, err := service.CreateBackup(cfg, path, password, *redact)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Backup of %d files saved to %s\n", len(files), path)
+	if *redact {
+		fmt.Printf("🔒 Secrets were removed from config, it is restored as %s\n", service.RedactedConfigFile)
+	}
+	return nil
+}
+
+// runRestore extracts archive into state directory after confirmation
+func runRestore(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("restore", flag.ContinueOnError)
+	yes := flags.Bool("yes", false, "don't ask for confirmation")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New("usage: restore [--yes] <archive>")
+	}
+	path := flags.Arg(0)
+
+	if !*yes {
+		fmt.Printf("⚠️ Files in %s will be overwritten by %s. Continue? (y/N): ", config.StateDir(), path)
+		input, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if strings.ToLower(strings.TrimSpace(input)) != "y" {
+			fmt.Println("❌ Restore cancelled")
+			return nil
+		}
+	}
+
+	password, err := readBackupPassword(false)
+	if err != nil {
+		return err
+	}
+
+	files, err := service.RestoreBackup(cfg, path, password)
+	for _, name := range files {
+		fmt.Printf("   📄 %s\n", name)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ Restored %d files\n", len(files))
+	return nil
+}
+
+// readBackupPassword returns password from environment or asks for it. New password is asked twice
+func readBackupPassword(confirm bool) (string, error) {
+	if password := os.Getenv(BackupPasswordEnv); password != "" {
+		return password, nil
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print("🔑 Backup password: ")
+	password, _ := reader.ReadString('\n')
+	password = strings.TrimSpace(password)
+	if password == "" {
+		return "", errors.New("backup password is empty")
+	}
+
+	if confirm {
+		fmt.Print("🔑 Repeat password: ")
+		repeated, _ := reader.ReadString('\n')
+		if strings.TrimSpace(repeated) != password {
+			return "", errors.New("passwords don't match")
+		}
+	}
+	return password, nil
+}
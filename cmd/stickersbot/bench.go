@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"stickersbot/internal/config"
+	"stickersbot/internal/mockshop"
+	"stickersbot/internal/service"
+)
+
+// runBench measures purchase threads of configuration against the built-in mock shop
+func runBench(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("bench", flag.ContinueOnError)
+	configPath := flags.String("config", "", "configuration with accounts to bench (default: config.json of state directory)")
+	duration := flags.Duration("duration", 30*time.Second, "how long every round runs")
+	threads := flags.String("threads", "", "comma-separated thread counts of every account, one round each (default: threads of config)")
+	latency := flags.Duration("latency", 100*time.Millisecond, "response time of the mock shop")
+	jitter := flags.Duration("jitter", 0, "random extra response time of the mock shop up to this value")
+	errorRate := flags.Float64("error-rate", 0, "share of orders the mock shop fails with 500 (0-1)")
+	noProxy := flags.Bool("no-proxy", false, "send orders directly instead of through proxies of accounts")
+	listen := flags.String("listen", "", "address of the mock shop (default: random port of 127.0.0.1)")
+	shopURL := flags.String("url", "", "URL proxies reach the mock shop at, e.g. http://203.0.113.5:8099")
+	asJSON := flags.Bool("json", false, "print reports as JSON")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return fmt.Errorf("expected bench [--config file] [--duration 30s] [--threads 4,8,16] [--latency 100ms] [--no-proxy] [--json]")
+	}
+	if *duration <= 0 {
+		return fmt.Errorf("duration must be positive")
+	}
+
+	if *configPath != "" {
+		loaded, err := config.Load(*configPath)
+		if err != nil {
+			return fmt.Errorf("configuration loading (%s): %w", *configPath, err)
+		}
+		cfg = loaded
+	}
+
+	rounds := []int{0}
+	if *threads != "" {
+		rounds = nil
+		for _, value := range strings.Split(*threads, ",") {
+			count, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || count < 1 {
+				return fmt.Errorf("invalid thread count %q", value)
+			}
+			rounds = append(rounds, count)
+		}
+	}
+
+	options := service.BenchOptions{
+		Duration: *duration,
+		Shop:     mockshop.Options{Latency: *latency, Jitter: *jitter, ErrorRate: *errorRate},
+		Listen:   *listen,
+		ShopURL:  *shopURL,
+		NoProxy:  *noProxy,
+	}
+
+	var reports []*service.BenchReport
+	for _, count := range rounds {
+		roundCfg := cfg
+		if count > 0 {
+			clone, err := cfg.Clone()
+			if err != nil {
+				return err
+			}
+			// Fixed thread count replaces autoscaling, every round measures one size
+			for i := range clone.Accounts {
+				clone.Accounts[i].Threads = count
+				clone.Accounts[i].Autoscale = nil
+			}
+			roundCfg = clone
+		}
+
+		if !*asJSON {
+			label := "threads of config"
+			if count > 0 {
+				label = fmt.Sprintf("%d threads per account", count)
+			}
+			fmt.Printf("⏱️ Bench: %s, %s against mock shop (latency %s)...\n", label, *duration, *latency)
+		}
+		report, err := service.RunBench(roundCfg, options)
+		if err != nil {
+			return err
+		}
+		reports = append(reports, report)
+		if !*asJSON {
+			printBenchReport(report)
+		}
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(reports)
+	}
+	if len(reports) > 1 {
+		printBenchSummary(reports)
+	}
+	return nil
+}
+
+// printBenchReport prints totals and accounts of one bench round
+func printBenchReport(report *service.BenchReport) {
+	fmt.Println(strings.Repeat("-", 80))
+	fmt.Printf("📊 Threads: %d | Requests: %d | Failed: %d | RPS: %.1f | Mock shop received: %d\n",
+		report.Threads, report.Requests, report.Failed, report.RPS, report.ShopOrders)
+	fmt.Printf("⏱️ Latency:    %s\n", formatBenchLatency(report.Latency))
+	fmt.Printf("🚦 Queue wait: %s\n", formatBenchLatency(report.QueueWait))
+	fmt.Printf("🔒 Mutex wait: %s total, %s per request\n", report.MutexWait,
+		perRequest(report.MutexWait, report.Requests))
+
+	for _, account := range report.Accounts {
+		fmt.Printf("   %-20s %3d threads via %-20s RPS: %7.1f | Failed: %d | p50 %s / p99 %s\n",
+			account.Name, account.Threads, account.Proxy, account.RPS, account.Failed,
+			account.Latency.P50.Round(time.Millisecond), account.Latency.P99.Round(time.Millisecond))
+	}
+	fmt.Println()
+}
+
+// printBenchSummary compares rounds of different thread counts
+func printBenchSummary(reports []*service.BenchReport) {
+	fmt.Println("📈 Summary:")
+	fmt.Printf("   %8s %10s %10s %10s %12s\n", "Threads", "RPS", "p50", "p99", "Queue p99")
+	for _, report := range reports {
+		fmt.Printf("   %8d %10.1f %10s %10s %12s\n", report.Threads, report.RPS,
+			report.Latency.P50.Round(time.Millisecond), report.Latency.P99.Round(time.Millisecond),
+			report.QueueWait.P99.Round(time.Millisecond))
+	}
+}
+
+// formatBenchLatency formats distribution of durations
+func formatBenchLatency(latency service.BenchLatency) string {
+	return fmt.Sprintf("p50 %s / p90 %s / p99 %s / max %s", latency.P50.Round(time.Microsecond),
+		latency.P90.Round(time.Microsecond), latency.P99.Round(time.Microsecond), latency.Max.Round(time.Microsecond))
+}
+
+// perRequest returns share of duration per request
+func perRequest(duration time.Duration, requests int) time.Duration {
+	if requests == 0 {
+		return 0
+	}
+	return duration / time.Duration(requests)
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"stickersbot/internal/api"
+	"stickersbot/internal/service"
+	"stickersbot/internal/types"
+)
+
+// controlAPI exposes CLI actions to HTTP control API
+type controlAPI struct {
+	cli *CLI
+}
+
+// startControlAPI starts HTTP control API in background
+func (c *CLI) startControlAPI() {
+	cfg := c.config.ControlAPI
+	c.controlAPI = api.NewServer(cfg.Listen, cfg.Token, &controlAPI{cli: c})
+	c.controlAPI.Start(func(err error) {
+		fmt.Printf("❌ Control API error: %v\n", err)
+	})
+
+	fmt.Printf("🌐 Control API and dashboard listening on http://%s\n", c.controlAPI.Addr())
+}
+
+// startTelegramBot starts control through Telegram bot in background.
+// Bot owners also receive event notifications
+func (c *CLI) startTelegramBot() {
+	cfg := c.config.TelegramBot
+	bot := api.NewTelegramBot(cfg.Token, cfg.OwnerIDs, &controlAPI{cli: c})
+	bot.OnError = func(err error) {
+		fmt.Printf("⚠️ Telegram bot error: %v\n", err)
+	}
+
+	c.buyerService.Notifier().Register("telegram_bot", bot)
+	go bot.Run(context.Background())
+
+	fmt.Printf("🤖 Telegram bot control started (%d owners)\n", len(cfg.OwnerIDs))
+}
+
+// StartTask starts purchase/monitoring task
+func (a *controlAPI) StartTask() error {
+	if err := a.cli.startTask(); err != nil {
+		return err
+	}
+	fmt.Println("🚀 Task started through control API")
+	return nil
+}
+
+// StopTask stops running task
+func (a *controlAPI) StopTask() error {
+	if err := a.cli.stopTask(); err != nil {
+		return err
+	}
+	fmt.Println("🛑 Task stopped through control API")
+	return nil
+}
+
+// IsRunning checks if task is running
+func (a *controlAPI) IsRunning() bool {
+	return a.cli.buyerService.IsRunning()
+}
+
+// State returns lifecycle state of purchase service
+func (a *controlAPI) State() service.State {
+	return a.cli.buyerService.State()
+}
+
+// Statistics returns purchase statistics
+func (a *controlAPI) Statistics() *types.Statistics {
+	return a.cli.buyerService.GetStatistics()
+}
+
+// AccountStates returns runtime state of accounts
+func (a *controlAPI) AccountStates() []service.AccountState {
+	return a.cli.buyerService.GetAccountStates()
+}
+
+// PauseAccount pauses purchases of account
+func (a *controlAPI) PauseAccount(name string) error {
+	return a.cli.buyerService.PauseAccount(name)
+}
+
+// ResumeAccount resumes purchases of account
+func (a *controlAPI) ResumeAccount(name string) error {
+	return a.cli.buyerService.ResumeAccount(name)
+}
+
+// RefreshToken forcibly refreshes token of account
+func (a *controlAPI) RefreshToken(name string) error {
+	return a.cli.buyerService.RefreshToken(name)
+}
+
+// RefreshConfig fetches and applies remote configuration
+func (a *controlAPI) RefreshConfig() error {
+	if err := a.cli.refreshRemoteConfig(); err != nil {
+		return err
+	}
+	fmt.Println("🌍 Remote configuration refreshed through control API")
+	return nil
+}
+
+// Balances returns wallet balances of all accounts
+func (a *controlAPI) Balances(ctx context.Context) []service.WalletInfo {
+	return a.cli.walletService.GetAllBalances(ctx)
+}
+
+// Transactions returns latest sent transactions
+func (a *controlAPI) Transactions(limit int) ([]types.TransactionLog, error) {
+	return a.cli.storage.LoadTransactions(limit)
+}
+
+// Logs returns log lines after sequence number
+func (a *controlAPI) Logs(after int64) []service.LogLine {
+	lines, _ := a.cli.buyerService.Logs().Since(after)
+	return lines
+}
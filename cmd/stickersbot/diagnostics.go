@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"stickersbot/internal/service"
+)
+
+// diagnosticsWorstShown number of worst threads shown by diagnostics view
+const diagnosticsWorstShown = 10
+
+// handleShowDiagnostics shows request health of threads, proxies and snipe monitors
+func (c *CLI) handleShowDiagnostics() {
+	fmt.Println("🩺 Diagnostics")
+	fmt.Println(strings.Repeat("-", 80))
+
+	health := c.buyerService.Health()
+	workers := health.Workers()
+	proxies := health.Proxies()
+	monitors := c.buyerService.GetMonitorsHealth()
+	checks := c.buyerService.ProxyHealth()
+
+	if len(workers) == 0 && len(proxies) == 0 && len(monitors) == 0 && len(checks) == 0 {
+		fmt.Println("ℹ️  No requests yet, start the task first")
+		fmt.Print("Press Enter to continue...")
+		bufio.NewReader(os.Stdin).ReadLine()
+		return
+	}
+
+	if len(checks) > 0 {
+		fmt.Println("🩻 Proxy checks (dead first):")
+		for _, status := range checks {
+			if status.Alive {
+				fmt.Printf("   ✅ %-30s %s, checked %s\n", status.Name, status.Latency.Round(time.Millisecond), status.CheckedAt.Format("15:04:05"))
+			} else {
+				fmt.Printf("   💀 %-30s dead since %s, checked %s\n", status.Name, status.DeadSince.Format("15:04:05"), status.CheckedAt.Format("15:04:05"))
+				fmt.Printf("      last error: %s\n", status.LastError)
+			}
+			fmt.Printf("      accounts: %s\n", strings.Join(status.Accounts, ", "))
+		}
+		fmt.Println()
+	}
+
+	if len(proxies) > 0 {
+		fmt.Println("🌐 Proxies (worst first):")
+		fmt.Printf("   %-30s %8s %8s %6s %6s %9s\n", "proxy", "requests", "success", "403", "429", "avg")
+		for _, item := range proxies {
+			fmt.Printf("   %-30s %8d %7.1f%% %6d %6d %9s\n", item.Name, item.Requests, item.SuccessRate()*100,
+				item.Forbidden, item.RateLimited, item.AverageLatency().Round(time.Millisecond))
+			if until := c.buyerService.ProxyQuarantinedUntil(item.Name); !until.IsZero() {
+				fmt.Printf("      🚷 quarantined as banned until %s\n", until.Format("15:04:05"))
+			}
+			if item.LastError != "" {
+				fmt.Printf("      last error %s: %s\n", item.LastErrorAt.Format("15:04:05"), item.LastError)
+			}
+		}
+	}
+
+	if len(workers) > 0 {
+		fmt.Printf("\n🧵 Worst %d of %d threads:\n", min(diagnosticsWorstShown, len(workers)), len(workers))
+		for _, item := range workers[:min(diagnosticsWorstShown, len(workers))] {
+			printHealthStats(item)
+		}
+	}
+
+	if len(monitors) > 0 {
+		fmt.Println("\n🎯 Snipe monitors:")
+		for _, monitor := range monitors {
+			status := "✅"
+			if !monitor.Healthy {
+				status = "❌"
+			}
+			fmt.Printf("   %s %-30s failures in a row: %d, restarts: %d\n", status, monitor.AccountName, monitor.ConsecutiveFailures, monitor.Restarts)
+			if monitor.LastError != "" {
+				fmt.Printf("      last error: %s\n", monitor.LastError)
+			}
+		}
+	}
+
+	fmt.Print("\nPress Enter to continue...")
+	bufio.NewReader(os.Stdin).ReadLine()
+}
+
+// printHealthStats prints request health of thread or proxy
+func printHealthStats(item service.HealthStats) {
+	fmt.Printf("   %-30s %5.1f%% success of %d, avg %s\n",
+		item.Name, item.SuccessRate()*100, item.Requests, item.AverageLatency().Round(time.Millisecond))
+	if item.LastError != "" {
+		fmt.Printf("      last error %s: %s\n", item.LastErrorAt.Format("15:04:05"), item.LastError)
+	}
+}
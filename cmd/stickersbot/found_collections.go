@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"stickersbot/internal/monitor"
+)
+
+// foundCollectionsShown number of latest matches shown by viewer
+const foundCollectionsShown = 20
+
+// foundMatch matches of one collection:character aggregated across accounts
+type foundMatch struct {
+	CollectionID  int
+	Name          string
+	CharacterID   int
+	CharacterName string
+	PriceTON      float64
+	Supply        int
+	FirstFound    time.Time
+	LastFound     time.Time
+	Matches       int
+	Accounts      []string
+}
+
+// handleShowFoundCollections shows snipe matches of all accounts
+func (c *CLI) handleShowFoundCollections() {
+	fmt.Println("📜 Found collections (snipe matches)")
+	fmt.Println(strings.Repeat("-", 80))
+
+	collections, err := c.storage.LoadFoundCollections()
+	if err != nil {
+		fmt.Printf("❌ Error reading found collections: %v\n", err)
+		fmt.Print("Press Enter to continue...")
+		bufio.NewReader(os.Stdin).ReadLine()
+		return
+	}
+
+	if len(collections) == 0 {
+		fmt.Println("ℹ️  No matches found yet")
+		fmt.Print("Press Enter to continue...")
+		bufio.NewReader(os.Stdin).ReadLine()
+		return
+	}
+
+	matches, perAccount := aggregateFoundCollections(collections)
+
+	fmt.Printf("📊 Total matches: %d, unique characters: %d\n\n", len(collections), len(matches))
+
+	fmt.Println("👤 Matches per account:")
+	accounts := make([]string, 0, len(perAccount))
+	for account := range perAccount {
+		accounts = append(accounts, account)
+	}
+	sort.Strings(accounts)
+	for _, account := range accounts {
+		fmt.Printf("   %-30s %d\n", account, perAccount[account])
+	}
+
+	fmt.Printf("\n🕒 Latest %d characters:\n", min(foundCollectionsShown, len(matches)))
+	start := max(len(matches)-foundCollectionsShown, 0)
+	for _, match := range matches[start:] {
+		name := match.Name
+		if match.CharacterName != "" {
+			name = fmt.Sprintf("%s / %s", match.Name, match.CharacterName)
+		}
+
+		fmt.Printf("   %s  #%d:%d %s\n", match.LastFound.Format("2006-01-02 15:04:05"), match.CollectionID, match.CharacterID, name)
+		fmt.Printf("      💰 %.2f TON, supply %d, matched %d times by: %s\n",
+			match.PriceTON, match.Supply, match.Matches, strings.Join(match.Accounts, ", "))
+	}
+
+	fmt.Print("\nPress Enter to continue...")
+	bufio.NewReader(os.Stdin).ReadLine()
+}
+
+// aggregateFoundCollections groups matches by collection:character ordered by last match
+// and counts matches per account
+func aggregateFoundCollections(collections []monitor.FoundCollection) ([]*foundMatch, map[string]int) {
+	byKey := make(map[string]*foundMatch)
+	perAccount := make(map[string]int)
+	var matches []*foundMatch
+
+	for _, item := range collections {
+		perAccount[item.AccountName]++
+
+		key := fmt.Sprintf("%d:%d", item.ID, item.CharacterID)
+		match, exists := byKey[key]
+		if !exists {
+			match = &foundMatch{
+				CollectionID:  item.ID,
+				Name:          item.Name,
+				CharacterID:   item.CharacterID,
+				CharacterName: item.CharacterName,
+				FirstFound:    item.FoundAt,
+			}
+			byKey[key] = match
+			matches = append(matches, match)
+		}
+
+		match.Matches++
+		match.PriceTON = item.PriceTON
+		match.Supply = item.Supply
+		match.LastFound = item.FoundAt
+
+		known := false
+		for _, account := range match.Accounts {
+			if account == item.AccountName {
+				known = true
+				break
+			}
+		}
+		if !known {
+			match.Accounts = append(match.Accounts, item.AccountName)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].LastFound.Before(matches[j].LastFound)
+	})
+
+	return matches, perAccount
+}
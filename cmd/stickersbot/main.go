@@ -3,16 +3,25 @@ package main
 import (
 	"bufio"
 	"context"
+	"flag"
 	"fmt"
+	"log"
 	"math/big"
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"stickersbot/internal/api"
 	"stickersbot/internal/client"
 	"stickersbot/internal/config"
+	"stickersbot/internal/logfile"
+	"stickersbot/internal/monitor"
+	"stickersbot/internal/notify"
+	"stickersbot/internal/redact"
 	"stickersbot/internal/service"
+	"stickersbot/internal/telegram"
 )
 
 // CLI represents the command line interface
@@ -21,14 +30,17 @@ type CLI struct {
 	authIntegration *service.AuthIntegration
 	buyerService    *service.BuyerService
 	tokenManager    *service.TokenManager
+	storage         service.Storage
 	walletService   *service.WalletService
-	isRunning       bool
+	taskMu          sync.Mutex // Serializes task start/stop from menu and control API
 	stopChan        chan struct{}
+	controlAPI      *api.Server
+	shutdownOnce    sync.Once // Menu exit and stop signal tear down only once
 }
 
 // printHeader displays the ASCII art header with project info
 func printHeader() {
-	fmt.Println(`
+	fmt.Print(`
 ╔══════════════════════════════════════════════════════════════════════════════╗
 ║                                                                              ║
 ║    ████████╗███████╗██╗     ███████╗ ██████╗ ██████╗  █████╗ ███╗   ███╗    ║
@@ -61,11 +73,57 @@ func printHeader() {
 ║                                                                              ║
 ╚══════════════════════════════════════════════════════════════════════════════╝
 `)
+	fmt.Printf("  %s\n\n", buildString())
 }
 
 func main() {
-	// Display header
-	printHeader()
+	fillBuildInfo()
+
+	stateDir := flag.String("state-dir", "", "directory of config.json, sessions, tokens and logs (default: current directory)")
+	noRedact := flag.Bool("no-redact", false, "do not mask tokens, seed phrases and proxy credentials in logs (debugging only)")
+	verbose := flag.Bool("verbose", false, "log response bodies of all order requests, not only of failed ones")
+	showVersion := flag.Bool("version", false, "print version and build information and exit")
+	serviceMode := flag.Bool("service", false, "run without menu as system service: start the task right away, plain output, stop on SIGTERM")
+	workDir := flag.String("workdir", "", "change to this directory before start (used by installed Windows services)")
+	flag.Parse()
+
+	if *workDir != "" {
+		if err := os.Chdir(*workDir); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(exitConfig)
+		}
+	}
+
+	// Version is printed without header, e.g. for scripts
+	if *showVersion || flag.Arg(0) == "version" {
+		printVersion()
+		return
+	}
+
+	// Display header, service logs and backtest and bench reports get plain text only
+	if !*serviceMode && flag.Arg(0) != "backtest" && flag.Arg(0) != "bench" {
+		printHeader()
+	}
+
+	config.SetStateDir(*stateDir)
+	service.SetVerbose(*verbose)
+
+	// Every log sink goes through redaction
+	redact.SetEnabled(!*noRedact)
+	log.SetOutput(redact.Writer(os.Stderr))
+
+	if *serviceMode {
+		os.Exit(serve(*stateDir == ""))
+	}
+
+	// Subcommands run without the menu
+	if flag.NArg() > 0 {
+		if err := runCommand(flag.Args(), *stateDir == ""); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
 
 	// Initialize CLI
 	cli := &CLI{
@@ -73,11 +131,23 @@ func main() {
 	}
 
 	// Load and validate configuration
-	if err := cli.initializeConfig(); err != nil {
+	if err := cli.initializeConfig(*stateDir == ""); err != nil {
 		cli.handleError("Configuration loading error", err)
 		return
 	}
 
+	checkCompatibility()
+
+	// Only one instance may work with the files of the state directory
+	lock, err := service.AcquireInstanceLock(config.StatePath(service.LockFile))
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		fmt.Print("\nPress Enter to exit...")
+		bufio.NewReader(os.Stdin).ReadLine()
+		return
+	}
+	defer lock.Release()
+
 	//// Perform license check
 	//if err := cli.checkLicense(); err != nil {
 	//	cli.handleError("License check error", err)
@@ -90,13 +160,20 @@ func main() {
 		return
 	}
 
+	// Ctrl+C and SIGTERM stop the task like the menu does instead of killing payments midway
+	go cli.shutdownOnSignal(signalStop(), lock)
+
+	// Previous run that crashed may be continued
+	cli.offerResume()
+
 	// Start CLI menu
 	cli.runMainMenu()
 }
 
-// initializeConfig loads and validates configuration
-func (c *CLI) initializeConfig() error {
-	cfgPath := "./config.json"
+// initializeConfig loads and validates configuration. State directory of config is used
+// unless it was already set by command line
+func (c *CLI) initializeConfig(useConfigStateDir bool) error {
+	cfgPath := config.StatePath(config.ConfigFile)
 	cfg, err := config.Load(cfgPath)
 	if err != nil {
 		return fmt.Errorf("configuration loading (%s): %w", cfgPath, err)
@@ -105,34 +182,258 @@ func (c *CLI) initializeConfig() error {
 	fmt.Printf("📋 Configuration loaded: %s\n", cfgPath)
 	c.config = cfg
 
+	if useConfigStateDir && cfg.StateDir != "" {
+		config.SetStateDir(cfg.StateDir)
+	}
+	if err := os.MkdirAll(config.StateDir(), 0755); err != nil {
+		return fmt.Errorf("creating state directory: %w", err)
+	}
+	if config.StateDir() != "." {
+		fmt.Printf("📁 State directory: %s\n", config.StateDir())
+	}
+
+	// Centrally managed targets replace local ones before anything uses them
+	if cfg.RemoteConfig != nil {
+		if err := applyRemoteConfig(cfg); err != nil {
+			return fmt.Errorf("remote configuration: %w", err)
+		}
+	}
+	registerSecrets(cfg)
+
+	if err := loadProxyAuth(cfg); err != nil {
+		return fmt.Errorf("proxy credentials loading: %w", err)
+	}
+
 	// Validate configuration
-	if err := c.validateConfig(); err != nil {
+	if err := c.validateConfig(c.config); err != nil {
 		return fmt.Errorf("configuration validation: %w", err)
 	}
 
 	return nil
 }
 
+// loadProxyAuth loads credentials of proxies whose proxy_url has none
+func loadProxyAuth(cfg *config.Config) error {
+	path := cfg.ProxyAuthPath()
+	if cfg.ProxyAuthFile != "" {
+		// File set explicitly must exist
+		if _, err := os.Stat(path); err != nil {
+			return err
+		}
+	}
+
+	auth, err := config.LoadProxyAuth(path)
+	if err != nil {
+		return err
+	}
+	if len(auth) > 0 {
+		fmt.Printf("🔑 Proxy credentials loaded: %s (%d entries)\n", path, len(auth))
+	}
+	for _, credentials := range auth {
+		redact.AddSecret(credentials.Password)
+	}
+	config.SetProxyAuth(auth)
+	return nil
+}
+
+// registerSecrets makes secrets of configuration masked in all log output
+func registerSecrets(cfg *config.Config) {
+	for _, account := range cfg.Accounts {
+		redact.AddSecret(account.AuthToken, account.APIHash, account.SeedPhrase, account.ProxyURL, account.TelegramProxyURL, account.TONProxyURL)
+		// Keychain is not queried here, resolved password is not logged anyway
+		if !strings.HasPrefix(account.TwoFactorPassword, config.SecretKeyringPrefix) {
+			if password, err := config.ResolveSecret(account.TwoFactorPassword); err == nil {
+				redact.AddSecret(password)
+			}
+		}
+	}
+	redact.AddSecret(cfg.LicenseKey)
+	if cfg.TelegramBot != nil {
+		redact.AddSecret(cfg.TelegramBot.Token)
+	}
+	if cfg.ControlAPI != nil {
+		redact.AddSecret(cfg.ControlAPI.Token)
+	}
+	if cfg.Notifications != nil {
+		redact.AddSecret(cfg.Notifications.WebhookURL)
+		if chat := cfg.Notifications.Telegram; chat != nil {
+			addSecretRefs(chat.BotToken)
+		}
+		if email := cfg.Notifications.Email; email != nil {
+			addSecretRefs(email.Password)
+		}
+		if ntfy := cfg.Notifications.Ntfy; ntfy != nil {
+			addSecretRefs(ntfy.Topic, ntfy.Token)
+		}
+		if pushover := cfg.Notifications.Pushover; pushover != nil {
+			addSecretRefs(pushover.AppToken, pushover.UserKey)
+		}
+		for _, webhook := range cfg.Notifications.Webhooks {
+			addSecretRefs(webhook.URL)
+			for _, value := range webhook.Headers {
+				addSecretRefs(value)
+			}
+		}
+	}
+	if cfg.RemoteConfig != nil {
+		redact.AddSecret(cfg.RemoteConfig.HMACSecret, cfg.RemoteConfig.Token)
+	}
+	if cfg.Storage != nil && cfg.Storage.Tokens != nil {
+		addSecretRefs(cfg.Storage.Tokens.Password, cfg.Storage.Tokens.Token)
+	}
+	if cfg.Coordination != nil {
+		addSecretRefs(cfg.Coordination.Password)
+	}
+	redact.SetAddresses(cfg.Logging != nil && cfg.Logging.RedactAddresses)
+}
+
+// addSecretRefs masks values that may be secret references. Keychain is not queried,
+// resolved keychain secrets are not logged anyway
+func addSecretRefs(values ...string) {
+	for _, value := range values {
+		if strings.HasPrefix(value, config.SecretKeyringPrefix) {
+			continue
+		}
+		if resolved, err := config.ResolveSecret(value); err == nil {
+			redact.AddSecret(resolved)
+		}
+	}
+}
+
 // validateConfig performs comprehensive configuration validation
-func (c *CLI) validateConfig() error {
+func (c *CLI) validateConfig(cfg *config.Config) error {
 	var errors []string
 
 	// Check basic configuration validity
-	if !c.config.IsValid() {
+	if !cfg.IsValid() {
 		errors = append(errors, "Basic configuration is invalid")
 	}
 
 	// Check if there are accounts
-	if len(c.config.Accounts) == 0 {
+	if len(cfg.Accounts) == 0 {
 		errors = append(errors, "No accounts configured")
 	}
 
 	// Check each account
-	for i, account := range c.config.Accounts {
-		accountErrors := c.validateAccount(i+1, account)
+	for i, account := range cfg.Accounts {
+		accountErrors := c.validateAccount(cfg, i+1, account)
 		errors = append(errors, accountErrors...)
 	}
 
+	// Check snipe strategy
+	if err := service.ValidateStrategy(cfg.SnipeStrategy); err != nil {
+		errors = append(errors, err.Error())
+	}
+
+	// Check automatic stop
+	if cfg.RunFor != "" {
+		if duration, err := time.ParseDuration(cfg.RunFor); err != nil || duration <= 0 {
+			errors = append(errors, fmt.Sprintf("run_for: invalid duration %q (e.g. \"90m\" or \"2h30m\")", cfg.RunFor))
+		}
+	}
+	if cfg.StopAt != "" {
+		if _, err := config.ParseTime(cfg.StopAt); err != nil {
+			errors = append(errors, fmt.Sprintf("stop_at: %v", err))
+		}
+	}
+
+	// Check wallet payment backlog
+	if cfg.MaxPendingPayments > client.TransactionQueueSize {
+		errors = append(errors, fmt.Sprintf("max_pending_payments: %d exceeds wallet transaction queue size %d",
+			cfg.MaxPendingPayments, client.TransactionQueueSize))
+	}
+
+	// Check fault injection
+	if cfg.Chaos != nil {
+		if err := cfg.Chaos.Validate(cfg.TestMode); err != nil {
+			errors = append(errors, err.Error())
+		}
+	}
+
+	// Check control API
+	if cfg.ControlAPI != nil && cfg.ControlAPI.Enabled && len(cfg.ControlAPI.Token) < 16 {
+		errors = append(errors, "control_api: token must be at least 16 characters")
+	}
+
+	// Check Telegram bot control
+	if bot := cfg.TelegramBot; bot != nil && bot.Enabled {
+		if bot.Token == "" {
+			errors = append(errors, "telegram_bot: token not specified")
+		}
+		if len(bot.OwnerIDs) == 0 {
+			errors = append(errors, "telegram_bot: owner_ids must contain at least one Telegram user ID")
+		}
+	}
+
+	// Check Telegram notifications
+	if cfg.Notifications != nil {
+		if chat := cfg.Notifications.Telegram; chat != nil && chat.Enabled {
+			if chat.BotToken == "" {
+				errors = append(errors, "notifications.telegram: bot_token not specified")
+			} else if _, err := config.ResolveSecret(chat.BotToken); err != nil {
+				errors = append(errors, fmt.Sprintf("notifications.telegram: bot_token: %v", err))
+			}
+			if chat.ChatID == "" {
+				errors = append(errors, "notifications.telegram: chat_id not specified")
+			}
+		}
+		if email := cfg.Notifications.Email; email != nil && email.Enabled {
+			if email.SMTPHost == "" {
+				errors = append(errors, "notifications.email: smtp_host not specified")
+			}
+			if email.From == "" || len(email.To) == 0 {
+				errors = append(errors, "notifications.email: from and to must be specified")
+			}
+			if _, err := config.ResolveSecret(email.Password); err != nil {
+				errors = append(errors, fmt.Sprintf("notifications.email: password: %v", err))
+			}
+		}
+		if ntfy := cfg.Notifications.Ntfy; ntfy != nil && ntfy.Enabled {
+			if ntfy.Topic == "" {
+				errors = append(errors, "notifications.ntfy: topic not specified")
+			}
+			errors = append(errors, pushErrors("ntfy", ntfy.MinSeverity, ntfy.Topic, ntfy.Token)...)
+		}
+		if pushover := cfg.Notifications.Pushover; pushover != nil && pushover.Enabled {
+			if pushover.AppToken == "" || pushover.UserKey == "" {
+				errors = append(errors, "notifications.pushover: app_token and user_key must be specified")
+			}
+			errors = append(errors, pushErrors("pushover", pushover.MinSeverity, pushover.AppToken, pushover.UserKey)...)
+		}
+		for _, rule := range cfg.Notifications.Alerts {
+			if err := rule.Validate(); err != nil {
+				errors = append(errors, fmt.Sprintf("notifications.alerts: %v", err))
+			}
+			if _, err := monitor.ParseActiveWindow(rule.ActiveFrom, rule.ActiveUntil); err != nil {
+				errors = append(errors, fmt.Sprintf("notifications.alerts: alert %s: %v", rule.RuleName(), err))
+			}
+			if _, err := notify.ParseSeverity(rule.Severity); err != nil {
+				errors = append(errors, fmt.Sprintf("notifications.alerts: alert %s: severity: %v", rule.RuleName(), err))
+			}
+		}
+		if cfg.Notifications.LowBalance < 0 || cfg.Notifications.AuthFailureLimit < 0 {
+			errors = append(errors, "notifications: low_balance and auth_failure_limit must not be negative")
+		}
+		for i, webhook := range cfg.Notifications.Webhooks {
+			name := webhook.WebhookName(i)
+			if webhook.URL == "" {
+				errors = append(errors, fmt.Sprintf("notifications.webhooks: %s: url not specified", name))
+			} else if _, err := config.ResolveSecret(webhook.URL); err != nil {
+				errors = append(errors, fmt.Sprintf("notifications.webhooks: %s: url: %v", name, err))
+			}
+			if webhook.Template != "" {
+				if _, err := notify.ParseTemplate(webhook.Template); err != nil {
+					errors = append(errors, fmt.Sprintf("notifications.webhooks: %s: %v", name, err))
+				}
+			}
+			for header, value := range webhook.Headers {
+				if _, err := config.ResolveSecret(value); err != nil {
+					errors = append(errors, fmt.Sprintf("notifications.webhooks: %s: header %s: %v", name, header, err))
+				}
+			}
+		}
+	}
+
 	// Individual API validation is now handled in validateAccount function
 	// Each account must have its own API credentials
 
@@ -148,8 +449,22 @@ func (c *CLI) validateConfig() error {
 	return nil
 }
 
+// pushErrors validates minimal severity and secret references of push notification service
+func pushErrors(name, minSeverity string, secrets ...string) []string {
+	var errors []string
+	if _, err := notify.ParseSeverity(minSeverity); err != nil {
+		errors = append(errors, fmt.Sprintf("notifications.%s: min_severity: %v", name, err))
+	}
+	for _, secret := range secrets {
+		if _, err := config.ResolveSecret(secret); err != nil {
+			errors = append(errors, fmt.Sprintf("notifications.%s: %v", name, err))
+		}
+	}
+	return errors
+}
+
 // validateAccount validates individual account configuration
-func (c *CLI) validateAccount(num int, account config.Account) []string {
+func (c *CLI) validateAccount(cfg *config.Config, num int, account config.Account) []string {
 	var errors []string
 	prefix := fmt.Sprintf("Account %d (%s)", num, account.Name)
 
@@ -180,6 +495,23 @@ func (c *CLI) validateAccount(num int, account config.Account) []string {
 			errors = append(errors, prefix+": api_hash not specified (required for phone authentication)")
 		}
 	}
+	if account.LoginMethod != "" && account.LoginMethod != config.LoginCode && account.LoginMethod != config.LoginQR {
+		errors = append(errors, prefix+": login_method must be \"code\" or \"qr\"")
+	}
+	if _, err := service.NewCodeProvider(cfg, account); err != nil {
+		errors = append(errors, prefix+": "+err.Error())
+	}
+	// Keychain is not queried here, it may ask to unlock
+	if strings.HasPrefix(account.TwoFactorPassword, config.SecretEnvPrefix) {
+		if _, err := config.ResolveSecret(account.TwoFactorPassword); err != nil {
+			errors = append(errors, prefix+": two_factor_password: "+err.Error())
+		}
+	}
+	for j, app := range account.WebApps {
+		if app.BotUsername == "" || app.WebAppURL == "" {
+			errors = append(errors, fmt.Sprintf("%s: web_apps[%d] needs bot_username and web_app_url", prefix, j))
+		}
+	}
 
 	// Check seed phrase
 	if account.SeedPhrase == "" {
@@ -197,15 +529,31 @@ func (c *CLI) validateAccount(num int, account config.Account) []string {
 	}
 
 	// Check proxy settings
+	if cfg.ProxyRequired && !account.UseProxy {
+		errors = append(errors, prefix+": proxy_required is set, but use_proxy is disabled")
+	}
+	if cfg.ProxyRequired && account.DirectFallback != nil {
+		errors = append(errors, prefix+": proxy_required is set, direct_fallback is not allowed")
+	}
 	if account.UseProxy {
 		if account.ProxyURL == "" {
 			errors = append(errors, prefix+": use_proxy is enabled but proxy_url is not specified")
 		} else {
 			// Validate proxy URL format
-			if err := validateProxyURL(account.ProxyURL); err != nil {
+			if _, err := config.ParseProxyChain(account.ProxyURL); err != nil {
 				errors = append(errors, prefix+": "+err.Error())
 			}
 		}
+		if account.TelegramProxyURL != "" {
+			if _, err := config.ParseProxyChain(account.TelegramProxyURL); err != nil {
+				errors = append(errors, prefix+": telegram_proxy_url: "+err.Error())
+			}
+		}
+		if account.TONProxyURL != "" {
+			if _, err := config.ParseProxyChain(account.TONProxyURL); err != nil {
+				errors = append(errors, prefix+": ton_proxy_url: "+err.Error())
+			}
+		}
 	}
 
 	// Check collection and character
@@ -213,50 +561,92 @@ func (c *CLI) validateAccount(num int, account config.Account) []string {
 		errors = append(errors, prefix+": collection must be greater than 0")
 	}
 
-	// Check currency
-	if account.Currency == "" {
-		errors = append(errors, prefix+": currency not specified")
+	// Check fallback targets
+	for i, target := range account.Fallbacks {
+		if target.Collection <= 0 || target.Character <= 0 {
+			errors = append(errors, fmt.Sprintf("%s: fallbacks[%d]: collection and character must be greater than 0", prefix, i))
+		}
 	}
 
-	// Check count
-	if account.Count <= 0 {
-		errors = append(errors, prefix+": count must be greater than 0")
+	// Check buy-until-sold-out mode
+	if account.SnipeMonitor != nil && account.SnipeMonitor.Enabled && account.HasUntilSoldOut() {
+		errors = append(errors, prefix+": until_sold_out is not used in snipe mode")
 	}
 
-	return errors
-}
+	// Check scheduled start
+	if account.StartAt != "" {
+		if account.SnipeMonitor != nil && account.SnipeMonitor.Enabled {
+			errors = append(errors, prefix+": start_at is not used in snipe mode, use snipe_monitor.active_from")
+		} else if _, err := config.ParseTime(account.StartAt); err != nil {
+			errors = append(errors, fmt.Sprintf("%s: start_at: %v", prefix, err))
+		}
+	}
 
-// validateProxyURL validates proxy URL format
-func validateProxyURL(proxyURL string) error {
-	parts := strings.Split(proxyURL, ":")
-	if len(parts) != 2 && len(parts) != 4 {
-		return fmt.Errorf("invalid proxy URL format, expected host:port or host:port:user:pass")
+	// Check retry policy
+	if account.Retry != nil {
+		if account.Retry.MaxAttempts < 1 {
+			errors = append(errors, prefix+": retry.max_attempts must be at least 1")
+		}
+		if account.Retry.BackoffMs < 0 || account.Retry.MaxBackoffMs < 0 {
+			errors = append(errors, prefix+": retry backoff can't be negative")
+		}
+		for _, class := range account.Retry.RetryOn {
+			switch class {
+			case config.RetryOnRateLimit, config.RetryOnServer, config.RetryOnNetwork:
+			default:
+				errors = append(errors, fmt.Sprintf("%s: retry.retry_on: unknown error class '%s' (use \"429\", \"5xx\" or \"network\")", prefix, class))
+			}
+		}
 	}
 
-	// Validate host
-	if parts[0] == "" {
-		return fmt.Errorf("proxy host cannot be empty")
+	// Check thread autoscaling
+	if scale := account.Autoscale; scale != nil {
+		if account.SnipeMonitor != nil && account.SnipeMonitor.Enabled {
+			errors = append(errors, prefix+": autoscale is not used in snipe mode, use snipe_monitor.parallel_orders")
+		}
+		if scale.MinThreads < 1 || scale.MaxThreads < scale.MinThreads {
+			errors = append(errors, prefix+": autoscale requires 1 <= min_threads <= max_threads")
+		}
+		if scale.IntervalSeconds < 0 || scale.SlowLatencyMs < 0 {
+			errors = append(errors, prefix+": autoscale interval and latency can't be negative")
+		}
+		if scale.MaxErrorRate < 0 || scale.MaxErrorRate > 1 {
+			errors = append(errors, prefix+": autoscale.max_error_rate must be between 0 and 1")
+		}
 	}
 
-	// Validate port
-	if parts[1] == "" {
-		return fmt.Errorf("proxy port cannot be empty")
+	// Check currency
+	if account.Currency == "" {
+		errors = append(errors, prefix+": currency not specified")
 	}
-	if _, err := strconv.Atoi(parts[1]); err != nil {
-		return fmt.Errorf("proxy port must be a number")
+
+	// Check count
+	if account.Count <= 0 {
+		errors = append(errors, prefix+": count must be greater than 0")
 	}
 
-	// If auth is provided, validate user and pass
-	if len(parts) == 4 {
-		if parts[2] == "" {
-			return fmt.Errorf("proxy username cannot be empty when authentication is provided")
+	// Check snipe filter expression
+	if account.SnipeMonitor != nil && account.SnipeMonitor.Filter != "" {
+		if err := monitor.ValidateFilter(account.SnipeMonitor.Filter); err != nil {
+			errors = append(errors, prefix+": "+err.Error())
 		}
-		if parts[3] == "" {
-			return fmt.Errorf("proxy password cannot be empty when authentication is provided")
+	}
+
+	// Check snipe active window
+	if account.SnipeMonitor != nil {
+		if err := monitor.ValidateSnipeWindow(account.SnipeMonitor); err != nil {
+			errors = append(errors, prefix+": "+err.Error())
 		}
 	}
 
-	return nil
+	return errors
+}
+
+// notifyLicenseFailed sends critical alert about failed license verification
+func (c *CLI) notifyLicenseFailed(err error) {
+	if c.buyerService != nil {
+		c.buyerService.NotifyLicenseFailed(err)
+	}
 }
 
 // checkLicense performs license validation (currently disabled for development)
@@ -271,13 +661,14 @@ func (c *CLI) checkLicense() error {
 		}
 
 		// Authenticate license key
-		err := authenticate(c.config.LicenseKey)
+		grace := licenseGrace(c.config)
+		err := authenticateWithRetry(c.config.LicenseKey, grace)
 		if err != nil {
 			return fmt.Errorf("license authentication: %w", err)
 		}
 
 		fmt.Println("✅ License authenticated successfully")
-		startVerifier(c.config.LicenseKey)
+		startVerifier(c.config.LicenseKey, grace, c.notifyLicenseFailed)
 	} else {
 		fmt.Println("🧪 Running in development mode (license check disabled)")
 		if c.config.LicenseKey == "" {
@@ -303,19 +694,42 @@ func (c *CLI) initializeServices() error {
 	}
 
 	// Create sessions folder if it doesn't exist
-	if err := os.MkdirAll("sessions", 0755); err != nil {
+	if err := os.MkdirAll(config.StatePath(service.SessionsDir), 0755); err != nil {
 		return fmt.Errorf("creating sessions folder: %w", err)
 	}
 
-	// Create token manager
-	c.tokenManager = service.NewTokenManager(c.config)
+	// Open storage of tokens, transactions and found collections
+	storage, err := service.OpenStorage(c.config)
+	if err != nil {
+		return fmt.Errorf("opening storage: %w", err)
+	}
+	c.storage = storage
+
+	// Create token manager, the only one of the process
+	c.tokenManager, err = service.NewTokenManager(c.config, c.storage)
+	if err != nil {
+		return fmt.Errorf("creating token manager: %w", err)
+	}
 
 	// Create buyer service
-	c.buyerService = service.NewBuyerService(c.config)
+	c.buyerService, err = service.NewBuyerService(c.config, c.tokenManager, c.storage)
+	if err != nil {
+		return fmt.Errorf("creating buyer service: %w", err)
+	}
 
 	// Create wallet service
 	c.walletService = service.NewWalletService(c.config)
 
+	// Start control API
+	if c.config.ControlAPI != nil && c.config.ControlAPI.Enabled {
+		c.startControlAPI()
+	}
+
+	// Start control through Telegram bot
+	if c.config.TelegramBot != nil && c.config.TelegramBot.Enabled {
+		c.startTelegramBot()
+	}
+
 	fmt.Println("✅ Services initialized")
 	return nil
 }
@@ -341,7 +755,7 @@ func (c *CLI) runMainMenu() {
 	for {
 		c.printMainMenu()
 
-		fmt.Print("Select menu option (1-6): ")
+		fmt.Print("Select menu option (1-12): ")
 		input, _ := reader.ReadString('\n')
 		choice := strings.TrimSpace(input)
 
@@ -357,7 +771,19 @@ func (c *CLI) runMainMenu() {
 		case "5":
 			c.handleCheckDeployWallets()
 		case "6":
-			fmt.Println("👋 Goodbye!")
+			c.handleShowFoundCollections()
+		case "7":
+			c.handleEditSnipeFilters()
+		case "8":
+			c.handleShowStatsHistory()
+		case "9":
+			c.handleShowSpendReport()
+		case "10":
+			c.handleShowTransactionHistory()
+		case "11":
+			c.handleShowDiagnostics()
+		case "12":
+			c.shutdown()
 			return
 		default:
 			fmt.Println("❌ Invalid choice. Please try again.")
@@ -374,8 +800,13 @@ func (c *CLI) printMainMenu() {
 	fmt.Println(strings.Repeat("=", 60))
 
 	status := "⭕ Stopped"
-	if c.isRunning {
+	switch c.buyerService.State() {
+	case service.StateStarting:
+		status = "🔄 Starting"
+	case service.StateRunning:
 		status = "🟢 Running"
+	case service.StateDraining:
+		status = "⏳ Stopping (finishing purchases in progress)"
 	}
 
 	fmt.Printf("Status: %s\n", status)
@@ -385,58 +816,124 @@ func (c *CLI) printMainMenu() {
 	fmt.Println("3. 🔐 Manage account authentication")
 	fmt.Println("4. 💰 Show wallet balances")
 	fmt.Println("5. 🔧 Check/Deploy wallets")
-	fmt.Println("6. 🚪 Exit")
+	fmt.Println("6. 📜 Show found collections")
+	fmt.Println("7. ✏️  Edit snipe filters")
+	fmt.Println("8. 📊 Statistics history")
+	fmt.Println("9. 💸 Spend report")
+	fmt.Println("10. 🧾 Transaction history")
+	fmt.Println("11. 🩺 Diagnostics")
+	fmt.Println("12. 🚪 Exit")
 	fmt.Println(strings.Repeat("=", 60))
 }
 
 // handleStartTask handles task start
 func (c *CLI) handleStartTask() {
-	if c.isRunning {
+	if c.buyerService.IsRunning() {
 		fmt.Println("⚠️  Task is already running! Stop current task first.")
 		return
 	}
 
 	fmt.Println("🔄 Preparing to start...")
 
-	// Perform Telegram authorization for accounts that need it
-	ctx := context.Background()
-	if err := c.authIntegration.AuthorizeAccounts(ctx); err != nil {
-		fmt.Printf("❌ Authorization error: %v\n", err)
+	if err := c.startTask(); err != nil {
+		fmt.Printf("❌ %v\n", err)
 		fmt.Print("Press Enter to continue...")
 		bufio.NewReader(os.Stdin).ReadLine()
 		return
 	}
 
+	fmt.Println("🚀 Task started!")
+	fmt.Println("💡 Press '2' in main menu to stop")
+}
+
+// offerResume offers to continue run that didn't stop normally, keeping its counters
+// and bought items instead of starting from zero
+func (c *CLI) offerResume() {
+	snapshot, err := service.LoadRunSnapshot()
+	if err != nil {
+		fmt.Printf("⚠️ Previous run snapshot can't be read: %v\n", err)
+		return
+	}
+	if snapshot == nil {
+		return
+	}
+
+	stats := snapshot.Statistics
+	fmt.Println("\n♻️  The previous run didn't stop normally")
+	fmt.Printf("   Started: %s, last saved: %s\n",
+		stats.StartTime.Format("2006-01-02 15:04:05"), snapshot.SavedAt.Format("2006-01-02 15:04:05"))
+	fmt.Printf("   Transactions: %d, spent: %.4f TON\n", stats.SentTransactions, float64(stats.SpentNano)/1000000000)
+	for name, count := range snapshot.PendingPayments {
+		fmt.Printf("   ⚠️ %s: %d payments were in progress, they will be counted as done\n", name, count)
+	}
+
+	fmt.Print("Resume it? Otherwise a new run starts from zero later (y/N): ")
+	input, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if strings.ToLower(strings.TrimSpace(input)) != "y" {
+		if err := service.DiscardRunSnapshot(); err != nil {
+			fmt.Printf("⚠️ %v\n", err)
+		}
+		return
+	}
+
+	c.buyerService.ResumeRun(snapshot)
+	c.handleStartTask()
+}
+
+// startTask authorizes accounts and starts purchase/monitoring task
+func (c *CLI) startTask() error {
+	c.taskMu.Lock()
+	defer c.taskMu.Unlock()
+
+	if c.buyerService.IsRunning() {
+		return fmt.Errorf("task is already running")
+	}
+
+	// Perform Telegram authorization for accounts that need it
+	ctx := context.Background()
+	if err := c.authIntegration.AuthorizeAccounts(ctx); err != nil {
+		return fmt.Errorf("authorization error: %w", err)
+	}
+
+	// Lines logged during startup are shown too
+	firstLog := c.buyerService.Logs().LastSeq()
+
+	// Subscribe before start, so a run finishing right away is still reported
+	stateChanges, unsubscribe := c.buyerService.SubscribeState()
+
 	// Start service
 	if err := c.buyerService.Start(); err != nil {
-		fmt.Printf("❌ Service startup error: %v\n", err)
-		fmt.Print("Press Enter to continue...")
-		bufio.NewReader(os.Stdin).ReadLine()
-		return
+		unsubscribe()
+		return fmt.Errorf("service startup error: %v", err)
 	}
 
-	c.isRunning = true
-	fmt.Println("🚀 Task started!")
-	fmt.Println("💡 Press '2' in main menu to stop")
+	// Save console log of this run if enabled
+	sessionLog, err := service.OpenSessionLog(c.config, time.Now())
+	if err != nil {
+		fmt.Printf("⚠️  Session log is not saved: %v\n", err)
+	} else if sessionLog != nil {
+		fmt.Printf("📝 Session log: %s\n", sessionLog.Path())
+	}
 
 	// Start log monitoring in background
-	go c.monitorLogs()
-	go c.monitorStats()
+	go c.monitorLogs(sessionLog, firstLog)
+	go c.monitorStats(stateChanges, unsubscribe)
+
+	return nil
 }
 
 // handleStopTask handles task stop
 func (c *CLI) handleStopTask() {
-	if !c.isRunning {
+	if !c.buyerService.IsRunning() {
 		fmt.Println("⚠️  Task is not running.")
 		return
 	}
 
 	fmt.Println("🛑 Stopping task...")
-	c.buyerService.Stop()
-	c.isRunning = false
-
-	// Give workers time to finish gracefully
-	time.Sleep(2 * time.Second)
+	if err := c.stopTask(); err != nil {
+		fmt.Printf("⚠️  %v\n", err)
+		return
+	}
 
 	stats := c.buyerService.GetStatistics()
 	fmt.Printf("✅ Task stopped. Statistics: Total: %d, Success: %d, Errors: %d, TON sent: %d\n",
@@ -448,6 +945,55 @@ func (c *CLI) handleStopTask() {
 	bufio.NewReader(os.Stdin).ReadLine()
 }
 
+// stopTask stops running task and waits for workers to finish
+func (c *CLI) stopTask() error {
+	c.taskMu.Lock()
+	defer c.taskMu.Unlock()
+
+	if !c.buyerService.IsRunning() {
+		return fmt.Errorf("task is not running")
+	}
+
+	// Waits for purchases and payments in progress
+	c.buyerService.Stop()
+
+	return nil
+}
+
+// shutdownOnSignal stops the task when stop is closed and exits after the teardown. Teardown
+// already started by menu exit is waited for instead of being repeated
+func (c *CLI) shutdownOnSignal(stop <-chan struct{}, lock *service.InstanceLock) {
+	<-stop
+
+	c.shutdown()
+	lock.Release()
+	os.Exit(exitOK)
+}
+
+// shutdown stops the task waiting for purchases and payments in progress, then writes out
+// logs and storage with the run summary. Later calls wait for the first one to finish
+func (c *CLI) shutdown() {
+	c.shutdownOnce.Do(func() {
+		if c.buyerService.IsRunning() {
+			fmt.Println("🛑 Stopping task, waiting for purchases and payments in progress (press Ctrl+C again to exit right away)...")
+			if err := c.stopTask(); err != nil {
+				fmt.Printf("⚠️ %v\n", err)
+			}
+			printFinalStats(c)
+		}
+
+		waitPayments(c)
+		if !c.buyerService.Logs().Flush(logFlushTimeout) {
+			fmt.Println("⚠️ Not all log lines were written to the log file")
+		}
+		if err := c.storage.Close(); err != nil {
+			fmt.Printf("⚠️ Error closing storage: %v\n", err)
+		}
+
+		fmt.Println("👋 Goodbye!")
+	})
+}
+
 // handleShowBalances shows wallet balances for all accounts
 func (c *CLI) handleShowBalances() {
 	fmt.Println("💰 Getting wallet balances...")
@@ -473,60 +1019,95 @@ func (c *CLI) handleShowBalances() {
 	bufio.NewReader(os.Stdin).ReadLine()
 }
 
-// monitorLogs monitors and displays logs
-func (c *CLI) monitorLogs() {
-	for c.isRunning && c.buyerService.IsRunning() {
+// monitorLogs displays log lines after sequence number, also writing them to session log if it is set
+func (c *CLI) monitorLogs(sessionLog *logfile.RotatingFile, after int64) {
+	if sessionLog != nil {
+		defer sessionLog.Close()
+	}
+
+	logs := c.buyerService.Logs()
+	last := after
+	for {
+		updated := logs.Updated()
+
+		lines, skipped := logs.Since(last)
+		if skipped > 0 {
+			fmt.Printf("⚠️ %d log lines skipped - console can't keep up\n", skipped)
+		}
+		for _, line := range lines {
+			fmt.Printf("📝 %s\n", line.Text)
+			if sessionLog != nil {
+				sessionLog.WriteString(line.Time.Format("2006-01-02 15:04:05.000") + " " + line.Text + "\n")
+			}
+			last = line.Seq
+		}
+
+		// Lines logged while stopping are printed before exit
+		if !c.buyerService.IsRunning() {
+			return
+		}
+
 		select {
-		case log := <-c.buyerService.GetLogChannel():
-			fmt.Printf("📝 %s\n", log)
+		case <-updated:
 		case <-c.stopChan:
 			return
 		}
 	}
 }
 
-// monitorStats monitors and displays statistics
-func (c *CLI) monitorStats() {
+// monitorStats displays statistics of running task and its state changes until the run stops
+func (c *CLI) monitorStats(stateChanges <-chan service.StateChange, unsubscribe func()) {
+	defer unsubscribe()
+
 	ticker := time.NewTicker(10 * time.Second)
 	defer ticker.Stop()
 
-	for c.isRunning && c.buyerService.IsRunning() {
+	for {
 		select {
 		case <-ticker.C:
-			if c.isRunning && c.buyerService.IsRunning() {
+			if c.buyerService.State() != service.StateRunning {
+				continue
+			}
+			stats := c.buyerService.GetStatistics()
+			fmt.Printf("📈 Stats: Total: %d | Success: %d | Errors: %d | TON: %d | RPS: %.1f | Time: %s\n",
+				stats.TotalRequests,
+				stats.SuccessRequests,
+				stats.FailedRequests,
+				stats.SentTransactions,
+				stats.RequestsPerSec,
+				stats.Duration.Truncate(time.Second),
+			)
+			if classes := stats.Errors.String(); classes != "" {
+				fmt.Printf("⚠️ Errors by class: %s\n", classes)
+			}
+		case change := <-stateChanges:
+			if change.To == service.StateDraining && change.Reason != service.StopRequested {
+				fmt.Printf("⏳ Task is stopping: %s\n", change.Reason)
+			}
+			if change.To != service.StateStopped {
+				continue
+			}
+
+			// Stop from menu or control API reports statistics itself
+			if change.Reason != service.StopRequested {
 				stats := c.buyerService.GetStatistics()
-				fmt.Printf("📈 Stats: Total: %d | Success: %d | Errors: %d | TON: %d | RPS: %.1f | Time: %s\n",
+				fmt.Printf("🏁 Final Stats: Total: %d | Success: %d | Errors: %d | TON: %d | Time: %s\n",
 					stats.TotalRequests,
 					stats.SuccessRequests,
 					stats.FailedRequests,
 					stats.SentTransactions,
-					stats.RequestsPerSec,
 					stats.Duration.Truncate(time.Second),
 				)
+				if classes := stats.Errors.String(); classes != "" {
+					fmt.Printf("⚠️ Errors by class: %s\n", classes)
+				}
+				fmt.Printf("\n✅ Task finished: %s\n", change.Reason)
 			}
+			return
 		case <-c.stopChan:
 			return
 		}
 	}
-
-	// Show final stats when service stops automatically
-	if c.isRunning && !c.buyerService.IsRunning() {
-		stats := c.buyerService.GetStatistics()
-		fmt.Printf("🏁 Final Stats: Total: %d | Success: %d | Errors: %d | TON: %d | Time: %s\n",
-			stats.TotalRequests,
-			stats.SuccessRequests,
-			stats.FailedRequests,
-			stats.SentTransactions,
-			stats.Duration.Truncate(time.Second),
-		)
-		fmt.Printf("\n✅ All tasks completed successfully!\n")
-		fmt.Printf("💡 Press Enter to return to main menu...")
-
-		// Wait for user input
-		bufio.NewReader(os.Stdin).ReadLine()
-
-		c.isRunning = false // Stop other monitoring
-	}
 }
 
 // maskPhoneNumber masks phone number for display
@@ -546,11 +1127,6 @@ func maskSeedPhrase(seed string) string {
 	return words[0] + " " + strings.Repeat("*", 20) + " " + words[len(words)-1]
 }
 
-// findConfigPath returns the path to the configuration file
-func findConfigPath() string {
-	return "./config.json"
-}
-
 // handleManageAccountAuthentication manages account authentication
 func (c *CLI) handleManageAccountAuthentication() {
 	fmt.Println("🔐 Account Authentication Management")
@@ -565,10 +1141,12 @@ func (c *CLI) handleManageAccountAuthentication() {
 		fmt.Println("\nOptions:")
 		fmt.Println("1. 🔄 Authenticate selected accounts")
 		fmt.Println("2. 🔄 Authenticate all accounts")
-		fmt.Println("3. 📋 Refresh account statuses")
-		fmt.Println("4. 🔙 Back to main menu")
+		fmt.Println("3. 🔁 Re-authenticate accounts with missing or revoked sessions")
+		fmt.Println("4. 🧪 Test tokens against the API")
+		fmt.Println("5. 📋 Refresh account statuses")
+		fmt.Println("6. 🔙 Back to main menu")
 
-		fmt.Print("Select option (1-4): ")
+		fmt.Print("Select option (1-6): ")
 		reader := bufio.NewReader(os.Stdin)
 		input, _ := reader.ReadString('\n')
 		choice := strings.TrimSpace(input)
@@ -579,9 +1157,13 @@ func (c *CLI) handleManageAccountAuthentication() {
 		case "2":
 			c.handleAuthenticateAllAccounts(&accountStatuses)
 		case "3":
+			c.handleReauthenticateAccounts(&accountStatuses)
+		case "4":
+			c.handleTestTokens()
+		case "5":
 			accountStatuses = c.checkAccountStatuses()
 			fmt.Println("✅ Account statuses refreshed")
-		case "4":
+		case "6":
 			return
 		default:
 			fmt.Println("❌ Invalid choice. Please try again.")
@@ -598,6 +1180,12 @@ type AccountStatus struct {
 	PhoneNumber  string
 	HasAuthToken bool
 	HasSession   bool
+	Session      telegram.SessionStatus // Empty if account has no Telegram authorization
+	SessionFile  string
+	SessionError string // Why session could not be checked
+	NeedsReauth  bool   // Session is missing or revoked, or token can't be refreshed
+	ReauthReason string // Why token can't be used or refreshed
+	DeadReason   string // Why Telegram reported account banned or deactivated in the current run
 	IsActive     bool
 	Error        string
 }
@@ -606,6 +1194,9 @@ type AccountStatus struct {
 func (c *CLI) checkAccountStatuses() []AccountStatus {
 	var statuses []AccountStatus
 
+	fmt.Println("🔍 Checking Telegram sessions...")
+	sessions := c.authIntegration.CheckSessions(context.Background())
+
 	for i, account := range c.config.Accounts {
 		status := AccountStatus{
 			Index:        i,
@@ -614,52 +1205,29 @@ func (c *CLI) checkAccountStatuses() []AccountStatus {
 			HasAuthToken: account.AuthToken != "",
 		}
 
-		// Check if session file exists - look in multiple possible locations
-		if account.PhoneNumber != "" {
-			// Clean phone number (remove + and other characters for file names)
-			cleanPhone := strings.ReplaceAll(account.PhoneNumber, "+", "")
-
-			// Try different session file patterns and locations
-			possiblePaths := []string{
-				// Current directory patterns with original phone
-				fmt.Sprintf("sessions/%s.session", account.PhoneNumber),
-				fmt.Sprintf("session/%s.session", account.PhoneNumber),
-				fmt.Sprintf("%s.session", account.PhoneNumber),
-				fmt.Sprintf("sessions/%s", account.PhoneNumber),
-				fmt.Sprintf("session/%s", account.PhoneNumber),
-				// Current directory patterns with clean phone (without +)
-				fmt.Sprintf("sessions/%s.session", cleanPhone),
-				fmt.Sprintf("session/%s.session", cleanPhone),
-				fmt.Sprintf("%s.session", cleanPhone),
-				fmt.Sprintf("sessions/%s", cleanPhone),
-				fmt.Sprintf("session/%s", cleanPhone),
-				// bin directory patterns (where exe is located) with original phone
-				fmt.Sprintf("bin/sessions/%s.session", account.PhoneNumber),
-				fmt.Sprintf("bin/session/%s.session", account.PhoneNumber),
-				fmt.Sprintf("bin/%s.session", account.PhoneNumber),
-				fmt.Sprintf("bin/sessions/%s", account.PhoneNumber),
-				fmt.Sprintf("bin/session/%s", account.PhoneNumber),
-				// bin directory patterns with clean phone
-				fmt.Sprintf("bin/sessions/%s.session", cleanPhone),
-				fmt.Sprintf("bin/session/%s.session", cleanPhone),
-				fmt.Sprintf("bin/%s.session", cleanPhone),
-				fmt.Sprintf("bin/sessions/%s", cleanPhone),
-				fmt.Sprintf("bin/session/%s", cleanPhone),
-				// Relative to exe location
-				fmt.Sprintf("./sessions/%s.session", account.PhoneNumber),
-				fmt.Sprintf("./session/%s.session", account.PhoneNumber),
-				fmt.Sprintf("./%s.session", account.PhoneNumber),
-				fmt.Sprintf("./sessions/%s.session", cleanPhone),
-				fmt.Sprintf("./session/%s.session", cleanPhone),
-				fmt.Sprintf("./%s.session", cleanPhone),
-			}
-
-			for _, path := range possiblePaths {
-				if _, err := os.Stat(path); err == nil {
-					status.HasSession = true
-					break
-				}
+		// Temporary tokens are never accepted by the API, in strict mode they don't count
+		if c.config.StrictAuthEnabled() && telegram.IsTempToken(account.AuthToken) {
+			status.HasAuthToken = false
+			status.ReauthReason = "saved token is a temporary token"
+		}
+		if reason := c.buyerService.NeedsReauth(account.Name); reason != "" {
+			status.ReauthReason = reason
+		}
+		status.DeadReason = c.buyerService.DeadReason(account.Name)
+
+		// Session is checked with Telegram, not only on disk
+		if check, ok := sessions[i]; ok {
+			status.Session = check.Status
+			status.SessionFile = service.SessionFile(&account)
+			if check.Err != nil {
+				status.SessionError = check.Err.Error()
 			}
+			// Unknown keeps account active, a network error doesn't mean the session is lost
+			status.HasSession = check.Status == telegram.SessionAuthorized || check.Status == telegram.SessionUnknown
+			status.NeedsReauth = check.Status.NeedsReauth()
+		}
+		if status.ReauthReason != "" {
+			status.NeedsReauth = true
 		}
 
 		// Determine if account is active (has either auth token or session)
@@ -670,6 +1238,8 @@ func (c *CLI) checkAccountStatuses() []AccountStatus {
 			status.Error = "No phone number or auth token specified"
 		} else if account.PhoneNumber != "" && !strings.HasPrefix(account.PhoneNumber, "+") {
 			status.Error = "Phone number must start with '+'"
+		} else if status.Session == telegram.SessionDeactivated {
+			status.Error = "Telegram account is deleted or banned"
 		}
 
 		statuses = append(statuses, status)
@@ -700,16 +1270,20 @@ func (c *CLI) printAccountStatuses(statuses []AccountStatus) {
 			fmt.Printf("   🎫 Auth Token: ❌ Not available\n")
 		}
 
-		// Session status with debug info
-		if status.HasSession {
-			fmt.Printf("   📁 Session: ✅ Active\n")
-		} else {
-			fmt.Printf("   📁 Session: ❌ Not found\n")
-			// Show where we looked for sessions (debug info)
-			if status.PhoneNumber != "" {
-				cleanPhone := strings.ReplaceAll(status.PhoneNumber, "+", "")
-				fmt.Printf("   🔍 Searched for: %s.session, %s.session\n", status.PhoneNumber, cleanPhone)
-			}
+		// Session status checked with Telegram
+		switch status.Session {
+		case telegram.SessionAuthorized:
+			fmt.Printf("   📁 Session: ✅ Authorized\n")
+		case telegram.SessionMissing:
+			fmt.Printf("   📁 Session: ❌ Not found (%s)\n", status.SessionFile)
+		case telegram.SessionRevoked:
+			fmt.Printf("   📁 Session: ⛔ Revoked, re-authentication required\n")
+		case telegram.SessionDeactivated:
+			fmt.Printf("   📁 Session: 🚫 Account deactivated\n")
+		case telegram.SessionUnknown:
+			fmt.Printf("   📁 Session: ⚠️  Not checked: %s\n", status.SessionError)
+		default:
+			fmt.Printf("   📁 Session: ➖ Telegram authorization not configured\n")
 		}
 
 		// Proxy status
@@ -730,6 +1304,13 @@ func (c *CLI) printAccountStatuses(statuses []AccountStatus) {
 			fmt.Printf("   🔴 Status: INACTIVE\n")
 		}
 
+		if status.ReauthReason != "" {
+			fmt.Printf("   🔐 Re-authorization required: %s\n", status.ReauthReason)
+		}
+		if status.DeadReason != "" {
+			fmt.Printf("   💀 Dead: %s\n", status.DeadReason)
+		}
+
 		// Error if any
 		if status.Error != "" {
 			fmt.Printf("   ⚠️  Issue: %s\n", status.Error)
@@ -803,6 +1384,68 @@ func (c *CLI) handleSelectiveAuthentication(accountStatuses *[]AccountStatus) {
 	fmt.Println("📋 Account statuses refreshed after authentication")
 }
 
+// handleReauthenticateAccounts authenticates accounts whose session is missing or revoked
+func (c *CLI) handleReauthenticateAccounts(accountStatuses *[]AccountStatus) {
+	var indices []int
+	for _, status := range *accountStatuses {
+		if status.NeedsReauth && status.Error == "" {
+			indices = append(indices, status.Index)
+		}
+	}
+
+	if len(indices) == 0 {
+		fmt.Println("✅ All Telegram sessions are authorized")
+		return
+	}
+
+	c.authenticateSelectedAccounts(indices)
+
+	// Refresh statuses after authentication
+	*accountStatuses = c.checkAccountStatuses()
+	fmt.Println("📋 Account statuses refreshed after authentication")
+}
+
+// handleTestTokens checks tokens of all accounts with the API and offers to refresh rejected ones
+func (c *CLI) handleTestTokens() {
+	fmt.Println("🧪 Testing tokens against the API...")
+	checks := c.buyerService.CheckTokens()
+
+	var expired []string
+	for _, check := range checks {
+		switch check.Validity {
+		case service.TokenValid:
+			fmt.Printf("   ✅ %-30s valid\n", check.Account)
+		case service.TokenExpired:
+			fmt.Printf("   ⌛ %-30s expired: %s\n", check.Account, check.Detail)
+			expired = append(expired, check.Account)
+		case service.TokenBanned:
+			fmt.Printf("   🚫 %-30s banned: %s\n", check.Account, check.Detail)
+		case service.TokenMissing:
+			fmt.Printf("   ❌ %-30s no token: %s\n", check.Account, check.Detail)
+		default:
+			fmt.Printf("   ⚠️  %-30s not checked: %s\n", check.Account, check.Detail)
+		}
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	if len(expired) > 0 {
+		fmt.Printf("\nRefresh %d expired tokens now? (y/N): ", len(expired))
+		input, _ := reader.ReadString('\n')
+		if strings.EqualFold(strings.TrimSpace(input), "y") {
+			for _, name := range expired {
+				if err := c.buyerService.RefreshToken(name); err != nil {
+					fmt.Printf("❌ %s: %v\n", name, err)
+				} else {
+					fmt.Printf("✅ %s: token refreshed\n", name)
+				}
+			}
+		}
+	}
+
+	fmt.Print("Press Enter to continue...")
+	reader.ReadLine()
+}
+
 // handleAuthenticateAllAccounts authenticates all inactive accounts
 func (c *CLI) handleAuthenticateAllAccounts(accountStatuses *[]AccountStatus) {
 	fmt.Println("🔄 Authenticating all accounts...")
@@ -829,18 +1472,6 @@ func (c *CLI) authenticateSelectedAccounts(indices []int) {
 	ctx := context.Background()
 	successCount := 0
 
-	// ИСПРАВЛЕНИЕ: НЕ создаем tempAuthIntegration который портит конфиг!
-	// Вместо этого аутентифицируем аккаунты через основной authIntegration
-	// но только выбранные индексы
-
-	// Сохраняем оригинальные токены
-	originalTokens := make(map[int]string)
-	for _, index := range indices {
-		if index >= 0 && index < len(c.config.Accounts) {
-			originalTokens[index] = c.config.Accounts[index].AuthToken
-		}
-	}
-
 	for _, index := range indices {
 		if index < 0 || index >= len(c.config.Accounts) {
 			continue
@@ -849,26 +1480,13 @@ func (c *CLI) authenticateSelectedAccounts(indices []int) {
 		account := c.config.Accounts[index]
 		fmt.Printf("🔐 Authenticating %s (%s)...\n", account.Name, maskPhoneNumber(account.PhoneNumber))
 
-		// Временно очищаем токен чтобы authIntegration попытался аутентифицировать
-		c.config.Accounts[index].AuthToken = ""
-
-		// Используем основной authIntegration для аутентификации всех аккаунтов
-		// но только те что нуждаются в аутентификации (без токенов) будут обработаны
-		if err := c.authIntegration.AuthorizeAccounts(ctx); err != nil {
+		// Token of the account is kept if authorization fails
+		if err := c.authIntegration.AuthorizeAccount(ctx, index); err != nil {
 			fmt.Printf("❌ Failed to authenticate %s: %v\n", account.Name, err)
-			// Восстанавливаем оригинальный токен при ошибке
-			c.config.Accounts[index].AuthToken = originalTokens[index]
 		} else {
 			fmt.Printf("✅ Successfully authenticated %s\n", account.Name)
 			successCount++
 		}
-
-		// Восстанавливаем токены других аккаунтов (которые не должны были аутентифицироваться)
-		for otherIndex, originalToken := range originalTokens {
-			if otherIndex != index && originalToken != "" {
-				c.config.Accounts[otherIndex].AuthToken = originalToken
-			}
-		}
 	}
 
 	fmt.Printf("📊 Authentication complete: %d/%d accounts successful\n", successCount, len(indices))
@@ -886,7 +1504,7 @@ func (c *CLI) handleCheckDeployWallets() {
 
 	var deployRequired []int
 
-	fmt.Println("🔍 Scanning wallet states for all accounts...\n")
+	fmt.Print("🔍 Scanning wallet states for all accounts...\n\n")
 
 	// Check all accounts
 	for i, account := range c.config.Accounts {
@@ -906,7 +1524,7 @@ func (c *CLI) handleCheckDeployWallets() {
 		}
 
 		// Create TON client
-		tonClient, err := client.NewTONClient(account.SeedPhrase)
+		tonClient, err := client.NewTONClientWithProxy(account.SeedPhrase, account.UseProxy, account.TONProxyURL)
 		if err != nil {
 			fmt.Printf("   ❌ Error creating TON client: %v\n\n", err)
 			continue
@@ -1052,7 +1670,7 @@ func (c *CLI) deployWallets(accountIndices []int) {
 		fmt.Printf("🔄 Deploying wallet for %s...\n", account.Name)
 
 		// Create TON client
-		tonClient, err := client.NewTONClient(account.SeedPhrase)
+		tonClient, err := client.NewTONClientWithProxy(account.SeedPhrase, account.UseProxy, account.TONProxyURL)
 		if err != nil {
 			fmt.Printf("   ❌ Error creating TON client: %v\n\n", err)
 			continue
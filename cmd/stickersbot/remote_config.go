@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+
+	"stickersbot/internal/config"
+)
+
+// applyRemoteConfig applies remote configuration at start. While the URL is unreachable or
+// serves a document with invalid signature, the latest verified copy is used instead;
+// without one the local configuration stays as is
+func applyRemoteConfig(cfg *config.Config) error {
+	remote := cfg.RemoteConfig
+	data, err := config.FetchRemote(remote)
+	fetched := err == nil
+	if err != nil {
+		fmt.Printf("⚠️ Remote configuration %s: %v\n", remote.URL, err)
+		if data, err = config.LoadRemoteCache(); err != nil || data == nil {
+			fmt.Println("⚠️ No saved copy of remote configuration, local configuration is used")
+			return nil
+		}
+		fmt.Println("📦 Using the latest saved copy of remote configuration")
+	}
+
+	if err := cfg.ApplyRemote(data); err != nil {
+		return err
+	}
+	if fetched {
+		if err := config.SaveRemoteCache(data); err != nil {
+			fmt.Printf("⚠️ Remote configuration is not saved: %v\n", err)
+		}
+	}
+	fmt.Printf("🌍 Remote configuration applied: %s\n", remote.URL)
+	return nil
+}
+
+// refreshRemoteConfig fetches remote configuration again and applies it while the task is
+// stopped. Document that fails verification or validation leaves configuration unchanged
+func (c *CLI) refreshRemoteConfig() error {
+	c.taskMu.Lock()
+	defer c.taskMu.Unlock()
+
+	if c.config.RemoteConfig == nil {
+		return fmt.Errorf("remote_config is not configured")
+	}
+	if c.buyerService.IsRunning() {
+		return fmt.Errorf("stop the task before applying remote configuration")
+	}
+
+	data, err := config.FetchRemote(c.config.RemoteConfig)
+	if err != nil {
+		return err
+	}
+
+	candidate, err := c.config.Clone()
+	if err != nil {
+		return err
+	}
+	if err := candidate.ApplyRemote(data); err != nil {
+		return err
+	}
+	if err := c.validateConfig(candidate); err != nil {
+		return fmt.Errorf("remote configuration: %w", err)
+	}
+
+	// Services share the configuration, so it is replaced in place
+	*c.config = *candidate
+	registerSecrets(c.config)
+	if err := config.SaveRemoteCache(data); err != nil {
+		fmt.Printf("⚠️ Remote configuration is not saved: %v\n", err)
+	}
+	fmt.Printf("🌍 Remote configuration applied: %s\n", c.config.RemoteConfig.URL)
+	return nil
+}
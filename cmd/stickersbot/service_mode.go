@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+	"unicode"
+
+	"stickersbot/internal/config"
+	"stickersbot/internal/redact"
+	"stickersbot/internal/service"
+	"stickersbot/internal/telegram"
+)
+
+// Exit codes of service mode. Service managers restart the bot after failures,
+// configuration errors are reported with a code restart policies can exclude
+const (
+	exitOK       = 0
+	exitTempFail = 75 // EX_TEMPFAIL: run failed, restart may help
+	exitConfig   = 78 // EX_CONFIG: configuration or authorization must be fixed first
+)
+
+// defaultServiceName name of installed service
+const defaultServiceName = "stickersbot"
+
+// serviceStatusInterval how often statistics are reported to service manager
+const serviceStatusInterval = 30 * time.Second
+
+// logFlushTimeout how long exit waits for log lines to reach the log file
+const logFlushTimeout = 5 * time.Second
+
+// serviceStatus reports state of the bot to service manager
+type serviceStatus interface {
+	Ready()
+	Status(text string)
+	Stopping()
+}
+
+// ansiPattern terminal escape sequences
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+// plainLine removes terminal escape sequences and emoji from line of output
+func plainLine(line string) string {
+	line = ansiPattern.ReplaceAllString(line, "")
+	line = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 0x1F000, unicode.Is(unicode.So, r), r == 0x200D, r >= 0xFE00 && r <= 0xFE0F:
+			return -1
+		}
+		return r
+	}, line)
+	return strings.TrimLeft(strings.Join(strings.Fields(line), " "), " ")
+}
+
+// plainLines copies reader to w line by line without escape sequences and emoji
+func plainLines(reader io.Reader, w io.Writer) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if line := plainLine(scanner.Text()); line != "" {
+			fmt.Fprintln(w, line)
+		}
+	}
+}
+
+// enablePlainOutput makes all console output plain text for service logs (journald, log file).
+// Returns function writing out the rest of output before exit
+func enablePlainOutput() func() {
+	stdout := os.Stdout
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return func() {}
+	}
+	os.Stdout = writer
+	stdoutDone := make(chan struct{})
+	go func() {
+		plainLines(reader, stdout)
+		close(stdoutDone)
+	}()
+
+	logReader, logWriter := io.Pipe()
+	logDone := make(chan struct{})
+	go func() {
+		plainLines(logReader, os.Stderr)
+		close(logDone)
+	}()
+	log.SetFlags(0)
+	log.SetOutput(redact.Writer(logWriter))
+
+	return func() {
+		os.Stdout = stdout
+		writer.Close()
+		<-stdoutDone
+		logWriter.Close()
+		<-logDone
+	}
+}
+
+// signalStop returns channel closed on SIGINT or SIGTERM. The second signal
+// exits right away without waiting for purchases and payments in progress
+func signalStop() <-chan struct{} {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	stop := make(chan struct{})
+	go func() {
+		sig := <-signals
+		fmt.Printf("Received %s, stopping\n", sig)
+		close(stop)
+
+		sig = <-signals
+		fmt.Printf("Received %s again, exiting without waiting for purchases and payments in progress\n", sig)
+		os.Exit(exitTempFail)
+	}()
+	return stop
+}
+
+// runService runs the bot without menu: starts the task right away and works until stop
+// is closed or the run finishes by itself. Returns exit code of the process
+func runService(useConfigStateDir bool, stop <-chan struct{}, status serviceStatus) int {
+	flush := enablePlainOutput()
+	defer flush()
+
+	// Nobody answers prompts of a service
+	service.SetLoginPrompter(telegram.NoPrompter{})
+
+	cli := &CLI{stopChan: make(chan struct{})}
+	defer close(cli.stopChan)
+
+	status.Status("loading configuration")
+	if err := cli.initializeConfig(useConfigStateDir); err != nil {
+		fmt.Printf("Configuration error: %v\n", err)
+		return exitConfig
+	}
+
+	lock, err := service.AcquireInstanceLock(config.StatePath(service.LockFile))
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		return exitTempFail
+	}
+	defer lock.Release()
+
+	if err := cli.initializeServices(); err != nil {
+		fmt.Printf("Services initialization error: %v\n", err)
+		return exitTempFail
+	}
+	defer cli.storage.Close()
+	defer cli.buyerService.Logs().Flush(logFlushTimeout)
+	defer waitPayments(cli)
+
+	// Restarted service continues the run it was killed in
+	if snapshot, err := service.LoadRunSnapshot(); err != nil {
+		fmt.Printf("Previous run snapshot can't be read: %v\n", err)
+	} else if snapshot != nil {
+		fmt.Println("Resuming previous run that didn't stop normally")
+		cli.buyerService.ResumeRun(snapshot)
+	}
+
+	stateChanges, unsubscribe := cli.buyerService.SubscribeState()
+	defer unsubscribe()
+
+	status.Status("authorizing accounts and starting")
+	if err := cli.startTask(); err != nil {
+		fmt.Printf("Start error: %v\n", err)
+		if errors.Is(err, telegram.ErrNoPrompt) {
+			fmt.Println("Authorize accounts once in the interactive menu, then start the service again")
+			return exitConfig
+		}
+		return exitTempFail
+	}
+	status.Ready()
+
+	ticker := time.NewTicker(serviceStatusInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			stats := cli.buyerService.GetStatistics()
+			status.Status(fmt.Sprintf("running: %d requests, %d successful, %d errors, %d transactions",
+				stats.TotalRequests, stats.SuccessRequests, stats.FailedRequests, stats.SentTransactions))
+		case <-stop:
+			status.Stopping()
+			if err := cli.stopTask(); err != nil {
+				fmt.Printf("%v\n", err)
+			}
+			printFinalStats(cli)
+			return exitOK
+		case change := <-stateChanges:
+			if change.To == service.StateDraining {
+				status.Stopping()
+			}
+			if change.To == service.StateStopped {
+				printFinalStats(cli)
+				return exitOK
+			}
+		}
+	}
+}
+
+// printFinalStats prints statistics of finished run
+func printFinalStats(cli *CLI) {
+	stats := cli.buyerService.GetStatistics()
+	fmt.Printf("Task stopped. Total: %d, Success: %d, Errors: %d, TON sent: %d, Time: %s\n",
+		stats.TotalRequests, stats.SuccessRequests, stats.FailedRequests, stats.SentTransactions,
+		stats.Duration.Truncate(time.Second))
+}
+
+// waitPayments waits for queued payments to be reconciled, their results are written
+// to storage, so it is closed only after that
+func waitPayments(cli *CLI) {
+	if !cli.buyerService.WaitPayments() {
+		fmt.Println("⚠️ Payments are still pending, their results will not be recorded")
+	}
+}
+
+// serviceArgs returns command line of installed service: state directory given on
+// command line is kept, work directory is where config.json was found
+func serviceArgs(useConfigStateDir bool) ([]string, string, error) {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return nil, "", err
+	}
+
+	args := []string{"--service"}
+	if !useConfigStateDir {
+		stateDir, err := filepath.Abs(config.StateDir())
+		if err != nil {
+			return nil, "", err
+		}
+		args = append([]string{"--state-dir", stateDir}, args...)
+	}
+	return args, workDir, nil
+}
+
+// runServiceCommand installs or removes the bot as system service
+func runServiceCommand(args []string, useConfigStateDir bool) error {
+	if len(args) == 0 {
+		return fmt.Errorf("expected service install or service uninstall")
+	}
+
+	name := defaultServiceName
+	if len(args) > 1 {
+		name = args[1]
+	}
+
+	switch args[0] {
+	case "install":
+		serviceArgs, workDir, err := serviceArgs(useConfigStateDir)
+		if err != nil {
+			return err
+		}
+		return installService(name, serviceArgs, workDir)
+	case "uninstall":
+		return uninstallService(name)
+	default:
+		return fmt.Errorf("unknown service command %q, expected install or uninstall", args[0])
+	}
+}
+
+// consoleStatus service mode started from console has no service manager to report to
+type consoleStatus struct{}
+
+func (consoleStatus) Ready()        {}
+func (consoleStatus) Status(string) {}
+func (consoleStatus) Stopping()     {}
+
+// executablePath returns absolute path of the running binary
+func executablePath() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("locating executable: %v", err)
+	}
+	return filepath.EvalSymlinks(exe)
+}
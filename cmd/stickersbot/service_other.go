@@ -0,0 +1,136 @@
+//go:build !windows
+
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// systemdUnitDir directory installed unit files are written to
+const systemdUnitDir = "/etc/systemd/system"
+
+// serve runs service mode. Under systemd readiness and status are reported through sd_notify
+func serve(useConfigStateDir bool) int {
+	return runService(useConfigStateDir, signalStop(), systemdNotifier{socket: os.Getenv("NOTIFY_SOCKET")})
+}
+
+// systemdNotifier reports service state to systemd, does nothing outside of it
+type systemdNotifier struct {
+	socket string
+}
+
+// notify sends state to NOTIFY_SOCKET
+func (n systemdNotifier) notify(state string) {
+	if n.socket == "" {
+		return
+	}
+	addr := n.socket
+	if strings.HasPrefix(addr, "@") {
+		// Abstract socket
+		addr = "\x00" + addr[1:]
+	}
+
+	conn, err := net.Dial("unixgram", addr)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	conn.Write([]byte(state))
+}
+
+// Ready tells systemd the bot has started
+func (n systemdNotifier) Ready() { n.notify("READY=1") }
+
+// Status sets status line shown by systemctl status
+func (n systemdNotifier) Status(text string) { n.notify("STATUS=" + text) }
+
+// Stopping tells systemd the bot is stopping
+func (n systemdNotifier) Stopping() { n.notify("STOPPING=1") }
+
+// installService writes systemd unit of the bot and enables it
+func installService(name string, args []string, workDir string) error {
+	if runtime.GOOS != "linux" {
+		return fmt.Errorf("service install is supported with systemd on Linux and on Windows")
+	}
+
+	exe, err := executablePath()
+	if err != nil {
+		return err
+	}
+
+	command := []string{systemdQuote(exe)}
+	for _, arg := range args {
+		command = append(command, systemdQuote(arg))
+	}
+
+	var unit strings.Builder
+	fmt.Fprintf(&unit, "[Unit]\nDescription=Stickers bot (%s)\nAfter=network-online.target\nWants=network-online.target\n\n", name)
+	fmt.Fprintf(&unit, "[Service]\nType=notify\nNotifyAccess=main\nExecStart=%s\nWorkingDirectory=%s\n", strings.Join(command, " "), systemdQuote(workDir))
+	// Installed with sudo, the service runs as the user who owns the config
+	if user := os.Getenv("SUDO_USER"); user != "" {
+		fmt.Fprintf(&unit, "User=%s\n", user)
+	}
+	fmt.Fprintf(&unit, "Restart=on-failure\nRestartSec=10\nRestartPreventExitStatus=%d\nTimeoutStopSec=180\n\n", exitConfig)
+	fmt.Fprintf(&unit, "[Install]\nWantedBy=multi-user.target\n")
+
+	path := filepath.Join(systemdUnitDir, name+".service")
+	if err := os.WriteFile(path, []byte(unit.String()), 0644); err != nil {
+		return fmt.Errorf("writing %s (run with sudo): %v", path, err)
+	}
+	if err := systemctl("daemon-reload"); err != nil {
+		return err
+	}
+	if err := systemctl("enable", name); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Service %s installed: %s\n", name, path)
+	fmt.Printf("   Start:  sudo systemctl start %s\n", name)
+	fmt.Printf("   Status: systemctl status %s\n", name)
+	fmt.Printf("   Logs:   journalctl -u %s -f\n", name)
+	return nil
+}
+
+// uninstallService stops, disables and removes systemd unit of the bot
+func uninstallService(name string) error {
+	path := filepath.Join(systemdUnitDir, name+".service")
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("service %s is not installed: %v", name, err)
+	}
+
+	if err := systemctl("disable", "--now", name); err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("removing %s (run with sudo): %v", path, err)
+	}
+	if err := systemctl("daemon-reload"); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Service %s removed\n", name)
+	return nil
+}
+
+// systemctl runs systemctl with arguments
+func systemctl(args ...string) error {
+	output, err := exec.Command("systemctl", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("systemctl %s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// systemdQuote quotes argument of unit file command line if needed
+func systemdQuote(arg string) string {
+	if !strings.ContainsAny(arg, " \t\"'\\") {
+		return arg
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(arg) + `"`
+}
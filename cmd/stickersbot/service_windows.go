@@ -0,0 +1,161 @@
+//go:build windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// serviceLogFile console output of the bot running as Windows service
+const serviceLogFile = "service.log"
+
+// serve runs service mode: under service control manager it reports its state to it,
+// started from console it works until Ctrl+C
+func serve(useConfigStateDir bool) int {
+	inService, err := svc.IsWindowsService()
+	if err != nil || !inService {
+		return runService(useConfigStateDir, signalStop(), consoleStatus{})
+	}
+
+	// Service has no console, its output goes to file in work directory
+	if file, err := os.OpenFile(serviceLogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
+		os.Stdout, os.Stderr = file, file
+	}
+
+	handler := &windowsService{useConfigStateDir: useConfigStateDir}
+	if err := svc.Run(defaultServiceName, handler); err != nil {
+		fmt.Printf("Service error: %v\n", err)
+		return exitTempFail
+	}
+	return handler.code
+}
+
+// windowsService runs the bot under service control manager
+type windowsService struct {
+	useConfigStateDir bool
+	code              int
+}
+
+// Execute runs the bot until service control manager stops it or the run finishes
+func (s *windowsService) Execute(args []string, requests <-chan svc.ChangeRequest, changes chan<- svc.Status) (bool, uint32) {
+	changes <- svc.Status{State: svc.StartPending}
+
+	stop := make(chan struct{})
+	var stopOnce sync.Once
+	done := make(chan int, 1)
+	status := scmStatus{changes: changes}
+	go func() { done <- runService(s.useConfigStateDir, stop, status) }()
+
+	for {
+		select {
+		case code := <-done:
+			s.code = code
+			if code == exitOK {
+				return false, 0
+			}
+			// Non-zero code triggers recovery actions of the service
+			return true, uint32(code)
+		case request := <-requests:
+			switch request.Cmd {
+			case svc.Interrogate:
+				changes <- request.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				stopOnce.Do(func() { close(stop) })
+			}
+		}
+	}
+}
+
+// scmStatus reports state of the bot to service control manager
+type scmStatus struct {
+	changes chan<- svc.Status
+}
+
+// Ready reports running service accepting stop
+func (s scmStatus) Ready() {
+	s.changes <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+}
+
+// Status is not shown by service control manager, the text is logged instead
+func (s scmStatus) Status(text string) {
+	fmt.Println(text)
+}
+
+// Stopping reports stopping service, purchases in progress are finished first
+func (s scmStatus) Stopping() {
+	s.changes <- svc.Status{State: svc.StopPending, WaitHint: 180000}
+}
+
+// installService registers the bot as automatically started Windows service restarted after failures
+func installService(name string, args []string, workDir string) error {
+	exe, err := executablePath()
+	if err != nil {
+		return err
+	}
+
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("connecting to service manager (run as administrator): %v", err)
+	}
+	defer m.Disconnect()
+
+	if s, err := m.OpenService(name); err == nil {
+		s.Close()
+		return fmt.Errorf("service %s already exists", name)
+	}
+
+	// Services start in system directory, work directory of config is passed explicitly
+	args = append([]string{"--workdir", workDir}, args...)
+	s, err := m.CreateService(name, exe, mgr.Config{
+		DisplayName: fmt.Sprintf("Stickers bot (%s)", name),
+		Description: "Buys stickers and monitors drops",
+		StartType:   mgr.StartAutomatic,
+	}, args...)
+	if err != nil {
+		return fmt.Errorf("creating service: %v", err)
+	}
+	defer s.Close()
+
+	restart := mgr.RecoveryAction{Type: mgr.ServiceRestart, Delay: 10 * time.Second}
+	if err := s.SetRecoveryActions([]mgr.RecoveryAction{restart, restart, restart}, uint32((24 * time.Hour).Seconds())); err != nil {
+		return fmt.Errorf("setting restart on failure: %v", err)
+	}
+	if err := s.SetRecoveryActionsOnNonCrashFailures(true); err != nil {
+		return fmt.Errorf("setting restart on failure: %v", err)
+	}
+
+	fmt.Printf("✅ Service %s installed\n", name)
+	fmt.Printf("   Start: sc start %s\n", name)
+	fmt.Printf("   Log:   %s\n", serviceLogFile)
+	return nil
+}
+
+// uninstallService stops and removes Windows service of the bot
+func uninstallService(name string) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("connecting to service manager (run as administrator): %v", err)
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(name)
+	if err != nil {
+		return fmt.Errorf("service %s is not installed", name)
+	}
+	defer s.Close()
+
+	// Not running service can't be stopped, it is removed anyway
+	s.Control(svc.Stop)
+	if err := s.Delete(); err != nil {
+		return fmt.Errorf("removing service: %v", err)
+	}
+
+	fmt.Printf("✅ Service %s removed\n", name)
+	return nil
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"stickersbot/internal/config"
+)
+
+// handleEditSnipeFilters changes snipe filters of account, also while task is running
+func (c *CLI) handleEditSnipeFilters() {
+	reader := bufio.NewReader(os.Stdin)
+
+	fmt.Println("✏️  Edit snipe filters")
+	fmt.Println(strings.Repeat("-", 80))
+
+	var accounts []string
+	for _, account := range c.config.Accounts {
+		if account.SnipeMonitor != nil && account.SnipeMonitor.Enabled {
+			accounts = append(accounts, account.Name)
+		}
+	}
+	if len(accounts) == 0 {
+		fmt.Println("ℹ️  No accounts with enabled snipe monitor")
+		return
+	}
+
+	for i, name := range accounts {
+		settings, err := c.buyerService.GetSnipeFilters(name)
+		if err != nil {
+			fmt.Printf("%d. %s - ❌ %v\n", i+1, name, err)
+			continue
+		}
+		fmt.Printf("%d. %s - %s\n", i+1, name, settings)
+	}
+
+	fmt.Print("\nSelect account (Enter - cancel): ")
+	input, _ := reader.ReadString('\n')
+	index, err := strconv.Atoi(strings.TrimSpace(input))
+	if err != nil || index < 1 || index > len(accounts) {
+		return
+	}
+	accountName := accounts[index-1]
+
+	settings, err := c.buyerService.GetSnipeFilters(accountName)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return
+	}
+
+	fmt.Println("💡 Enter - keep current value, '-' - remove filter")
+
+	if settings.SupplyRange, err = promptRange(reader, "Supply range (min-max)", settings.SupplyRange, 1); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return
+	}
+	if settings.PriceRange, err = promptRange(reader, "Price range in TON (min-max)", settings.PriceRange, 1000000000); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return
+	}
+
+	currentWords := strings.Join(settings.WordFilter, ", ")
+	if words, changed := promptValue(reader, "Word filter (comma separated)", currentWords); changed {
+		settings.WordFilter = nil
+		for _, word := range strings.Split(words, ",") {
+			if word = strings.TrimSpace(word); word != "" {
+				settings.WordFilter = append(settings.WordFilter, word)
+			}
+		}
+	}
+
+	if expression, changed := promptValue(reader, "Filter expression (replaces filters above)", settings.Filter); changed {
+		settings.Filter = expression
+	}
+
+	if err := c.buyerService.UpdateSnipeFilters(accountName, settings); err != nil {
+		fmt.Printf("❌ Filters not changed: %v\n", err)
+		return
+	}
+	fmt.Printf("✅ Filters of '%s' updated: %s\n", accountName, settings)
+
+	fmt.Print("💾 Save to config.json? (y/N): ")
+	input, _ = reader.ReadString('\n')
+	if strings.EqualFold(strings.TrimSpace(input), "y") {
+		if err := c.config.Save(c.config.Path()); err != nil {
+			fmt.Printf("❌ Error saving configuration: %v\n", err)
+			return
+		}
+		fmt.Println("✅ Configuration saved")
+	}
+}
+
+// promptValue asks for new value. Returns changed=false if input is empty,
+// "-" clears the value
+func promptValue(reader *bufio.Reader, label string, current string) (string, bool) {
+	if current == "" {
+		current = "not set"
+	}
+	fmt.Printf("%s [%s]: ", label, current)
+
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(input)
+
+	switch input {
+	case "":
+		return "", false
+	case "-":
+		return "", true
+	}
+	return input, true
+}
+
+// promptRange asks for "min-max" range, values are multiplied by unit
+func promptRange(reader *bufio.Reader, label string, current *config.Range, unit float64) (*config.Range, error) {
+	currentText := ""
+	if current != nil {
+		currentText = fmt.Sprintf("%s-%s", formatUnits(current.Min, unit), formatUnits(current.Max, unit))
+	}
+
+	input, changed := promptValue(reader, label, currentText)
+	if !changed {
+		return current, nil
+	}
+	if input == "" {
+		return nil, nil
+	}
+
+	parts := strings.SplitN(input, "-", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid range %q, expected min-max", input)
+	}
+
+	min, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid minimum %q", parts[0])
+	}
+	max, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid maximum %q", parts[1])
+	}
+
+	return &config.Range{
+		Min: int(min * unit),
+		Max: int(max * unit),
+	}, nil
+}
+
+// formatUnits formats value divided by unit without trailing zeros
+func formatUnits(value int, unit float64) string {
+	return strconv.FormatFloat(float64(value)/unit, 'f', -1, 64)
+}
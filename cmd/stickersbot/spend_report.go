@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"stickersbot/internal/config"
+	"stickersbot/internal/service"
+)
+
+// handleShowSpendReport shows spend per account and per collection and offers CSV export
+func (c *CLI) handleShowSpendReport() {
+	fmt.Println("💸 Spend report")
+	fmt.Println(strings.Repeat("-", 80))
+
+	reader := bufio.NewReader(os.Stdin)
+
+	transactions, err := c.storage.LoadTransactions(0)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		fmt.Print("Press Enter to continue...")
+		reader.ReadLine()
+		return
+	}
+
+	if len(transactions) == 0 {
+		fmt.Println("ℹ️  No transactions yet")
+		fmt.Print("Press Enter to continue...")
+		reader.ReadLine()
+		return
+	}
+
+	report := service.BuildSpendReport(transactions)
+
+	fmt.Printf("🧮 %s\n", formatSpendLine(report.Total))
+	if report.TestTransactions > 0 {
+		fmt.Printf("🧪 Including %d test mode transactions\n", report.TestTransactions)
+	}
+
+	fmt.Println("\n👤 Per account:")
+	for _, line := range report.Accounts {
+		fmt.Printf("   %-30s %s\n", line.Name, formatSpendLine(line))
+	}
+
+	fmt.Println("\n🎨 Per collection:")
+	for _, line := range report.Collections {
+		fmt.Printf("   %-30s %s\n", line.Name, formatSpendLine(line))
+	}
+
+	path := config.StatePath(service.SpendReportFile)
+	fmt.Printf("\nExport to %s? (y/N): ", path)
+	input, _ := reader.ReadString('\n')
+	if strings.ToLower(strings.TrimSpace(input)) == "y" {
+		if err := service.ExportSpendReport(path, report); err != nil {
+			fmt.Printf("❌ %v\n", err)
+		} else {
+			fmt.Printf("✅ Spend report saved to %s\n", path)
+		}
+	}
+}
+
+// formatSpendLine formats spend of account or collection
+func formatSpendLine(line service.SpendLine) string {
+	text := fmt.Sprintf("TX: %d | Items: %d | Spent: %.4f TON | Fees: %.4f TON",
+		line.Transactions, line.Items, float64(line.SpentNano)/1000000000, float64(line.FeesNano)/1000000000)
+	if average := line.AveragePriceNano(); average > 0 {
+		text += fmt.Sprintf(" | Avg price: %.4f TON", float64(average)/1000000000)
+	}
+	return text
+}
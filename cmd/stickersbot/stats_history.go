@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"stickersbot/internal/config"
+	"stickersbot/internal/service"
+	"stickersbot/internal/types"
+)
+
+// statsHistoryShown number of latest runs shown by history viewer
+const statsHistoryShown = 10
+
+// handleShowStatsHistory shows cumulative statistics and previous runs
+func (c *CLI) handleShowStatsHistory() {
+	fmt.Println("📊 Statistics history")
+	fmt.Println(strings.Repeat("-", 80))
+
+	sessions, err := service.LoadStatsHistory(config.StatePath(service.StatsHistoryFile))
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		fmt.Print("Press Enter to continue...")
+		bufio.NewReader(os.Stdin).ReadLine()
+		return
+	}
+
+	// Running task is included in totals, it is saved to history when stopped
+	running := c.buyerService.IsRunning()
+	if running {
+		sessions = append(sessions, types.SessionStatistics{
+			Statistics: *c.buyerService.GetStatistics(),
+			EndTime:    time.Now(),
+		})
+	}
+
+	if len(sessions) == 0 {
+		fmt.Println("ℹ️  No finished runs yet")
+		fmt.Print("Press Enter to continue...")
+		bufio.NewReader(os.Stdin).ReadLine()
+		return
+	}
+
+	totals := service.AggregateStats(sessions)
+
+	fmt.Printf("🧮 Total of %d runs since %s (%s running)\n",
+		totals.Sessions, totals.FirstRun.Format("2006-01-02 15:04"), totals.Duration.Truncate(time.Second))
+	fmt.Printf("   %s\n\n", formatCounters(totals.Counters))
+
+	fmt.Println("👤 Per account:")
+	accounts := make([]string, 0, len(totals.Accounts))
+	for account := range totals.Accounts {
+		accounts = append(accounts, account)
+	}
+	sort.Strings(accounts)
+	for _, account := range accounts {
+		fmt.Printf("   %-30s %s\n", account, formatCounters(*totals.Accounts[account]))
+	}
+
+	fmt.Printf("\n🕒 Latest %d runs:\n", min(statsHistoryShown, len(sessions)))
+	start := max(len(sessions)-statsHistoryShown, 0)
+	for i := len(sessions) - 1; i >= start; i-- {
+		session := sessions[i]
+		label := session.EndTime.Format("15:04")
+		if running && i == len(sessions)-1 {
+			label = "now (running)"
+		}
+		fmt.Printf("   %s - %s (%s)\n", session.StartTime.Format("2006-01-02 15:04"), label, session.Duration.Truncate(time.Second))
+		fmt.Printf("      %s\n", formatCounters(session.Counters))
+	}
+
+	fmt.Print("\nPress Enter to continue...")
+	bufio.NewReader(os.Stdin).ReadLine()
+}
+
+// formatCounters formats request and transaction counters
+func formatCounters(counters types.Counters) string {
+	text := fmt.Sprintf("Total: %d | Success: %d | Errors: %d | InvalidTokens: %d | TON: %d | Spent: %.4f TON",
+		counters.TotalRequests, counters.SuccessRequests, counters.FailedRequests,
+		counters.InvalidTokens, counters.SentTransactions, float64(counters.SpentNano)/1000000000)
+	if classes := counters.Errors.String(); classes != "" {
+		text += " (" + classes + ")"
+	}
+	return text
+}
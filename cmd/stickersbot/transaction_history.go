@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"stickersbot/internal/service"
+)
+
+// transactionHistoryShown number of matching transactions shown at once
+const transactionHistoryShown = 20
+
+// handleShowTransactionHistory shows sent transactions filtered by account, dates and status
+func (c *CLI) handleShowTransactionHistory() {
+	fmt.Println("🧾 Transaction history")
+	fmt.Println(strings.Repeat("-", 80))
+
+	reader := bufio.NewReader(os.Stdin)
+
+	transactions, err := c.storage.LoadTransactions(0)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		fmt.Print("Press Enter to continue...")
+		reader.ReadLine()
+		return
+	}
+
+	if len(transactions) == 0 {
+		fmt.Println("ℹ️  No transactions yet")
+		fmt.Print("Press Enter to continue...")
+		reader.ReadLine()
+		return
+	}
+
+	filter, err := readTransactionFilter(reader)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		fmt.Print("Press Enter to continue...")
+		reader.ReadLine()
+		return
+	}
+
+	matched := service.FilterTransactions(transactions, filter)
+	if len(matched) == 0 {
+		fmt.Println("\nℹ️  No transactions match the filter")
+		fmt.Print("Press Enter to continue...")
+		reader.ReadLine()
+		return
+	}
+
+	var totalNano int64
+	for _, tx := range matched {
+		totalNano += tx.Amount
+	}
+	fmt.Printf("\n🧮 %d of %d transactions | Total: %.4f TON\n", len(matched), len(transactions), float64(totalNano)/1000000000)
+
+	// Newest first, page by page
+	for start := 0; start < len(matched); start += transactionHistoryShown {
+		end := min(start+transactionHistoryShown, len(matched))
+		for _, tx := range matched[start:end] {
+			fmt.Printf("\n%s | %s | %.4f TON | %s\n", tx.Timestamp.Local().Format("2006-01-02 15:04:05"), tx.AccountName,
+				float64(tx.Amount)/1000000000, service.TransactionStatus(tx))
+			fmt.Printf("   Order: %s | Collection: %d | Character: %d\n", tx.OrderID, tx.Collection, tx.Character)
+			if tx.TransactionID != "" {
+				fmt.Printf("   TX: %s\n", tx.TransactionID)
+			}
+		}
+
+		if end == len(matched) {
+			break
+		}
+		fmt.Printf("\nShown %d of %d. Enter - more, q - stop: ", end, len(matched))
+		input, _ := reader.ReadString('\n')
+		if strings.ToLower(strings.TrimSpace(input)) == "q" {
+			break
+		}
+	}
+
+	// Raw records help with purchases that were paid but not credited
+	for {
+		fmt.Print("\nOrder ID to show raw order record (empty - back): ")
+		input, _ := reader.ReadString('\n')
+		orderID := strings.TrimSpace(input)
+		if orderID == "" {
+			return
+		}
+		c.showOrderRecords(orderID)
+	}
+}
+
+// showOrderRecords prints saved order request, response and payment of the order
+func (c *CLI) showOrderRecords(orderID string) {
+	records, err := c.storage.FindOrderRecords(orderID)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return
+	}
+	if len(records) == 0 {
+		fmt.Printf("ℹ️  No record of order %s\n", orderID)
+		return
+	}
+
+	for _, record := range records {
+		fmt.Printf("\n📦 Order %s | %s | %s\n", record.OrderID, record.AccountName, record.Result)
+		fmt.Printf("   Request: %s\n", record.Request)
+		fmt.Printf("   Response (HTTP %d at %s): %s\n", record.StatusCode,
+			record.RespondedAt.Local().Format("2006-01-02 15:04:05.000"), record.ResponseBody)
+		if record.TransactionID != "" || record.PaymentTo != "" {
+			fmt.Printf("   Payment: %.9f TON to %s, comment %q\n", float64(record.PaymentAmount)/1000000000, record.PaymentTo, record.PaymentComment)
+			fmt.Printf("   TX: %s (broadcast %s)\n", record.TransactionID, record.BroadcastAt.Local().Format("2006-01-02 15:04:05"))
+		}
+		if record.Error != "" {
+			fmt.Printf("   Error: %s\n", record.Error)
+		}
+	}
+}
+
+// readTransactionFilter asks for filter fields, empty answer skips the field
+func readTransactionFilter(reader *bufio.Reader) (service.TransactionFilter, error) {
+	var filter service.TransactionFilter
+
+	fmt.Print("Account (empty - all): ")
+	input, _ := reader.ReadString('\n')
+	filter.Account = strings.TrimSpace(input)
+
+	fmt.Print("From date YYYY-MM-DD (empty - any): ")
+	input, _ = reader.ReadString('\n')
+	if input = strings.TrimSpace(input); input != "" {
+		from, err := time.ParseInLocation("2006-01-02", input, time.Local)
+		if err != nil {
+			return filter, fmt.Errorf("invalid date %q", input)
+		}
+		filter.From = from
+	}
+
+	fmt.Print("To date YYYY-MM-DD, inclusive (empty - any): ")
+	input, _ = reader.ReadString('\n')
+	if input = strings.TrimSpace(input); input != "" {
+		to, err := time.ParseInLocation("2006-01-02", input, time.Local)
+		if err != nil {
+			return filter, fmt.Errorf("invalid date %q", input)
+		}
+		filter.To = to.AddDate(0, 0, 1)
+	}
+
+	statuses := []string{service.TxStatusCredited, service.TxStatusNotCredited, service.TxStatusUnchecked, service.TxStatusTest}
+	fmt.Printf("Status: %s (empty - any): ", strings.Join(statuses, ", "))
+	input, _ = reader.ReadString('\n')
+	if input = strings.ToLower(strings.TrimSpace(input)); input != "" {
+		for _, status := range statuses {
+			if input == status {
+				filter.Status = status
+			}
+		}
+		if filter.Status == "" {
+			return filter, fmt.Errorf("unknown status %q", input)
+		}
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"stickersbot/internal/update"
+	"stickersbot/internal/version"
+)
+
+var releasesUrl = fmt.Sprintf("https://%s/api/app/releases/%s", authHost, appId)
+
+// runUpdate checks release endpoint and replaces the binary with the newest signed release of the channel
+func runUpdate(args []string) error {
+	flags := flag.NewFlagSet("update", flag.ContinueOnError)
+	check := flags.Bool("check", false, "only report whether a newer release is available")
+	channel := flags.String("channel", update.Channel(version.Prerelease), "release channel: stable, rc, beta or dev (default: channel of this build)")
+	url := flags.String("url", releasesUrl, "release endpoint")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := update.ValidateChannel(*channel); err != nil {
+		return err
+	}
+
+	current := update.Release{Version: version.Version, Prerelease: version.Prerelease}
+	fmt.Printf("🔍 Checking for updates (current %s, channel %s, %s)...\n", current, *channel, update.Platform())
+
+	releases, err := update.FetchReleases(*url)
+	if err != nil {
+		return err
+	}
+	release, ok := update.Latest(releases, *channel, version.Version, version.Prerelease)
+	if !ok {
+		fmt.Println("✅ You are running the latest release")
+		return nil
+	}
+
+	fmt.Printf("🆕 Release %s is available\n", release)
+	if release.Notes != "" {
+		fmt.Println(release.Notes)
+	}
+	if *check {
+		return nil
+	}
+
+	fmt.Println("⬇️ Downloading and verifying signature...")
+	path, err := update.Apply(release)
+	if err != nil {
+		return fmt.Errorf("update failed: %w", err)
+	}
+	fmt.Printf("✅ Updated to %s: %s (previous binary kept as %s.old)\n", release, path, path)
+	fmt.Println("🔄 Restart the bot to run the new version")
+	return nil
+}
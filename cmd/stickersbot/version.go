@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"sync"
+
+	"stickersbot/internal/update"
+	"stickersbot/internal/version"
+)
+
+// requiredVersionHeader header of license server responses with the oldest version it still serves
+const requiredVersionHeader = "X-Required-Version"
+
+var requiredVersionWarned sync.Once
+
+// fillBuildInfo takes commit and build date from VCS information of the binary unless set at build time
+func fillBuildInfo() {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return
+	}
+
+	modified := false
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if version.Commit == "" {
+				version.Commit = setting.Value
+			}
+		case "vcs.time":
+			if version.BuildDate == "" {
+				version.BuildDate = setting.Value
+			}
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	if modified && len(version.Commit) >= 12 {
+		version.Commit = version.Commit[:12] + "-dirty"
+	}
+}
+
+// versionString returns version with pre-release marker
+func versionString() string {
+	return update.Release{Version: version.Version, Prerelease: version.Prerelease}.String()
+}
+
+// buildString returns short description of the build for header and logs
+func buildString() string {
+	commit := version.Commit
+	if commit == "" {
+		commit = "unknown"
+	} else if len(commit) > 12 && commit[12] != '-' {
+		commit = commit[:12]
+	}
+	built := version.BuildDate
+	if built == "" {
+		built = "unknown"
+	}
+	return fmt.Sprintf("v%s (commit %s, built %s)", versionString(), commit, built)
+}
+
+// printVersion prints full build information for version subcommand
+func printVersion() {
+	fmt.Printf("Version:    %s\n", versionString())
+	fmt.Printf("Channel:    %s\n", update.Channel(version.Prerelease))
+	fmt.Printf("Commit:     %s\n", valueOr(version.Commit, "unknown"))
+	fmt.Printf("Built:      %s\n", valueOr(version.BuildDate, "unknown"))
+	fmt.Printf("Go:         %s\n", runtime.Version())
+	fmt.Printf("Platform:   %s\n", update.Platform())
+	fmt.Printf("Production: %t\n", version.Production)
+}
+
+// valueOr returns value, or fallback if it is empty
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// warnRequiredVersion warns once when license server advertises a newer required version
+func warnRequiredVersion(resp *http.Response) {
+	required := resp.Header.Get(requiredVersionHeader)
+	if required == "" {
+		return
+	}
+
+	requiredVersion, requiredPrerelease := update.ParseVersion(required)
+	if update.Compare(version.Version, version.Prerelease, requiredVersion, requiredPrerelease) >= 0 {
+		return
+	}
+	requiredVersionWarned.Do(func() {
+		fmt.Printf("⚠️ Version %s or newer is required, this is %s. Run \"stickersbot update\" to upgrade\n", required, versionString())
+	})
+}
+
+// checkCompatibility looks for newer releases in background at startup and warns when
+// a release newer than this build is marked required
+func checkCompatibility() {
+	go func() {
+		releases, err := update.FetchReleases(releasesUrl)
+		if err != nil {
+			// Release endpoint being down must not bother the user
+			return
+		}
+
+		for _, release := range releases {
+			if release.Required && update.Compare(release.Version, release.Prerelease, version.Version, version.Prerelease) > 0 {
+				fmt.Printf("\n⚠️ Release %s is required, this build %s may no longer work. Run \"stickersbot update\" to upgrade\n",
+					release, versionString())
+				return
+			}
+		}
+		if release, ok := update.Latest(releases, update.Channel(version.Prerelease), version.Version, version.Prerelease); ok {
+			fmt.Printf("\n🆕 Release %s is available, run \"stickersbot update\" to install it\n", release)
+		}
+	}()
+}
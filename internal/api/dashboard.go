@@ -0,0 +1,19 @@
+package api
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// dashboardPage single page web dashboard using control API
+//
+//go:embed dashboard.html
+var dashboardPage []byte
+
+// handleDashboard serves web dashboard. The page holds no data itself,
+// it asks for the API token and polls the API
+func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write(dashboardPage)
+}
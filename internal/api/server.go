@@ -0,0 +1,230 @@
+// Package api serves HTTP control API of the running bot, so it can be driven
+// remotely or from scripts instead of the interactive menu
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"stickersbot/internal/service"
+	"stickersbot/internal/types"
+)
+
+// DefaultListen address of control API when listen is not configured
+const DefaultListen = "127.0.0.1:8080"
+
+// Controller actions of the bot available through API
+type Controller interface {
+	StartTask() error
+	StopTask() error
+	IsRunning() bool
+	State() service.State
+	Statistics() *types.Statistics
+	AccountStates() []service.AccountState
+	PauseAccount(name string) error
+	ResumeAccount(name string) error
+	RefreshToken(name string) error
+	RefreshConfig() error
+	Balances(ctx context.Context) []service.WalletInfo
+	Transactions(limit int) ([]types.TransactionLog, error)
+	Logs(after int64) []service.LogLine
+}
+
+// Server HTTP control API server
+type Server struct {
+	controller Controller
+	token      string
+	server     *http.Server
+}
+
+// NewServer creates control API server. Every API request must carry
+// "Authorization: Bearer <token>" header, dashboard page asks for the token
+func NewServer(listen string, token string, controller Controller) *Server {
+	if listen == "" {
+		listen = DefaultListen
+	}
+
+	s := &Server{
+		controller: controller,
+		token:      token,
+	}
+
+	s.server = &http.Server{
+		Addr:              listen,
+		Handler:           s.routes(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	return s
+}
+
+// Start starts serving requests in background. Errors after start are passed to onError
+func (s *Server) Start(onError func(error)) {
+	go func() {
+		if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed && onError != nil {
+			onError(err)
+		}
+	}()
+}
+
+// Stop stops the server
+func (s *Server) Stop(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
+// Addr returns listen address of the server
+func (s *Server) Addr() string {
+	return s.server.Addr
+}
+
+// routes registers dashboard and API endpoints
+func (s *Server) routes() http.Handler {
+	apiMux := http.NewServeMux()
+	apiMux.HandleFunc("GET /api/status", s.handleStatus)
+	apiMux.HandleFunc("GET /api/stats", s.handleStats)
+	apiMux.HandleFunc("GET /api/balances", s.handleBalances)
+	apiMux.HandleFunc("GET /api/transactions", s.handleTransactions)
+	apiMux.HandleFunc("GET /api/logs", s.handleLogs)
+	apiMux.HandleFunc("POST /api/start", s.handleStart)
+	apiMux.HandleFunc("POST /api/stop", s.handleStop)
+	apiMux.HandleFunc("POST /api/config/refresh", s.handleRefreshConfig)
+	apiMux.HandleFunc("POST /api/accounts/{name}/pause", s.handleAccountAction(s.controller.PauseAccount))
+	apiMux.HandleFunc("POST /api/accounts/{name}/resume", s.handleAccountAction(s.controller.ResumeAccount))
+	apiMux.HandleFunc("POST /api/accounts/{name}/refresh-token", s.handleAccountAction(s.controller.RefreshToken))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.handleDashboard)
+	mux.Handle("/api/", s.authorize(apiMux))
+
+	return mux
+}
+
+// authorize checks bearer token of request
+func (s *Server) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if s.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			writeError(w, http.StatusUnauthorized, fmt.Errorf("invalid or missing token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleStatus returns whether task is running and state of accounts
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"running":  s.controller.IsRunning(),
+		"state":    s.controller.State(),
+		"accounts": s.controller.AccountStates(),
+	})
+}
+
+// handleStats returns purchase statistics
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.controller.Statistics())
+}
+
+// handleBalances returns wallet balances of all accounts
+func (s *Server) handleBalances(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.controller.Balances(r.Context()))
+}
+
+// handleTransactions returns latest sent transactions, newest first
+func (s *Server) handleTransactions(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid limit %q", value))
+			return
+		}
+		limit = parsed
+	}
+
+	transactions, err := s.controller.Transactions(limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if transactions == nil {
+		transactions = []types.TransactionLog{}
+	}
+	writeJSON(w, http.StatusOK, transactions)
+}
+
+// handleLogs returns log lines after sequence number from "after" parameter
+func (s *Server) handleLogs(w http.ResponseWriter, r *http.Request) {
+	var after int64
+	if value := r.URL.Query().Get("after"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid after %q", value))
+			return
+		}
+		after = parsed
+	}
+	writeJSON(w, http.StatusOK, s.controller.Logs(after))
+}
+
+// handleStart starts purchase/monitoring task
+func (s *Server) handleStart(w http.ResponseWriter, r *http.Request) {
+	if err := s.controller.StartTask(); err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"ok": true})
+}
+
+// handleStop stops running task and returns final statistics
+func (s *Server) handleStop(w http.ResponseWriter, r *http.Request) {
+	if err := s.controller.StopTask(); err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"ok":         true,
+		"statistics": s.controller.Statistics(),
+	})
+}
+
+// handleRefreshConfig fetches remote configuration and applies it to the stopped task
+func (s *Server) handleRefreshConfig(w http.ResponseWriter, r *http.Request) {
+	if err := s.controller.RefreshConfig(); err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"ok": true})
+}
+
+// handleAccountAction runs action for account from request path
+func (s *Server) handleAccountAction(action func(name string) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := action(r.PathValue("name")); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"ok": true})
+	}
+}
+
+// writeJSON writes value as JSON response
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+// writeError writes error as JSON response
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]interface{}{
+		"ok":    false,
+		"error": err.Error(),
+	})
+}
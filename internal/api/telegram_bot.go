@@ -0,0 +1,232 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"stickersbot/internal/notify"
+	"stickersbot/internal/telegram"
+)
+
+// botPollTimeout long polling timeout of bot updates
+const botPollTimeout = 30 * time.Second
+
+// botHelp list of bot commands
+const botHelp = `🤖 Telegram Auto Buy control
+/start_task - start purchase/monitoring
+/stop - stop task
+/status - task and account state
+/stats - statistics
+/balances - wallet balances
+/pause <account> - pause purchases of account
+/resume <account> - resume purchases of account
+/refresh_token <account> - refresh token of account
+/refresh_config - fetch remote configuration (task must be stopped)`
+
+// TelegramBot controls the bot through Telegram bot chat. Only owners can
+// send commands, they also receive event notifications
+type TelegramBot struct {
+	bot        *telegram.BotAPI
+	owners     map[int64]bool
+	ownerIDs   []int64
+	controller Controller
+
+	// OnError is called when updates can't be received or reply can't be sent (optional)
+	OnError func(err error)
+}
+
+// NewTelegramBot creates Telegram bot control for owner user IDs
+func NewTelegramBot(token string, ownerIDs []int64, controller Controller) *TelegramBot {
+	owners := make(map[int64]bool, len(ownerIDs))
+	for _, id := range ownerIDs {
+		owners[id] = true
+	}
+
+	return &TelegramBot{
+		bot:        telegram.NewBotAPI(token),
+		owners:     owners,
+		ownerIDs:   ownerIDs,
+		controller: controller,
+	}
+}
+
+// Run receives and executes commands until context is cancelled
+func (t *TelegramBot) Run(ctx context.Context) {
+	var offset int64
+	for {
+		updates, err := t.bot.GetUpdates(ctx, offset, botPollTimeout)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			t.reportError(err)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(5 * time.Second):
+			}
+			continue
+		}
+
+		for _, update := range updates {
+			offset = update.UpdateID + 1
+			if update.Message == nil || update.Message.From == nil || update.Message.Text == "" {
+				continue
+			}
+			if !t.owners[update.Message.From.ID] {
+				t.reply(ctx, update.Message.Chat.ID, fmt.Sprintf("⛔ Access denied. Your user ID: %d", update.Message.From.ID))
+				continue
+			}
+
+			t.reply(ctx, update.Message.Chat.ID, t.execute(ctx, update.Message.Text))
+		}
+	}
+}
+
+// Notify sends event to all owners, implements notify.Notifier
+func (t *TelegramBot) Notify(ctx context.Context, event notify.Event) error {
+	var lastErr error
+	for _, id := range t.ownerIDs {
+		if err := t.bot.SendMessage(ctx, id, event.Severity.Icon()+" "+event.Text()); err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// execute runs command and returns reply text
+func (t *TelegramBot) execute(ctx context.Context, text string) string {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return botHelp
+	}
+
+	// Commands in groups may be addressed as /stats@botname
+	command := strings.SplitN(fields[0], "@", 2)[0]
+	argument := strings.TrimSpace(strings.TrimPrefix(text, fields[0]))
+
+	switch command {
+	case "/start", "/help":
+		return botHelp
+	case "/start_task":
+		if err := t.controller.StartTask(); err != nil {
+			return "❌ " + err.Error()
+		}
+		return "🚀 Task started"
+	case "/stop":
+		if err := t.controller.StopTask(); err != nil {
+			return "❌ " + err.Error()
+		}
+		return "🛑 Task stopped\n" + t.formatStats()
+	case "/status":
+		return t.formatStatus()
+	case "/stats":
+		return t.formatStats()
+	case "/balances":
+		return t.formatBalances(ctx)
+	case "/refresh_config":
+		if err := t.controller.RefreshConfig(); err != nil {
+			return "❌ " + err.Error()
+		}
+		return "🌍 Remote configuration applied"
+	case "/pause", "/resume", "/refresh_token":
+		if argument == "" {
+			return fmt.Sprintf("❌ Usage: %s <account>", command)
+		}
+		return t.accountAction(command, argument)
+	default:
+		return "❓ Unknown command\n\n" + botHelp
+	}
+}
+
+// accountAction runs per-account command
+func (t *TelegramBot) accountAction(command string, account string) string {
+	var (
+		action func(name string) error
+		done   string
+	)
+	switch command {
+	case "/pause":
+		action, done = t.controller.PauseAccount, "⏸️ Paused"
+	case "/resume":
+		action, done = t.controller.ResumeAccount, "▶️ Resumed"
+	default:
+		action, done = t.controller.RefreshToken, "🔑 Token refreshed for"
+	}
+
+	if err := action(account); err != nil {
+		return "❌ " + err.Error()
+	}
+	return fmt.Sprintf("%s '%s'", done, account)
+}
+
+// formatStatus formats task and account states
+func (t *TelegramBot) formatStatus() string {
+	var sb strings.Builder
+	if t.controller.IsRunning() {
+		sb.WriteString("🟢 Running\n")
+	} else {
+		sb.WriteString("⭕ Stopped\n")
+	}
+
+	for _, account := range t.controller.AccountStates() {
+		state := "active"
+		if account.Dead != "" {
+			state = "dead: " + account.Dead
+		} else if account.Paused {
+			state = "paused"
+		} else if !account.Active {
+			state = "finished"
+		}
+		sb.WriteString(fmt.Sprintf("• %s (%s) - %s\n", account.Name, account.Mode, state))
+	}
+	return sb.String()
+}
+
+// formatStats formats purchase statistics
+func (t *TelegramBot) formatStats() string {
+	stats := t.controller.Statistics()
+	text := fmt.Sprintf("📈 Total: %d | Success: %d | Errors: %d | TON: %d | RPS: %.1f | Time: %s",
+		stats.TotalRequests, stats.SuccessRequests, stats.FailedRequests, stats.SentTransactions,
+		stats.RequestsPerSec, stats.Duration.Truncate(time.Second))
+	if classes := stats.Errors.String(); classes != "" {
+		text += "\n⚠️ Errors: " + classes
+	}
+
+	for _, latency := range stats.Latencies {
+		text += fmt.Sprintf("\n⏱️ %s p50 %s / p95 %s", latency.Stage,
+			latency.P50.Round(time.Millisecond), latency.P95.Round(time.Millisecond))
+	}
+	return text
+}
+
+// formatBalances formats wallet balances
+func (t *TelegramBot) formatBalances(ctx context.Context) string {
+	var sb strings.Builder
+	sb.WriteString("💰 Balances\n")
+	for _, wallet := range t.controller.Balances(ctx) {
+		if wallet.Error != "" {
+			sb.WriteString(fmt.Sprintf("• %s - ❌ %s\n", wallet.AccountName, wallet.Error))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("• %s - %.4f %s\n", wallet.AccountName, wallet.Balance, wallet.Currency))
+	}
+	return sb.String()
+}
+
+// reply sends reply to chat
+func (t *TelegramBot) reply(ctx context.Context, chatID int64, text string) {
+	if err := t.bot.SendMessage(ctx, chatID, text); err != nil {
+		t.reportError(err)
+	}
+}
+
+// reportError passes error to OnError
+func (t *TelegramBot) reportError(err error) {
+	if t.OnError != nil {
+		t.OnError(err)
+	}
+}
@@ -41,9 +41,6 @@ type TelegramAuthResponse struct {
 
 // AuthenticateWithTelegramData performs authentication by sending authData to API
 func (c *HTTPClient) AuthenticateWithTelegramData(apiURL string, authData *AuthData) (*TelegramAuthResponse, error) {
-	// Log data being sent
-	fmt.Printf("🔍 Data being sent: %s\n", authData.Data[:min(100, len(authData.Data))])
-
 	// Send data as raw text (as in curl)
 	rawData := authData.Data
 
@@ -82,9 +79,6 @@ func (c *HTTPClient) AuthenticateWithTelegramData(apiURL string, authData *AuthD
 		}, err
 	}
 
-	// Log response for debugging
-	fmt.Printf("🔍 API Response: %s\n", string(body))
-
 	if resp.StatusCode == 200 {
 		var authResp AuthResponse
 		if err := json.Unmarshal(body, &authResp); err != nil {
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sync/atomic"
+	"time"
+
+	"stickersbot/internal/config"
+)
+
+// chaos faults injected into requests, nil - none
+var chaos atomic.Pointer[config.ChaosConfig]
+
+// SetChaos enables fault injection of test mode, disabled or nil settings turn it off
+func SetChaos(settings *config.ChaosConfig) {
+	if settings == nil || !settings.Enabled {
+		chaos.Store(nil)
+		return
+	}
+	copied := *settings
+	chaos.Store(&copied)
+}
+
+// chaosOrder delays order by chance and returns injected 429 or 503 response instead of
+// sending the request, nil if the order goes to the shop
+func chaosOrder(request string) *BuyStickersResponse {
+	settings := chaos.Load()
+	if settings == nil {
+		return nil
+	}
+
+	if settings.LatencyMs > 0 && rand.Float64() < settings.LatencyRate {
+		time.Sleep(time.Duration(settings.LatencyMs) * time.Millisecond)
+	}
+
+	status, code := 0, ""
+	switch roll := rand.Float64(); {
+	case roll < settings.RateLimitRate:
+		status, code = 429, "chaos_rate_limit"
+	case roll < settings.RateLimitRate+settings.ServerErrorRate:
+		status, code = 503, "chaos_server_error"
+	default:
+		return nil
+	}
+
+	return &BuyStickersResponse{
+		StatusCode:  status,
+		Body:        fmt.Sprintf(`{"ok":false,"errorCode":%q}`, code),
+		ErrorCode:   code,
+		RespondedAt: time.Now(),
+		Request:     request,
+	}
+}
+
+// chaosTransfer by chance waits until transfer context expires and fails like
+// a TON transfer that timed out, nothing is sent then
+func chaosTransfer(ctx context.Context) error {
+	settings := chaos.Load()
+	if settings == nil || rand.Float64() >= settings.TONTimeoutRate {
+		return nil
+	}
+
+	<-ctx.Done()
+	return fmt.Errorf("chaos: %w", ctx.Err())
+}
@@ -0,0 +1,111 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"stickersbot/internal/constants"
+)
+
+// InventoryItem character owned by account
+type InventoryItem struct {
+	Collection int
+	Character  int
+}
+
+// GetInventory returns characters owned by account. Items are collected from every object of
+// the response carrying collection and character IDs, so wrapping of the list doesn't matter
+func (c *HTTPClient) GetInventory(authToken string) ([]InventoryItem, error) {
+	headers := map[string]string{
+		"accept":          "application/json",
+		"accept-language": "ru-RU,ru;q=0.9,en-US;q=0.8,en;q=0.7",
+		"authorization":   fmt.Sprintf("Bearer %s", authToken),
+		"cache-control":   "no-cache",
+		"pragma":          "no-cache",
+		"User-Agent":      "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/136.0.0.0 Safari/537.36",
+	}
+
+	resp, err := c.Get(constants.InventoryAPIURL, headers)
+	if err != nil {
+		return nil, fmt.Errorf("GET request error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("response reading error: %v", err)
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("unsuccessful status code: %d", resp.StatusCode)
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, fmt.Errorf("JSON parsing error: %v", err)
+	}
+
+	var items []InventoryItem
+	collectInventory(data, &items)
+	return items, nil
+}
+
+// CheckToken requests inventory, the cheapest authenticated endpoint, and reports whether API
+// accepts the token. Status code is returned as well, error means the API wasn't reached
+func (c *HTTPClient) CheckToken(authToken string) (int, bool, error) {
+	headers := map[string]string{
+		"accept":          "application/json",
+		"accept-language": "ru-RU,ru;q=0.9,en-US;q=0.8,en;q=0.7",
+		"authorization":   fmt.Sprintf("Bearer %s", authToken),
+		"cache-control":   "no-cache",
+		"pragma":          "no-cache",
+		"User-Agent":      "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/136.0.0.0 Safari/537.36",
+	}
+
+	resp, err := c.Get(constants.InventoryAPIURL, headers)
+	if err != nil {
+		return 0, false, fmt.Errorf("GET request error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, false, fmt.Errorf("response reading error: %v", err)
+	}
+
+	return resp.StatusCode, !isTokenErrorResponse(resp.StatusCode, body), nil
+}
+
+// collectInventory walks JSON value and adds objects with collection and character IDs to items
+func collectInventory(value interface{}, items *[]InventoryItem) {
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			collectInventory(item, items)
+		}
+	case map[string]interface{}:
+		collection, okCollection := jsonID(v, "collection_id", "collection")
+		character, okCharacter := jsonID(v, "character_id", "character")
+		if okCollection && okCharacter {
+			*items = append(*items, InventoryItem{Collection: collection, Character: character})
+		}
+		for _, item := range v {
+			collectInventory(item, items)
+		}
+	}
+}
+
+// jsonID returns ID under first present key: a number or an object with "id"
+func jsonID(object map[string]interface{}, keys ...string) (int, bool) {
+	for _, key := range keys {
+		switch v := object[key].(type) {
+		case float64:
+			return int(v), true
+		case map[string]interface{}:
+			if id, ok := v["id"].(float64); ok {
+				return int(id), true
+			}
+		}
+	}
+	return 0, false
+}
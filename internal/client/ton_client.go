@@ -2,12 +2,19 @@ package client
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"math/big"
+	"net"
+	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"stickersbot/internal/config"
+	"stickersbot/internal/proxydial"
+
 	"github.com/xssnick/tonutils-go/address"
 	"github.com/xssnick/tonutils-go/liteclient"
 	"github.com/xssnick/tonutils-go/tlb"
@@ -22,9 +29,13 @@ type TransactionRequest struct {
 	Comment     string
 	TestMode    bool
 	TestAddress string
+	Deadline    time.Time // Transaction is dropped if it is not sent by this time (zero - no limit)
 	ResultChan  chan *TransactionResult
 }
 
+// TransactionQueueSize number of transactions waiting in queue of one wallet at most
+const TransactionQueueSize = 100
+
 // TransactionQueue transaction queue for one seed phrase
 type TransactionQueue struct {
 	wallet     *wallet.Wallet
@@ -55,7 +66,7 @@ func NewTransactionQueue(seedPhrase string, client *ton.APIClient) (*Transaction
 		wallet:     w,
 		client:     client,
 		seedPhrase: seedPhrase,
-		queue:      make(chan *TransactionRequest, 100), // Buffer for 100 transactions
+		queue:      make(chan *TransactionRequest, TransactionQueueSize),
 		ctx:        ctx,
 		cancel:     cancel,
 	}
@@ -95,6 +106,20 @@ func (tq *TransactionQueue) processTransaction(req *TransactionRequest) *Transac
 		toAddress = req.TestAddress
 	}
 
+	// Transaction waited in queue for too long, its order can't be paid anymore
+	if !req.Deadline.IsZero() && time.Now().After(req.Deadline) {
+		fmt.Printf("⌛ [QUEUE %s] Transaction deadline passed %s ago, transaction dropped\n",
+			maskedSeed, time.Since(req.Deadline).Round(time.Second))
+		return &TransactionResult{
+			FromAddress: tq.wallet.WalletAddress().String(),
+			ToAddress:   toAddress,
+			Amount:      req.Amount,
+			Comment:     req.Comment,
+			Success:     false,
+			Expired:     true,
+		}
+	}
+
 	// Parse recipient address
 	addr, err := address.ParseAddr(toAddress)
 	if err != nil {
@@ -134,8 +159,11 @@ func (tq *TransactionQueue) processTransaction(req *TransactionRequest) *Transac
 	txCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// Send transaction (does NOT wait for confirmation)
-	err = tq.wallet.Transfer(txCtx, addr, tlb.FromNanoTONU(uint64(req.Amount)), req.Comment)
+	// Send transaction (does NOT wait for confirmation), test mode may time it out on purpose
+	err = chaosTransfer(txCtx)
+	if err == nil {
+		err = tq.wallet.Transfer(txCtx, addr, tlb.FromNanoTONU(uint64(req.Amount)), req.Comment)
+	}
 	if err != nil {
 		fmt.Printf("❌ [QUEUE %s] Transfer failed: %v\n", maskedSeed, err)
 		return &TransactionResult{
@@ -148,6 +176,7 @@ func (tq *TransactionQueue) processTransaction(req *TransactionRequest) *Transac
 		}
 	}
 
+	broadcastAt := time.Now()
 	fmt.Printf("📤 [QUEUE %s] Transaction sent, waiting for confirmation (expected seqno: %d)...\n", maskedSeed, initialSeqno+1)
 
 	// Wait for transaction confirmation (seqno change)
@@ -179,6 +208,7 @@ func (tq *TransactionQueue) processTransaction(req *TransactionRequest) *Transac
 			Amount:        req.Amount,
 			Comment:       req.Comment,
 			Success:       false,
+			BroadcastAt:   broadcastAt,
 		}
 	}
 
@@ -190,6 +220,7 @@ func (tq *TransactionQueue) processTransaction(req *TransactionRequest) *Transac
 		Amount:        req.Amount,
 		Comment:       req.Comment,
 		Success:       true,
+		BroadcastAt:   broadcastAt,
 	}
 
 	fmt.Printf("🎉 [QUEUE %s] Transaction completed successfully!\n", maskedSeed)
@@ -323,6 +354,13 @@ func formatTON(nanoTON *big.Int) string {
 
 // AddTransaction adds transaction to queue and waits for result
 func (tq *TransactionQueue) AddTransaction(toAddress string, amount int64, comment string, testMode bool, testAddress string) *TransactionResult {
+	// Wait for result (may take up to 60 seconds per transaction)
+	return <-tq.Enqueue(toAddress, amount, comment, testMode, testAddress, time.Time{})
+}
+
+// Enqueue adds transaction to queue without waiting, its result is delivered to returned channel.
+// Transaction not sent before deadline is dropped (zero deadline - no limit)
+func (tq *TransactionQueue) Enqueue(toAddress string, amount int64, comment string, testMode bool, testAddress string, deadline time.Time) <-chan *TransactionResult {
 	resultChan := make(chan *TransactionResult, 1)
 
 	req := &TransactionRequest{
@@ -331,26 +369,28 @@ func (tq *TransactionQueue) AddTransaction(toAddress string, amount int64, comme
 		Comment:     comment,
 		TestMode:    testMode,
 		TestAddress: testAddress,
+		Deadline:    deadline,
 		ResultChan:  resultChan,
 	}
 
 	// Add to queue
-	select {
-	case tq.queue <- req:
-		// Wait for result (may take up to 60 seconds per transaction)
-		result := <-resultChan
-		return result
-	case <-time.After(5 * time.Second):
-		// Queue addition timeout
-		return &TransactionResult{
-			FromAddress:   tq.wallet.WalletAddress().String(),
-			ToAddress:     toAddress,
-			TransactionID: "",
-			Amount:        amount,
-			Comment:       comment,
-			Success:       false,
+	go func() {
+		select {
+		case tq.queue <- req:
+		case <-time.After(5 * time.Second):
+			// Queue addition timeout
+			resultChan <- &TransactionResult{
+				FromAddress:   tq.wallet.WalletAddress().String(),
+				ToAddress:     toAddress,
+				TransactionID: "",
+				Amount:        amount,
+				Comment:       comment,
+				Success:       false,
+			}
 		}
-	}
+	}()
+
+	return resultChan
 }
 
 // Close closes transaction queue
@@ -378,13 +418,17 @@ var managersOnce sync.Once
 var managersMu sync.RWMutex
 
 // getWalletManager returns wallet manager instance for specific proxy settings
-func getWalletManager(useProxy bool, proxyURL string) *WalletManager {
+func getWalletManager(useProxy bool, proxyURL string) (*WalletManager, error) {
+	// Proxy enabled without TON proxy connects directly, like disabled one
+	if !useProxy || proxyURL == "" {
+		useProxy, proxyURL = false, ""
+	}
 	key := WalletManagerKey{UseProxy: useProxy, ProxyURL: proxyURL}
 
 	managersMu.RLock()
 	if manager, exists := globalWalletManagers[key]; exists {
 		managersMu.RUnlock()
-		return manager
+		return manager, nil
 	}
 	managersMu.RUnlock()
 
@@ -393,29 +437,40 @@ func getWalletManager(useProxy bool, proxyURL string) *WalletManager {
 
 	// Double check after getting write lock
 	if manager, exists := globalWalletManagers[key]; exists {
-		return manager
+		return manager, nil
 	}
 
-	// Create new manager
-	manager := createWalletManager(useProxy, proxyURL)
+	// Create new manager, failed connection is tried again by the next call
+	manager, err := createWalletManager(useProxy, proxyURL)
+	if err != nil {
+		return nil, err
+	}
 	globalWalletManagers[key] = manager
-	return manager
+	return manager, nil
 }
 
+// tonConfigURL global configuration of TON mainnet with liteserver addresses
+const tonConfigURL = "https://ton.org/global.config.json"
+
+// tonProxyConnectTimeout time liteserver connections through proxy are waited for
+const tonProxyConnectTimeout = 15 * time.Second
+
 // createWalletManager creates a new wallet manager with optional proxy
-func createWalletManager(useProxy bool, proxyURL string) *WalletManager {
+func createWalletManager(useProxy bool, proxyURL string) (*WalletManager, error) {
 	// Connect to TON mainnet
 	connection := liteclient.NewConnectionPool()
 
-	// TODO: Add proxy support to liteclient when available
-	// For now, note that TON liteclient doesn't support proxy directly
-	// This would require custom implementation or waiting for library update
-
-	// Add public configurations
-	configUrl := "https://ton.org/global.config.json"
-	err := connection.AddConnectionsFromConfigUrl(context.Background(), configUrl)
-	if err != nil {
-		panic(fmt.Errorf("error connecting to TON: %v", err))
+	if useProxy {
+		if err := addProxiedConnections(connection, proxyURL); err != nil {
+			connection.Stop()
+			return nil, fmt.Errorf("error connecting to TON through proxy: %v", err)
+		}
+	} else {
+		// Add public configurations
+		err := connection.AddConnectionsFromConfigUrl(context.Background(), tonConfigURL)
+		if err != nil {
+			return nil, fmt.Errorf("error connecting to TON: %v", err)
+		}
 	}
 
 	// Create API client
@@ -423,6 +478,73 @@ func createWalletManager(useProxy bool, proxyURL string) *WalletManager {
 
 	return &WalletManager{
 		client: client,
+	}, nil
+}
+
+// tonProxyDialer returns dialer of TON proxy. Proxy without scheme is HTTP proxy, like for shop API requests
+func tonProxyDialer(proxyURL string) (proxydial.Dialer, error) {
+	hops, err := config.ParseProxyChain(proxyURL)
+	if err != nil {
+		return nil, err
+	}
+	return proxydial.Chain(hops, config.ProxySchemeHTTP)
+}
+
+// addProxiedConnections connects pool to liteservers through proxy or proxy chain. Liteclient
+// dials liteservers itself, so each of them is reached through local tunnel of the proxy
+func addProxiedConnections(connection *liteclient.ConnectionPool, proxyURL string) error {
+	dialer, err := tonProxyDialer(proxyURL)
+	if err != nil {
+		return err
+	}
+
+	// Network configuration is loaded through the proxy as well
+	httpClient := &http.Client{
+		Transport: &http.Transport{DialContext: dialer.DialContext},
+		Timeout:   30 * time.Second,
+	}
+	resp, err := httpClient.Get(tonConfigURL)
+	if err != nil {
+		return fmt.Errorf("loading network configuration: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("loading network configuration: status %d", resp.StatusCode)
+	}
+
+	var global liteclient.GlobalConfig
+	if err := json.NewDecoder(resp.Body).Decode(&global); err != nil {
+		return fmt.Errorf("parsing network configuration: %v", err)
+	}
+
+	var tunnels []net.Listener
+	for i, ls := range global.Liteservers {
+		target := net.JoinHostPort(net.IPv4(byte(ls.IP>>24), byte(ls.IP>>16), byte(ls.IP>>8), byte(ls.IP)).String(), strconv.Itoa(ls.Port))
+		tunnel, err := proxydial.Tunnel(dialer, target)
+		if err != nil {
+			closeTunnels(tunnels)
+			return err
+		}
+		tunnels = append(tunnels, tunnel)
+
+		// Liteserver is addressed by its key, so it is reached at the tunnel address as well
+		global.Liteservers[i].IP = 0x7f000001 // 127.0.0.1
+		global.Liteservers[i].Port = tunnel.Addr().(*net.TCPAddr).Port
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), tonProxyConnectTimeout)
+	defer cancel()
+	if err := connection.AddConnectionsFromConfig(ctx, &global); err != nil {
+		closeTunnels(tunnels)
+		return err
+	}
+	return nil
+}
+
+// closeTunnels stops local tunnels of liteservers
+func closeTunnels(tunnels []net.Listener) {
+	for _, tunnel := range tunnels {
+		tunnel.Close()
 	}
 }
 
@@ -475,7 +597,10 @@ func NewTONClient(seedPhrase string) (*TONClient, error) {
 
 // NewTONClientWithProxy creates a new TON client with proxy support
 func NewTONClientWithProxy(seedPhrase string, useProxy bool, proxyURL string) (*TONClient, error) {
-	wm := getWalletManager(useProxy, proxyURL)
+	wm, err := getWalletManager(useProxy, proxyURL)
+	if err != nil {
+		return nil, err
+	}
 
 	// Get or create queue for this seed phrase
 	queue, err := getOrCreateQueue(seedPhrase, wm.client)
@@ -499,13 +624,15 @@ type TransactionResult struct {
 	Amount        int64
 	Comment       string
 	Success       bool
+	BroadcastAt   time.Time // Time the transaction was sent to network (zero if it was not sent)
+	Expired       bool      // Deadline passed while transaction waited in queue, nothing was sent
 }
 
 // SendTON sends TON transaction through queue and returns information about it
 func (c *TONClient) SendTON(ctx context.Context, toAddress string, amount int64, comment string, testMode bool, testAddress string) (*TransactionResult, error) {
 	// Add transaction to queue and wait for result
 	// This may take time as transaction waits for confirmation
-	result := c.queue.AddTransaction(toAddress, amount, comment, testMode, testAddress)
+	result := <-c.SendTONAsync(toAddress, amount, comment, testMode, testAddress, time.Time{})
 
 	if !result.Success {
 		return result, fmt.Errorf("transaction failed")
@@ -514,9 +641,18 @@ func (c *TONClient) SendTON(ctx context.Context, toAddress string, amount int64,
 	return result, nil
 }
 
+// SendTONAsync adds TON transaction to queue and returns channel receiving its result.
+// Transaction not sent before deadline is dropped (zero deadline - no limit)
+func (c *TONClient) SendTONAsync(toAddress string, amount int64, comment string, testMode bool, testAddress string, deadline time.Time) <-chan *TransactionResult {
+	return c.queue.Enqueue(toAddress, amount, comment, testMode, testAddress, deadline)
+}
+
 // GetBalance gets wallet balance
 func (c *TONClient) GetBalance(ctx context.Context) (*big.Int, error) {
-	wm := getWalletManager(c.useProxy, c.proxyURL)
+	wm, err := getWalletManager(c.useProxy, c.proxyURL)
+	if err != nil {
+		return nil, err
+	}
 	block, err := wm.client.CurrentMasterchainInfo(ctx)
 	if err != nil {
 		return nil, err
@@ -530,6 +666,13 @@ func (c *TONClient) GetBalance(ctx context.Context) (*big.Int, error) {
 	return balance.NanoTON(), nil
 }
 
+// Warmup connects to TON liteservers and loads wallet ahead of time, so the first
+// payment does not wait for network configuration and connection setup
+func (c *TONClient) Warmup(ctx context.Context) error {
+	_, err := c.GetBalance(ctx)
+	return err
+}
+
 // GetAddress returns wallet address
 func (c *TONClient) GetAddress() *address.Address {
 	return c.queue.wallet.WalletAddress()
@@ -0,0 +1,75 @@
+package client
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+)
+
+// fakeConnectProxy accepts one HTTP CONNECT request and reports its target
+func fakeConnectProxy(t *testing.T) (string, <-chan string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	targets := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		req, err := http.ReadRequest(bufio.NewReader(conn))
+		if err != nil {
+			targets <- "not HTTP: " + err.Error()
+			return
+		}
+		targets <- req.Method + " " + req.Host + " " + req.Header.Get("Proxy-Authorization")
+		io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+	}()
+	return listener.Addr().String(), targets
+}
+
+func TestTONProxyDialer(t *testing.T) {
+	tests := []struct {
+		name  string
+		proxy func(addr string) string
+		want  string
+	}{
+		{"schemeless proxy is HTTP", func(addr string) string { return addr }, "CONNECT 10.0.0.1:443 "},
+		{"schemeless proxy with credentials", func(addr string) string { return addr + ":user:pass" },
+			"CONNECT 10.0.0.1:443 Basic dXNlcjpwYXNz"},
+		{"http scheme", func(addr string) string { return "http://" + addr }, "CONNECT 10.0.0.1:443 "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr, targets := fakeConnectProxy(t)
+
+			dialer, err := tonProxyDialer(tt.proxy(addr))
+			if err != nil {
+				t.Fatal(err)
+			}
+			conn, err := dialer.Dial("tcp", "10.0.0.1:443")
+			if err != nil {
+				t.Fatalf("Dial() = %v", err)
+			}
+			conn.Close()
+
+			if got := <-targets; got != tt.want {
+				t.Errorf("proxy got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTONProxyDialerInvalid(t *testing.T) {
+	if _, err := tonProxyDialer("not a proxy"); err == nil {
+		t.Error("tonProxyDialer() = nil error for invalid proxy")
+	}
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+)
+
+// ProxyAuthFile default name of proxy credentials file in state directory
+const ProxyAuthFile = "proxies_auth.json"
+
+// ProxyAuthAny key of credentials used for proxies without own entry
+const ProxyAuthAny = "*"
+
+// ProxyCredentials username and password of proxy. Both may be secret references ("env:NAME", "keyring:name")
+type ProxyCredentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// ProxyAuth credentials of proxies by "host:port", "host" or "*" for all other proxies
+type ProxyAuth map[string]ProxyCredentials
+
+var (
+	proxyAuth   ProxyAuth
+	proxyAuthMu sync.RWMutex
+)
+
+// ProxyAuthPath returns path of proxy credentials file of configuration
+func (c *Config) ProxyAuthPath() string {
+	if c.ProxyAuthFile != "" {
+		return StatePath(c.ProxyAuthFile)
+	}
+	return StatePath(ProxyAuthFile)
+}
+
+// LoadProxyAuth reads proxy credentials file and resolves secret references in it.
+// Missing file gives empty credentials
+func LoadProxyAuth(path string) (ProxyAuth, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ProxyAuth{}, nil
+		}
+		return nil, err
+	}
+
+	var auth ProxyAuth
+	if err := json.Unmarshal(data, &auth); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	for key, credentials := range auth {
+		if credentials.Username, err = ResolveSecret(credentials.Username); err != nil {
+			return nil, fmt.Errorf("%s: username of %s: %v", path, key, err)
+		}
+		if credentials.Password, err = ResolveSecret(credentials.Password); err != nil {
+			return nil, fmt.Errorf("%s: password of %s: %v", path, key, err)
+		}
+		if credentials.Username == "" {
+			return nil, fmt.Errorf("%s: username of %s cannot be empty", path, key)
+		}
+		auth[key] = credentials
+	}
+	return auth, nil
+}
+
+// SetProxyAuth sets credentials used for proxies whose proxy_url has none
+func SetProxyAuth(auth ProxyAuth) {
+	proxyAuthMu.Lock()
+	defer proxyAuthMu.Unlock()
+
+	proxyAuth = auth
+}
+
+// lookupProxyAuth returns credentials of proxy: entry of its host:port, of its host, or "*"
+func lookupProxyAuth(host, port string) (ProxyCredentials, bool) {
+	proxyAuthMu.RLock()
+	defer proxyAuthMu.RUnlock()
+
+	for _, key := range []string{net.JoinHostPort(host, port), host, ProxyAuthAny} {
+		if credentials, ok := proxyAuth[key]; ok {
+			return credentials, true
+		}
+	}
+	return ProxyCredentials{}, false
+}
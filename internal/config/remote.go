@@ -0,0 +1,247 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// RemoteConfigFile verified copy of the latest remote configuration, used while the URL is unreachable
+const RemoteConfigFile = "remote_config.json"
+
+// Scopes of remote configuration
+const (
+	RemoteScopeTargets = "targets" // Purchase targets and snipe filters of accounts
+	RemoteScopeConfig  = "config"  // The whole configuration
+)
+
+// SignatureHeader response header with signature of remote configuration.
+// Without it the signature is fetched from the URL with ".sig" appended to its path
+const SignatureHeader = "X-Signature"
+
+const (
+	remoteTimeout = 30 * time.Second
+	remoteMaxSize = 4 << 20
+)
+
+// remoteTargetFields account fields a targets document may set, everything else stays local
+var remoteTargetFields = map[string]bool{
+	"collection":       true,
+	"character":        true,
+	"currency":         true,
+	"count":            true,
+	"max_transactions": true,
+	"max_price_nano":   true,
+	"until_sold_out":   true,
+	"start_at":         true,
+	"fallbacks":        true,
+	"snipe_monitor":    true,
+}
+
+// RemoteConfig configuration fetched from URL, so targets of many instances are changed in one place
+type RemoteConfig struct {
+	URL        string `json:"url"`                   // HTTPS URL of the document
+	Scope      string `json:"scope,omitempty"`       // "targets" (default) or "config"
+	HMACSecret string `json:"hmac_secret,omitempty"` // Secret of hex HMAC-SHA256 signature, "env:VAR" or "keyring:name" reference
+	PublicKey  string `json:"public_key,omitempty"`  // Hex ed25519 public key of base64 signature, takes precedence over hmac_secret
+	Token      string `json:"token,omitempty"`       // Bearer token sent with requests, "env:VAR" or "keyring:name" reference (optional)
+}
+
+// remoteTargets document of targets scope: account fields by account name, "*" for all accounts
+type remoteTargets struct {
+	Accounts map[string]map[string]json.RawMessage `json:"accounts"`
+}
+
+// FetchRemote downloads remote configuration and verifies its signature. Returns the verified document
+func FetchRemote(rc *RemoteConfig) ([]byte, error) {
+	target, err := url.Parse(rc.URL)
+	if err != nil || target.Scheme != "https" || target.Host == "" {
+		return nil, fmt.Errorf("url must be an https:// URL, got %q", rc.URL)
+	}
+	if rc.PublicKey == "" && rc.HMACSecret == "" {
+		return nil, errors.New("public_key or hmac_secret must be set, unsigned configuration is not accepted")
+	}
+
+	body, header, err := fetchRemote(rc, target.String())
+	if err != nil {
+		return nil, err
+	}
+
+	signature := strings.TrimSpace(header.Get(SignatureHeader))
+	if signature == "" {
+		target.Path += ".sig"
+		data, _, err := fetchRemote(rc, target.String())
+		if err != nil {
+			return nil, fmt.Errorf("no %s header, signature file: %w", SignatureHeader, err)
+		}
+		signature = strings.TrimSpace(string(data))
+	}
+
+	if err := verifyRemote(rc, body, signature); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// fetchRemote downloads document from URL with bearer token of remote configuration
+func fetchRemote(rc *RemoteConfig, target string) ([]byte, http.Header, error) {
+	req, err := http.NewRequest(http.MethodGet, target, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if rc.Token != "" {
+		token, err := ResolveSecret(rc.Token)
+		if err != nil {
+			return nil, nil, fmt.Errorf("token: %v", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	httpClient := &http.Client{Timeout: remoteTimeout}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fetching %s: %v", target, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("fetching %s: status %d", target, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, remoteMaxSize+1))
+	if err != nil {
+		return nil, nil, fmt.Errorf("fetching %s: %v", target, err)
+	}
+	if len(body) > remoteMaxSize {
+		return nil, nil, fmt.Errorf("fetching %s: document is larger than %d bytes", target, remoteMaxSize)
+	}
+	return body, resp.Header, nil
+}
+
+// verifyRemote checks signature of document: base64 ed25519 signature if public key is set,
+// hex HMAC-SHA256 ("sha256=" prefix allowed) otherwise
+func verifyRemote(rc *RemoteConfig, body []byte, signature string) error {
+	if rc.PublicKey != "" {
+		key, err := hex.DecodeString(rc.PublicKey)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return errors.New("public_key must be a hex ed25519 public key")
+		}
+		sig, err := base64.StdEncoding.DecodeString(signature)
+		if err != nil || !ed25519.Verify(key, body, sig) {
+			return errors.New("signature of remote configuration is not valid")
+		}
+		return nil
+	}
+
+	secret, err := ResolveSecret(rc.HMACSecret)
+	if err != nil {
+		return fmt.Errorf("hmac_secret: %v", err)
+	}
+	sig, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil {
+		return errors.New("signature of remote configuration is not valid")
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return errors.New("signature of remote configuration is not valid")
+	}
+	return nil
+}
+
+// ApplyRemote applies verified remote document to configuration according to its scope.
+// remote_config and state_dir always stay local
+func (c *Config) ApplyRemote(data []byte) error {
+	switch c.RemoteConfig.Scope {
+	case "", RemoteScopeTargets:
+		return c.applyRemoteTargets(data)
+	case RemoteScopeConfig:
+		remote := Default()
+		if err := json.Unmarshal(data, remote); err != nil {
+			return fmt.Errorf("parsing remote configuration: %w", err)
+		}
+		remote.RemoteConfig, remote.StateDir, remote.path = c.RemoteConfig, c.StateDir, c.path
+		*c = *remote
+		return nil
+	default:
+		return fmt.Errorf("unknown scope %q, expected %q or %q", c.RemoteConfig.Scope, RemoteScopeTargets, RemoteScopeConfig)
+	}
+}
+
+// applyRemoteTargets sets target fields of accounts from document of targets scope.
+// Entry "*" applies to every account, entry of account name is applied after it.
+// Entries of accounts this configuration doesn't have are ignored
+func (c *Config) applyRemoteTargets(data []byte) error {
+	var doc remoteTargets
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("parsing remote targets: %w", err)
+	}
+
+	for name, fields := range doc.Accounts {
+		for field := range fields {
+			if !remoteTargetFields[field] {
+				return fmt.Errorf("remote targets of %s: field %q can't be set remotely", name, field)
+			}
+		}
+	}
+
+	for i := range c.Accounts {
+		account := &c.Accounts[i]
+		for _, name := range []string{"*", account.Name} {
+			fields, ok := doc.Accounts[name]
+			if !ok {
+				continue
+			}
+			// Fallbacks are replaced as a whole, not merged with local ones element by element
+			if _, ok := fields["fallbacks"]; ok {
+				account.Fallbacks = nil
+			}
+			data, err := json.Marshal(fields)
+			if err != nil {
+				return err
+			}
+			if err := json.Unmarshal(data, account); err != nil {
+				return fmt.Errorf("remote targets of %s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Clone returns deep copy of configuration
+func (c *Config) Clone() (*Config, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	clone := &Config{}
+	if err := json.Unmarshal(data, clone); err != nil {
+		return nil, err
+	}
+	clone.path = c.path
+	return clone, nil
+}
+
+// SaveRemoteCache keeps verified remote document for starts while the URL is unreachable
+func SaveRemoteCache(data []byte) error {
+	return WriteFileAtomic(StatePath(RemoteConfigFile), data, 0600)
+}
+
+// LoadRemoteCache returns the latest verified remote document, nil if there is none
+func LoadRemoteCache() ([]byte, error) {
+	data, err := os.ReadFile(StatePath(RemoteConfigFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return data, err
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Prefixes of secret references in config values
+const (
+	SecretEnvPrefix     = "env:"     // "env:ACC1_2FA" - value of environment variable
+	SecretKeyringPrefix = "keyring:" // "keyring:acc1" - password stored in OS keychain under KeyringService
+)
+
+// KeyringService service name secrets are stored under in OS keychain
+const KeyringService = "stickersbot"
+
+// ResolveSecret returns value of secret reference, other values are returned as is
+func ResolveSecret(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, SecretEnvPrefix):
+		name := strings.TrimPrefix(value, SecretEnvPrefix)
+		secret, ok := os.LookupEnv(name)
+		if !ok || secret == "" {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return secret, nil
+	case strings.HasPrefix(value, SecretKeyringPrefix):
+		return keyringSecret(strings.TrimPrefix(value, SecretKeyringPrefix))
+	default:
+		return value, nil
+	}
+}
+
+// keyringSecret reads secret from OS keychain with its command line tool:
+// security on macOS, secret-tool (libsecret) on Linux
+func keyringSecret(name string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("keyring entry name is empty")
+	}
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("security", "find-generic-password", "-s", KeyringService, "-a", name, "-w")
+	case "linux", "freebsd", "openbsd":
+		cmd = exec.Command("secret-tool", "lookup", "service", KeyringService, "account", name)
+	default:
+		return "", fmt.Errorf("keyring is not supported on %s, use %s reference instead", runtime.GOOS, SecretEnvPrefix)
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("reading keyring entry %s/%s: %v", KeyringService, name, err)
+	}
+
+	secret := strings.TrimRight(string(output), "\r\n")
+	if secret == "" {
+		return "", fmt.Errorf("keyring entry %s/%s not found", KeyringService, name)
+	}
+	return secret, nil
+}
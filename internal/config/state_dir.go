@@ -0,0 +1,30 @@
+package config
+
+import "path/filepath"
+
+// ConfigFile name of configuration file in state directory
+const ConfigFile = "config.json"
+
+// stateDir directory runtime files (config, sessions, tokens, logs) are kept in
+var stateDir = "."
+
+// SetStateDir sets directory runtime files are resolved against, empty - current directory
+func SetStateDir(dir string) {
+	if dir == "" {
+		dir = "."
+	}
+	stateDir = dir
+}
+
+// StateDir returns directory of runtime files
+func StateDir() string {
+	return stateDir
+}
+
+// StatePath resolves path of runtime file against state directory. Absolute paths are kept
+func StatePath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(stateDir, path)
+}
@@ -8,6 +8,9 @@ const (
 	APIBaseURL  = "https://stickerdom.store"
 	TokenAPIURL = "https://api.stickerdom.store/api/v1"
 
+	// Stickers owned by the authorized account
+	InventoryAPIURL = TokenAPIURL + "/inventory"
+
 	// Web App URL (используем APIBaseURL для избежания дублирования)
 	WebAppURL = APIBaseURL
 )
@@ -0,0 +1,269 @@
+// Package filter implements expression language for snipe rules, e.g.
+//
+//	price <= 5 TON && supply < 3000 && title ~ "cat" && creator.verified
+//
+// Supported: numbers with optional TON unit, strings, true/false, variables,
+// comparisons (== != < <= > >=), case-insensitive contains (~, !~),
+// logical operators (&& || !) and parentheses.
+package filter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Env values of variables available to expression.
+// Values are float64, string, bool or []string
+type Env map[string]interface{}
+
+// Expression compiled filter expression
+type Expression struct {
+	source string
+	root   node
+}
+
+// Compile parses expression
+func Compile(source string) (*Expression, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos)
+	}
+
+	return &Expression{source: source, root: root}, nil
+}
+
+// String returns source of expression
+func (e *Expression) String() string {
+	return e.source
+}
+
+// Match evaluates expression, result must be boolean
+func (e *Expression) Match(env Env) (bool, error) {
+	value, err := e.root.eval(env)
+	if err != nil {
+		return false, err
+	}
+	result, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("expression result is %s, not boolean", typeName(value))
+	}
+	return result, nil
+}
+
+// node element of expression tree
+type node interface {
+	eval(env Env) (interface{}, error)
+}
+
+// literalNode constant value
+type literalNode struct {
+	value interface{}
+}
+
+func (n *literalNode) eval(env Env) (interface{}, error) {
+	return n.value, nil
+}
+
+// identNode variable reference
+type identNode struct {
+	name string
+}
+
+func (n *identNode) eval(env Env) (interface{}, error) {
+	value, ok := env[n.name]
+	if !ok {
+		return nil, fmt.Errorf("unknown variable %q", n.name)
+	}
+	return value, nil
+}
+
+// notNode logical negation
+type notNode struct {
+	operand node
+}
+
+func (n *notNode) eval(env Env) (interface{}, error) {
+	value, err := evalBool(n.operand, env)
+	if err != nil {
+		return nil, err
+	}
+	return !value, nil
+}
+
+// logicalNode && and || with short-circuit evaluation
+type logicalNode struct {
+	op          string
+	left, right node
+}
+
+func (n *logicalNode) eval(env Env) (interface{}, error) {
+	left, err := evalBool(n.left, env)
+	if err != nil {
+		return nil, err
+	}
+	if n.op == "&&" && !left {
+		return false, nil
+	}
+	if n.op == "||" && left {
+		return true, nil
+	}
+	return evalBool(n.right, env)
+}
+
+// compareNode comparison of two values
+type compareNode struct {
+	op          string
+	left, right node
+}
+
+func (n *compareNode) eval(env Env) (interface{}, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.op == "~" || n.op == "!~" {
+		matched, err := contains(left, right)
+		if err != nil {
+			return nil, err
+		}
+		return matched == (n.op == "~"), nil
+	}
+
+	switch l := left.(type) {
+	case float64:
+		r, ok := right.(float64)
+		if !ok {
+			break
+		}
+		switch n.op {
+		case "==":
+			return l == r, nil
+		case "!=":
+			return l != r, nil
+		case "<":
+			return l < r, nil
+		case "<=":
+			return l <= r, nil
+		case ">":
+			return l > r, nil
+		case ">=":
+			return l >= r, nil
+		}
+	case string:
+		r, ok := right.(string)
+		if !ok {
+			break
+		}
+		switch n.op {
+		case "==":
+			return strings.EqualFold(l, r), nil
+		case "!=":
+			return !strings.EqualFold(l, r), nil
+		}
+	case bool:
+		r, ok := right.(bool)
+		if !ok {
+			break
+		}
+		switch n.op {
+		case "==":
+			return l == r, nil
+		case "!=":
+			return l != r, nil
+		}
+	}
+
+	return nil, fmt.Errorf("operator %s cannot compare %s and %s", n.op, typeName(left), typeName(right))
+}
+
+// evalBool evaluates node which must produce boolean
+func evalBool(n node, env Env) (bool, error) {
+	value, err := n.eval(env)
+	if err != nil {
+		return false, err
+	}
+	result, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("expected boolean, got %s", typeName(value))
+	}
+	return result, nil
+}
+
+// contains checks case-insensitively if string (or any element of list) contains substring
+func contains(value interface{}, substr interface{}) (bool, error) {
+	needle, ok := substr.(string)
+	if !ok {
+		return false, fmt.Errorf("right side of ~ must be string, got %s", typeName(substr))
+	}
+	needle = strings.ToLower(needle)
+
+	switch v := value.(type) {
+	case string:
+		return strings.Contains(strings.ToLower(v), needle), nil
+	case []string:
+		for _, item := range v {
+			if strings.Contains(strings.ToLower(item), needle) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return false, fmt.Errorf("left side of ~ must be string or list, got %s", typeName(value))
+}
+
+// typeName returns name of value type for error messages
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case []string:
+		return "list"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// Variables returns names of variables used in expression
+func (e *Expression) Variables() []string {
+	var names []string
+	seen := make(map[string]bool)
+
+	var walk func(n node)
+	walk = func(n node) {
+		switch v := n.(type) {
+		case *identNode:
+			if !seen[v.name] {
+				seen[v.name] = true
+				names = append(names, v.name)
+			}
+		case *notNode:
+			walk(v.operand)
+		case *logicalNode:
+			walk(v.left)
+			walk(v.right)
+		case *compareNode:
+			walk(v.left)
+			walk(v.right)
+		}
+	}
+	walk(e.root)
+
+	return names
+}
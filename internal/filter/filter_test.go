@@ -0,0 +1,161 @@
+package filter
+
+import (
+	"strings"
+	"testing"
+)
+
+// stickerEnv variables of a character as snipe monitor passes them, taken from a real drop
+func stickerEnv() Env {
+	return Env{
+		"price":          float64(3500000000),
+		"supply":         float64(2500),
+		"left":           float64(1840),
+		"sold":           float64(660),
+		"name":           "Lazy Cat",
+		"description":    "Sleeps through the drop",
+		"type":           "static",
+		"character.id":   float64(412),
+		"character.name": "Lazy Cat",
+
+		"title":                  "Cats of Durov",
+		"badges":                 []string{"Verified", "new"},
+		"collection.id":          float64(97),
+		"collection.title":       "Cats of Durov",
+		"collection.description": "Limited cat stickers",
+		"collection.type":        "characters",
+		"collection.status":      "active",
+		"collection.badges":      []string{"Verified", "new"},
+
+		"creator.name":     "Goodies",
+		"creator.status":   "verified",
+		"creator.verified": true,
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		wantErr string
+	}{
+		{"empty", "", "unexpected end of expression"},
+		{"unterminated string", `title ~ "cat`, "unterminated string at position 8"},
+		{"invalid number", "price < 1.2.3", `invalid number "1.2.3"`},
+		{"unexpected character", "price # 5", "unexpected character '#' at position 6"},
+		{"missing operand", "price <", "unexpected end of expression"},
+		{"missing paren", "(price < 5 TON", "expected ')' at position 14"},
+		{"extra paren", "price < 5 TON)", `unexpected ")" at position 13`},
+		{"chained comparison", "1 < price < 5", `unexpected "<" at position 10`},
+		{"dangling operator", "&& supply < 10", `unexpected "&&" at position 0`},
+		{"two operands", "price supply", `unexpected "supply" at position 6`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(tt.source)
+			if err == nil {
+				t.Fatalf("Compile(%q) succeeded, want error %q", tt.source, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Compile(%q) error = %q, want %q", tt.source, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   bool
+	}{
+		{"price in TON", "price <= 5 TON", true},
+		{"price in nanotons", "price == 3_500_000_000 nano", true},
+		{"fractional TON", "price > 3.4 ton && price < 3.6 TON", true},
+		{"supply", "supply < 2000", false},
+		{"sold", "sold >= 660 && left == 1840", true},
+		{"contains ignores case", `title ~ "CAT"`, true},
+		{"not contains", `description !~ "sleep"`, false},
+		{"list contains", `badges ~ "verif"`, true},
+		{"list not contains", `collection.badges !~ "limited"`, true},
+		{"string equality ignores case", `collection.status == "ACTIVE"`, true},
+		{"string inequality", `type != "animated"`, true},
+		{"bool variable", "creator.verified", true},
+		{"bool comparison", "creator.verified == false", false},
+		{"negation", "!creator.verified", false},
+		{"double negation", "!!creator.verified", true},
+		{"and binds tighter than or", "supply < 100 && price < 1 TON || creator.verified", true},
+		{"and binds tighter than or on the right", "creator.verified || supply < 100 && price < 1 TON", true},
+		{"parentheses override precedence", "(creator.verified || supply < 100) && price < 1 TON", false},
+		{"not binds tighter than and", "!creator.verified && supply > 100", false},
+		{"not of parentheses", "!(supply < 100 && price < 1 TON)", true},
+		{"literal", "true && !false", true},
+		{"or short-circuits unknown variable", "creator.verified || missing", true},
+		{"and short-circuits unknown variable", "supply < 100 && missing", false},
+	}
+
+	env := stickerEnv()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expression, err := Compile(tt.source)
+			if err != nil {
+				t.Fatalf("Compile(%q) error = %v", tt.source, err)
+			}
+			got, err := expression.Match(env)
+			if err != nil {
+				t.Fatalf("Match(%q) error = %v", tt.source, err)
+			}
+			if got != tt.want {
+				t.Errorf("Match(%q) = %v, want %v", tt.source, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		wantErr string
+	}{
+		{"unknown variable", "rarity > 3", `unknown variable "rarity"`},
+		{"unknown variable on the right", "creator.verified && rarity", `unknown variable "rarity"`},
+		{"number result", "price", "expression result is number, not boolean"},
+		{"number and string", `price == "5"`, "operator == cannot compare number and string"},
+		{"ordering strings", `title < "z"`, "operator < cannot compare string and string"},
+		{"contains number", "price ~ 5", "right side of ~ must be string, got number"},
+		{"contains on number", `price ~ "5"`, "left side of ~ must be string or list, got number"},
+		{"not of number", "!price", "expected boolean, got number"},
+		{"and of string", `title && creator.verified`, "expected boolean, got string"},
+	}
+
+	env := stickerEnv()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expression, err := Compile(tt.source)
+			if err != nil {
+				t.Fatalf("Compile(%q) error = %v", tt.source, err)
+			}
+			_, err = expression.Match(env)
+			if err == nil {
+				t.Fatalf("Match(%q) succeeded, want error %q", tt.source, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Match(%q) error = %q, want %q", tt.source, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVariables(t *testing.T) {
+	expression, err := Compile(`(price < 5 TON || creator.verified) && !(title ~ "cat") && price > 1 TON`)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+
+	got := strings.Join(expression.Variables(), ",")
+	if want := "price,creator.verified,title"; got != want {
+		t.Errorf("Variables() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,201 @@
+// Package logfile provides append-only log files with size and age based rotation
+package logfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rotation defaults
+const (
+	DefaultMaxSize = 10 * 1024 * 1024 // Rotate file after 10 MB
+	DefaultBackups = 5                // Number of rotated files kept
+)
+
+// Options rotation settings of log file
+type Options struct {
+	MaxSize int64         // Rotate when file would exceed this size (0 - DefaultMaxSize)
+	MaxAge  time.Duration // Rotate when file is older than this (0 - no age limit)
+	Backups int           // Number of rotated files kept (0 - DefaultBackups)
+}
+
+// RotatingFile log file that is rotated as file -> file.1 -> file.2 ...,
+// the oldest backup is removed
+type RotatingFile struct {
+	path    string
+	options Options
+
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	mu       sync.Mutex
+}
+
+// Open opens log file for appending, creating its directory if needed
+func Open(path string, options Options) (*RotatingFile, error) {
+	if options.MaxSize <= 0 {
+		options.MaxSize = DefaultMaxSize
+	}
+	if options.Backups <= 0 {
+		options.Backups = DefaultBackups
+	}
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("error creating log directory: %v", err)
+		}
+	}
+
+	f := &RotatingFile{
+		path:    path,
+		options: options,
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Write appends data to file, rotating it first if limits would be exceeded
+func (f *RotatingFile) Write(data []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	if f.needsRotation(int64(len(data))) {
+		if err := f.rotate(); err != nil {
+			return 0, fmt.Errorf("log rotation error: %v", err)
+		}
+	}
+
+	n, err := f.file.Write(data)
+	f.size += int64(n)
+	return n, err
+}
+
+// WriteString appends string to file
+func (f *RotatingFile) WriteString(s string) (int, error) {
+	return f.Write([]byte(s))
+}
+
+// Sync commits written data to disk
+func (f *RotatingFile) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return os.ErrClosed
+	}
+	return f.file.Sync()
+}
+
+// Close closes the file
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// Path returns path of current log file
+func (f *RotatingFile) Path() string {
+	return f.path
+}
+
+// open opens current file and reads its size and age
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("error opening log file: %v", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("error reading log file info: %v", err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+	f.openedAt = time.Now()
+	if f.size > 0 {
+		// Age of existing file is counted from its last change
+		f.openedAt = info.ModTime()
+	}
+	return nil
+}
+
+// needsRotation checks size and age limits. Empty file is never rotated
+func (f *RotatingFile) needsRotation(nextWrite int64) bool {
+	if f.size == 0 {
+		return false
+	}
+	if f.size+nextWrite > f.options.MaxSize {
+		return true
+	}
+	return f.options.MaxAge > 0 && time.Since(f.openedAt) > f.options.MaxAge
+}
+
+// rotate closes current file, shifts backups and opens new file
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+
+	if err := Rotate(f.path, f.options.Backups); err != nil {
+		return err
+	}
+	return f.open()
+}
+
+// Rotate shifts file -> file.1 -> file.2 ... keeping given number of backups
+func Rotate(path string, backups int) error {
+	os.Remove(fmt.Sprintf("%s.%d", path, backups))
+	for i := backups - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", path, i), fmt.Sprintf("%s.%d", path, i+1))
+	}
+	return os.Rename(path, path+".1")
+}
+
+// Files returns existing rotated backups of log file and the file itself, oldest first
+func Files(path string) []string {
+	matches, _ := filepath.Glob(path + ".*")
+
+	type backup struct {
+		path  string
+		index int
+	}
+	var backups []backup
+	for _, match := range matches {
+		index, err := strconv.Atoi(strings.TrimPrefix(match, path+"."))
+		if err != nil || index <= 0 {
+			continue
+		}
+		backups = append(backups, backup{match, index})
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].index > backups[j].index })
+
+	files := make([]string, 0, len(backups)+1)
+	for _, b := range backups {
+		files = append(files, b.path)
+	}
+	if _, err := os.Stat(path); err == nil {
+		files = append(files, path)
+	}
+	return files
+}
@@ -0,0 +1,113 @@
+// Package mockshop serves a local imitation of the shop order API, so the purchase
+// pipeline can be measured without touching the real shop or paying anything
+package mockshop
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Options behaviour of mock shop
+type Options struct {
+	Latency   time.Duration // Time every order takes to answer
+	Jitter    time.Duration // Random extra time up to this value added to Latency
+	ErrorRate float64       // Share of orders answered with 500 (0-1)
+	PriceNano int64         // Price of one sticker in orders (default 1 TON)
+}
+
+// Server mock shop API listening on a local address
+type Server struct {
+	options  Options
+	listener net.Listener
+	server   *http.Server
+	orders   atomic.Int64 // Order requests received
+	failed   atomic.Int64 // Order requests answered with error
+}
+
+// Start starts mock shop on address, e.g. "127.0.0.1:0" for a random free port
+func Start(listen string, options Options) (*Server, error) {
+	if options.ErrorRate < 0 || options.ErrorRate > 1 {
+		return nil, fmt.Errorf("error rate must be between 0 and 1, got %v", options.ErrorRate)
+	}
+	if options.PriceNano <= 0 {
+		options.PriceNano = 1000000000
+	}
+
+	listener, err := net.Listen("tcp", listen)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{options: options, listener: listener}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/shop/buy/crypto", s.handleOrder)
+	mux.HandleFunc("GET /", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	s.server = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go s.server.Serve(listener)
+	return s, nil
+}
+
+// URL returns origin of the mock shop to send orders to
+func (s *Server) URL() string {
+	return "http://" + s.listener.Addr().String()
+}
+
+// Orders returns number of order requests received and how many of them were answered with error
+func (s *Server) Orders() (int64, int64) {
+	return s.orders.Load(), s.failed.Load()
+}
+
+// Close stops the server, requests in progress are dropped
+func (s *Server) Close() error {
+	return s.server.Close()
+}
+
+// handleOrder answers order like the shop does, but without order ID, so the order is never paid
+func (s *Server) handleOrder(w http.ResponseWriter, r *http.Request) {
+	s.orders.Add(1)
+
+	delay := s.options.Latency
+	if s.options.Jitter > 0 {
+		delay += rand.N(s.options.Jitter)
+	}
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+		s.failed.Add(1)
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "errorCode": "unauthorized"})
+		return
+	}
+	if s.options.ErrorRate > 0 && rand.Float64() < s.options.ErrorRate {
+		s.failed.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "errorCode": "internal_error"})
+		return
+	}
+
+	count, _ := strconv.Atoi(r.URL.Query().Get("count"))
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"ok": true,
+		"data": map[string]interface{}{
+			"total_amount": int64(max(1, count)) * s.options.PriceNano,
+			"currency":     r.URL.Query().Get("currency"),
+		},
+	})
+}
@@ -0,0 +1,33 @@
+package monitor
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// announcementPatterns patterns of collection IDs in drop announcements:
+// store links (stickerdom.store/collection/123, startapp=collection_123)
+// and plain mentions ("collection 123", "collection #123", "коллекция 123")
+var announcementPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)collections?[/=_](\d+)`),
+	regexp.MustCompile(`(?i)(?:collection|коллекци[яиюей])\s*(?:id)?\s*[#№:]?\s*(\d+)`),
+}
+
+// ParseAnnouncementCollections extracts collection IDs mentioned in announcement text
+func ParseAnnouncementCollections(text string) []int {
+	var ids []int
+	seen := make(map[int]bool)
+
+	for _, pattern := range announcementPatterns {
+		for _, match := range pattern.FindAllStringSubmatch(text, -1) {
+			id, err := strconv.Atoi(match[1])
+			if err != nil || id <= 0 || seen[id] {
+				continue
+			}
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
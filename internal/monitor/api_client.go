@@ -13,6 +13,7 @@ import (
 type APIClient struct {
 	httpClient *client.HTTPClient
 	baseURL    string
+	limiter    *rateLimiter
 }
 
 // NewAPIClient creates a new API client
@@ -23,6 +24,11 @@ func NewAPIClient(httpClient *client.HTTPClient) *APIClient {
 	}
 }
 
+// SetRateLimit limits number of requests per second (0 - unlimited)
+func (a *APIClient) SetRateLimit(rps int) {
+	a.limiter = newRateLimiter(rps)
+}
+
 // APIResponse structure for checking token errors
 type APIResponse struct {
 	OK        bool   `json:"ok"`
@@ -79,6 +85,7 @@ func (a *APIClient) GetCollections(authToken string) (*CollectionsResponse, erro
 		"User-Agent":         "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/136.0.0.0 Safari/537.36",
 	}
 
+	a.limiter.Wait()
 	resp, err := a.httpClient.Get(url, headers)
 	if err != nil {
 		return nil, fmt.Errorf("GET request error: %v", err)
@@ -134,6 +141,7 @@ func (a *APIClient) GetCollectionDetails(authToken string, collectionID int) (*C
 		"User-Agent":         "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/136.0.0.0 Safari/537.36",
 	}
 
+	a.limiter.Wait()
 	resp, err := a.httpClient.Get(url, headers)
 	if err != nil {
 		return nil, fmt.Errorf("GET request error: %v", err)
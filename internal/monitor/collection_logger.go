@@ -1,95 +1,151 @@
 package monitor
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 	"time"
+
+	"stickersbot/internal/config"
+	"stickersbot/internal/logfile"
+)
+
+// Rotation defaults of found collections log
+const (
+	DefaultLogMaxSize = 10 * 1024 * 1024 // Rotate file after 10 MB
+	DefaultLogBackups = 5                // Number of rotated files kept
 )
 
 // FoundCollection structure for saving found collection
 type FoundCollection struct {
-	ID          int       `json:"id"`
-	Name        string    `json:"name"`
-	CharacterID int       `json:"character_id"`
-	Supply      int       `json:"supply"`
-	PriceTON    float64   `json:"price_ton"`
-	PriceNano   int       `json:"price_nano"`
-	FoundAt     time.Time `json:"found_at"`
-	AccountName string    `json:"account_name"`
+	ID            int       `json:"id"`
+	Name          string    `json:"name"`
+	CharacterID   int       `json:"character_id"`
+	CharacterName string    `json:"character_name,omitempty"`
+	Supply        int       `json:"supply"`
+	Left          int       `json:"left"`
+	PriceTON      float64   `json:"price_ton"`
+	PriceNano     int       `json:"price_nano"`
+	FoundAt       time.Time `json:"found_at"`
+	AccountName   string    `json:"account_name"`
+}
+
+// FoundCollectionStore keeps collections found by snipe monitors
+type FoundCollectionStore interface {
+	AddFoundCollection(item FoundCollection) error
 }
 
-// CollectionLogger logger for saving found collections
+// NewFoundCollection returns record of character found by account
+func NewFoundCollection(collection Collection, character Character, accountName string) FoundCollection {
+	return FoundCollection{
+		ID:            collection.ID,
+		Name:          collection.Title,
+		CharacterID:   character.ID,
+		CharacterName: character.Name,
+		Supply:        character.Supply,
+		Left:          character.Left,
+		PriceTON:      float64(character.Price) / 1000000000.0, // Convert price from nanotons to TON
+		PriceNano:     character.Price,
+		FoundAt:       time.Now(),
+		AccountName:   accountName,
+	}
+}
+
+// CollectionLogger append-only JSONL log of found collections with size-based rotation
 type CollectionLogger struct {
-	filename string
-	mutex    sync.Mutex
+	filename   string
+	maxSize    int64
+	maxBackups int
+	mutex      sync.Mutex
 }
 
 // NewCollectionLogger creates a new collection logger
 func NewCollectionLogger(filename string) *CollectionLogger {
 	return &CollectionLogger{
-		filename: filename,
+		filename:   filename,
+		maxSize:    DefaultLogMaxSize,
+		maxBackups: DefaultLogBackups,
 	}
 }
 
-// LogFoundCollection saves found collection to file
+// FoundCollectionsFile returns log filename of account in state directory
+func FoundCollectionsFile(accountName string) string {
+	return config.StatePath(fmt.Sprintf("found_collections_%s.jsonl", strings.ReplaceAll(accountName, " ", "_")))
+}
+
+// LogFoundCollection appends found collection to file
 func (cl *CollectionLogger) LogFoundCollection(collection Collection, character Character, accountName string) error {
+	return cl.AddFoundCollection(NewFoundCollection(collection, character, accountName))
+}
+
+// AddFoundCollection appends found collection record to file
+func (cl *CollectionLogger) AddFoundCollection(foundCollection FoundCollection) error {
 	cl.mutex.Lock()
 	defer cl.mutex.Unlock()
 
-	// Convert price from nanotons to TON
-	priceTON := float64(character.Price) / 1000000000.0
-
-	foundCollection := FoundCollection{
-		ID:          collection.ID,
-		Name:        collection.Title,
-		CharacterID: character.ID,
-		Supply:      character.Supply,
-		PriceTON:    priceTON,
-		PriceNano:   character.Price,
-		FoundAt:     time.Now(),
-		AccountName: accountName,
+	data, err := json.Marshal(foundCollection)
+	if err != nil {
+		return fmt.Errorf("JSON serialization error: %v", err)
 	}
+	data = append(data, '\n')
 
-	// Read existing data
-	var collections []FoundCollection
-	if data, err := os.ReadFile(cl.filename); err == nil {
-		json.Unmarshal(data, &collections)
+	if err := cl.rotateIfNeeded(int64(len(data))); err != nil {
+		return fmt.Errorf("log rotation error: %v", err)
 	}
 
-	// Add new collection
-	collections = append(collections, foundCollection)
-
-	// Save back to file
-	data, err := json.MarshalIndent(collections, "", "  ")
+	file, err := os.OpenFile(cl.filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
 	if err != nil {
-		return fmt.Errorf("JSON serialization error: %v", err)
+		return fmt.Errorf("file open error: %v", err)
 	}
+	defer file.Close()
 
-	if err := os.WriteFile(cl.filename, data, 0644); err != nil {
+	if _, err := file.Write(data); err != nil {
 		return fmt.Errorf("file write error: %v", err)
 	}
 
 	return nil
 }
 
-// GetFoundCollections returns all found collections
+// rotateIfNeeded rotates log when next write would exceed maximum size:
+// file -> file.1 -> file.2 ... oldest backup is removed
+func (cl *CollectionLogger) rotateIfNeeded(nextWrite int64) error {
+	info, err := os.Stat(cl.filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	if info.Size()+nextWrite <= cl.maxSize {
+		return nil
+	}
+
+	return logfile.Rotate(cl.filename, cl.maxBackups)
+}
+
+// GetFoundCollections returns all found collections including rotated files, oldest first
 func (cl *CollectionLogger) GetFoundCollections() ([]FoundCollection, error) {
 	cl.mutex.Lock()
 	defer cl.mutex.Unlock()
 
 	var collections []FoundCollection
-	data, err := os.ReadFile(cl.filename)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return collections, nil // Return empty array if file doesn't exist
+	for i := cl.maxBackups; i >= 0; i-- {
+		filename := cl.filename
+		if i > 0 {
+			filename = fmt.Sprintf("%s.%d", cl.filename, i)
 		}
-		return nil, fmt.Errorf("file read error: %v", err)
-	}
 
-	if err := json.Unmarshal(data, &collections); err != nil {
-		return nil, fmt.Errorf("JSON parsing error: %v", err)
+		items, err := readFoundCollections(filename)
+		if err != nil {
+			return nil, err
+		}
+		collections = append(collections, items...)
 	}
 
 	return collections, nil
@@ -103,3 +159,81 @@ func (cl *CollectionLogger) GetCollectionCount() int {
 	}
 	return len(collections)
 }
+
+// LoadAllFoundCollections reads found collections of all accounts from directory,
+// including rotated files and legacy found_collections_<account>.json files.
+// Result is sorted by time of finding
+func LoadAllFoundCollections(dir string) ([]FoundCollection, error) {
+	var files []string
+	for _, pattern := range []string{"found_collections_*.jsonl*", "found_collections_*.json"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+
+	var collections []FoundCollection
+	for _, filename := range files {
+		items, err := readFoundCollections(filename)
+		if err != nil {
+			return nil, err
+		}
+		collections = append(collections, items...)
+	}
+
+	sort.SliceStable(collections, func(i, j int) bool {
+		return collections[i].FoundAt.Before(collections[j].FoundAt)
+	})
+
+	return collections, nil
+}
+
+// readFoundCollections reads JSONL file (or legacy JSON array file).
+// Missing file is not an error, broken lines are skipped
+func readFoundCollections(filename string) ([]FoundCollection, error) {
+	if strings.HasSuffix(filename, ".json") {
+		var collections []FoundCollection
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("file read error: %v", err)
+		}
+		if err := json.Unmarshal(data, &collections); err != nil {
+			return nil, fmt.Errorf("JSON parsing error in %s: %v", filename, err)
+		}
+		return collections, nil
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("file read error: %v", err)
+	}
+	defer file.Close()
+
+	var collections []FoundCollection
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var item FoundCollection
+		if err := json.Unmarshal([]byte(line), &item); err != nil {
+			continue
+		}
+		collections = append(collections, item)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("file read error: %v", err)
+	}
+
+	return collections, nil
+}
@@ -0,0 +1,87 @@
+package monitor
+
+import (
+	"fmt"
+	"strings"
+
+	"stickersbot/internal/filter"
+)
+
+// filterEnv returns variables available to filter expression for character
+func filterEnv(collection Collection, character Character) filter.Env {
+	badges := collection.Badges
+	if badges == nil {
+		badges = []string{}
+	}
+
+	verified := strings.EqualFold(collection.Creator.Status, "verified")
+	for _, badge := range badges {
+		if strings.EqualFold(badge, "verified") {
+			verified = true
+		}
+	}
+
+	return filter.Env{
+		// Character
+		"price":          float64(character.Price),
+		"supply":         float64(character.Supply),
+		"left":           float64(character.Left),
+		"sold":           float64(character.Supply - character.Left),
+		"name":           character.Name,
+		"description":    character.Description,
+		"type":           character.Type,
+		"character.id":   float64(character.ID),
+		"character.name": character.Name,
+
+		// Collection
+		"title":                  collection.Title,
+		"badges":                 badges,
+		"collection.id":          float64(collection.ID),
+		"collection.title":       collection.Title,
+		"collection.description": collection.Description,
+		"collection.type":        collection.Type,
+		"collection.status":      collection.Status,
+		"collection.badges":      badges,
+
+		// Creator
+		"creator.name":     collection.Creator.Name,
+		"creator.status":   collection.Creator.Status,
+		"creator.verified": verified,
+	}
+}
+
+// compileFilter compiles filter expression of snipe settings and checks its variables
+func compileFilter(source string) (*filter.Expression, error) {
+	expression, err := filter.Compile(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter expression: %v", err)
+	}
+
+	known := filterEnv(Collection{}, Character{})
+	for _, name := range expression.Variables() {
+		if _, ok := known[name]; !ok {
+			return nil, fmt.Errorf("invalid filter expression: unknown variable %q", name)
+		}
+	}
+
+	return expression, nil
+}
+
+// ValidateFilter checks that filter expression can be used by snipe monitor
+func ValidateFilter(source string) error {
+	_, err := compileFilter(source)
+	return err
+}
+
+// matchesExpression evaluates filter expression for character
+func (s *SnipeMonitor) matchesExpression(expression *filter.Expression, collection Collection, character Character) bool {
+	matched, err := expression.Match(filterEnv(collection, character))
+	if err != nil {
+		s.log("⚠️ Filter expression error for %s: %v", character.Name, err)
+		return false
+	}
+	if !matched {
+		s.log("🚫 Character %s did not pass filter expression", character.Name)
+	}
+	return matched
+}
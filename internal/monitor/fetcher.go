@@ -0,0 +1,106 @@
+package monitor
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultDetailWorkers number of parallel collection detail requests
+const DefaultDetailWorkers = 8
+
+// rateLimiter limits number of API requests per second
+type rateLimiter struct {
+	interval time.Duration
+	next     time.Time
+	mu       sync.Mutex
+}
+
+// newRateLimiter creates limiter allowing rps requests per second (0 - unlimited)
+func newRateLimiter(rps int) *rateLimiter {
+	if rps <= 0 {
+		return nil
+	}
+	return &rateLimiter{
+		interval: time.Second / time.Duration(rps),
+	}
+}
+
+// Wait blocks until the next request is allowed
+func (r *rateLimiter) Wait() {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	now := time.Now()
+	if r.next.Before(now) {
+		r.next = now
+	}
+	delay := r.next.Sub(now)
+	r.next = r.next.Add(r.interval)
+	r.mu.Unlock()
+
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+}
+
+// detailsResult result of fetching details of one collection
+type detailsResult struct {
+	collectionID int
+	details      *CollectionDetailsResponse
+	err          error
+}
+
+// fetchCollectionDetails fetches details of collections using bounded worker pool.
+// Results are returned in the same order as collectionIDs
+func (s *SnipeMonitor) fetchCollectionDetails(token string, collectionIDs []int) []detailsResult {
+	results := make([]detailsResult, len(collectionIDs))
+	if len(collectionIDs) == 0 {
+		return results
+	}
+
+	workers := DefaultDetailWorkers
+	if s.config.SnipeMonitor.DetailWorkers > 0 {
+		workers = s.config.SnipeMonitor.DetailWorkers
+	}
+	if workers > len(collectionIDs) {
+		workers = len(collectionIDs)
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				collectionID := collectionIDs[index]
+				details, err := s.api().GetCollectionDetails(token, collectionID)
+				results[index] = detailsResult{
+					collectionID: collectionID,
+					details:      details,
+					err:          err,
+				}
+			}
+		}()
+	}
+
+	for index := range collectionIDs {
+		jobs <- index
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// collectionIDs returns IDs of collections
+func collectionIDs(collections []Collection) []int {
+	ids := make([]int, 0, len(collections))
+	for _, collection := range collections {
+		ids = append(ids, collection.ID)
+	}
+	return ids
+}
@@ -0,0 +1,132 @@
+package monitor
+
+import (
+	"fmt"
+	"strings"
+
+	"stickersbot/internal/config"
+	"stickersbot/internal/filter"
+)
+
+// FilterSettings filters of snipe monitor that can be changed while it is running
+type FilterSettings struct {
+	SupplyRange *config.Range // nil - any supply
+	PriceRange  *config.Range // nil - any price (in nanotons)
+	WordFilter  []string      // Empty - any collection title
+	Filter      string        // Filter expression, replaces other filters when set
+}
+
+// activeFilters filter settings with compiled expression
+type activeFilters struct {
+	FilterSettings
+	expression *filter.Expression
+}
+
+// FilterSettingsFromConfig returns filter settings of snipe monitor configuration
+func FilterSettingsFromConfig(cfg *config.SnipeMonitorConfig) FilterSettings {
+	return FilterSettings{
+		SupplyRange: cfg.SupplyRange,
+		PriceRange:  cfg.PriceRange,
+		WordFilter:  cfg.WordFilter,
+		Filter:      cfg.Filter,
+	}
+}
+
+// Validate checks filter settings
+func (f FilterSettings) Validate() error {
+	if f.SupplyRange != nil && f.SupplyRange.Min > f.SupplyRange.Max {
+		return fmt.Errorf("supply range minimum %d is greater than maximum %d", f.SupplyRange.Min, f.SupplyRange.Max)
+	}
+	if f.PriceRange != nil && f.PriceRange.Min > f.PriceRange.Max {
+		return fmt.Errorf("price range minimum %d is greater than maximum %d", f.PriceRange.Min, f.PriceRange.Max)
+	}
+	if f.Filter != "" {
+		return ValidateFilter(f.Filter)
+	}
+	return nil
+}
+
+// Apply writes filter settings to snipe monitor configuration
+func (f FilterSettings) Apply(cfg *config.SnipeMonitorConfig) {
+	cfg.SupplyRange = f.SupplyRange
+	cfg.PriceRange = f.PriceRange
+	cfg.WordFilter = f.WordFilter
+	cfg.Filter = f.Filter
+}
+
+// String returns short description of filters
+func (f FilterSettings) String() string {
+	if f.Filter != "" {
+		return fmt.Sprintf("filter: %s", f.Filter)
+	}
+
+	var parts []string
+	if f.SupplyRange != nil {
+		parts = append(parts, fmt.Sprintf("supply: %d - %d", f.SupplyRange.Min, f.SupplyRange.Max))
+	}
+	if f.PriceRange != nil {
+		parts = append(parts, fmt.Sprintf("price: %d - %d nanoton", f.PriceRange.Min, f.PriceRange.Max))
+	}
+	if len(f.WordFilter) > 0 {
+		parts = append(parts, fmt.Sprintf("words: %s", strings.Join(f.WordFilter, ", ")))
+	}
+	if len(parts) == 0 {
+		return "no filters"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Filters returns current filters of the monitor
+func (s *SnipeMonitor) Filters() FilterSettings {
+	return s.currentFilters().FilterSettings
+}
+
+// UpdateFilters replaces filters of the monitor. Can be called while monitor is running,
+// new filters are used from the next check
+func (s *SnipeMonitor) UpdateFilters(settings FilterSettings) error {
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+
+	filters := activeFilters{FilterSettings: settings}
+	if settings.Filter != "" {
+		expression, err := compileFilter(settings.Filter)
+		if err != nil {
+			return err
+		}
+		filters.expression = expression
+	}
+
+	s.filtersMu.Lock()
+	s.filters = filters
+	settings.Apply(s.config.SnipeMonitor)
+	s.filtersMu.Unlock()
+
+	return nil
+}
+
+// currentFilters returns snapshot of filters used by checks
+func (s *SnipeMonitor) currentFilters() activeFilters {
+	s.filtersMu.RLock()
+	defer s.filtersMu.RUnlock()
+
+	return s.filters
+}
+
+// logFilters logs current filters
+func (s *SnipeMonitor) logFilters() {
+	filters := s.Filters()
+	if filters.Filter != "" {
+		s.log("   Filter: %s", filters.Filter)
+		return
+	}
+	if filters.SupplyRange != nil {
+		s.log("   Supply: %d - %d", filters.SupplyRange.Min, filters.SupplyRange.Max)
+	}
+	if filters.PriceRange != nil {
+		s.log("   Price: %d - %d nanoton", filters.PriceRange.Min, filters.PriceRange.Max)
+	}
+	if len(filters.WordFilter) > 0 {
+		s.log("   Word filter: %v", filters.WordFilter)
+	}
+}
@@ -0,0 +1,52 @@
+package monitor
+
+// KnownState collections and characters monitor already knows, saved so a resumed
+// run doesn't treat them as new and doesn't miss ones published while it was down
+type KnownState struct {
+	Collections    []int    `json:"collections"`
+	Characters     []string `json:"characters"`                // "collectionID:characterID"
+	WhitelistFired []string `json:"whitelist_fired,omitempty"` // "collectionID:characterID"
+}
+
+// KnownState returns known collections and characters
+func (s *SnipeMonitor) KnownState() KnownState {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var state KnownState
+	for id := range s.knownCollections {
+		state.Collections = append(state.Collections, id)
+	}
+	for key := range s.knownCharacters {
+		state.Characters = append(state.Characters, key)
+	}
+	for key := range s.whitelistFired {
+		state.WhitelistFired = append(state.WhitelistFired, key)
+	}
+	return state
+}
+
+// RestoreKnownState restores state saved by previous run. Must be called before Start,
+// which then skips initial scan, so collections published meanwhile are detected as new
+func (s *SnipeMonitor) RestoreKnownState(state KnownState) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, id := range state.Collections {
+		s.knownCollections[id] = true
+	}
+	for _, key := range state.Characters {
+		s.knownCharacters[key] = true
+	}
+	for _, key := range state.WhitelistFired {
+		s.whitelistFired[key] = true
+	}
+}
+
+// restored checks if state of previous run was restored
+func (s *SnipeMonitor) restored() bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return len(s.knownCollections) > 0
+}
@@ -0,0 +1,190 @@
+package monitor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"stickersbot/internal/config"
+)
+
+// CatalogSnapshot catalog of the shop at one moment: every collection with its characters
+type CatalogSnapshot struct {
+	Time        time.Time           `json:"time"`
+	Collections []CollectionDetails `json:"collections"`
+}
+
+// catalogRecorder appends catalog snapshots to JSON lines file, skipping checks that saw no change
+type catalogRecorder struct {
+	path     string
+	lastHash [sha256.Size]byte
+	failed   bool // Write error was already reported
+	mu       sync.Mutex
+}
+
+// recordCatalog saves catalog fetched by check for backtests. Check with failed
+// detail requests is not saved, its collections would look removed
+func (s *SnipeMonitor) recordCatalog(collections []Collection, results []detailsResult) {
+	if s.recorder == nil {
+		return
+	}
+
+	snapshot := CatalogSnapshot{Time: s.checkStartedAt}
+	for i, collection := range collections {
+		if results[i].err != nil {
+			return
+		}
+		// Stickers are not used by filters and make most of the size
+		snapshot.Collections = append(snapshot.Collections, CollectionDetails{
+			Collection: collection,
+			Characters: results[i].details.Data.Characters,
+		})
+	}
+
+	if err := s.recorder.add(snapshot); err != nil {
+		s.log("⚠️ Catalog recording error: %v", err)
+	}
+}
+
+// add appends snapshot unless catalog is the same as in the previous one.
+// Returns only the first write error
+func (r *catalogRecorder) add(snapshot CatalogSnapshot) error {
+	catalog, err := json.Marshal(snapshot.Collections)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	hash := sha256.Sum256(catalog)
+	if hash == r.lastHash {
+		return nil
+	}
+
+	line, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err == nil {
+		_, err = file.Write(append(line, '\n'))
+		file.Close()
+	}
+	if err != nil {
+		if r.failed {
+			return nil
+		}
+		r.failed = true
+		return err
+	}
+
+	r.lastHash, r.failed = hash, false
+	return nil
+}
+
+// ReadCatalogTimeline reads recorded snapshots one by one and passes them to fn in file order
+func ReadCatalogTimeline(reader io.Reader, fn func(CatalogSnapshot) error) error {
+	decoder := json.NewDecoder(reader)
+	for index := 1; ; index++ {
+		var snapshot CatalogSnapshot
+		if err := decoder.Decode(&snapshot); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("snapshot %d: %v", index, err)
+		}
+		if err := fn(snapshot); err != nil {
+			return err
+		}
+	}
+}
+
+// NewReplayMonitor creates monitor of account checking recorded catalog snapshots instead of
+// the shop API. Its matches go to purchaseCallback, nothing is requested or saved
+func NewReplayMonitor(account *config.Account, purchaseCallback PurchaseCallback) (*SnipeMonitor, error) {
+	if account.SnipeMonitor == nil {
+		return nil, fmt.Errorf("account %s has no snipe_monitor settings", account.Name)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &SnipeMonitor{
+		config:           account,
+		purchaseCallback: purchaseCallback,
+		knownCollections: make(map[int]bool),
+		knownCharacters:  make(map[string]bool),
+		whitelistFired:   make(map[string]bool),
+		whitelistStatus:  make(map[int]string),
+		armedCollections: make(map[int]bool),
+		windowActive:     true,
+		ctx:              ctx,
+		cancel:           cancel,
+		logPrefix:        fmt.Sprintf("[REPLAY:%s]", account.Name),
+		collections:      discardCollections{},
+	}
+
+	if err := s.UpdateFilters(FilterSettingsFromConfig(account.SnipeMonitor)); err != nil {
+		return nil, err
+	}
+	window, err := parseSnipeWindow(account.SnipeMonitor)
+	if err != nil {
+		return nil, err
+	}
+	s.window = window
+	return s, nil
+}
+
+// ReplaySnapshot runs one monitor check against recorded catalog. Like the catalog loaded at
+// start, the first snapshot and snapshots outside the active window only become known.
+// Watched collections are checked in every snapshot inside the window
+func (s *SnipeMonitor) ReplaySnapshot(snapshot CatalogSnapshot) {
+	s.checkStartedAt = snapshot.Time
+	active, _ := s.window.state(snapshot.Time)
+
+	collections := make([]Collection, 0, len(snapshot.Collections))
+	results := make([]detailsResult, 0, len(snapshot.Collections))
+	for _, details := range snapshot.Collections {
+		collections = append(collections, details.Collection)
+		results = append(results, detailsResult{
+			collectionID: details.Collection.ID,
+			details:      &CollectionDetailsResponse{OK: true, Data: details},
+		})
+	}
+
+	if active && s.hasWhitelist() {
+		watched := make(map[int]bool)
+		for _, id := range s.watchedCollections() {
+			watched[id] = true
+		}
+		for _, result := range results {
+			if watched[result.collectionID] {
+				s.checkWhitelistCollection(result.collectionID, result.details.Data)
+			}
+		}
+	}
+
+	if !s.discoveryEnabled() {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !active || !s.replayed {
+		s.rememberCollections(results)
+		s.replayed = true
+		return
+	}
+	s.processCatalog(collections, results)
+}
+
+// discardCollections found collection store of replayed monitors
+type discardCollections struct{}
+
+func (discardCollections) AddFoundCollection(FoundCollection) error {
+	return nil
+}
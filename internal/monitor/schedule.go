@@ -0,0 +1,234 @@
+package monitor
+
+import (
+	"fmt"
+	"time"
+
+	"stickersbot/internal/config"
+)
+
+// DefaultPrewarmLead how long before snipe window the monitor is prepared
+const DefaultPrewarmLead = 30 * time.Second
+
+// snipeWindow time window when snipe monitor is active. Bounds are either
+// absolute times (RFC3339, "2006-01-02 15:04") or daily times ("15:04")
+type snipeWindow struct {
+	from, until           time.Time
+	hasFrom, hasUntil     bool
+	daily                 bool
+	fromClock, untilClock time.Duration // Offsets from midnight for daily window
+}
+
+// parseSnipeWindow parses active_from / active_until of snipe settings
+func parseSnipeWindow(cfg *config.SnipeMonitorConfig) (*snipeWindow, error) {
+	return parseWindow(cfg.ActiveFrom, cfg.ActiveUntil)
+}
+
+// parseWindow parses active_from / active_until bounds, empty bounds - always active
+func parseWindow(activeFrom, activeUntil string) (*snipeWindow, error) {
+	window := &snipeWindow{}
+	if activeFrom == "" && activeUntil == "" {
+		return window, nil
+	}
+
+	fromClock, fromDaily := parseClock(activeFrom)
+	untilClock, untilDaily := parseClock(activeUntil)
+
+	if fromDaily || untilDaily {
+		if !fromDaily || !untilDaily {
+			return nil, fmt.Errorf("daily window needs both active_from and active_until in HH:MM format")
+		}
+		window.daily = true
+		window.fromClock = fromClock
+		window.untilClock = untilClock
+		return window, nil
+	}
+
+	var err error
+	if activeFrom != "" {
+		if window.from, err = parseWindowTime(activeFrom); err != nil {
+			return nil, fmt.Errorf("invalid active_from: %v", err)
+		}
+		window.hasFrom = true
+	}
+	if activeUntil != "" {
+		if window.until, err = parseWindowTime(activeUntil); err != nil {
+			return nil, fmt.Errorf("invalid active_until: %v", err)
+		}
+		window.hasUntil = true
+	}
+	if window.hasFrom && window.hasUntil && !window.until.After(window.from) {
+		return nil, fmt.Errorf("active_until must be after active_from")
+	}
+
+	return window, nil
+}
+
+// ValidateSnipeWindow checks active_from / active_until of snipe settings
+func ValidateSnipeWindow(cfg *config.SnipeMonitorConfig) error {
+	_, err := parseSnipeWindow(cfg)
+	return err
+}
+
+// ActiveWindow time window given by active_from / active_until in formats of snipe window,
+// e.g. when a drop is expected
+type ActiveWindow struct {
+	window *snipeWindow
+}
+
+// ParseActiveWindow parses window bounds, empty bounds - always active
+func ParseActiveWindow(activeFrom, activeUntil string) (*ActiveWindow, error) {
+	window, err := parseWindow(activeFrom, activeUntil)
+	if err != nil {
+		return nil, err
+	}
+	return &ActiveWindow{window: window}, nil
+}
+
+// Active returns whether time is inside the window
+func (w *ActiveWindow) Active(now time.Time) bool {
+	active, _ := w.window.state(now)
+	return active
+}
+
+// parseClock parses daily time "15:04"
+func parseClock(value string) (time.Duration, bool) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, false
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, true
+}
+
+// parseWindowTime parses absolute window bound in local time
+func parseWindowTime(value string) (time.Time, error) {
+	if t, err := config.ParseTime(value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("unknown time format %q (expected HH:MM, YYYY-MM-DD HH:MM or RFC3339)", value)
+}
+
+// isScheduled checks if window limits monitor activity at all
+func (w *snipeWindow) isScheduled() bool {
+	return w.daily || w.hasFrom || w.hasUntil
+}
+
+// state returns whether now is inside the window and the start of the next window
+// (zero if there is no upcoming window)
+func (w *snipeWindow) state(now time.Time) (bool, time.Time) {
+	if !w.isScheduled() {
+		return true, time.Time{}
+	}
+
+	if w.daily {
+		midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		clock := now.Sub(midnight)
+
+		var active bool
+		if w.fromClock <= w.untilClock {
+			active = clock >= w.fromClock && clock < w.untilClock
+		} else {
+			// Window over midnight, e.g. 22:00 - 02:00
+			active = clock >= w.fromClock || clock < w.untilClock
+		}
+
+		next := midnight.Add(w.fromClock)
+		if !next.After(now) {
+			next = next.AddDate(0, 0, 1)
+		}
+		return active, next
+	}
+
+	if w.hasUntil && !now.Before(w.until) {
+		return false, time.Time{}
+	}
+	if w.hasFrom && now.Before(w.from) {
+		return false, w.from
+	}
+	return true, time.Time{}
+}
+
+// String returns description of window
+func (w *snipeWindow) String() string {
+	if w.daily {
+		return fmt.Sprintf("daily %02d:%02d - %02d:%02d",
+			int(w.fromClock.Hours()), int(w.fromClock.Minutes())%60,
+			int(w.untilClock.Hours()), int(w.untilClock.Minutes())%60)
+	}
+
+	from, until := "now", "forever"
+	if w.hasFrom {
+		from = w.from.Format("2006-01-02 15:04:05")
+	}
+	if w.hasUntil {
+		until = w.until.Format("2006-01-02 15:04:05")
+	}
+	return fmt.Sprintf("%s - %s", from, until)
+}
+
+// prewarmLead returns how long before window the monitor is prepared
+func (s *SnipeMonitor) prewarmLead() time.Duration {
+	if s.config.SnipeMonitor.PrewarmSeconds > 0 {
+		return time.Duration(s.config.SnipeMonitor.PrewarmSeconds) * time.Second
+	}
+	return DefaultPrewarmLead
+}
+
+// checkWindow decides if monitor should check now. Outside the window the monitor
+// idles and prepares itself shortly before the next window starts.
+// Called only from monitor loop goroutine
+func (s *SnipeMonitor) checkWindow(now time.Time) bool {
+	active, nextStart := s.window.state(now)
+
+	if active != s.windowActive {
+		s.windowActive = active
+		if active {
+			s.log("⏰ Snipe window started (%s)", s.window)
+		} else if nextStart.IsZero() {
+			s.log("⏰ Snipe window is over (%s)", s.window)
+		} else {
+			s.log("💤 Outside snipe window, idling until %s", nextStart.Format("2006-01-02 15:04:05"))
+		}
+	}
+
+	if active {
+		return true
+	}
+
+	if !nextStart.IsZero() && nextStart.Sub(now) <= s.prewarmLead() && !s.prewarmedFor.Equal(nextStart) {
+		s.prewarmedFor = nextStart
+		s.prewarm(nextStart)
+	}
+
+	return false
+}
+
+// prewarm refreshes token, warms HTTP connection and rebuilds known catalog
+// baseline, so the first check in the window is fast
+func (s *SnipeMonitor) prewarm(windowStart time.Time) {
+	s.log("🔥 Pre-warming before snipe window (starts in %s)", time.Until(windowStart).Round(time.Second))
+
+	// Order and payment connections are prepared while the catalog loads
+	if s.prewarmHook != nil {
+		go s.prewarmHook()
+	}
+
+	// Status 0 is not a token error, so a token refreshed moments ago is reused
+	if _, err := s.tokens.RefreshTokenOnError(s.config.Name, 0); err != nil {
+		s.log("⚠️ Pre-warm: token refresh error: %v", err)
+	}
+
+	// Loading the catalog also opens connection to API
+	if s.discoveryEnabled() {
+		if err := s.initializeState(); err != nil {
+			s.log("⚠️ Pre-warm: catalog initialization error: %v", err)
+			return
+		}
+	} else if token, err := s.tokens.GetValidToken(s.config.Name); err == nil {
+		if _, err := s.api().GetCollections(token); err != nil {
+			s.log("⚠️ Pre-warm: connection warm-up error: %v", err)
+		}
+	}
+
+	s.log("🔥 Pre-warm completed")
+}
@@ -19,29 +19,51 @@ type PurchaseRequest struct {
 	Price        int
 	Supply       int
 	Name         string
+
+	CheckStartedAt time.Time // Start of monitoring check that found the match
+	DetectedAt     time.Time // Time the match was detected
 }
 
 // PurchaseCallback is a callback function for purchase
 type PurchaseCallback func(request PurchaseRequest) error
 
-// TokenCallback is a callback function for getting a valid token
-type TokenCallback func(accountName string) (string, error)
-
-// TokenRefreshCallback is a callback function for refreshing token on error
-type TokenRefreshCallback func(accountName string, statusCode int) (string, error)
+// TokenProvider gives tokens of accounts. One provider is shared by the whole process
+type TokenProvider interface {
+	// GetValidToken returns current token of account
+	GetValidToken(accountName string) (string, error)
+	// RefreshTokenOnError gets new token after API rejected the current one with statusCode
+	RefreshTokenOnError(accountName string, statusCode int) (string, error)
+}
 
 // SnipeMonitor represents snipe monitor structure
 type SnipeMonitor struct {
-	config               *config.Account
-	apiClient            *APIClient
-	httpClient           *client.HTTPClient
-	purchaseCallback     PurchaseCallback
-	tokenCallback        TokenCallback
-	tokenRefreshCallback TokenRefreshCallback
+	config           *config.Account
+	purchaseCallback PurchaseCallback
+	tokens           TokenProvider
+
+	// Start of current monitoring check (used only by monitor loop goroutine)
+	checkStartedAt time.Time
+
+	// Active window (used only by monitor loop goroutine after start)
+	window       *snipeWindow
+	windowActive bool      // Whether the last check was inside the window
+	prewarmedFor time.Time // Start of the window monitor was last pre-warmed for
+
+	// Clients, replaced by watchdog while fetch goroutines use them
+	apiClient  *APIClient
+	httpClient *client.HTTPClient
+	clientMu   sync.RWMutex
+
+	// Filters, can be changed while monitor is running
+	filters   activeFilters
+	filtersMu sync.RWMutex
 
 	// State
 	knownCollections map[int]bool    // IDs of known collections
 	knownCharacters  map[string]bool // "collectionID:characterID" of known characters
+	whitelistFired   map[string]bool // "collectionID:characterID" of whitelisted characters already sent to purchase
+	whitelistStatus  map[int]string  // Last reported status of whitelisted collections
+	armedCollections map[int]bool    // Collection IDs armed from channel announcements
 	mutex            sync.RWMutex
 
 	// Lifecycle management
@@ -49,30 +71,59 @@ type SnipeMonitor struct {
 	cancel context.CancelFunc
 
 	// Logging
-	logPrefix        string
-	collectionLogger *CollectionLogger
+	logPrefix   string
+	collections FoundCollectionStore
+
+	// Called when monitor pre-warms before its window (nil - nothing extra)
+	prewarmHook func()
+
+	// Catalog changes saved for backtests (nil - not recorded)
+	recorder *catalogRecorder
+
+	// Replayed monitor has seen its first snapshot
+	replayed bool
+
+	// Health tracking for watchdog
+	consecutiveFailures int
+	lastError           string
+	lastSuccess         time.Time
+	restarts            int
+	healthMu            sync.Mutex
 }
 
 // NewSnipeMonitor creates a new snipe monitor
-func NewSnipeMonitor(account *config.Account, httpClient *client.HTTPClient, purchaseCallback PurchaseCallback, tokenCallback TokenCallback, tokenRefreshCallback TokenRefreshCallback) *SnipeMonitor {
+func NewSnipeMonitor(account *config.Account, httpClient *client.HTTPClient, purchaseCallback PurchaseCallback, tokens TokenProvider) *SnipeMonitor {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	// Create filename for collection logs
-	logFilename := fmt.Sprintf("found_collections_%s.json", strings.ReplaceAll(account.Name, " ", "_"))
+	logFilename := FoundCollectionsFile(account.Name)
+
+	apiClient := NewAPIClient(httpClient)
+	var recorder *catalogRecorder
+	if account.SnipeMonitor != nil {
+		apiClient.SetRateLimit(account.SnipeMonitor.RequestsPerSecond)
+		if account.SnipeMonitor.RecordFile != "" {
+			recorder = &catalogRecorder{path: config.StatePath(account.SnipeMonitor.RecordFile)}
+		}
+	}
 
 	return &SnipeMonitor{
-		config:               account,
-		apiClient:            NewAPIClient(httpClient),
-		httpClient:           httpClient,
-		purchaseCallback:     purchaseCallback,
-		tokenCallback:        tokenCallback,
-		tokenRefreshCallback: tokenRefreshCallback,
-		knownCollections:     make(map[int]bool),
-		knownCharacters:      make(map[string]bool),
-		ctx:                  ctx,
-		cancel:               cancel,
-		logPrefix:            fmt.Sprintf("[SNIPE:%s]", account.Name),
-		collectionLogger:     NewCollectionLogger(logFilename),
+		config:           account,
+		apiClient:        apiClient,
+		httpClient:       httpClient,
+		purchaseCallback: purchaseCallback,
+		tokens:           tokens,
+		knownCollections: make(map[int]bool),
+		knownCharacters:  make(map[string]bool),
+		whitelistFired:   make(map[string]bool),
+		whitelistStatus:  make(map[int]string),
+		armedCollections: make(map[int]bool),
+		windowActive:     true,
+		ctx:              ctx,
+		cancel:           cancel,
+		logPrefix:        fmt.Sprintf("[SNIPE:%s]", account.Name),
+		collections:      NewCollectionLogger(logFilename),
+		recorder:         recorder,
 	}
 }
 
@@ -87,20 +138,40 @@ func (s *SnipeMonitor) Start() error {
 	}
 
 	s.log("🎯 Snipe monitor started")
+	if s.config.SnipeMonitor.WatchOnly {
+		s.log("👀 Watch-only mode: matches are reported, purchases are disabled")
+	}
+	// Load filters from settings, expression filter replaces supply/price/word filters
+	if err := s.UpdateFilters(FilterSettingsFromConfig(s.config.SnipeMonitor)); err != nil {
+		return err
+	}
+
+	window, err := parseSnipeWindow(s.config.SnipeMonitor)
+	if err != nil {
+		return err
+	}
+	s.window = window
+
 	s.log("📊 Settings:")
-	if s.config.SnipeMonitor.SupplyRange != nil {
-		s.log("   Supply: %d - %d", s.config.SnipeMonitor.SupplyRange.Min, s.config.SnipeMonitor.SupplyRange.Max)
+	s.logFilters()
+	if s.window.isScheduled() {
+		s.log("   Active window: %s (pre-warm %s before)", s.window, s.prewarmLead())
 	}
-	if s.config.SnipeMonitor.PriceRange != nil {
-		s.log("   Price: %d - %d nanoton", s.config.SnipeMonitor.PriceRange.Min, s.config.SnipeMonitor.PriceRange.Max)
+	if s.hasWhitelist() {
+		s.log("   Watched collections: %v", s.config.SnipeMonitor.WatchCollections)
 	}
-	if len(s.config.SnipeMonitor.WordFilter) > 0 {
-		s.log("   Word filter: %v", s.config.SnipeMonitor.WordFilter)
+
+	if s.config.SnipeMonitor.WhitelistOnly && !s.hasWhitelist() {
+		return fmt.Errorf("whitelist_only is enabled but watch_collections is empty")
 	}
 
-	// Initialize state - get current collections
-	if err := s.initializeState(); err != nil {
-		s.log("⚠️ State initialization error: %v", err)
+	// Initialize state - get current collections, unless restored from previous run
+	if s.restored() {
+		s.log("♻️ Resumed with %d known collections", len(s.KnownState().Collections))
+	} else if s.discoveryEnabled() {
+		if err := s.initializeState(); err != nil {
+			s.log("⚠️ State initialization error: %v", err)
+		}
 	}
 
 	// Start main monitoring loop
@@ -115,6 +186,17 @@ func (s *SnipeMonitor) Stop() {
 	s.cancel()
 }
 
+// SetFoundCollectionStore replaces per-account log file found collections are saved to
+func (s *SnipeMonitor) SetFoundCollectionStore(store FoundCollectionStore) {
+	s.collections = store
+}
+
+// SetPrewarmHook sets function preparing purchases of account (order and payment
+// connections) when monitor pre-warms before its window
+func (s *SnipeMonitor) SetPrewarmHook(hook func()) {
+	s.prewarmHook = hook
+}
+
 // GetAccountName returns the account name associated with this snipe monitor
 func (s *SnipeMonitor) GetAccountName() string {
 	return s.config.Name
@@ -123,24 +205,24 @@ func (s *SnipeMonitor) GetAccountName() string {
 // initializeState initializes monitor state
 func (s *SnipeMonitor) initializeState() error {
 	// Get valid token
-	token, err := s.tokenCallback(s.config.Name)
+	token, err := s.tokens.GetValidToken(s.config.Name)
 	if err != nil {
 		return fmt.Errorf("error getting token: %v", err)
 	}
 
-	collections, err := s.apiClient.GetCollections(token)
+	collections, err := s.api().GetCollections(token)
 	if err != nil {
 		// Check if this is a token error
 		if tokenErr, ok := err.(*TokenError); ok {
 			s.log("🔑 Token error during initialization: %v", tokenErr)
 			// Try to refresh token
-			newToken, refreshErr := s.tokenRefreshCallback(s.config.Name, tokenErr.StatusCode)
+			newToken, refreshErr := s.tokens.RefreshTokenOnError(s.config.Name, tokenErr.StatusCode)
 			if refreshErr != nil {
 				return fmt.Errorf("error refreshing token: %v", refreshErr)
 			}
 			token = newToken // Update token for further use
 			// Retry request with new token
-			collections, err = s.apiClient.GetCollections(newToken)
+			collections, err = s.api().GetCollections(newToken)
 			if err != nil {
 				return fmt.Errorf("error getting collections after token refresh: %v", err)
 			}
@@ -149,31 +231,37 @@ func (s *SnipeMonitor) initializeState() error {
 		}
 	}
 
+	started := time.Now()
+	results := s.fetchCollectionDetails(token, collectionIDs(collections.Data))
+
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	// Remember all existing collections
-	for _, collection := range collections.Data {
-		s.knownCollections[collection.ID] = true
+	// Remember all existing collections and their characters
+	s.rememberCollections(results)
+
+	s.log("📋 Initialized: %d collections, %d characters in %s",
+		len(s.knownCollections), len(s.knownCharacters), time.Since(started).Truncate(time.Millisecond))
 
-		// Get collection details to remember characters
-		details, err := s.apiClient.GetCollectionDetails(token, collection.ID)
-		if err != nil {
-			s.log("⚠️ Error getting collection details %d: %v", collection.ID, err)
+	return nil
+}
+
+// rememberCollections marks fetched collections and their characters as known.
+// Must be called with s.mutex held
+func (s *SnipeMonitor) rememberCollections(results []detailsResult) {
+	for _, result := range results {
+		s.knownCollections[result.collectionID] = true
+
+		if result.err != nil {
+			s.log("⚠️ Error getting collection details %d: %v", result.collectionID, result.err)
 			continue
 		}
 
-		// Remember all characters
-		for _, character := range details.Data.Characters {
-			key := fmt.Sprintf("%d:%d", collection.ID, character.ID)
+		for _, character := range result.details.Data.Characters {
+			key := fmt.Sprintf("%d:%d", result.collectionID, character.ID)
 			s.knownCharacters[key] = true
 		}
 	}
-
-	s.log("📋 Initialized: %d collections, %d characters",
-		len(s.knownCollections), len(s.knownCharacters))
-
-	return nil
 }
 
 // monitorLoop is the main monitoring loop
@@ -186,36 +274,77 @@ func (s *SnipeMonitor) monitorLoop() {
 		case <-s.ctx.Done():
 			return
 		case <-ticker.C:
-			if err := s.checkForNewItems(); err != nil {
-				s.log("❌ Check error: %v", err)
+			var checkErr error
+			s.checkStartedAt = time.Now()
+
+			// Outside the snipe window the monitor idles
+			if !s.checkWindow(s.checkStartedAt) {
+				continue
+			}
+
+			if s.hasWhitelist() {
+				if err := s.checkWhitelist(); err != nil {
+					s.log("❌ Whitelist check error: %v", err)
+					checkErr = err
+				}
+			}
+
+			if s.discoveryEnabled() {
+				if err := s.checkForNewItems(); err != nil {
+					s.log("❌ Check error: %v", err)
+					checkErr = err
+				}
+			}
+
+			// Restart monitor if checks keep failing
+			if s.recordCheck(checkErr) {
+				s.restart()
 			}
 		}
 	}
 }
 
+// newPurchaseRequest creates purchase request for matched character
+func (s *SnipeMonitor) newPurchaseRequest(collectionID int, character Character) PurchaseRequest {
+	return PurchaseRequest{
+		CollectionID:   collectionID,
+		CharacterID:    character.ID,
+		Price:          character.Price,
+		Supply:         character.Supply,
+		Name:           character.Name,
+		CheckStartedAt: s.checkStartedAt,
+		DetectedAt:     time.Now(),
+	}
+}
+
+// discoveryEnabled checks if monitor looks for any new collections
+func (s *SnipeMonitor) discoveryEnabled() bool {
+	return !s.config.SnipeMonitor.WhitelistOnly
+}
+
 // checkForNewItems checks for new collections and characters
 func (s *SnipeMonitor) checkForNewItems() error {
 	// Get cached token (without API verification)
-	token, err := s.tokenCallback(s.config.Name)
+	token, err := s.tokens.GetValidToken(s.config.Name)
 	if err != nil {
 		return fmt.Errorf("error getting token: %v", err)
 	}
 
-	collections, err := s.apiClient.GetCollections(token)
+	collections, err := s.api().GetCollections(token)
 	tokenWasRefreshed := false
 	if err != nil {
 		// Check if this is a token error
 		if tokenErr, ok := err.(*TokenError); ok {
 			s.log("�� Token error during monitoring: %v", tokenErr)
 			// Try to refresh token
-			newToken, refreshErr := s.tokenRefreshCallback(s.config.Name, tokenErr.StatusCode)
+			newToken, refreshErr := s.tokens.RefreshTokenOnError(s.config.Name, tokenErr.StatusCode)
 			if refreshErr != nil {
 				return fmt.Errorf("error refreshing token: %v", refreshErr)
 			}
 			tokenWasRefreshed = true
 			token = newToken // Update token for further use
 			// Retry request with new token
-			collections, err = s.apiClient.GetCollections(newToken)
+			collections, err = s.api().GetCollections(newToken)
 			if err != nil {
 				return fmt.Errorf("error getting collections after token refresh: %v", err)
 			}
@@ -224,6 +353,9 @@ func (s *SnipeMonitor) checkForNewItems() error {
 		}
 	}
 
+	// Fetch details of all collections in parallel
+	results := s.fetchCollectionDetails(token, collectionIDs(collections.Data))
+
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
@@ -232,22 +364,7 @@ func (s *SnipeMonitor) checkForNewItems() error {
 		s.log("🔄 Token was refreshed and state is empty, performing reinitialization...")
 
 		// Remember all existing collections as known (not new)
-		for _, collection := range collections.Data {
-			s.knownCollections[collection.ID] = true
-
-			// Get collection details to remember characters
-			details, err := s.apiClient.GetCollectionDetails(token, collection.ID)
-			if err != nil {
-				s.log("⚠️ Error getting collection details %d during reinitialization: %v", collection.ID, err)
-				continue
-			}
-
-			// Remember all characters
-			for _, character := range details.Data.Characters {
-				key := fmt.Sprintf("%d:%d", collection.ID, character.ID)
-				s.knownCharacters[key] = true
-			}
-		}
+		s.rememberCollections(results)
 
 		s.log("🔄 Reinitialization completed: %d collections, %d characters marked as known",
 			len(s.knownCollections), len(s.knownCharacters))
@@ -256,96 +373,82 @@ func (s *SnipeMonitor) checkForNewItems() error {
 		return nil
 	}
 
-	// Check for new collections
-	for _, collection := range collections.Data {
-		if !s.knownCollections[collection.ID] {
+	s.recordCatalog(collections.Data, results)
+	s.processCatalog(collections.Data, results)
+	return nil
+}
+
+// processCatalog checks fetched catalog for new collections and characters.
+// results are details of collections in the same order. Must be called with s.mutex held
+func (s *SnipeMonitor) processCatalog(collections []Collection, results []detailsResult) {
+	for i, collection := range collections {
+		result := results[i]
+		isNew := !s.knownCollections[collection.ID]
+
+		if isNew {
 			s.log("🆕 New collection found: %d - %s", collection.ID, collection.Title)
 			s.knownCollections[collection.ID] = true
+		}
 
-			// Check collection against filters
-			if err := s.checkCollection(collection); err != nil {
-				s.log("⚠️ Collection check error %d: %v", collection.ID, err)
-			}
+		if result.err != nil {
+			// If authorization error, token will be refreshed automatically in buyer.go
+			s.log("⚠️ Error getting collection details %d: %v", collection.ID, result.err)
+			continue
 		}
 
-		// Check for new characters in existing collections
-		if err := s.checkCollectionForNewCharacters(collection.ID); err != nil {
-			s.log("⚠️ Character check error in collection %d: %v", collection.ID, err)
+		if isNew {
+			// Check collection against filters
+			s.checkCollection(collection, result.details.Data)
+		} else {
+			// Check for new characters in existing collections
+			s.checkCollectionForNewCharacters(collection.ID, result.details.Data)
 		}
 	}
-
-	return nil
 }
 
-// checkCollection checks collection against filters
-func (s *SnipeMonitor) checkCollection(collection Collection) error {
-	// Get cached token (without API verification)
-	token, err := s.tokenCallback(s.config.Name)
-	if err != nil {
-		return fmt.Errorf("error getting token: %v", err)
-	}
-
-	details, err := s.apiClient.GetCollectionDetails(token, collection.ID)
-	if err != nil {
-		// If authorization error, token will be refreshed automatically in buyer.go
-		return fmt.Errorf("error getting collection details: %v", err)
+// checkCollection checks new collection against filters
+func (s *SnipeMonitor) checkCollection(collection Collection, details CollectionDetails) {
+	// Remember all characters of the new collection
+	for _, character := range details.Characters {
+		key := fmt.Sprintf("%d:%d", collection.ID, character.ID)
+		s.knownCharacters[key] = true
 	}
 
 	// Check word filter
 	if !s.matchesWordFilter(collection.Title) {
 		s.log("🚫 Collection %d did not pass word filter: %s", collection.ID, collection.Title)
-		return nil
+		return
 	}
 
 	// Check each character
-	for _, character := range details.Data.Characters {
-		key := fmt.Sprintf("%d:%d", collection.ID, character.ID)
-		s.knownCharacters[key] = true
-
-		if s.matchesFilters(character) {
+	for _, character := range details.Characters {
+		if s.matchesCharacter(collection, character) {
 			s.log("✅ Suitable character found: %s (ID: %d, Price: %d, Supply: %d)",
 				character.Name, character.ID, character.Price, character.Supply)
 
 			// Log found collection to file
-			if err := s.collectionLogger.LogFoundCollection(collection, character, s.config.Name); err != nil {
+			if err := s.collections.AddFoundCollection(NewFoundCollection(collection, character, s.config.Name)); err != nil {
 				s.log("⚠️ Error saving collection to log: %v", err)
 			} else {
 				s.log("💾 Collection saved to log file")
 			}
 
 			// Send purchase request
-			request := PurchaseRequest{
-				CollectionID: collection.ID,
-				CharacterID:  character.ID,
-				Price:        character.Price,
-				Supply:       character.Supply,
-				Name:         character.Name,
-			}
+			request := s.newPurchaseRequest(collection.ID, character)
 
 			if err := s.purchaseCallback(request); err != nil {
 				s.log("❌ Purchase error: %v", err)
 			}
 		}
 	}
-
-	return nil
 }
 
 // checkCollectionForNewCharacters checks for new characters in collection
-func (s *SnipeMonitor) checkCollectionForNewCharacters(collectionID int) error {
-	// Get cached token (without API verification)
-	token, err := s.tokenCallback(s.config.Name)
-	if err != nil {
-		return fmt.Errorf("error getting token: %v", err)
-	}
-
-	details, err := s.apiClient.GetCollectionDetails(token, collectionID)
-	if err != nil {
-		// If authorization error, token will be refreshed automatically in buyer.go
-		return fmt.Errorf("error getting collection details: %v", err)
-	}
+func (s *SnipeMonitor) checkCollectionForNewCharacters(collectionID int, details CollectionDetails) {
+	collection := details.Collection
+	collection.ID = collectionID
 
-	for _, character := range details.Data.Characters {
+	for _, character := range details.Characters {
 		key := fmt.Sprintf("%d:%d", collectionID, character.ID)
 
 		if !s.knownCharacters[key] {
@@ -353,31 +456,25 @@ func (s *SnipeMonitor) checkCollectionForNewCharacters(collectionID int) error {
 			s.knownCharacters[key] = true
 
 			// Check word filter for collection title
-			if !s.matchesWordFilter(details.Data.Collection.Title) {
+			if !s.matchesWordFilter(details.Collection.Title) {
 				s.log("🚫 Character %d did not pass collection word filter: %s",
-					character.ID, details.Data.Collection.Title)
+					character.ID, details.Collection.Title)
 				continue
 			}
 
-			if s.matchesFilters(character) {
+			if s.matchesCharacter(collection, character) {
 				s.log("✅ Suitable new character found: %s (ID: %d, Price: %d, Supply: %d)",
 					character.Name, character.ID, character.Price, character.Supply)
 
 				// Log found collection to file
-				if err := s.collectionLogger.LogFoundCollection(details.Data.Collection, character, s.config.Name); err != nil {
+				if err := s.collections.AddFoundCollection(NewFoundCollection(collection, character, s.config.Name)); err != nil {
 					s.log("⚠️ Error saving collection to log: %v", err)
 				} else {
 					s.log("💾 Collection saved to log file")
 				}
 
 				// Send purchase request
-				request := PurchaseRequest{
-					CollectionID: collectionID,
-					CharacterID:  character.ID,
-					Price:        character.Price,
-					Supply:       character.Supply,
-					Name:         character.Name,
-				}
+				request := s.newPurchaseRequest(collectionID, character)
 
 				if err := s.purchaseCallback(request); err != nil {
 					s.log("❌ Purchase error: %v", err)
@@ -385,21 +482,21 @@ func (s *SnipeMonitor) checkCollectionForNewCharacters(collectionID int) error {
 			}
 		}
 	}
-
-	return nil
 }
 
 // matchesWordFilter checks against word filter
 func (s *SnipeMonitor) matchesWordFilter(title string) bool {
-	// If filter not specified, skip all
-	if len(s.config.SnipeMonitor.WordFilter) == 0 {
+	filters := s.currentFilters()
+
+	// If filter not specified or replaced by expression, skip all
+	if len(filters.WordFilter) == 0 || filters.expression != nil {
 		return true
 	}
 
 	titleLower := strings.ToLower(title)
 
 	// Check for presence of at least one word from filter
-	for _, word := range s.config.SnipeMonitor.WordFilter {
+	for _, word := range filters.WordFilter {
 		if strings.Contains(titleLower, strings.ToLower(word)) {
 			return true
 		}
@@ -408,26 +505,35 @@ func (s *SnipeMonitor) matchesWordFilter(title string) bool {
 	return false
 }
 
-// matchesFilters checks against all filters
-func (s *SnipeMonitor) matchesFilters(character Character) bool {
+// matchesCharacter checks character against filter expression or supply/price filters
+func (s *SnipeMonitor) matchesCharacter(collection Collection, character Character) bool {
+	filters := s.currentFilters()
+	if filters.expression != nil {
+		return s.matchesExpression(filters.expression, collection, character)
+	}
+	return s.matchesFilters(filters.FilterSettings, character)
+}
+
+// matchesFilters checks against supply and price filters
+func (s *SnipeMonitor) matchesFilters(filters FilterSettings, character Character) bool {
 	// Check quantity range
-	if s.config.SnipeMonitor.SupplyRange != nil {
-		if character.Supply < s.config.SnipeMonitor.SupplyRange.Min ||
-			character.Supply > s.config.SnipeMonitor.SupplyRange.Max {
+	if filters.SupplyRange != nil {
+		if character.Supply < filters.SupplyRange.Min ||
+			character.Supply > filters.SupplyRange.Max {
 			s.log("🚫 Character %s did not pass supply filter: %d (need: %d-%d)",
 				character.Name, character.Supply,
-				s.config.SnipeMonitor.SupplyRange.Min, s.config.SnipeMonitor.SupplyRange.Max)
+				filters.SupplyRange.Min, filters.SupplyRange.Max)
 			return false
 		}
 	}
 
 	// Check price range
-	if s.config.SnipeMonitor.PriceRange != nil {
-		if character.Price < s.config.SnipeMonitor.PriceRange.Min ||
-			character.Price > s.config.SnipeMonitor.PriceRange.Max {
+	if filters.PriceRange != nil {
+		if character.Price < filters.PriceRange.Min ||
+			character.Price > filters.PriceRange.Max {
 			s.log("🚫 Character %s did not pass price filter: %d (need: %d-%d)",
 				character.Name, character.Price,
-				s.config.SnipeMonitor.PriceRange.Min, s.config.SnipeMonitor.PriceRange.Max)
+				filters.PriceRange.Min, filters.PriceRange.Max)
 			return false
 		}
 	}
@@ -0,0 +1,124 @@
+package monitor
+
+import (
+	"fmt"
+	"time"
+
+	"stickersbot/internal/client"
+)
+
+// DefaultWatchdogFailures consecutive failed checks before monitor is restarted
+const DefaultWatchdogFailures = 10
+
+// MonitorHealth health state of snipe monitor
+type MonitorHealth struct {
+	AccountName         string
+	Healthy             bool
+	ConsecutiveFailures int
+	LastError           string
+	LastSuccess         time.Time
+	Restarts            int
+}
+
+// Health returns current health state of the monitor
+func (s *SnipeMonitor) Health() MonitorHealth {
+	s.healthMu.Lock()
+	defer s.healthMu.Unlock()
+
+	return MonitorHealth{
+		AccountName:         s.config.Name,
+		Healthy:             s.consecutiveFailures == 0,
+		ConsecutiveFailures: s.consecutiveFailures,
+		LastError:           s.lastError,
+		LastSuccess:         s.lastSuccess,
+		Restarts:            s.restarts,
+	}
+}
+
+// recordCheck updates health state after a monitoring check.
+// Returns true when watchdog should restart the monitor
+func (s *SnipeMonitor) recordCheck(err error) bool {
+	s.healthMu.Lock()
+	defer s.healthMu.Unlock()
+
+	if err == nil {
+		if s.consecutiveFailures > 0 {
+			s.log("💚 Monitor recovered after %d failed checks", s.consecutiveFailures)
+		}
+		s.consecutiveFailures = 0
+		s.lastSuccess = time.Now()
+		return false
+	}
+
+	s.consecutiveFailures++
+	s.lastError = err.Error()
+
+	threshold := DefaultWatchdogFailures
+	if s.config.SnipeMonitor.WatchdogFailures > 0 {
+		threshold = s.config.SnipeMonitor.WatchdogFailures
+	}
+
+	return s.consecutiveFailures%threshold == 0
+}
+
+// api returns current API client, watchdog may replace it at any time
+func (s *SnipeMonitor) api() *APIClient {
+	s.clientMu.RLock()
+	defer s.clientMu.RUnlock()
+	return s.apiClient
+}
+
+// restart recovers monitor after series of failures: reconnects HTTP client,
+// forces token refresh and re-initializes known collections
+func (s *SnipeMonitor) restart() {
+	s.healthMu.Lock()
+	s.restarts++
+	restarts := s.restarts
+	failures := s.consecutiveFailures
+	s.healthMu.Unlock()
+
+	s.log("🐕 Watchdog: %d consecutive failures, restarting monitor (restart #%d)", failures, restarts)
+
+	// Reconnect through account proxy with fresh connection
+	httpClient, err := client.NewForAccount(s.config.UseProxy, s.config.ProxyURL)
+	if err != nil {
+		s.log("⚠️ Watchdog: error recreating HTTP client: %v", err)
+	} else {
+		apiClient := NewAPIClient(httpClient)
+		apiClient.SetRateLimit(s.config.SnipeMonitor.RequestsPerSecond)
+
+		s.clientMu.Lock()
+		s.httpClient = httpClient
+		s.apiClient = apiClient
+		s.clientMu.Unlock()
+	}
+
+	// Force token refresh
+	if _, err := s.tokens.RefreshTokenOnError(s.config.Name, 401); err != nil {
+		s.log("⚠️ Watchdog: token refresh error: %v", err)
+	}
+
+	// Initialize state if it was never initialized (e.g. monitor failed from the start).
+	// Existing state is kept, so drops that appeared during the outage are still detected as new
+	s.mutex.RLock()
+	initialized := len(s.knownCollections) > 0
+	s.mutex.RUnlock()
+
+	if s.discoveryEnabled() && !initialized {
+		if err := s.initializeState(); err != nil {
+			s.log("⚠️ Watchdog: state initialization error: %v", err)
+			return
+		}
+	}
+
+	s.log("🐕 Watchdog: monitor restarted")
+}
+
+// String returns short health description
+func (h MonitorHealth) String() string {
+	if h.Healthy {
+		return fmt.Sprintf("%s: OK (restarts: %d)", h.AccountName, h.Restarts)
+	}
+	return fmt.Sprintf("%s: %d failures, last error: %s (restarts: %d)",
+		h.AccountName, h.ConsecutiveFailures, h.LastError, h.Restarts)
+}
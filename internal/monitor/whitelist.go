@@ -0,0 +1,148 @@
+package monitor
+
+import (
+	"fmt"
+)
+
+// hasWhitelist checks if collection IDs are configured or armed for watching
+func (s *SnipeMonitor) hasWhitelist() bool {
+	return len(s.watchedCollections()) > 0
+}
+
+// watchedCollections returns configured and armed collection IDs
+func (s *SnipeMonitor) watchedCollections() []int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	ids := append([]int{}, s.config.SnipeMonitor.WatchCollections...)
+	for id := range s.armedCollections {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// ArmCollection adds announced collection to watched collections so it is
+// bought as soon as it goes on sale. Returns false if it is already watched
+func (s *SnipeMonitor) ArmCollection(collectionID int) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.armedCollections[collectionID] {
+		return false
+	}
+	for _, id := range s.config.SnipeMonitor.WatchCollections {
+		if id == collectionID {
+			return false
+		}
+	}
+
+	s.armedCollections[collectionID] = true
+	s.log("📢 Collection %d armed from announcement", collectionID)
+	return true
+}
+
+// isAvailable checks if character is already on sale
+func isAvailable(character Character) bool {
+	return character.Price > 0 && character.Left > 0
+}
+
+// checkWhitelist polls whitelisted collections and triggers purchase
+// as soon as their characters become available
+func (s *SnipeMonitor) checkWhitelist() error {
+	token, err := s.tokens.GetValidToken(s.config.Name)
+	if err != nil {
+		return fmt.Errorf("error getting token: %v", err)
+	}
+
+	ids := s.watchedCollections()
+	results := s.fetchCollectionDetails(token, ids)
+
+	// Refresh token once if any request failed with token error
+	for _, result := range results {
+		if tokenErr, ok := result.err.(*TokenError); ok {
+			newToken, refreshErr := s.tokens.RefreshTokenOnError(s.config.Name, tokenErr.StatusCode)
+			if refreshErr != nil {
+				return fmt.Errorf("error refreshing token: %v", refreshErr)
+			}
+			results = s.fetchCollectionDetails(newToken, ids)
+			break
+		}
+	}
+
+	for _, result := range results {
+		if result.err != nil {
+			// Announced collections usually return errors until they are published
+			s.setWhitelistStatus(result.collectionID, "not available yet", result.err.Error())
+			continue
+		}
+
+		s.checkWhitelistCollection(result.collectionID, result.details.Data)
+	}
+
+	return nil
+}
+
+// checkWhitelistCollection checks characters of whitelisted collection
+func (s *SnipeMonitor) checkWhitelistCollection(collectionID int, details CollectionDetails) {
+	collection := details.Collection
+	collection.ID = collectionID
+
+	available := 0
+	for _, character := range details.Characters {
+		key := fmt.Sprintf("%d:%d", collection.ID, character.ID)
+
+		s.mutex.Lock()
+		s.knownCharacters[key] = true
+		fired := s.whitelistFired[key]
+		s.mutex.Unlock()
+
+		if fired || !isAvailable(character) {
+			continue
+		}
+		available++
+
+		if !s.matchesCharacter(collection, character) {
+			continue
+		}
+
+		s.log("🎯 Whitelisted character is available: %s (Collection: %d, ID: %d, Price: %d, Left: %d)",
+			character.Name, collection.ID, character.ID, character.Price, character.Left)
+
+		s.mutex.Lock()
+		s.whitelistFired[key] = true
+		s.mutex.Unlock()
+
+		// Log found collection to file
+		if err := s.collections.AddFoundCollection(NewFoundCollection(collection, character, s.config.Name)); err != nil {
+			s.log("⚠️ Error saving collection to log: %v", err)
+		}
+
+		request := s.newPurchaseRequest(collection.ID, character)
+
+		if err := s.purchaseCallback(request); err != nil {
+			s.log("❌ Purchase error: %v", err)
+			// Allow another attempt on the next tick
+			s.mutex.Lock()
+			delete(s.whitelistFired, key)
+			s.mutex.Unlock()
+		}
+	}
+
+	if available > 0 {
+		s.setWhitelistStatus(collection.ID, "on sale", fmt.Sprintf("%d characters available", available))
+	} else {
+		s.setWhitelistStatus(collection.ID, "published, waiting for sale", fmt.Sprintf("%d characters", len(details.Characters)))
+	}
+}
+
+// setWhitelistStatus logs status of whitelisted collection only when it changes
+func (s *SnipeMonitor) setWhitelistStatus(collectionID int, status string, details string) {
+	s.mutex.Lock()
+	changed := s.whitelistStatus[collectionID] != status
+	s.whitelistStatus[collectionID] = status
+	s.mutex.Unlock()
+
+	if changed {
+		s.log("📌 Whitelisted collection %d: %s (%s)", collectionID, status, details)
+	}
+}
@@ -0,0 +1,125 @@
+package notify
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// implicitTLSPort SMTP port where connection is TLS from the start instead of STARTTLS
+const implicitTLSPort = 465
+
+// EmailOptions SMTP server and addresses of email notifier
+type EmailOptions struct {
+	Host     string   // SMTP server host
+	Port     int      // SMTP server port (default 587)
+	Username string   // Login, empty - no authentication
+	Password string   // Password of login
+	From     string   // Sender address
+	To       []string // Recipient addresses
+}
+
+// Email sends events as plain text emails through SMTP
+type Email struct {
+	options EmailOptions
+}
+
+// NewEmail creates email notifier
+func NewEmail(options EmailOptions) *Email {
+	if options.Port == 0 {
+		options.Port = 587
+	}
+	return &Email{options: options}
+}
+
+// Notify sends event as email to all recipients
+func (e *Email) Notify(ctx context.Context, event Event) error {
+	addr := net.JoinHostPort(e.options.Host, strconv.Itoa(e.options.Port))
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("error connecting to %s: %v", addr, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	tlsConfig := &tls.Config{ServerName: e.options.Host}
+	if e.options.Port == implicitTLSPort {
+		conn = tls.Client(conn, tlsConfig)
+	}
+	c, err := smtp.NewClient(conn, e.options.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("error starting SMTP session: %v", err)
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok && e.options.Port != implicitTLSPort {
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("STARTTLS error: %v", err)
+		}
+	}
+	if e.options.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", e.options.Username, e.options.Password, e.options.Host)); err != nil {
+			return fmt.Errorf("SMTP authentication error: %v", err)
+		}
+	}
+
+	if err := c.Mail(e.options.From); err != nil {
+		return fmt.Errorf("MAIL FROM error: %v", err)
+	}
+	for _, to := range e.options.To {
+		if err := c.Rcpt(to); err != nil {
+			return fmt.Errorf("RCPT TO %s error: %v", to, err)
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("DATA error: %v", err)
+	}
+	if _, err := w.Write(e.message(event)); err != nil {
+		return fmt.Errorf("error writing message: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("error sending message: %v", err)
+	}
+	return c.Quit()
+}
+
+// message returns event as email with headers
+func (e *Email) message(event Event) []byte {
+	subject := event.Severity.Icon() + " " + event.Title
+	if event.Account != "" {
+		subject += fmt.Sprintf(" [%s]", event.Account)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("From: " + e.options.From + "\r\n")
+	sb.WriteString("To: " + strings.Join(e.options.To, ", ") + "\r\n")
+	sb.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", subject) + "\r\n")
+	sb.WriteString("Date: " + event.Time.Format(time.RFC1123Z) + "\r\n")
+	sb.WriteString("MIME-Version: 1.0\r\n")
+	sb.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	sb.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+
+	sb.WriteString(event.Text() + "\r\n\r\n")
+	sb.WriteString(fmt.Sprintf("Event: %s (%s)\r\n", event.Type, event.Severity))
+	sb.WriteString("Time: " + event.Time.Format("2006-01-02 15:04:05 MST") + "\r\n")
+	names := make([]string, 0, len(event.Fields))
+	for name := range event.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		sb.WriteString(fmt.Sprintf("%s: %v\r\n", name, event.Fields[name]))
+	}
+	return []byte(sb.String())
+}
@@ -0,0 +1,182 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Severity importance level of event
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityCritical
+)
+
+// String returns severity name
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityCritical:
+		return "critical"
+	default:
+		return "info"
+	}
+}
+
+// Icon returns emoji marking severity in messages
+func (s Severity) Icon() string {
+	switch s {
+	case SeverityWarning:
+		return "⚠️"
+	case SeverityCritical:
+		return "🚨"
+	default:
+		return "ℹ️"
+	}
+}
+
+// MarshalText encodes severity as its name
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// EventType type of notification event
+type EventType string
+
+const (
+	EventSnipeMatch    EventType = "snipe_match"    // Snipe monitor found suitable character
+	EventSnipePurchase EventType = "snipe_purchase" // Result of purchase of snipe match
+	EventTaskStopped   EventType = "task_stopped"   // Task stopped by itself
+	EventNotCredited   EventType = "not_credited"   // Paid order didn't appear in account inventory
+	EventAccountDead   EventType = "account_dead"   // Telegram banned or deactivated account
+	EventPurchase      EventType = "purchase"       // Order paid
+	EventPaymentFailed EventType = "payment_failed" // Created order could not be paid
+	EventTokenExpired  EventType = "token_expired"  // Token of account could not be refreshed
+	EventAuthFailures  EventType = "auth_failures"  // Token of account failed to refresh several times in a row
+	EventAllInactive   EventType = "all_inactive"   // No account is left to buy with, the task stops
+	EventLowBalance    EventType = "low_balance"    // Wallet balance fell below notifications.low_balance
+	EventLicenseFailed EventType = "license_failed" // License could not be verified anymore
+	EventAlert         EventType = "alert"          // Rule of notifications.alerts was triggered
+)
+
+// Event notification event
+type Event struct {
+	Type     EventType              `json:"type"`
+	Severity Severity               `json:"severity"`
+	Account  string                 `json:"account,omitempty"`
+	Title    string                 `json:"title"`
+	Message  string                 `json:"message"`
+	Fields   map[string]interface{} `json:"fields,omitempty"`
+	Time     time.Time              `json:"time"`
+}
+
+// Text returns event as human readable text
+func (e Event) Text() string {
+	var sb strings.Builder
+	sb.WriteString(e.Title)
+	if e.Account != "" {
+		sb.WriteString(fmt.Sprintf(" [%s]", e.Account))
+	}
+	if e.Message != "" {
+		sb.WriteString("\n")
+		sb.WriteString(e.Message)
+	}
+	return sb.String()
+}
+
+// Notifier delivers events to one destination
+type Notifier interface {
+	Notify(ctx context.Context, event Event) error
+}
+
+// NotifierFunc adapter to use ordinary functions as notifiers
+type NotifierFunc func(ctx context.Context, event Event) error
+
+// Notify calls f(ctx, event)
+func (f NotifierFunc) Notify(ctx context.Context, event Event) error {
+	return f(ctx, event)
+}
+
+// Only returns notifier delivering events of given types only, all events if types are empty
+func Only(types []string, notifier Notifier) Notifier {
+	if len(types) == 0 {
+		return notifier
+	}
+	allowed := make(map[EventType]bool)
+	for _, eventType := range types {
+		allowed[EventType(eventType)] = true
+	}
+	return NotifierFunc(func(ctx context.Context, event Event) error {
+		if !allowed[event.Type] {
+			return nil
+		}
+		return notifier.Notify(ctx, event)
+	})
+}
+
+// AtLeast returns notifier delivering events of given severity or higher only
+func AtLeast(severity Severity, notifier Notifier) Notifier {
+	return NotifierFunc(func(ctx context.Context, event Event) error {
+		if event.Severity < severity {
+			return nil
+		}
+		return notifier.Notify(ctx, event)
+	})
+}
+
+// deliveryTimeout maximum time of delivering one event to one notifier
+const deliveryTimeout = 15 * time.Second
+
+// Dispatcher sends events to all registered notifiers without blocking the caller
+type Dispatcher struct {
+	notifiers map[string]Notifier
+	mu        sync.RWMutex
+
+	// OnError is called when notifier fails to deliver event (optional)
+	OnError func(name string, err error)
+}
+
+// NewDispatcher creates dispatcher without notifiers
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{
+		notifiers: make(map[string]Notifier),
+	}
+}
+
+// Register adds notifier under given name, replacing notifier with the same name
+func (d *Dispatcher) Register(name string, notifier Notifier) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.notifiers[name] = notifier
+}
+
+// Send delivers event to all notifiers in background
+func (d *Dispatcher) Send(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	for name, notifier := range d.notifiers {
+		go d.deliver(name, notifier, event)
+	}
+}
+
+// deliver delivers event to one notifier
+func (d *Dispatcher) deliver(name string, notifier Notifier, event Event) {
+	ctx, cancel := context.WithTimeout(context.Background(), deliveryTimeout)
+	defer cancel()
+
+	if err := notifier.Notify(ctx, event); err != nil && d.OnError != nil {
+		d.OnError(name, err)
+	}
+}
@@ -0,0 +1,147 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// defaultNtfyServer public ntfy server
+const defaultNtfyServer = "https://ntfy.sh"
+
+// pushoverURL message endpoint of Pushover API
+const pushoverURL = "https://api.pushover.net/1/messages.json"
+
+// ParseSeverity returns severity by its name, empty name is info
+func ParseSeverity(name string) (Severity, error) {
+	switch strings.ToLower(name) {
+	case "", "info":
+		return SeverityInfo, nil
+	case "warning":
+		return SeverityWarning, nil
+	case "critical":
+		return SeverityCritical, nil
+	default:
+		return SeverityInfo, fmt.Errorf("unknown severity %q (info, warning or critical)", name)
+	}
+}
+
+// Ntfy publishes events to ntfy topic
+type Ntfy struct {
+	server string
+	topic  string
+	token  string
+	client *http.Client
+}
+
+// NewNtfy creates ntfy notifier for topic of server (default ntfy.sh), token is needed for protected topics only
+func NewNtfy(server, topic, token string) *Ntfy {
+	if server == "" {
+		server = defaultNtfyServer
+	}
+	return &Ntfy{
+		server: strings.TrimRight(server, "/"),
+		topic:  topic,
+		token:  token,
+		client: &http.Client{},
+	}
+}
+
+// Notify publishes event with priority of its severity
+func (n *Ntfy) Notify(ctx context.Context, event Event) error {
+	// Default, high and urgent priorities of ntfy
+	priority := map[Severity]int{SeverityInfo: 3, SeverityWarning: 4, SeverityCritical: 5}[event.Severity]
+
+	title := event.Title
+	if event.Account != "" {
+		title += fmt.Sprintf(" [%s]", event.Account)
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"topic":    n.topic,
+		"title":    title,
+		"message":  event.Message,
+		"priority": priority,
+		"tags":     []string{string(event.Type)},
+	})
+	if err != nil {
+		return fmt.Errorf("error encoding message: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.server, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error creating request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if n.token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.token)
+	}
+	return send(n.client, req, "ntfy")
+}
+
+// Pushover sends events to Pushover user or group
+type Pushover struct {
+	appToken string
+	userKey  string
+	client   *http.Client
+}
+
+// NewPushover creates Pushover notifier for application token and user or group key
+func NewPushover(appToken, userKey string) *Pushover {
+	return &Pushover{
+		appToken: appToken,
+		userKey:  userKey,
+		client:   &http.Client{},
+	}
+}
+
+// Notify sends event with priority of its severity
+func (p *Pushover) Notify(ctx context.Context, event Event) error {
+	// Low, normal and high priorities of Pushover, high ones bypass quiet hours
+	priority := map[Severity]int{SeverityInfo: -1, SeverityWarning: 0, SeverityCritical: 1}[event.Severity]
+
+	title := event.Title
+	if event.Account != "" {
+		title += fmt.Sprintf(" [%s]", event.Account)
+	}
+	message := event.Message
+	if message == "" {
+		// Pushover rejects messages without text
+		message = title
+	}
+	form := url.Values{
+		"token":     {p.appToken},
+		"user":      {p.userKey},
+		"title":     {title},
+		"message":   {message},
+		"priority":  {strconv.Itoa(priority)},
+		"timestamp": {strconv.FormatInt(event.Time.Unix(), 10)},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, pushoverURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("error creating request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return send(p.client, req, "pushover")
+}
+
+// send sends request of push service and checks its status
+func send(client *http.Client, req *http.Request, service string) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending request: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned status %d: %s", service, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
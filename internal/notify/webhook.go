@@ -0,0 +1,120 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"text/template"
+)
+
+// WebhookOptions request settings of templated webhook
+type WebhookOptions struct {
+	Method      string            // HTTP method (default POST)
+	Headers     map[string]string // Extra request headers
+	Template    string            // Go template of request body, event is its data (default event as JSON)
+	ContentType string            // Content-Type of body (default application/json)
+}
+
+// Webhook posts events as JSON to user-configured URL
+type Webhook struct {
+	url      string
+	options  WebhookOptions
+	template *template.Template
+	client   *http.Client
+}
+
+// NewWebhook creates webhook notifier for given URL
+func NewWebhook(url string) *Webhook {
+	return &Webhook{
+		url:    url,
+		client: &http.Client{},
+	}
+}
+
+// NewTemplateWebhook creates webhook notifier sending body rendered from template of options
+func NewTemplateWebhook(url string, options WebhookOptions) (*Webhook, error) {
+	w := NewWebhook(url)
+	w.options = options
+	if options.Template != "" {
+		tmpl, err := ParseTemplate(options.Template)
+		if err != nil {
+			return nil, err
+		}
+		w.template = tmpl
+	}
+	return w, nil
+}
+
+// ParseTemplate parses webhook body template. Event is data of the template, e.g.
+// {{.Title}} or {{.Fields.price}}; {{.Text}} is the human readable text and
+// {{json .Message}} embeds a value as JSON with quotes and escaping
+func ParseTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("webhook").Funcs(template.FuncMap{
+		"json": func(value interface{}) (string, error) {
+			encoded, err := json.Marshal(value)
+			return string(encoded), err
+		},
+	}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing template: %v", err)
+	}
+	return tmpl, nil
+}
+
+// Notify sends event as JSON POST request, or as rendered template with method and headers of options
+func (w *Webhook) Notify(ctx context.Context, event Event) error {
+	body, err := w.body(event)
+	if err != nil {
+		return err
+	}
+
+	method := w.options.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+	req, err := http.NewRequestWithContext(ctx, method, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error creating request: %v", err)
+	}
+	contentType := w.options.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	req.Header.Set("Content-Type", contentType)
+	for name, value := range w.options.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending request: %v", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// body returns request body of event
+func (w *Webhook) body(event Event) ([]byte, error) {
+	if w.template == nil {
+		body, err := json.Marshal(event)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding event: %v", err)
+		}
+		return body, nil
+	}
+
+	var buf bytes.Buffer
+	if err := w.template.Execute(&buf, event); err != nil {
+		return nil, fmt.Errorf("error rendering template: %v", err)
+	}
+	return buf.Bytes(), nil
+}
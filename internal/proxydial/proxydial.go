@@ -0,0 +1,222 @@
+// Package proxydial opens connections through proxies and proxy chains of accounts
+package proxydial
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"stickersbot/internal/config"
+
+	"golang.org/x/net/proxy"
+)
+
+// Dialer opens connections through proxy
+type Dialer interface {
+	proxy.Dialer
+	proxy.ContextDialer
+}
+
+// Chain returns dialer going through hops in order, the last hop connects to the
+// destination. Hops without scheme use defaultScheme
+func Chain(hops []*config.Proxy, defaultScheme string) (Dialer, error) {
+	var dialer Dialer = &net.Dialer{}
+	for i, hop := range hops {
+		next, err := hopDialer(hop, defaultScheme, dialer)
+		if err != nil {
+			return nil, fmt.Errorf("proxy hop %d: %v", i+1, err)
+		}
+		dialer = next
+	}
+	return dialer, nil
+}
+
+// hopDialer returns dialer connecting through proxy reached by forward
+func hopDialer(p *config.Proxy, defaultScheme string, forward Dialer) (Dialer, error) {
+	switch scheme := p.SchemeOr(defaultScheme); scheme {
+	case config.ProxySchemeHTTP, config.ProxySchemeHTTPS:
+		return &httpConnectDialer{proxy: p, useTLS: scheme == config.ProxySchemeHTTPS, forward: forward}, nil
+	default:
+		var auth *proxy.Auth
+		if p.Username != "" {
+			auth = &proxy.Auth{
+				User:     p.Username,
+				Password: p.Password,
+			}
+		}
+
+		dialer, err := proxy.SOCKS5("tcp", p.Address(), auth, forward)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create SOCKS5 proxy: %v", err)
+		}
+		contextDialer, ok := dialer.(Dialer)
+		if !ok {
+			return nil, fmt.Errorf("SOCKS5 dialer does not support context")
+		}
+		return contextDialer, nil
+	}
+}
+
+// httpConnectDialer opens tunnels through HTTP proxy with CONNECT requests
+type httpConnectDialer struct {
+	proxy   *config.Proxy
+	useTLS  bool
+	forward Dialer
+}
+
+// Dial opens tunnel to addr
+func (d *httpConnectDialer) Dial(network, addr string) (net.Conn, error) {
+	return d.DialContext(context.Background(), network, addr)
+}
+
+// DialContext opens tunnel to addr
+func (d *httpConnectDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	conn, err := d.forward.DialContext(ctx, "tcp", d.proxy.Address())
+	if err != nil {
+		return nil, fmt.Errorf("connecting to proxy: %v", err)
+	}
+	if d.useTLS {
+		conn = tls.Client(conn, &tls.Config{ServerName: d.proxy.Host})
+	}
+
+	// Handshake must not outlive the context
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if d.proxy.Username != "" {
+		credentials := base64.StdEncoding.EncodeToString([]byte(d.proxy.Username + ":" + d.proxy.Password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("sending CONNECT to proxy: %v", err)
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("reading CONNECT response of proxy: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy refused tunnel: %s", resp.Status)
+	}
+
+	conn.SetDeadline(time.Time{})
+	if reader.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, reader: reader}, nil
+	}
+	return conn, nil
+}
+
+// bufferedConn connection whose first bytes were already read into reader
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+// Read reads buffered bytes first
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+// Local forwarders of proxy chains (chain -> local address)
+var (
+	forwarders   = make(map[string]string)
+	forwardersMu sync.Mutex
+)
+
+// Forward returns local address whose connections are forwarded to the last hop of
+// chain through the hops before it. Clients that only take a single proxy use the
+// local address in place of the last hop. Forwarder lives until the process exits
+func Forward(hops []*config.Proxy, defaultScheme string) (string, error) {
+	if len(hops) < 2 {
+		return "", fmt.Errorf("proxy chain needs at least two hops")
+	}
+
+	urls := make([]string, len(hops))
+	for i, hop := range hops {
+		urls[i] = hop.URL(defaultScheme)
+	}
+	key := strings.Join(urls, config.ProxyChainSeparator)
+
+	forwardersMu.Lock()
+	defer forwardersMu.Unlock()
+
+	if addr, ok := forwarders[key]; ok {
+		return addr, nil
+	}
+
+	dialer, err := Chain(hops[:len(hops)-1], defaultScheme)
+	if err != nil {
+		return "", err
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", fmt.Errorf("starting proxy chain forwarder: %v", err)
+	}
+	go serveForwarder(listener, dialer, hops[len(hops)-1].Address())
+
+	forwarders[key] = listener.Addr().String()
+	return listener.Addr().String(), nil
+}
+
+// Tunnel starts local listener whose connections are forwarded to target through dialer,
+// for clients that dial their servers themselves. Closing the listener stops the tunnel
+func Tunnel(dialer Dialer, target string) (net.Listener, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("starting tunnel to %s: %v", target, err)
+	}
+	go serveForwarder(listener, dialer, target)
+	return listener, nil
+}
+
+// serveForwarder pipes every accepted connection to target dialed through dialer
+func serveForwarder(listener net.Listener, dialer Dialer, target string) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go func() {
+			defer conn.Close()
+
+			upstream, err := dialer.Dial("tcp", target)
+			if err != nil {
+				return
+			}
+			defer upstream.Close()
+
+			done := make(chan struct{}, 2)
+			go func() {
+				io.Copy(upstream, conn)
+				done <- struct{}{}
+			}()
+			go func() {
+				io.Copy(conn, upstream)
+				done <- struct{}{}
+			}()
+			<-done
+		}()
+	}
+}
@@ -0,0 +1,132 @@
+// Package redact removes tokens, seed phrases, Telegram initData and proxy credentials
+// from text before it reaches any log sink
+package redact
+
+import (
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Mask text replacing removed secrets
+const Mask = "[REDACTED]"
+
+// minSecretLength shorter registered values are ignored, they would mask ordinary words
+const minSecretLength = 6
+
+var (
+	enabled   atomic.Bool
+	addresses atomic.Bool
+
+	secrets  = make(map[string]bool)
+	replacer = strings.NewReplacer()
+	mu       sync.RWMutex
+)
+
+func init() {
+	enabled.Store(true)
+}
+
+// Patterns of secrets found without knowing their values
+var (
+	bearerPattern    = regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9\-_.~+/=]+`)
+	jwtPattern       = regexp.MustCompile(`eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]+`)
+	jsonFieldPattern = regexp.MustCompile(`(?i)("[a-z_]*(?:token|auth|init_?data|password|seed|secret|api_hash)[a-z_]*"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+	initDataPattern  = regexp.MustCompile(`(?:query_id|user)=[^\s"]*?hash=[0-9a-fA-F]+`)
+	proxyURLPattern  = regexp.MustCompile(`([a-zA-Z][a-zA-Z0-9+.-]*://)[^\s:/@]+:[^\s/@]+@`)
+	proxyPlainPat    = regexp.MustCompile(`\b([A-Za-z0-9.-]+:\d{2,5}):[^\s:@"]+:[^\s:@"]+`)
+	addressPattern   = regexp.MustCompile(`\b((?:EQ|UQ|kQ|0Q)[A-Za-z0-9_-]{2})[A-Za-z0-9_-]{40}([A-Za-z0-9_-]{4})\b`)
+)
+
+// SetEnabled turns redaction on or off. Off is meant for local debugging only
+func SetEnabled(on bool) {
+	enabled.Store(on)
+}
+
+// SetAddresses turns shortening of TON wallet addresses on or off
+func SetAddresses(on bool) {
+	addresses.Store(on)
+}
+
+// AddSecret registers known secret value (seed phrase, token, password), its every
+// occurrence is masked
+func AddSecret(values ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	changed := false
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if len(value) < minSecretLength || secrets[value] {
+			continue
+		}
+		secrets[value] = true
+		changed = true
+	}
+	if !changed {
+		return
+	}
+
+	// Longer secrets first, so a secret containing another one is masked whole
+	list := make([]string, 0, len(secrets))
+	for value := range secrets {
+		list = append(list, value)
+	}
+	sort.Slice(list, func(i, j int) bool { return len(list[i]) > len(list[j]) })
+
+	pairs := make([]string, 0, 2*len(list))
+	for _, value := range list {
+		pairs = append(pairs, value, Mask)
+	}
+	replacer = strings.NewReplacer(pairs...)
+}
+
+// String returns text with secrets masked
+func String(text string) string {
+	if !enabled.Load() {
+		return text
+	}
+
+	mu.RLock()
+	r := replacer
+	mu.RUnlock()
+	text = r.Replace(text)
+
+	text = JSONFields(text)
+	text = bearerPattern.ReplaceAllString(text, "${1}"+Mask)
+	text = jwtPattern.ReplaceAllString(text, Mask)
+	text = initDataPattern.ReplaceAllString(text, Mask)
+	text = proxyURLPattern.ReplaceAllString(text, "${1}***:***@")
+	text = proxyPlainPat.ReplaceAllString(text, "${1}:***:***")
+	if addresses.Load() {
+		text = addressPattern.ReplaceAllString(text, "${1}…${2}")
+	}
+	return text
+}
+
+// JSONFields masks values of JSON string fields named like tokens, passwords and initData.
+// Applied regardless of SetEnabled, used for data that is stored rather than logged
+func JSONFields(text string) string {
+	return jsonFieldPattern.ReplaceAllString(text, `${1}"`+Mask+`"`)
+}
+
+// writer masks secrets in everything written through it
+type writer struct {
+	out io.Writer
+}
+
+// Writer returns writer masking secrets before writing to out, for log.SetOutput
+func Writer(out io.Writer) io.Writer {
+	return &writer{out: out}
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(w.out, String(string(p))); err != nil {
+		return 0, err
+	}
+	// Caller wrote all of p, however long the masked text is
+	return len(p), nil
+}
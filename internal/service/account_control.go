@@ -0,0 +1,145 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"stickersbot/internal/config"
+	"stickersbot/internal/notify"
+)
+
+// pausedPollInterval how often paused worker checks if it was resumed
+const pausedPollInterval = 500 * time.Millisecond
+
+// AccountState runtime state of account
+type AccountState struct {
+	Name   string `json:"name"`
+	Mode   string `json:"mode"`   // "snipe" or "direct"
+	Active bool   `json:"active"` // False when limits are reached or all targets are sold out
+	Paused bool   `json:"paused"`
+	// Another instance holds lease of account and buys for it
+	Standby bool   `json:"standby,omitempty"`
+	Dead    string `json:"dead,omitempty"` // Why Telegram account is unusable (banned, deleted, session terminated)
+}
+
+// PauseAccount pauses purchases of account until it is resumed.
+// Snipe monitor keeps scanning but its matches are not bought
+func (bs *BuyerService) PauseAccount(accountName string) error {
+	if bs.findAccount(accountName) == nil {
+		return fmt.Errorf("account %s not found", accountName)
+	}
+
+	bs.pausedMu.Lock()
+	bs.pausedAccounts[accountName] = true
+	bs.pausedMu.Unlock()
+
+	bs.log(fmt.Sprintf("⏸️ Account '%s' paused", accountName))
+	return nil
+}
+
+// ResumeAccount resumes purchases of paused account
+func (bs *BuyerService) ResumeAccount(accountName string) error {
+	if bs.findAccount(accountName) == nil {
+		return fmt.Errorf("account %s not found", accountName)
+	}
+
+	bs.pausedMu.Lock()
+	delete(bs.pausedAccounts, accountName)
+	bs.pausedMu.Unlock()
+
+	bs.log(fmt.Sprintf("▶️ Account '%s' resumed", accountName))
+	return nil
+}
+
+// IsAccountPaused checks if purchases of account are paused
+func (bs *BuyerService) IsAccountPaused(accountName string) bool {
+	bs.pausedMu.RLock()
+	defer bs.pausedMu.RUnlock()
+
+	return bs.pausedAccounts[accountName]
+}
+
+// GetAccountStates returns runtime state of all accounts
+func (bs *BuyerService) GetAccountStates() []AccountState {
+	bs.activeAccountsMu.RLock()
+	defer bs.activeAccountsMu.RUnlock()
+
+	states := make([]AccountState, 0, len(bs.config.Accounts))
+	for _, account := range bs.config.Accounts {
+		mode := "direct"
+		if account.SnipeMonitor != nil && account.SnipeMonitor.Enabled {
+			mode = "snipe"
+		}
+
+		active, started := bs.activeAccounts[account.Name]
+		states = append(states, AccountState{
+			Name:    account.Name,
+			Mode:    mode,
+			Active:  active || !started,
+			Paused:  bs.IsAccountPaused(account.Name),
+			Standby: bs.isStandby(account.Name),
+			Dead:    bs.DeadReason(account.Name),
+		})
+	}
+	return states
+}
+
+// isAccountIdle checks if account has no working threads: it is paused or was stopped
+func (bs *BuyerService) isAccountIdle(accountName string) bool {
+	if bs.IsAccountPaused(accountName) {
+		return true
+	}
+
+	bs.activeAccountsMu.RLock()
+	defer bs.activeAccountsMu.RUnlock()
+	active, started := bs.activeAccounts[accountName]
+	return started && !active
+}
+
+// RefreshToken forcibly refreshes authorization token of account
+func (bs *BuyerService) RefreshToken(accountName string) error {
+	if bs.findAccount(accountName) == nil {
+		return fmt.Errorf("account %s not found", accountName)
+	}
+
+	if _, err := bs.tokenManager.ForceRefreshToken(accountName); err != nil {
+		return fmt.Errorf("token refresh error: %v", err)
+	}
+
+	bs.log(fmt.Sprintf("🔑 Token of '%s' refreshed on request", accountName))
+	return nil
+}
+
+// DeadReason returns why Telegram account is unusable, empty if it is fine
+func (bs *BuyerService) DeadReason(accountName string) string {
+	return bs.tokenManager.DeadReason(accountName)
+}
+
+// handleAccountDead stops purchases of account banned or deactivated by Telegram
+func (bs *BuyerService) handleAccountDead(accountName, reason string) {
+	bs.log(fmt.Sprintf("💀 Account '%s' is dead: %s", accountName, reason))
+
+	bs.notifier.Send(notify.Event{
+		Type:     notify.EventAccountDead,
+		Severity: notify.SeverityCritical,
+		Account:  accountName,
+		Title:    "💀 Telegram account is dead",
+		Message:  fmt.Sprintf("%s - purchases of the account are stopped", reason),
+		Fields: map[string]interface{}{
+			"reason": reason,
+		},
+	})
+
+	// Threads see the dead account and exit, the run stops if no account is left
+	bs.setAccountInactive(accountName, reason)
+}
+
+// findAccount returns account from configuration by name
+func (bs *BuyerService) findAccount(accountName string) *config.Account {
+	for i := range bs.config.Accounts {
+		if bs.config.Accounts[i].Name == accountName {
+			return &bs.config.Accounts[i]
+		}
+	}
+	return nil
+}
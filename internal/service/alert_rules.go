@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"stickersbot/internal/config"
+	"stickersbot/internal/monitor"
+	"stickersbot/internal/notify"
+	"stickersbot/internal/types"
+)
+
+// defaultAlertWindow window of alert rules without window_minutes
+const defaultAlertWindow = 5 * time.Minute
+
+// minAlertRequests requests in window below which error rate is not judged
+const minAlertRequests = 10
+
+// alertRule statistics rule of notifications.alerts with its state
+type alertRule struct {
+	config.AlertRule
+	window   time.Duration
+	active   *monitor.ActiveWindow
+	severity notify.Severity
+	firing   bool
+}
+
+// alertSample totals of run statistics at a moment
+type alertSample struct {
+	time     time.Time
+	counters types.Counters
+}
+
+// alertRules statistics rules of the run, checked on every statistics update
+type alertRules struct {
+	rules   []*alertRule
+	samples []alertSample // Oldest first
+	keep    time.Duration // How long samples are needed by the longest rule
+}
+
+// startAlertRules prepares statistics rules of notifications.alerts and starts balance
+// checks of low_balance rules. Nil if there are no statistics rules
+func (bs *BuyerService) startAlertRules(ctx context.Context) *alertRules {
+	if bs.config.Notifications == nil {
+		return nil
+	}
+
+	alerts := &alertRules{}
+	for _, settings := range bs.config.Notifications.Alerts {
+		rule, err := newAlertRule(settings)
+		if err != nil {
+			bs.log(fmt.Sprintf("⚠️ Alert '%s': %v, skipped", settings.RuleName(), err))
+			continue
+		}
+
+		if rule.Type == config.AlertLowBalance {
+			go bs.watchBalances(ctx, rule.Threshold, rule.window, rule.active, func(accountName string, wallet WalletInfo) {
+				bs.sendAlert(rule, accountName, fmt.Sprintf("Wallet %s has %.4f TON, below %.4f TON",
+					maskAddress(wallet.Address), wallet.Balance, rule.Threshold), wallet.Balance)
+			})
+			continue
+		}
+
+		alerts.rules = append(alerts.rules, rule)
+		// Drop of RPS compares two windows
+		alerts.keep = max(alerts.keep, 2*rule.window)
+	}
+
+	if len(alerts.rules) == 0 {
+		return nil
+	}
+	return alerts
+}
+
+// newAlertRule parses window and severity of rule
+func newAlertRule(settings config.AlertRule) (*alertRule, error) {
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+	active, err := monitor.ParseActiveWindow(settings.ActiveFrom, settings.ActiveUntil)
+	if err != nil {
+		return nil, err
+	}
+	severity := notify.SeverityWarning
+	if settings.Severity != "" {
+		if severity, err = notify.ParseSeverity(settings.Severity); err != nil {
+			return nil, err
+		}
+	}
+
+	window := defaultAlertWindow
+	if settings.WindowMinutes > 0 {
+		window = time.Duration(settings.WindowMinutes) * time.Minute
+	}
+	return &alertRule{AlertRule: settings, window: window, active: active, severity: severity}, nil
+}
+
+// evaluate records totals of the run and notifies about rules that became true.
+// Nil rules - nothing to check
+func (a *alertRules) evaluate(bs *BuyerService, now time.Time, counters types.Counters) {
+	if a == nil {
+		return
+	}
+	a.record(now, counters)
+
+	for _, rule := range a.rules {
+		message, value, triggered := a.check(rule, now, counters)
+		if !rule.active.Active(now) {
+			triggered = false
+		}
+
+		switch {
+		case triggered && !rule.firing:
+			rule.firing = true
+			bs.sendAlert(rule, "", message, value)
+		case !triggered && rule.firing:
+			rule.firing = false
+			bs.log(fmt.Sprintf("✅ Alert '%s' resolved", rule.RuleName()))
+		}
+	}
+}
+
+// check returns description and value of rule condition and whether it is true now
+func (a *alertRules) check(rule *alertRule, now time.Time, counters types.Counters) (string, float64, bool) {
+	past, ok := a.sampleAt(now.Add(-rule.window))
+	if !ok {
+		// The run is shorter than the window
+		return "", 0, false
+	}
+	requests := counters.TotalRequests - past.counters.TotalRequests
+	minutes := rule.window.Minutes()
+
+	switch rule.Type {
+	case config.AlertErrorRate:
+		if requests < minAlertRequests {
+			return "", 0, false
+		}
+		rate := float64(counters.FailedRequests-past.counters.FailedRequests) / float64(requests) * 100
+		return fmt.Sprintf("%.1f%% of %d requests failed over %.0f min (threshold %.1f%%)",
+			rate, requests, minutes, rule.Threshold), rate, rate > rule.Threshold
+
+	case config.AlertNoSuccess:
+		successes := counters.SuccessRequests - past.counters.SuccessRequests
+		return fmt.Sprintf("No successful request over %.0f min (%d requests sent)", minutes, requests),
+			float64(successes), successes == 0
+
+	case config.AlertRPSDrop:
+		older, ok := a.sampleAt(now.Add(-2 * rule.window))
+		if !ok {
+			return "", 0, false
+		}
+		previous := float64(past.counters.TotalRequests-older.counters.TotalRequests) / rule.window.Seconds()
+		current := float64(requests) / rule.window.Seconds()
+		if previous == 0 {
+			return "", 0, false
+		}
+		drop := (1 - current/previous) * 100
+		return fmt.Sprintf("RPS fell from %.1f to %.1f (-%.0f%%) over %.0f min (threshold %.0f%%)",
+			previous, current, drop, minutes, rule.Threshold), drop, drop > rule.Threshold
+	}
+	return "", 0, false
+}
+
+// record adds totals of the run and forgets samples no rule needs anymore
+func (a *alertRules) record(now time.Time, counters types.Counters) {
+	a.samples = append(a.samples, alertSample{time: now, counters: counters})
+
+	// The newest sample older than the longest window is kept for comparison
+	cutoff := now.Add(-a.keep)
+	drop := 0
+	for drop+1 < len(a.samples) && !a.samples[drop+1].time.After(cutoff) {
+		drop++
+	}
+	a.samples = a.samples[drop:]
+}
+
+// sampleAt returns the newest sample taken at or before time
+func (a *alertRules) sampleAt(at time.Time) (alertSample, bool) {
+	i := sort.Search(len(a.samples), func(i int) bool {
+		return a.samples[i].time.After(at)
+	})
+	if i == 0 {
+		return alertSample{}, false
+	}
+	return a.samples[i-1], true
+}
+
+// sendAlert notifies that rule became true
+func (bs *BuyerService) sendAlert(rule *alertRule, accountName, message string, value float64) {
+	bs.notifier.Send(notify.Event{
+		Type:     notify.EventAlert,
+		Severity: rule.severity,
+		Account:  accountName,
+		Title:    fmt.Sprintf("📉 Alert: %s", rule.RuleName()),
+		Message:  message,
+		Fields: map[string]interface{}{
+			"rule":           rule.RuleName(),
+			"rule_type":      rule.Type,
+			"value":          value,
+			"threshold":      rule.Threshold,
+			"window_minutes": int(rule.window.Minutes()),
+		},
+	})
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"stickersbot/internal/config"
+	"stickersbot/internal/monitor"
+	"stickersbot/internal/notify"
+)
+
+// balanceCheckInterval how often wallet balances are compared with notifications.low_balance
+const balanceCheckInterval = 5 * time.Minute
+
+// defaultAuthFailureLimit failed token refreshes in a row that send critical alert by default
+const defaultAuthFailureLimit = 3
+
+// registerEmail registers notifier sending critical events by email, for operators who
+// don't watch chat apps overnight
+func (bs *BuyerService) registerEmail() {
+	if bs.config.Notifications == nil {
+		return
+	}
+	settings := bs.config.Notifications.Email
+	if settings == nil || !settings.Enabled {
+		return
+	}
+
+	password, err := config.ResolveSecret(settings.Password)
+	if err != nil {
+		bs.log(fmt.Sprintf("⚠️ notifications.email: password: %v, skipped", err))
+		return
+	}
+
+	bs.notifier.Register("email", notify.AtLeast(notify.SeverityCritical, notify.NewEmail(notify.EmailOptions{
+		Host:     settings.SMTPHost,
+		Port:     settings.SMTPPort,
+		Username: settings.Username,
+		Password: password,
+		From:     settings.From,
+		To:       settings.To,
+	})))
+}
+
+// NotifyLicenseFailed sends critical alert that license could not be verified anymore
+func (bs *BuyerService) NotifyLicenseFailed(err error) {
+	bs.notifier.Send(notify.Event{
+		Type:     notify.EventLicenseFailed,
+		Severity: notify.SeverityCritical,
+		Title:    "🔐 License verification failed",
+		Message:  err.Error(),
+		Fields: map[string]interface{}{
+			"error": err.Error(),
+		},
+	})
+}
+
+// notifyAllInactive sends critical alert that no account is left and the task stops
+func (bs *BuyerService) notifyAllInactive(lastReason string) {
+	bs.notifier.Send(notify.Event{
+		Type:     notify.EventAllInactive,
+		Severity: notify.SeverityCritical,
+		Title:    "🛑 All accounts are inactive",
+		Message:  fmt.Sprintf("The task stops, last account stopped due to %s", lastReason),
+		Fields: map[string]interface{}{
+			"reason": lastReason,
+		},
+	})
+}
+
+// notifyAuthFailures sends critical alert once token refreshes of account failed
+// notifications.auth_failure_limit times in a row
+func (bs *BuyerService) notifyAuthFailures(accountName, reason string, failures int) {
+	limit := defaultAuthFailureLimit
+	if bs.config.Notifications != nil && bs.config.Notifications.AuthFailureLimit > 0 {
+		limit = bs.config.Notifications.AuthFailureLimit
+	}
+	if failures != limit {
+		return
+	}
+
+	bs.notifier.Send(notify.Event{
+		Type:     notify.EventAuthFailures,
+		Severity: notify.SeverityCritical,
+		Account:  accountName,
+		Title:    "🔑 Repeated authorization failures",
+		Message:  fmt.Sprintf("Token could not be refreshed %d times in a row, last error: %s", failures, reason),
+		Fields: map[string]interface{}{
+			"failures": failures,
+			"reason":   reason,
+		},
+	})
+}
+
+// notifyLowBalance sends critical alert that wallet balance fell below notifications.low_balance
+func (bs *BuyerService) notifyLowBalance(accountName string, wallet WalletInfo, threshold float64) {
+	bs.notifier.Send(notify.Event{
+		Type:     notify.EventLowBalance,
+		Severity: notify.SeverityCritical,
+		Account:  accountName,
+		Title:    "💸 Wallet balance is low",
+		Message: fmt.Sprintf("Wallet %s has %.4f TON, below %.4f TON",
+			maskAddress(wallet.Address), wallet.Balance, threshold),
+		Fields: map[string]interface{}{
+			"address":   wallet.Address,
+			"balance":   wallet.Balance,
+			"threshold": threshold,
+		},
+	})
+}
+
+// watchBalances compares wallet balances with threshold every interval while the task runs
+// and calls low once per wallet when its balance falls below it. Outside of active window
+// balances are not checked
+func (bs *BuyerService) watchBalances(ctx context.Context, threshold float64, interval time.Duration,
+	active *monitor.ActiveWindow, low func(accountName string, wallet WalletInfo)) {
+	wallets := NewWalletService(bs.config)
+	isLow := make(map[string]bool) // Seed phrase -> balance is below threshold
+
+	for {
+		checked := make(map[string]bool)
+		for _, account := range bs.config.Accounts {
+			if account.SeedPhrase == "" || checked[account.SeedPhrase] || (active != nil && !active.Active(time.Now())) {
+				continue
+			}
+			checked[account.SeedPhrase] = true
+
+			wallet := wallets.getAccountBalance(ctx, account)
+			if wallet.Error != "" {
+				continue
+			}
+			if wallet.Balance >= threshold {
+				isLow[account.SeedPhrase] = false
+				continue
+			}
+			if isLow[account.SeedPhrase] {
+				continue
+			}
+			isLow[account.SeedPhrase] = true
+			low(account.Name, wallet)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
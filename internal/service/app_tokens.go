@@ -0,0 +1,129 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"stickersbot/internal/config"
+)
+
+// appTokenKey returns key of token cache entry of account for Web App of bot. Token of the
+// account's own bot is keyed by account name alone, as it always was
+func appTokenKey(accountName, botUsername string) string {
+	return accountName + "@" + strings.TrimPrefix(botUsername, "@")
+}
+
+// AppToken returns token of account for Web App of another mint bot listed in its web_apps.
+// Tokens of different bots are cached and refreshed independently, so one account may
+// mint on several platforms in a single run. Empty bot or the account's own bot gives its main token
+func (tm *TokenManager) AppToken(accountName, botUsername string) (string, error) {
+	account, _, err := tm.findAccount(accountName)
+	if err != nil {
+		return "", err
+	}
+	if botUsername == "" || botUsername == account.BotUsername {
+		return tm.GetValidToken(accountName)
+	}
+
+	app, ok := account.WebApp(botUsername)
+	if !ok {
+		return "", fmt.Errorf("bot %s is not in web_apps of account %s", botUsername, accountName)
+	}
+
+	key := appTokenKey(accountName, app.BotUsername)
+	tm.mutex.RLock()
+	tokenInfo, cached := tm.tokens[key]
+	reason, dead := tm.dead[accountName]
+	tm.mutex.RUnlock()
+
+	if dead {
+		return "", fmt.Errorf("account %s is dead: %s", accountName, reason)
+	}
+	if cached && tokenInfo.IsValid && time.Now().Before(tokenInfo.ExpiresAt) {
+		return tokenInfo.Token, nil
+	}
+
+	return tm.refreshOnce(key, func() (string, error) {
+		return tm.refreshAppToken(account, app)
+	})
+}
+
+// RefreshAppToken obtains new token of account for Web App of another bot
+func (tm *TokenManager) RefreshAppToken(accountName, botUsername string) (string, error) {
+	account, _, err := tm.findAccount(accountName)
+	if err != nil {
+		return "", err
+	}
+	app, ok := account.WebApp(botUsername)
+	if !ok {
+		return "", fmt.Errorf("bot %s is not in web_apps of account %s", botUsername, accountName)
+	}
+
+	return tm.refreshOnce(appTokenKey(accountName, app.BotUsername), func() (string, error) {
+		return tm.refreshAppToken(account, app)
+	})
+}
+
+// refreshAppToken authorizes account in Web App of the bot and caches the token.
+// Unlike the main token it is not written to config
+func (tm *TokenManager) refreshAppToken(account config.Account, app config.WebAppConfig) (string, error) {
+	key := appTokenKey(account.Name, app.BotUsername)
+	log.Printf("🔄 Getting token of %s for bot %s...", account.Name, app.BotUsername)
+
+	appAccount := account
+	appAccount.BotUsername = app.BotUsername
+	appAccount.WebAppURL = app.WebAppURL
+	newToken, err := tm.refreshTokenViaTelegram(&appAccount)
+
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+
+	if err != nil {
+		log.Printf("❌ Error getting token of %s for bot %s: %v", account.Name, app.BotUsername, err)
+		if tokenInfo, ok := tm.tokens[key]; ok {
+			tokenInfo.IsValid = false
+		}
+		return "", fmt.Errorf("error getting token of %s for bot %s: %v", account.Name, app.BotUsername, err)
+	}
+
+	tm.tokens[key] = &TokenInfo{
+		Token:       newToken,
+		ExpiresAt:   time.Now().Add(tm.tokenTTL),
+		IsValid:     true,
+		LastCheck:   time.Now(),
+		RefreshedAt: time.Now(),
+		App:         app.BotUsername,
+	}
+	tm.saveTokens(key)
+	tm.scheduleRefresh(key)
+
+	log.Printf("✅ Token of %s for bot %s updated", account.Name, app.BotUsername)
+	return newToken, nil
+}
+
+// PrepareAppTokens obtains in background tokens of web_apps not restored from the previous run
+func (tm *TokenManager) PrepareAppTokens() {
+	for _, account := range tm.config.Accounts {
+		for _, app := range account.WebApps {
+			tm.mutex.RLock()
+			_, cached := tm.tokens[appTokenKey(account.Name, app.BotUsername)]
+			tm.mutex.RUnlock()
+			if cached {
+				continue
+			}
+
+			go func(accountName, botUsername string) {
+				if _, err := tm.AppToken(accountName, botUsername); err != nil {
+					log.Printf("⚠️ Token of %s for bot %s is not available: %v", accountName, botUsername, err)
+				}
+			}(account.Name, app.BotUsername)
+		}
+	}
+}
+
+// AppToken returns token of account for Web App of another mint bot from its web_apps
+func (bs *BuyerService) AppToken(accountName, botUsername string) (string, error) {
+	return bs.tokenManager.AppToken(accountName, botUsername)
+}
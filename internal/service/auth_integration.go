@@ -7,11 +7,31 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"stickersbot/internal/config"
 	"stickersbot/internal/telegram"
 )
 
+// SessionsDir directory of Telegram session files of accounts without session_file
+const SessionsDir = "sessions"
+
+// sessionCheckTimeout time to connect and check one session
+const sessionCheckTimeout = 20 * time.Second
+
+// loginLimiter limits logins of all accounts of the process
+var loginLimiter = telegram.NewLoginLimiter()
+
+// loginPrompter asks for code and 2FA password of accounts without other source (nil - console)
+var loginPrompter telegram.Prompter
+
+// SetLoginPrompter sets how logins ask for code and 2FA password, e.g. telegram.NoPrompter{}
+// for programs without user at the console. Must be called before accounts log in
+func SetLoginPrompter(prompter telegram.Prompter) {
+	loginPrompter = prompter
+}
+
 // AuthIntegration integrates Telegram authentication into the main service
 type AuthIntegration struct {
 	config *config.Config
@@ -26,51 +46,9 @@ func NewAuthIntegration(cfg *config.Config) *AuthIntegration {
 func (ai *AuthIntegration) AuthorizeAccounts(ctx context.Context) error {
 	for i, account := range ai.config.Accounts {
 		if ai.needsTelegramAuth(account) {
-			log.Printf("🔐 Telegram authorization for account: %s", account.Name)
-
-			// Validate account API credentials
-			if account.APIId == 0 {
-				return fmt.Errorf("account %s: API ID not specified", account.Name)
-			}
-
-			if account.APIHash == "" {
-				return fmt.Errorf("account %s: API Hash not specified", account.Name)
+			if err := ai.authorize(ctx, i); err != nil {
+				return err
 			}
-
-			// Determine session file path
-			sessionFile := account.SessionFile
-			if sessionFile == "" {
-				// Create session filename based on phone number
-				cleanPhone := strings.ReplaceAll(account.PhoneNumber, "+", "")
-				sessionFile = filepath.Join("sessions", fmt.Sprintf("%s.session", cleanPhone))
-			}
-
-			// Create sessions directory if it doesn't exist
-			sessionDir := filepath.Dir(sessionFile)
-			if err := os.MkdirAll(sessionDir, 0755); err != nil {
-				return fmt.Errorf("creating sessions directory %s: %w", sessionDir, err)
-			}
-
-			log.Printf("📁 Session file will be created/used: %s", sessionFile)
-
-			// Create authorization service with account's individual API credentials
-			authService := telegram.NewAuthService(
-				account.APIId,
-				account.APIHash,
-				account.PhoneNumber,
-				sessionFile,
-				account.TwoFactorPassword,
-			)
-
-			// Perform authorization
-			bearerToken, err := authService.AuthorizeAndGetToken(ctx)
-			if err != nil {
-				return fmt.Errorf("error authorizing account %s: %w", account.Name, err)
-			}
-
-			// Save received token
-			ai.config.Accounts[i].AuthToken = bearerToken
-			log.Printf("✅ Authorization completed for account: %s", account.Name)
 		} else if account.AuthToken != "" {
 			log.Printf("✅ Account %s already has Bearer token", account.Name)
 		} else {
@@ -86,6 +64,60 @@ func (ai *AuthIntegration) AuthorizeAccounts(ctx context.Context) error {
 	return nil
 }
 
+// AuthorizeAccount logs in account with the index and gets a new token, even if it already
+// has one. Other accounts are left untouched
+func (ai *AuthIntegration) AuthorizeAccount(ctx context.Context, index int) error {
+	if index < 0 || index >= len(ai.config.Accounts) {
+		return fmt.Errorf("account index %d out of range", index+1)
+	}
+
+	account := ai.config.Accounts[index]
+	if !ai.hasTelegramAuth(account) {
+		return fmt.Errorf("account %s is not configured for Telegram authorization", account.Name)
+	}
+
+	if err := ai.authorize(ctx, index); err != nil {
+		return err
+	}
+
+	if err := ai.saveConfig(); err != nil {
+		log.Printf("⚠️  Failed to save configuration: %v", err)
+	}
+	return nil
+}
+
+// authorize logs in account with the index and stores received token in config
+func (ai *AuthIntegration) authorize(ctx context.Context, index int) error {
+	account := ai.config.Accounts[index]
+	log.Printf("🔐 Telegram authorization for account: %s", account.Name)
+
+	authService, err := NewAccountAuthService(ai.config, &account)
+	if err != nil {
+		return fmt.Errorf("account %s: %w", account.Name, err)
+	}
+
+	// Create sessions directory if it doesn't exist
+	sessionFile := authService.SessionFile
+
+	sessionDir := filepath.Dir(sessionFile)
+	if err := os.MkdirAll(sessionDir, 0755); err != nil {
+		return fmt.Errorf("creating sessions directory %s: %w", sessionDir, err)
+	}
+
+	log.Printf("📁 Session file will be created/used: %s", sessionFile)
+
+	// Perform authorization
+	bearerToken, err := authService.AuthorizeAndGetToken(ctx)
+	if err != nil {
+		return fmt.Errorf("error authorizing account %s: %w", account.Name, err)
+	}
+
+	// Save received token
+	ai.config.Accounts[index].AuthToken = bearerToken
+	log.Printf("✅ Authorization completed for account: %s", account.Name)
+	return nil
+}
+
 // ValidateAccounts checks the correctness of Telegram authorization settings
 func (ai *AuthIntegration) ValidateAccounts() []error {
 	var errors []error
@@ -109,6 +141,105 @@ func (ai *AuthIntegration) ValidateAccounts() []error {
 	return errors
 }
 
+// SessionCheck result of checking Telegram session of account
+type SessionCheck struct {
+	Status telegram.SessionStatus
+	Err    error // Why status is unknown
+}
+
+// CheckSessions asks Telegram whether sessions of accounts with phone authorization
+// are still authorized. Accounts are checked in parallel; result is keyed by account
+// index and leaves out accounts without Telegram authorization
+func (ai *AuthIntegration) CheckSessions(ctx context.Context) map[int]SessionCheck {
+	results := make(map[int]SessionCheck)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for i, account := range ai.config.Accounts {
+		if !ai.hasTelegramAuth(account) {
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, account config.Account) {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, sessionCheckTimeout)
+			defer cancel()
+
+			status := telegram.SessionUnknown
+			authService, err := NewAccountAuthService(ai.config, &account)
+			if err == nil {
+				status, err = authService.CheckSession(checkCtx)
+			}
+
+			mu.Lock()
+			results[i] = SessionCheck{Status: status, Err: err}
+			mu.Unlock()
+		}(i, account)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// NewAccountAuthService creates Telegram authorization of account from its own settings:
+// API credentials, session, 2FA password, proxy, login method and token source
+func NewAccountAuthService(cfg *config.Config, account *config.Account) (*telegram.AuthService, error) {
+	if account.PhoneNumber == "" {
+		return nil, fmt.Errorf("phone number not specified for account %s", account.Name)
+	}
+	if account.APIId == 0 {
+		return nil, fmt.Errorf("API ID not specified for account %s", account.Name)
+	}
+	if account.APIHash == "" {
+		return nil, fmt.Errorf("API Hash not specified for account %s", account.Name)
+	}
+
+	codeProvider, err := NewCodeProvider(cfg, *account)
+	if err != nil {
+		return nil, err
+	}
+
+	twoFactorPassword, err := config.ResolveSecret(account.TwoFactorPassword)
+	if err != nil {
+		return nil, fmt.Errorf("two_factor_password: %v", err)
+	}
+
+	authService := telegram.NewAuthServiceWithProxy(
+		account.APIId,
+		account.APIHash,
+		account.PhoneNumber,
+		SessionFile(account),
+		twoFactorPassword,
+		account.UseProxy,
+		account.TelegramProxy(),
+	)
+	authService.ShopProxyURL = account.ProxyURL
+	authService.QRLogin = account.LoginMethod == config.LoginQR
+	authService.CodeProvider = codeProvider
+	authService.Prompter = loginPrompter
+	authService.BotUsername = account.BotUsername
+	authService.WebAppURL = account.WebAppURL
+	authService.AllowTempToken = !cfg.StrictAuthEnabled()
+
+	if limits := cfg.LoginLimits; limits != nil {
+		loginLimiter.SetLimits(time.Duration(limits.GapSeconds)*time.Second, limits.MaxAttempts, time.Duration(limits.CooldownMinutes)*time.Minute)
+	}
+	authService.Limiter = loginLimiter
+	return authService, nil
+}
+
+// SessionFile returns path of Telegram session file of account:
+// session_file if set, otherwise file named by phone number in SessionsDir
+func SessionFile(account *config.Account) string {
+	if account.SessionFile != "" {
+		return config.StatePath(account.SessionFile)
+	}
+	cleanPhone := strings.ReplaceAll(account.PhoneNumber, "+", "")
+	return config.StatePath(filepath.Join(SessionsDir, cleanPhone+".session"))
+}
+
 // hasTelegramAuth checks if Telegram authorization is configured for the account
 func (ai *AuthIntegration) hasTelegramAuth(account config.Account) bool {
 	return account.PhoneNumber != "" &&
@@ -118,10 +249,12 @@ func (ai *AuthIntegration) hasTelegramAuth(account config.Account) bool {
 
 // needsTelegramAuth checks if Telegram authorization is needed for the account
 func (ai *AuthIntegration) needsTelegramAuth(account config.Account) bool {
-	return account.AuthToken == "" && ai.hasTelegramAuth(account)
+	hasToken := account.AuthToken != "" &&
+		!(ai.config.StrictAuthEnabled() && telegram.IsTempToken(account.AuthToken))
+	return !hasToken && ai.hasTelegramAuth(account)
 }
 
 // saveConfig saves configuration to file
 func (ai *AuthIntegration) saveConfig() error {
-	return ai.config.Save("config.json")
+	return ai.config.Save(ai.config.Path())
 }
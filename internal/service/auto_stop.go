@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"stickersbot/internal/config"
+	"stickersbot/internal/notify"
+)
+
+// runDeadline returns time the run started at given time stops by itself, zero if
+// neither run_for nor stop_at is set. Formats are checked when configuration is loaded
+func runDeadline(cfg *config.Config, started time.Time) time.Time {
+	var deadline time.Time
+	if duration, err := time.ParseDuration(cfg.RunFor); cfg.RunFor != "" && err == nil {
+		deadline = started.Add(duration)
+	}
+	if stopAt, err := config.ParseTime(cfg.StopAt); cfg.StopAt != "" && err == nil {
+		if deadline.IsZero() || stopAt.Before(deadline) {
+			deadline = stopAt
+		}
+	}
+	return deadline
+}
+
+// stopAtDeadline stops the run when its deadline comes
+func (bs *BuyerService) stopAtDeadline(ctx context.Context, deadline time.Time) {
+	bs.log(fmt.Sprintf("⏱️ Task stops automatically at %s (in %s)",
+		deadline.Format("2006-01-02 15:04:05"), time.Until(deadline).Truncate(time.Second)))
+
+	if !sleepUntil(ctx, deadline) {
+		return
+	}
+
+	bs.log("⏱️ Run time is over, stopping task")
+	bs.stop(StopDeadline)
+}
+
+// notifyTaskStopped sends notification with final statistics of run that stopped by itself.
+// Requires bs.mu to be held
+func (bs *BuyerService) notifyTaskStopped(reason string) {
+	stats := bs.statistics.snapshot(false)
+	bs.notifier.Send(notify.Event{
+		Type:     notify.EventTaskStopped,
+		Severity: notify.SeverityInfo,
+		Title:    "🏁 Task stopped",
+		Message: fmt.Sprintf("%s - requests %d, success %d, errors %d, transactions %d, spent %.4f TON, time %s",
+			reason, stats.TotalRequests, stats.SuccessRequests, stats.FailedRequests, stats.SentTransactions,
+			float64(stats.SpentNano)/1000000000, stats.Duration.Truncate(time.Second)),
+		Fields: map[string]interface{}{
+			"reason":            reason,
+			"total_requests":    stats.TotalRequests,
+			"success_requests":  stats.SuccessRequests,
+			"failed_requests":   stats.FailedRequests,
+			"sent_transactions": stats.SentTransactions,
+			"spent_nano":        stats.SpentNano,
+			"duration_seconds":  int(stats.Duration.Seconds()),
+		},
+	})
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"stickersbot/internal/client"
+	"stickersbot/internal/config"
+)
+
+// Autoscale defaults
+const (
+	defaultAutoscaleInterval    = 10 * time.Second
+	defaultAutoscaleErrorRate   = 0.1
+	defaultAutoscaleSlowLatency = time.Second
+)
+
+// threadScaler adjusts number of active threads of account by results of its requests.
+// All threads up to the upper bound are launched, threads above current count wait idle
+type threadScaler struct {
+	minThreads   int
+	maxThreads   int
+	interval     time.Duration
+	maxErrorRate float64
+	slowLatency  time.Duration
+
+	threads   int           // Threads currently allowed to make requests
+	requests  int           // Requests of current interval
+	throttled int           // Rate limit, server and network failures of current interval
+	elapsed   time.Duration // Total time of requests of current interval
+	mu        sync.Mutex
+}
+
+// newThreadScaler creates scaler of account, nil if autoscaling is not configured
+func newThreadScaler(account config.Account) *threadScaler {
+	cfg := account.Autoscale
+	if cfg == nil {
+		return nil
+	}
+
+	scaler := &threadScaler{
+		minThreads:   cfg.MinThreads,
+		maxThreads:   cfg.MaxThreads,
+		interval:     time.Duration(cfg.IntervalSeconds) * time.Second,
+		maxErrorRate: cfg.MaxErrorRate,
+		slowLatency:  time.Duration(cfg.SlowLatencyMs) * time.Millisecond,
+		threads:      min(max(account.Threads, cfg.MinThreads), cfg.MaxThreads),
+	}
+	if scaler.interval <= 0 {
+		scaler.interval = defaultAutoscaleInterval
+	}
+	if scaler.maxErrorRate <= 0 {
+		scaler.maxErrorRate = defaultAutoscaleErrorRate
+	}
+	if scaler.slowLatency <= 0 {
+		scaler.slowLatency = defaultAutoscaleSlowLatency
+	}
+	return scaler
+}
+
+// maxThreads returns number of threads launched for account
+func maxThreads(account config.Account) int {
+	if account.Autoscale != nil {
+		return account.Autoscale.MaxThreads
+	}
+	return account.Threads
+}
+
+// active checks if thread with given slot (0-based index within account) may make requests
+func (s *threadScaler) active(slot int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slot < s.threads
+}
+
+// record adds result of order request to current interval
+func (s *threadScaler) record(resp *client.BuyStickersResponse, err error, elapsed time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests++
+	s.elapsed += elapsed
+	if retryClass(resp, err) != "" {
+		s.throttled++
+	}
+}
+
+// adjust applies results of finished interval: threads are halved when too many requests
+// are throttled and one thread is added when requests are answered fast enough.
+// Returns thread counts before and after and summary of the interval
+func (s *threadScaler) adjust() (int, int, string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	from := s.threads
+	requests, throttled, elapsed := s.requests, s.throttled, s.elapsed
+	s.requests, s.throttled, s.elapsed = 0, 0, 0
+
+	// Nothing to judge by while account is paused or waiting for start
+	if requests == 0 {
+		return from, from, ""
+	}
+
+	errorRate := float64(throttled) / float64(requests)
+	average := elapsed / time.Duration(requests)
+	switch {
+	case errorRate > s.maxErrorRate:
+		s.threads = max(s.minThreads, s.threads/2)
+	case average <= s.slowLatency:
+		s.threads = min(s.maxThreads, s.threads+1)
+	}
+
+	return from, s.threads, fmt.Sprintf("%d requests, %.0f%% throttled, avg %s",
+		requests, errorRate*100, average.Round(time.Millisecond))
+}
+
+// autoscaleThreads adjusts threads of account every interval until the run stops
+func (bs *BuyerService) autoscaleThreads(ctx context.Context, accountName string, scaler *threadScaler) {
+	ticker := time.NewTicker(scaler.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		from, to, summary := scaler.adjust()
+		switch {
+		case to > from:
+			bs.log(fmt.Sprintf("📈 Autoscale '%s': threads %d → %d (%s)", accountName, from, to, summary))
+		case to < from:
+			bs.log(fmt.Sprintf("📉 Autoscale '%s': threads %d → %d (%s)", accountName, from, to, summary))
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"stickersbot/internal/config"
+	"stickersbot/internal/monitor"
+)
+
+// BacktestDrop character that matched snipe filters of at least one account during backtest
+type BacktestDrop struct {
+	Time         time.Time `json:"time"` // Snapshot the character matched in
+	CollectionID int       `json:"collection_id"`
+	CharacterID  int       `json:"character_id"`
+	Name         string    `json:"name"`
+	Price        int       `json:"price"`
+	Supply       int       `json:"supply"`
+	MatchedBy    []string  `json:"matched_by"`        // Accounts whose filters matched
+	Buyers       []string  `json:"buyers,omitempty"`  // Accounts the snipe strategy would buy it with
+	Orders       int       `json:"orders"`            // Orders all buyers would create
+	Skipped      []string  `json:"skipped,omitempty"` // Why matching accounts would not buy it
+}
+
+// BacktestAccount simulated results of account
+type BacktestAccount struct {
+	Name      string `json:"name"`
+	Matches   int    `json:"matches"`
+	Orders    int    `json:"orders"`
+	SpentNano int64  `json:"spent_nano"`
+}
+
+// BacktestReport results of replaying recorded catalog through snipe monitors and strategy
+type BacktestReport struct {
+	Strategy  string             `json:"strategy"`
+	Snapshots int                `json:"snapshots"`
+	From      time.Time          `json:"from"`
+	Until     time.Time          `json:"until"`
+	Drops     []*BacktestDrop    `json:"drops"`
+	Accounts  []*BacktestAccount `json:"accounts"`
+}
+
+// backtest simulated run: counters of accounts instead of real purchases
+type backtest struct {
+	report      *BacktestReport
+	coordinator *SnipeCoordinator
+	accounts    map[string]*BacktestAccount
+	drops       map[string]*BacktestDrop // "collectionID:characterID" -> drop
+	now         time.Time                // Time of replayed snapshot
+}
+
+// RunBacktest replays recorded catalog timeline through snipe monitors of all accounts with
+// enabled snipe_monitor and the configured snipe strategy. Nothing is requested from the shop
+// and nothing is paid: matches are counted against transaction limits and budgets
+func RunBacktest(cfg *config.Config, timeline io.Reader) (*BacktestReport, error) {
+	bt := &backtest{
+		report:   &BacktestReport{},
+		accounts: make(map[string]*BacktestAccount),
+		drops:    make(map[string]*BacktestDrop),
+	}
+	bt.coordinator = NewSnipeCoordinator(cfg, func(account *config.Account, request monitor.PurchaseRequest) bool {
+		return bt.orders(account, request.Price) > 0
+	}, nil)
+	bt.report.Strategy = bt.coordinator.Strategy()
+
+	var monitors []*monitor.SnipeMonitor
+	for i := range cfg.Accounts {
+		account := &cfg.Accounts[i]
+		if account.SnipeMonitor == nil || !account.SnipeMonitor.Enabled {
+			continue
+		}
+		snipeMonitor, err := monitor.NewReplayMonitor(account, bt.purchaseCallback(account))
+		if err != nil {
+			return nil, fmt.Errorf("account %s: %v", account.Name, err)
+		}
+		monitors = append(monitors, snipeMonitor)
+
+		result := &BacktestAccount{Name: account.Name}
+		bt.accounts[account.Name] = result
+		bt.report.Accounts = append(bt.report.Accounts, result)
+	}
+	if len(monitors) == 0 {
+		return nil, fmt.Errorf("no account has enabled snipe_monitor")
+	}
+
+	err := monitor.ReadCatalogTimeline(timeline, func(snapshot monitor.CatalogSnapshot) error {
+		if bt.report.Snapshots == 0 {
+			bt.report.From = snapshot.Time
+		}
+		bt.report.Snapshots++
+		bt.report.Until = snapshot.Time
+		bt.now = snapshot.Time
+
+		for _, snipeMonitor := range monitors {
+			snipeMonitor.ReplaySnapshot(snapshot)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bt.report, nil
+}
+
+// orders returns number of orders account may still create for a match
+func (bt *backtest) orders(account *config.Account, price int) int {
+	result := bt.accounts[account.Name]
+	return snipeOrders(account, price, result.Orders, result.SpentNano)
+}
+
+// purchaseCallback records match of detector account and the purchase the strategy would make
+func (bt *backtest) purchaseCallback(detector *config.Account) monitor.PurchaseCallback {
+	return func(request monitor.PurchaseRequest) error {
+		// Watched collection found by discovery too is bought once, like purchase registry does
+		drop := bt.drop(request)
+		if slices.Contains(drop.MatchedBy, detector.Name) {
+			return nil
+		}
+		drop.MatchedBy = append(drop.MatchedBy, detector.Name)
+		bt.accounts[detector.Name].Matches++
+
+		account, ok := bt.coordinator.Assign(detector, request)
+		if !ok {
+			// Match is already bought by account assigned when another monitor detected it
+			return nil
+		}
+
+		if account.SnipeMonitor != nil && account.SnipeMonitor.WatchOnly {
+			drop.Skipped = append(drop.Skipped, fmt.Sprintf("%s: watch only", account.Name))
+			return nil
+		}
+
+		orders := bt.orders(account, request.Price)
+		if orders == 0 {
+			drop.Skipped = append(drop.Skipped, fmt.Sprintf("%s: transaction limit or budget exhausted", account.Name))
+			bt.coordinator.Unassign(request)
+			return nil
+		}
+
+		result := bt.accounts[account.Name]
+		result.Orders += orders
+		result.SpentNano += int64(orders) * snipeOrderCost(account, request.Price)
+		drop.Buyers = append(drop.Buyers, account.Name)
+		drop.Orders += orders
+		return nil
+	}
+}
+
+// drop returns drop of matched character, created on its first match
+func (bt *backtest) drop(request monitor.PurchaseRequest) *BacktestDrop {
+	key := fmt.Sprintf("%d:%d", request.CollectionID, request.CharacterID)
+	if drop, ok := bt.drops[key]; ok {
+		return drop
+	}
+
+	drop := &BacktestDrop{
+		Time:         bt.now,
+		CollectionID: request.CollectionID,
+		CharacterID:  request.CharacterID,
+		Name:         request.Name,
+		Price:        request.Price,
+		Supply:       request.Supply,
+	}
+	bt.drops[key] = drop
+	bt.report.Drops = append(bt.report.Drops, drop)
+	return drop
+}
@@ -0,0 +1,373 @@
+package service
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/scrypt"
+
+	"stickersbot/internal/config"
+	"stickersbot/internal/logfile"
+)
+
+// Backup archive layout: magic, scrypt salt, GCM nonce, then encrypted tar.gz
+const (
+	backupMagic     = "SBBACKUP1"
+	backupSaltSize  = 16
+	backupExtension = ".sbbackup"
+)
+
+// RedactedConfigFile name config is stored under in redacted backups. It is never
+// restored over config.json, whose secrets would be lost
+const RedactedConfigFile = "config.redacted.json"
+
+// redactedKeys config keys whose values are removed from redacted backups, besides
+// keys matching patterns of redactedKey
+var redactedKeys = map[string]bool{
+	"api_hash":    true,
+	"seed_phrase": true,
+	"url":         true, // Webhook URLs often carry their secret
+	"topic":       true, // ntfy topic is its password on public server
+}
+
+// redactedKey checks if value of config key is a secret. Patterns cover secrets of
+// settings added later too: passwords, tokens, secrets, keys and URLs
+func redactedKey(key string) bool {
+	key = strings.ToLower(key)
+	return redactedKeys[key] ||
+		strings.Contains(key, "password") ||
+		strings.Contains(key, "token") ||
+		strings.Contains(key, "secret") ||
+		strings.HasSuffix(key, "_key") ||
+		strings.HasSuffix(key, "_url")
+}
+
+// BackupFileName returns name of new backup archive
+func BackupFileName(now time.Time) string {
+	return "stickersbot_backup_" + now.Format("20060102_150405") + backupExtension
+}
+
+// CreateBackup writes config, tokens, sessions, transactions and found collections of the
+// state directory to archive encrypted with password. Returns archived files
+func CreateBackup(cfg *config.Config, path string, password string, redact bool) ([]string, error) {
+	if password == "" {
+		return nil, errors.New("backup password is empty")
+	}
+
+	var archive bytes.Buffer
+	gz := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gz)
+
+	var added []string
+	add := func(name string, data []byte) error {
+		header := &tar.Header{Name: filepath.ToSlash(name), Mode: 0600, Size: int64(len(data)), ModTime: time.Now()}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return err
+		}
+		added = append(added, name)
+		return nil
+	}
+
+	// Config
+	data, err := os.ReadFile(cfg.Path())
+	if err != nil {
+		return nil, fmt.Errorf("reading config: %v", err)
+	}
+	configName := config.ConfigFile
+	if redact {
+		if data, err = redactConfig(data); err != nil {
+			return nil, err
+		}
+		configName = RedactedConfigFile
+	}
+	if err := add(configName, data); err != nil {
+		return nil, err
+	}
+
+	// Proxy credentials, passwords are removed from redacted backups like secrets of config
+	if name, ok := stateRelative(cfg.ProxyAuthPath()); ok {
+		data, err := os.ReadFile(cfg.ProxyAuthPath())
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("reading %s: %v", name, err)
+		}
+		if err == nil {
+			if redact {
+				if data, err = redactConfig(data); err != nil {
+					return nil, fmt.Errorf("%s: %v", name, err)
+				}
+				name = strings.TrimSuffix(name, ".json") + ".redacted.json"
+			}
+			if err := add(name, data); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Other state files, missing ones are skipped
+	for _, name := range backupFiles(cfg) {
+		data, err := os.ReadFile(config.StatePath(name))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %v", name, err)
+		}
+		if err := add(name, data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+
+	encrypted, err := encryptBackup(archive.Bytes(), password)
+	if err != nil {
+		return nil, err
+	}
+	if err := config.WriteFileAtomic(path, encrypted, 0600); err != nil {
+		return nil, fmt.Errorf("writing %s: %v", path, err)
+	}
+	return added, nil
+}
+
+// backupFiles returns state files included into backup, relative to state directory
+func backupFiles(cfg *config.Config) []string {
+	var files []string
+	seen := make(map[string]bool)
+	addFile := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			files = append(files, name)
+		}
+	}
+	addRelative := func(path string) {
+		if rel, ok := stateRelative(path); ok {
+			addFile(rel)
+		}
+	}
+
+	addFile(TokensFile)
+	addFile(StatsHistoryFile)
+	for _, log := range []string{TransactionLogFile, OrderLogFile} {
+		for _, path := range logfile.Files(config.StatePath(log)) {
+			addRelative(path)
+		}
+	}
+
+	// Found collections of all accounts
+	for _, pattern := range []string{"found_collections_*.jsonl*", "found_collections_*.json"} {
+		matches, _ := filepath.Glob(config.StatePath(pattern))
+		for _, path := range matches {
+			addRelative(path)
+		}
+	}
+
+	// Sessions directory and session files set per account
+	filepath.WalkDir(config.StatePath(SessionsDir), func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			addRelative(path)
+		}
+		return nil
+	})
+	for i := range cfg.Accounts {
+		addRelative(SessionFile(&cfg.Accounts[i]))
+	}
+
+	// SQLite database with its write-ahead log
+	if cfg.Storage != nil && cfg.Storage.Type == StorageSQLite {
+		path := cfg.Storage.Path
+		if path == "" {
+			path = DefaultSQLitePath
+		}
+		for _, suffix := range []string{"", "-wal", "-shm"} {
+			addRelative(config.StatePath(path + suffix))
+		}
+	}
+
+	return files
+}
+
+// stateRelative returns path relative to state directory, false if it is outside of it
+func stateRelative(path string) (string, bool) {
+	rel, err := filepath.Rel(config.StateDir(), path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", false
+	}
+	return rel, true
+}
+
+// redactConfig removes secrets from config JSON. References to environment
+// variables and keyring are kept, they are not secrets themselves
+func redactConfig(data []byte) ([]byte, error) {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("parsing config: %v", err)
+	}
+	return json.MarshalIndent(redactValue(value), "", "  ")
+}
+
+// redactValue removes secrets from decoded JSON value, all values of headers are secrets
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if headers, ok := item.(map[string]interface{}); ok && strings.EqualFold(key, "headers") {
+				for name, header := range headers {
+					headers[name] = redactString(header)
+				}
+				continue
+			}
+			if _, ok := item.(string); ok && redactedKey(key) {
+				v[key] = redactString(item)
+				continue
+			}
+			v[key] = redactValue(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+	}
+	return value
+}
+
+// redactString replaces non-empty string that is not a secret reference, other values are kept
+func redactString(value interface{}) interface{} {
+	s, ok := value.(string)
+	if !ok || s == "" || strings.HasPrefix(s, config.SecretEnvPrefix) || strings.HasPrefix(s, config.SecretKeyringPrefix) {
+		return value
+	}
+	return "REDACTED"
+}
+
+// RestoreBackup extracts backup archive into state directory, overwriting existing files.
+// config.json is restored to the file cfg was loaded from. Returns restored files
+func RestoreBackup(cfg *config.Config, path string, password string) ([]string, error) {
+	encrypted, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	archive, err := decryptBackup(encrypted, password)
+	if err != nil {
+		return nil, err
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("reading archive: %v", err)
+	}
+	tr := tar.NewReader(gz)
+
+	var restored []string
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return restored, fmt.Errorf("reading archive: %v", err)
+		}
+
+		name := filepath.FromSlash(header.Name)
+		if !filepath.IsLocal(name) {
+			return restored, fmt.Errorf("archive contains unsafe path %q", header.Name)
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return restored, fmt.Errorf("reading %s: %v", name, err)
+		}
+
+		target := config.StatePath(name)
+		if name == config.ConfigFile {
+			target = cfg.Path()
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return restored, err
+		}
+		if err := config.WriteFileAtomic(target, data, 0600); err != nil {
+			return restored, fmt.Errorf("writing %s: %v", target, err)
+		}
+		restored = append(restored, name)
+	}
+	return restored, nil
+}
+
+// encryptBackup encrypts archive with AES-256-GCM, key is derived from password with scrypt
+func encryptBackup(plain []byte, password string) ([]byte, error) {
+	salt := make([]byte, backupSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	gcm, err := backupCipher(password, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	out := append([]byte(backupMagic), salt...)
+	out = append(out, nonce...)
+	return gcm.Seal(out, nonce, plain, []byte(backupMagic)), nil
+}
+
+// decryptBackup decrypts archive written by encryptBackup
+func decryptBackup(data []byte, password string) ([]byte, error) {
+	if !bytes.HasPrefix(data, []byte(backupMagic)) {
+		return nil, errors.New("not a backup archive")
+	}
+	data = data[len(backupMagic):]
+	if len(data) < backupSaltSize {
+		return nil, errors.New("backup archive is truncated")
+	}
+	salt, data := data[:backupSaltSize], data[backupSaltSize:]
+
+	gcm, err := backupCipher(password, salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("backup archive is truncated")
+	}
+	nonce, data := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+
+	plain, err := gcm.Open(nil, nonce, data, []byte(backupMagic))
+	if err != nil {
+		return nil, errors.New("wrong password or damaged archive")
+	}
+	return plain, nil
+}
+
+func backupCipher(password string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(password), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
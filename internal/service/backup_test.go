@@ -0,0 +1,136 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestBackupEncryption(t *testing.T) {
+	plain := []byte("archive contents")
+
+	encrypted, err := encryptBackup(plain, "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(encrypted, plain) {
+		t.Fatal("encrypted archive contains plain text")
+	}
+
+	truncated := encrypted[:len(backupMagic)+backupSaltSize+4]
+	damaged := bytes.Clone(encrypted)
+	damaged[len(damaged)-1] ^= 0xff
+
+	tests := []struct {
+		name     string
+		data     []byte
+		password string
+		wantErr  string
+	}{
+		{"right password", encrypted, "correct horse", ""},
+		{"wrong password", encrypted, "battery staple", "wrong password"},
+		{"damaged archive", damaged, "correct horse", "wrong password or damaged archive"},
+		{"truncated archive", truncated, "correct horse", "truncated"},
+		{"not an archive", []byte("plain tar.gz"), "correct horse", "not a backup archive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decryptBackup(tt.data, tt.password)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("decryptBackup() = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decryptBackup() = %v", err)
+			}
+			if !bytes.Equal(got, plain) {
+				t.Errorf("decryptBackup() = %q, want %q", got, plain)
+			}
+		})
+	}
+}
+
+func TestBackupEncryptionIsSalted(t *testing.T) {
+	first, err := encryptBackup([]byte("same"), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := encryptBackup([]byte("same"), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(first, second) {
+		t.Error("two encryptions of the same archive are equal")
+	}
+}
+
+func TestRedactConfig(t *testing.T) {
+	data := []byte(`{
+		"accounts": [{
+			"name": "main",
+			"api_id": 12345,
+			"api_hash": "0123456789abcdef",
+			"seed_phrase": "word1 word2",
+			"auth_token": "tg_token",
+			"two_factor_password": "env:TG_PASSWORD",
+			"proxy_url": "host:1080:user:pass",
+			"use_proxy": true
+		}],
+		"storage": {"tokens": {"backend": "redis", "password": "keyring:redis", "address": "localhost:6379"}},
+		"notifications": {"webhooks": [{"url": "https://hooks.example.com/secret", "headers": {"X-Api": "abc"}}]},
+		"license_key": "",
+		"threads": 4
+	}`)
+
+	redacted, err := redactConfig(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Accounts []map[string]interface{} `json:"accounts"`
+		Storage  struct {
+			Tokens map[string]interface{} `json:"tokens"`
+		} `json:"storage"`
+		Notifications struct {
+			Webhooks []struct {
+				URL     string            `json:"url"`
+				Headers map[string]string `json:"headers"`
+			} `json:"webhooks"`
+		} `json:"notifications"`
+		LicenseKey string  `json:"license_key"`
+		Threads    float64 `json:"threads"`
+	}
+	if err := json.Unmarshal(redacted, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	account := got.Accounts[0]
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{"name", account["name"], "main"},
+		{"api_id", account["api_id"], float64(12345)},
+		{"use_proxy", account["use_proxy"], true},
+		{"api_hash", account["api_hash"], "REDACTED"},
+		{"seed_phrase", account["seed_phrase"], "REDACTED"},
+		{"auth_token", account["auth_token"], "REDACTED"},
+		{"proxy_url", account["proxy_url"], "REDACTED"},
+		{"env reference", account["two_factor_password"], "env:TG_PASSWORD"},
+		{"keyring reference", got.Storage.Tokens["password"], "keyring:redis"},
+		{"nested plain value", got.Storage.Tokens["address"], "localhost:6379"},
+		{"webhook url", got.Notifications.Webhooks[0].URL, "REDACTED"},
+		{"webhook header", got.Notifications.Webhooks[0].Headers["X-Api"], "REDACTED"},
+		{"empty secret", got.LicenseKey, ""},
+		{"number", got.Threads, float64(4)},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,307 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"runtime/metrics"
+	"slices"
+	"sync"
+	"time"
+
+	"stickersbot/internal/client"
+	"stickersbot/internal/config"
+	"stickersbot/internal/mockshop"
+)
+
+// benchToken bearer token of benched accounts, the mock shop accepts any
+const benchToken = "bench"
+
+// mutexWaitMetric total time goroutines spent blocked on mutexes of the process
+const mutexWaitMetric = "/sync/mutex/wait/total:seconds"
+
+// BenchOptions settings of bench run
+type BenchOptions struct {
+	Duration time.Duration    // How long purchase threads run
+	Shop     mockshop.Options // Latency and errors of the mock shop
+	Listen   string           // Address of the mock shop (default random port of 127.0.0.1)
+	ShopURL  string           // URL orders are sent to when proxies reach the mock shop at another address
+	NoProxy  bool             // Orders go directly instead of through proxies of accounts
+}
+
+// BenchLatency distribution of durations
+type BenchLatency struct {
+	P50 time.Duration `json:"p50"`
+	P90 time.Duration `json:"p90"`
+	P99 time.Duration `json:"p99"`
+	Max time.Duration `json:"max"`
+}
+
+// BenchAccount results of purchase threads of one account
+type BenchAccount struct {
+	Name      string       `json:"name"`
+	Threads   int          `json:"threads"`
+	Proxy     string       `json:"proxy"`
+	Requests  int          `json:"requests"`
+	Failed    int          `json:"failed"`
+	RPS       float64      `json:"rps"`
+	Latency   BenchLatency `json:"latency"`    // Order request -> response
+	QueueWait BenchLatency `json:"queue_wait"` // Purchase submitted -> dispatched by wallet queue
+}
+
+// BenchReport results of running purchase threads against the mock shop
+type BenchReport struct {
+	Duration   time.Duration   `json:"duration"`
+	Threads    int             `json:"threads"`
+	Requests   int             `json:"requests"`
+	Failed     int             `json:"failed"`
+	RPS        float64         `json:"rps"`
+	Latency    BenchLatency    `json:"latency"`
+	QueueWait  BenchLatency    `json:"queue_wait"`
+	MutexWait  time.Duration   `json:"mutex_wait"`  // Time goroutines were blocked on locks
+	ShopOrders int64           `json:"shop_orders"` // Orders the mock shop received
+	Accounts   []*BenchAccount `json:"accounts"`
+}
+
+// benchRecorder timings of bench run, collected by purchase pipeline
+type benchRecorder struct {
+	accounts map[string]*benchSamples
+}
+
+// benchSamples timings of one account
+type benchSamples struct {
+	requests  int
+	failed    int
+	latency   []time.Duration
+	queueWait []time.Duration
+	mu        sync.Mutex
+}
+
+// newBenchRecorder creates recorder for accounts, accounts never change during the run
+func newBenchRecorder(accounts []config.Account) *benchRecorder {
+	r := &benchRecorder{accounts: make(map[string]*benchSamples)}
+	for _, account := range accounts {
+		r.accounts[account.Name] = &benchSamples{}
+	}
+	return r
+}
+
+// recordOrder records order request of account. Nil recorder - not a bench run
+func (r *benchRecorder) recordOrder(accountName string, resp *client.BuyStickersResponse, err error, elapsed time.Duration) {
+	if r == nil {
+		return
+	}
+	samples := r.accounts[accountName]
+	samples.mu.Lock()
+	defer samples.mu.Unlock()
+
+	samples.requests++
+	if err != nil || !resp.Success {
+		samples.failed++
+	}
+	samples.latency = append(samples.latency, elapsed)
+}
+
+// recordQueueWait records how long purchase of account waited for its wallet queue
+func (r *benchRecorder) recordQueueWait(accountName string, wait time.Duration) {
+	if r == nil {
+		return
+	}
+	samples := r.accounts[accountName]
+	samples.mu.Lock()
+	defer samples.mu.Unlock()
+
+	samples.queueWait = append(samples.queueWait, wait)
+}
+
+// RunBench runs purchase threads of accounts against the built-in mock shop and measures
+// achievable request rate, latencies and lock contention. Orders of the mock shop have no
+// order ID, so nothing is ever paid; snipe monitor accounts are not benched
+func RunBench(cfg *config.Config, options BenchOptions) (*BenchReport, error) {
+	benchCfg := benchConfig(cfg, options.NoProxy)
+	if len(benchCfg.Accounts) == 0 {
+		return nil, fmt.Errorf("no account with purchase threads to bench")
+	}
+
+	listen := options.Listen
+	if listen == "" {
+		listen = "127.0.0.1:0"
+	}
+	shop, err := mockshop.Start(listen, options.Shop)
+	if err != nil {
+		return nil, fmt.Errorf("mock shop: %v", err)
+	}
+	defer shop.Close()
+
+	shopURL := options.ShopURL
+	if shopURL == "" {
+		shopURL = shop.URL()
+	}
+	client.SetShopURL(shopURL)
+
+	tokenManager, err := NewTokenManager(benchCfg, nil)
+	if err != nil {
+		return nil, err
+	}
+	bs, err := newBuyerService(benchCfg, tokenManager, nil)
+	if err != nil {
+		return nil, err
+	}
+	bs.bench = newBenchRecorder(benchCfg.Accounts)
+
+	mutexWait := readMutexWait()
+	elapsed, err := bs.runBench(options.Duration)
+	if err != nil {
+		return nil, err
+	}
+
+	report := bs.bench.report(benchCfg.Accounts, elapsed)
+	report.MutexWait = readMutexWait() - mutexWait
+	report.ShopOrders, _ = shop.Orders()
+	return report, nil
+}
+
+// benchConfig returns configuration with purchase targets, threads and proxies of accounts only.
+// Seed phrases are replaced with placeholders that keep accounts sharing a wallet in one queue
+func benchConfig(cfg *config.Config, noProxy bool) *config.Config {
+	benchCfg := config.Default()
+	benchCfg.Accounts = nil
+
+	wallets := make(map[string]string)
+	for _, account := range cfg.Accounts {
+		if account.SnipeMonitor != nil && account.SnipeMonitor.Enabled {
+			continue
+		}
+
+		wallet := ""
+		if account.SeedPhrase != "" {
+			if wallets[account.SeedPhrase] == "" {
+				wallets[account.SeedPhrase] = fmt.Sprintf("bench-wallet-%d", len(wallets)+1)
+			}
+			wallet = wallets[account.SeedPhrase]
+		}
+
+		benched := config.Account{
+			Name:                account.Name,
+			AuthToken:           benchToken,
+			SeedPhrase:          wallet,
+			Threads:             account.Threads,
+			Collection:          account.Collection,
+			Character:           account.Character,
+			Currency:            account.Currency,
+			Count:               account.Count,
+			OrderDedupeWindowMs: account.OrderDedupeWindowMs,
+			Retry:               account.Retry,
+			Autoscale:           account.Autoscale,
+		}
+		if !noProxy {
+			benched.UseProxy, benched.ProxyURL = account.UseProxy, account.ProxyURL
+			benched.DirectFallback = account.DirectFallback
+		}
+		benchCfg.Accounts = append(benchCfg.Accounts, benched)
+	}
+	return benchCfg
+}
+
+// runBench runs purchase threads of all accounts for duration, then waits for requests in progress.
+// Returns time the threads were running
+func (bs *BuyerService) runBench(duration time.Duration) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), duration)
+	defer cancel()
+
+	bs.statistics.reset(time.Now())
+	bs.purchaseTargets = newPurchaseTargets(bs.config)
+	bs.purchaseQueue = bs.newPurchaseQueue()
+	bs.scalers = make(map[string]*threadScaler)
+	for _, account := range bs.config.Accounts {
+		if scaler := newThreadScaler(account); scaler != nil {
+			bs.scalers[account.Name] = scaler
+			go bs.autoscaleThreads(ctx, account.Name, scaler)
+		}
+	}
+
+	// Clients of all threads are created before the clock starts
+	var workers []*AccountWorker
+	var accountNums []int
+	for accountIndex, account := range bs.config.Accounts {
+		for i := 0; i < maxThreads(account); i++ {
+			worker, err := createAccountWorker(account, bs.purchaseTargets[account.Name], true, "", len(workers)+1)
+			if err != nil {
+				return 0, err
+			}
+			worker.slot = i
+			workers = append(workers, worker)
+			accountNums = append(accountNums, accountIndex+1)
+		}
+	}
+
+	started := time.Now()
+	var wg sync.WaitGroup
+	for i, worker := range workers {
+		wg.Add(1)
+		go bs.superviseWorker(ctx, &wg, worker, accountNums[i])
+	}
+
+	<-ctx.Done()
+	bs.purchaseQueue.Close()
+	wg.Wait()
+	bs.purchaseQueue.Wait()
+	return time.Since(started), nil
+}
+
+// report summarizes samples of accounts
+func (r *benchRecorder) report(accounts []config.Account, elapsed time.Duration) *BenchReport {
+	report := &BenchReport{Duration: elapsed}
+	var latency, queueWait []time.Duration
+	for _, account := range accounts {
+		samples := r.accounts[account.Name]
+		samples.mu.Lock()
+		result := &BenchAccount{
+			Name:      account.Name,
+			Threads:   maxThreads(account),
+			Proxy:     proxyName(account),
+			Requests:  samples.requests,
+			Failed:    samples.failed,
+			RPS:       float64(samples.requests) / elapsed.Seconds(),
+			Latency:   benchLatency(samples.latency),
+			QueueWait: benchLatency(samples.queueWait),
+		}
+		latency = append(latency, samples.latency...)
+		queueWait = append(queueWait, samples.queueWait...)
+		samples.mu.Unlock()
+
+		report.Threads += result.Threads
+		report.Requests += result.Requests
+		report.Failed += result.Failed
+		report.Accounts = append(report.Accounts, result)
+	}
+
+	report.RPS = float64(report.Requests) / elapsed.Seconds()
+	report.Latency = benchLatency(latency)
+	report.QueueWait = benchLatency(queueWait)
+	return report
+}
+
+// benchLatency returns distribution of samples
+func benchLatency(samples []time.Duration) BenchLatency {
+	if len(samples) == 0 {
+		return BenchLatency{}
+	}
+	sorted := slices.Clone(samples)
+	slices.Sort(sorted)
+	return BenchLatency{
+		P50: percentile(sorted, 50),
+		P90: percentile(sorted, 90),
+		P99: percentile(sorted, 99),
+		Max: sorted[len(sorted)-1],
+	}
+}
+
+// readMutexWait returns total time goroutines of the process were blocked on mutexes
+func readMutexWait() time.Duration {
+	sample := []metrics.Sample{{Name: mutexWaitMetric}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindFloat64 {
+		return 0
+	}
+	return time.Duration(sample[0].Value.Float64() * float64(time.Second))
+}
@@ -2,15 +2,17 @@ package service
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
+	"runtime/debug"
+	"strings"
 	"sync"
 	"time"
 
 	"stickersbot/internal/client"
 	"stickersbot/internal/config"
 	"stickersbot/internal/monitor"
+	"stickersbot/internal/notify"
 	"stickersbot/internal/types"
 )
 
@@ -21,32 +23,105 @@ type AccountWorker struct {
 	testMode         bool
 	testAddr         string
 	workerID         int
+	slot             int          // Index of the thread within account
+	targets          *TargetList  // Targets of account shared by its threads
 	transactionCount int          // Counter of successful transactions
+	pendingPayments  int          // Created orders whose payment result is not known yet
 	isActive         bool         // Account activity flag
 	mu               sync.RWMutex // Mutex for safe access to counters
 }
 
 // BuyerService service for purchasing stickers
 type BuyerService struct {
-	client         *client.HTTPClient
-	config         *config.Config
-	statistics     *types.Statistics
-	isRunning      bool
-	isStopping     bool // Flag to indicate stopping in progress
-	cancel         context.CancelFunc
-	mu             sync.RWMutex
-	logChan        chan string
-	transactionLog *os.File // File for transaction logging
+	client     *client.HTTPClient
+	config     *config.Config
+	statistics *runStats
+	lifecycle  lifecycle // Idle -> Starting -> Running -> Draining -> Stopped
+	cancel     context.CancelFunc
+	mu         sync.RWMutex
+	logs       *LogBuffer // Latest log lines, also written to LogFile
+	storage    Storage    // Transactions and found collections
 
 	// Snipe monitors
 	snipeMonitors []*monitor.SnipeMonitor
 
+	// Purchase threads of current run
+	workers []*AccountWorker
+
+	// Snapshot of crashed run the next start continues (nil - fresh start)
+	resume *RunSnapshot
+
 	// Token manager
 	tokenManager *TokenManager
 
 	// Snipe transaction counters per account
-	snipeTransactionCounters map[string]int // Account name -> transaction count
-	snipeCountersMu          sync.RWMutex   // Mutex for snipe counters
+	snipeTransactionCounters map[string]int   // Account name -> transaction count
+	snipeSpent               map[string]int64 // Account name -> nanotons spent by snipe purchases
+	snipePending             map[string]int   // Account name -> orders waiting for payment result
+	snipePendingNano         map[string]int64 // Account name -> nanotons of orders waiting for payment result
+	snipeCountersMu          sync.RWMutex     // Mutex for snipe counters
+
+	// Already purchased / in-flight snipe targets shared by all monitors
+	purchaseRegistry *PurchaseRegistry
+
+	// Remaining purchase targets of accounts (account name -> targets)
+	purchaseTargets map[string]*TargetList
+
+	// Distribution of snipe matches across accounts
+	snipeCoordinator *SnipeCoordinator
+
+	// Callbacks of purchase lifecycle registered by embedding code
+	hooks hooks
+
+	// Created orders guarded against double payment and near-duplicates
+	orderGuard *OrderGuard
+
+	// Purchases of all accounts dispatched per wallet by priority
+	purchaseQueue *PurchaseQueue
+
+	// Thread autoscalers of accounts (account name -> scaler)
+	scalers map[string]*threadScaler
+
+	// HTTP clients of account orders (account name -> client)
+	orderClients   map[string]*client.HTTPClient
+	orderClientsMu sync.Mutex
+
+	// Notifications about important events
+	notifier *notify.Dispatcher
+
+	// Request results per worker thread and per proxy
+	health *HealthTracker
+
+	// Results of proxy health checks and quarantine of banned proxies
+	proxies     *ProxyChecker
+	proxyBans   *ProxyBanDetector
+	fallback    *directFallback
+	proxyScores *ProxyScores
+
+	// Detection-to-purchase latencies of snipe purchases
+	latency *LatencyTracker
+
+	// Payments queued in wallet transaction queues and not reconciled yet (account name -> count)
+	paymentsPending map[string]int
+	paymentsMu      sync.Mutex
+
+	// Order payments reserved in wallet backlogs (seed phrase -> count) and wallets
+	// whose full backlog was reported, guarded by paymentsMu
+	walletPayments map[string]int
+	walletFull     map[string]bool
+
+	// Whether statistics of current run were saved to history
+	statsSaved bool
+
+	// Leases of accounts shared with redundant instances (nil - this instance buys for all)
+	coordinator *Coordinator
+
+	// Timings of orders and queue waits of bench run (nil - real run)
+	bench *benchRecorder
+
+	// Accounts paused through control API (account name -> paused)
+	pausedAccounts map[string]bool
+	pausedMu       sync.RWMutex
 
 	// Active accounts tracking
 	activeAccounts   map[string]bool // Account name -> is active
@@ -54,26 +129,91 @@ type BuyerService struct {
 	activeAccountsMu sync.RWMutex    // Mutex for active accounts
 }
 
-// NewBuyerService creates a new purchase service
-func NewBuyerService(cfg *config.Config) *BuyerService {
-	// Create file for transaction logging
-	logFile, err := os.OpenFile("transactions.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+// NewBuyerService creates a new purchase service using token manager of the process
+func NewBuyerService(cfg *config.Config, tokenManager *TokenManager, storage Storage) (*BuyerService, error) {
+	bs, err := newBuyerService(cfg, tokenManager, storage)
 	if err != nil {
-		fmt.Printf("⚠️ Failed to create transaction log file: %v\n", err)
-		logFile = nil
+		return nil, err
+	}
+
+	if cfg.Coordination != nil {
+		if bs.coordinator, err = NewCoordinator(cfg.Coordination); err != nil {
+			return nil, fmt.Errorf("coordination: %v", err)
+		}
+	}
+
+	// All log lines are written to file even when nobody reads the console
+	if botLog, err := openBotLog(cfg); err != nil {
+		fmt.Printf("⚠️ Failed to create log file: %v\n", err)
+	} else {
+		go bs.logs.writeToFile(botLog)
+	}
+
+	// Notifications are always shown in the log
+	bs.notifier.Register("console", notify.NotifierFunc(func(ctx context.Context, event notify.Event) error {
+		bs.log("🔔 " + strings.ReplaceAll(event.Text(), "\n", " | "))
+		return nil
+	}))
+	tokenManager.OnAccountDead = bs.handleAccountDead
+	tokenManager.OnTokenExpired = bs.handleTokenExpired
+
+	bs.notifier.OnError = func(name string, err error) {
+		bs.log(fmt.Sprintf("⚠️ Notification '%s' delivery error: %v", name, err))
+	}
+
+	if cfg.Notifications != nil && cfg.Notifications.WebhookURL != "" {
+		bs.notifier.Register("webhook", notify.NewWebhook(cfg.Notifications.WebhookURL))
+	}
+	bs.registerOwnerNotify()
+	bs.registerTelegramNotify()
+	bs.registerWebhooks()
+	bs.registerEmail()
+	bs.registerPush()
+	bs.startEventStream()
+
+	return bs, nil
+}
+
+// newBuyerService creates purchase service without log file, notifications and coordination
+func newBuyerService(cfg *config.Config, tokenManager *TokenManager, storage Storage) (*BuyerService, error) {
+	httpClient, err := client.New()
+	if err != nil {
+		return nil, err
 	}
 
 	return &BuyerService{
-		client:                   client.New(),
+		client:                   httpClient,
 		config:                   cfg,
-		statistics:               &types.Statistics{},
-		logChan:                  make(chan string, 1000),
-		transactionLog:           logFile,
-		tokenManager:             NewTokenManager(cfg),
+		statistics:               newRunStats(),
+		logs:                     NewLogBuffer(DefaultLogBufferSize),
+		storage:                  storage,
+		tokenManager:             tokenManager,
 		snipeTransactionCounters: make(map[string]int),
+		snipeSpent:               make(map[string]int64),
+		snipePending:             make(map[string]int),
+		snipePendingNano:         make(map[string]int64),
+		purchaseRegistry:         NewPurchaseRegistry(),
+		orderGuard:               NewOrderGuard(),
+		notifier:                 notify.NewDispatcher(),
+		latency:                  NewLatencyTracker(),
+		health:                   NewHealthTracker(),
+		proxies:                  NewProxyChecker(),
+		proxyBans:                NewProxyBanDetector(proxyQuarantine(cfg)),
+		fallback:                 newDirectFallback(),
+		proxyScores:              NewProxyScores(),
+		pausedAccounts:           make(map[string]bool),
+		paymentsPending:          make(map[string]int),
+		walletPayments:           make(map[string]int),
+		walletFull:               make(map[string]bool),
+		orderClients:             make(map[string]*client.HTTPClient),
 		activeAccounts:           make(map[string]bool),
 		totalAccounts:            0,
-	}
+	}, nil
+}
+
+// Notifier returns dispatcher used for event notifications
+func (bs *BuyerService) Notifier() *notify.Dispatcher {
+	return bs.notifier
 }
 
 // Start launches the sticker purchase process
@@ -81,60 +221,117 @@ func (bs *BuyerService) Start() error {
 	bs.mu.Lock()
 	defer bs.mu.Unlock()
 
-	if bs.isRunning {
+	previous, ok := bs.lifecycle.transition(StateStarting, "", StateIdle, StateStopped)
+	if !ok {
+		if previous == StateDraining {
+			return fmt.Errorf("service is still stopping")
+		}
 		return fmt.Errorf("service is already running")
 	}
 
 	if !bs.config.IsValid() {
+		bs.lifecycle.transition(previous, stopStartFailed, StateStarting)
 		return fmt.Errorf("invalid configuration: check accounts")
 	}
 
+	deadline := runDeadline(bs.config, time.Now())
+	if !deadline.IsZero() && !deadline.After(time.Now()) {
+		bs.lifecycle.transition(previous, stopStartFailed, StateStarting)
+		return fmt.Errorf("stop_at %s is already in the past", deadline.Format("2006-01-02 15:04:05"))
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	bs.cancel = cancel
-	bs.isRunning = true
 
-	// Create token manager
-	bs.tokenManager = NewTokenManager(bs.config)
+	// Unattended run stops itself when its time is over
+	if !deadline.IsZero() {
+		go bs.stopAtDeadline(ctx, deadline)
+	}
+	if bs.config.Notifications != nil && bs.config.Notifications.LowBalance > 0 {
+		threshold := bs.config.Notifications.LowBalance
+		go bs.watchBalances(ctx, threshold, balanceCheckInterval, nil, func(accountName string, wallet WalletInfo) {
+			bs.notifyLowBalance(accountName, wallet, threshold)
+		})
+	}
 
-	// Initialize token cache
+	// Initialize token cache and refresh tokens that went stale since the previous run
 	bs.tokenManager.InitializeTokens()
+	bs.tokenManager.PreventiveRefresh()
+	bs.tokenManager.PrepareAppTokens()
 
-	// Start preventive token refresh every 30 minutes
-	go func() {
-		ticker := time.NewTicker(30 * time.Minute)
-		defer ticker.Stop()
-		for {
-			select {
-			case <-ticker.C:
-				bs.tokenManager.PreventiveRefresh()
-			case <-ctx.Done():
-				return
-			}
+	// Every token is refreshed shortly before its own expiry while its account works
+	bs.tokenManager.StartRefreshScheduler(ctx, bs.isAccountIdle)
+
+	// Initialize statistics
+	bs.statistics.reset(time.Now())
+	bs.statsSaved = false
+
+	// Forget purchases of the previous run
+	bs.purchaseRegistry.Reset()
+	bs.orderGuard.Reset()
+	bs.latency.Reset()
+	bs.health.Reset()
+	bs.purchaseTargets = newPurchaseTargets(bs.config)
+	bs.workers = nil
+
+	// Crashed run continues with its counters instead of starting from zero
+	resumed := bs.applyResume()
+
+	// Thread counts of autoscaled accounts start from configured threads
+	bs.scalers = make(map[string]*threadScaler)
+	for _, account := range bs.config.Accounts {
+		if account.SnipeMonitor != nil && account.SnipeMonitor.Enabled {
+			continue
 		}
+		if scaler := newThreadScaler(account); scaler != nil {
+			bs.scalers[account.Name] = scaler
+		}
+	}
+
+	// Purchases are dispatched per wallet, snipe hits ahead of routine purchases
+	purchaseQueue := bs.newPurchaseQueue()
+	bs.purchaseQueue = purchaseQueue
+	go func() {
+		// Drop purchases that have not started when the run stops
+		<-ctx.Done()
+		purchaseQueue.Close()
 	}()
 
-	// Initialize statistics
-	bs.statistics = &types.Statistics{
-		StartTime: time.Now(),
+	// Distribute snipe matches according to configured strategy
+	bs.snipeCoordinator = NewSnipeCoordinator(bs.config, func(account *config.Account, request monitor.PurchaseRequest) bool {
+		return !bs.IsAccountPaused(account.Name) && !bs.isStandby(account.Name) && !bs.proxyUnusable(*account) &&
+			bs.snipeOrdersForMatch(account, request.Price) > 0
+	}, bs.accountScore)
+	if bs.snipeCoordinator.Strategy() != StrategyIndependent {
+		bs.log(fmt.Sprintf("🤝 Snipe strategy: %s", bs.snipeCoordinator.Strategy()))
 	}
 
-	bs.logChan <- "🚀 Starting sticker purchase..."
-	bs.logChan <- fmt.Sprintf("📊 Accounts: %d", len(bs.config.Accounts))
+	bs.log("🚀 Starting sticker purchase...")
+	bs.log(fmt.Sprintf("📊 Accounts: %d", len(bs.config.Accounts)))
 
 	// Initialize tokens from configuration
-	bs.logChan <- "🔍 Initializing authorization tokens..."
+	bs.log("🔍 Initializing authorization tokens...")
 
 	// Count total number of threads
 	totalThreads := 0
 	for _, account := range bs.config.Accounts {
 		totalThreads += account.Threads
 	}
-	bs.logChan <- fmt.Sprintf("🔄 Total number of threads: %d", totalThreads)
+	bs.log(fmt.Sprintf("🔄 Total number of threads: %d", totalThreads))
 
 	if bs.config.TestMode {
-		bs.logChan <- fmt.Sprintf("🧪 TEST MODE: payments will be sent to %s", bs.config.TestAddress)
+		bs.log(fmt.Sprintf("🧪 TEST MODE: payments will be sent to %s", bs.config.TestAddress))
+	} else {
+		bs.log("⚠️ PRODUCTION MODE: payments will be sent to addresses from API")
+	}
+
+	// Injected faults exercise retry and limit settings, never around real payments
+	if chaos := bs.config.Chaos; bs.config.TestMode && chaos != nil && chaos.Enabled {
+		client.SetChaos(chaos)
+		bs.log(fmt.Sprintf("🌪️ CHAOS: orders delayed by %dms %.0f%%, 429 %.0f%%, 503 %.0f%%, TON timeouts %.0f%%",
+			chaos.LatencyMs, chaos.LatencyRate*100, chaos.RateLimitRate*100, chaos.ServerErrorRate*100, chaos.TONTimeoutRate*100))
 	} else {
-		bs.logChan <- "⚠️ PRODUCTION MODE: payments will be sent to addresses from API"
+		client.SetChaos(nil)
 	}
 
 	// Initialize active accounts tracking
@@ -151,102 +348,144 @@ func (bs *BuyerService) Start() error {
 	}
 	bs.activeAccountsMu.Unlock()
 
+	// Dead proxies get no requests from the start
+	bs.startProxyChecks(ctx)
+
+	// Redundant instances split accounts before anyone buys
+	bs.startCoordination(ctx)
+
 	// Launch workers for each account
 	var wg sync.WaitGroup
 	workerCounter := 0
 
 	for accountIndex, account := range bs.config.Accounts {
-		bs.logChan <- fmt.Sprintf("🎯 Account '%s': Collection: %d, Character: %d, Currency: %s, Amount: %d, Threads: %d",
-			account.Name, account.Collection, account.Character, account.Currency, account.Count, account.Threads)
+		bs.log(fmt.Sprintf("🎯 Account '%s': Collection: %d, Character: %d, Currency: %s, Amount: %d, Threads: %d",
+			account.Name, account.Collection, account.Character, account.Currency, account.Count, account.Threads))
+
+		if len(account.Fallbacks) > 0 {
+			bs.log(fmt.Sprintf("↪️ Account '%s': %d fallback targets when sold out", account.Name, len(account.Fallbacks)))
+		}
 
 		if account.SeedPhrase != "" {
-			bs.logChan <- fmt.Sprintf("🔐 Account '%s': TON wallet configured", account.Name)
+			bs.log(fmt.Sprintf("🔐 Account '%s': TON wallet configured", account.Name))
 		} else {
-			bs.logChan <- fmt.Sprintf("⚠️ Account '%s': TON wallet NOT configured", account.Name)
+			bs.log(fmt.Sprintf("⚠️ Account '%s': TON wallet NOT configured", account.Name))
 		}
 
 		// Check if snipe monitor needs to be launched for this account
 		if account.SnipeMonitor != nil && account.SnipeMonitor.Enabled {
-			bs.logChan <- fmt.Sprintf("🎯 Account '%s': Launching snipe monitor", account.Name)
+			bs.log(fmt.Sprintf("🎯 Account '%s': Launching snipe monitor", account.Name))
 
 			// Create purchase callback function
 			purchaseCallback := bs.createPurchaseCallback(&account)
 
-			// Create token retrieval callback
-			tokenCallback := func(accountName string) (string, error) {
-				return bs.tokenManager.GetValidToken(accountName)
-			}
-
-			// Create token refresh callback
-			tokenRefreshCallback := func(accountName string, statusCode int) (string, error) {
-				return bs.tokenManager.RefreshTokenOnError(accountName, statusCode)
-			}
-
 			// Create HTTP client with account-specific proxy settings
 			monitorClient, err := client.NewForAccount(account.UseProxy, account.ProxyURL)
 			if err != nil {
-				bs.logChan <- fmt.Sprintf("❌ Error creating HTTP client for snipe monitor '%s': %v", account.Name, err)
+				bs.log(fmt.Sprintf("❌ Error creating HTTP client for snipe monitor '%s': %v", account.Name, err))
 				continue
 			}
 
 			// Create and launch snipe monitor
-			snipeMonitor := monitor.NewSnipeMonitor(&account, monitorClient, purchaseCallback, tokenCallback, tokenRefreshCallback)
+			snipeMonitor := monitor.NewSnipeMonitor(&account, monitorClient, purchaseCallback, bs.tokenManager)
+			snipeMonitor.SetFoundCollectionStore(bs.storage)
+			snipeAccount := account
+			snipeMonitor.SetPrewarmHook(func() { bs.warmupConnections(snipeAccount) })
+			if resumed != nil {
+				if state, ok := resumed.Monitors[account.Name]; ok {
+					snipeMonitor.RestoreKnownState(state)
+				}
+			}
 			bs.snipeMonitors = append(bs.snipeMonitors, snipeMonitor)
 
 			if err := snipeMonitor.Start(); err != nil {
-				bs.logChan <- fmt.Sprintf("❌ Error launching snipe monitor for account '%s': %v", account.Name, err)
+				bs.log(fmt.Sprintf("❌ Error launching snipe monitor for account '%s': %v", account.Name, err))
 			}
 		} else {
+			// Stock of targets bought until sold out is followed to switch them in time
+			if account.HasUntilSoldOut() {
+				go bs.trackStock(ctx, account)
+			}
+
+			// Scheduled account waits for its start time
+			if startAt := accountStartTime(account); !startAt.IsZero() {
+				go bs.scheduleAccountStart(ctx, account, startAt)
+			}
+
+			if scaler := bs.scalers[account.Name]; scaler != nil {
+				bs.log(fmt.Sprintf("📈 Account '%s': autoscaling %d-%d threads, starting with %d",
+					account.Name, scaler.minThreads, scaler.maxThreads, scaler.threads))
+				go bs.autoscaleThreads(ctx, account.Name, scaler)
+			}
+
 			// Launch regular threads for this account
-			for i := 0; i < account.Threads; i++ {
-				wg.Add(1)
+			for i := 0; i < maxThreads(account); i++ {
 				workerCounter++
 
-				accountWorker, err := createAccountWorker(account, bs.config.TestMode, bs.config.TestAddress, workerCounter)
+				accountWorker, err := createAccountWorker(account, bs.purchaseTargets[account.Name], bs.config.TestMode, bs.config.TestAddress, workerCounter)
 				if err != nil {
-					bs.logChan <- fmt.Sprintf("❌ Error creating account worker for account '%s': %v", account.Name, err)
+					bs.log(fmt.Sprintf("❌ Error creating account worker for account '%s': %v", account.Name, err))
 					continue
 				}
+				accountWorker.slot = i
+				if resumed != nil {
+					accountWorker.transactionCount = resumed.WorkerTransactions[workerKey(account.Name, i)]
+					if account.MaxTransactions > 0 && accountWorker.transactionCount >= account.MaxTransactions {
+						accountWorker.isActive = false
+					}
+				}
+				bs.workers = append(bs.workers, accountWorker)
 
-				go bs.accountWorker(ctx, &wg, accountWorker, accountIndex+1)
+				wg.Add(1)
+				go bs.superviseWorker(ctx, &wg, accountWorker, accountIndex+1)
 			}
 		}
 	}
 
-	// Launch goroutine for statistics update
-	go bs.updateStatistics(ctx)
+	// Arm snipe monitors from channel announcements
+	bs.startChannelWatcher(ctx)
+
+	// Launch goroutine for statistics update, it also checks alert rules
+	go bs.updateStatistics(ctx, bs.startAlertRules(ctx))
+
+	// State is saved regularly, so a crashed run can be resumed
+	go bs.saveSnapshots(ctx)
 
 	// Wait for completion in separate goroutine
+	hasMonitors := len(bs.snipeMonitors) > 0
 	go func() {
 		wg.Wait()
-		bs.mu.Lock()
-		bs.isRunning = false
-		bs.mu.Unlock()
-		bs.logChan <- "✅ All threads completed"
+		bs.log("✅ All threads completed")
+
+		// Snipe monitors keep the run going after threads finish
+		if !hasMonitors && ctx.Err() == nil {
+			bs.stop(StopThreadsDone)
+		}
 	}()
 
+	bs.lifecycle.transition(StateRunning, "", StateStarting)
 	return nil
 }
 
 // accountWorker executes purchases for a specific account
-func (bs *BuyerService) accountWorker(ctx context.Context, wg *sync.WaitGroup, worker *AccountWorker, accountNum int) {
-	defer wg.Done()
+func (bs *BuyerService) accountWorker(ctx context.Context, worker *AccountWorker, accountNum int) {
+	bs.log(fmt.Sprintf("🔄 Thread %d started for account %d '%s'", worker.workerID, accountNum, worker.account.Name))
 
-	bs.logChan <- fmt.Sprintf("🔄 Thread %d started for account %d '%s'", worker.workerID, accountNum, worker.account.Name)
+	// Threads of scheduled account start at the same moment
+	if startAt := accountStartTime(worker.account); !startAt.IsZero() && !sleepUntil(ctx, startAt) {
+		bs.log(fmt.Sprintf("🛑 Thread %d stopped before scheduled start", worker.workerID))
+		return
+	}
 
 	for {
 		select {
 		case <-ctx.Done():
-			bs.logChan <- fmt.Sprintf("🛑 Thread %d stopped", worker.workerID)
+			bs.log(fmt.Sprintf("🛑 Thread %d stopped", worker.workerID))
 			return
 		default:
 			// Check if service is stopping
-			bs.mu.RLock()
-			stopping := bs.isStopping
-			bs.mu.RUnlock()
-
-			if stopping {
-				bs.logChan <- fmt.Sprintf("🛑 Thread %d stopping gracefully", worker.workerID)
+			if bs.stopping() {
+				bs.log(fmt.Sprintf("🛑 Thread %d stopping gracefully", worker.workerID))
 				return
 			}
 
@@ -256,235 +495,358 @@ func (bs *BuyerService) accountWorker(ctx context.Context, wg *sync.WaitGroup, w
 			worker.mu.RUnlock()
 
 			if !isActive {
-				bs.logChan <- fmt.Sprintf("🛑 Thread %d inactive (reached transaction limit)", worker.workerID)
+				bs.log(fmt.Sprintf("🛑 Thread %d inactive", worker.workerID))
+				return
+			}
+
+			// Banned account makes no more requests
+			if reason := bs.DeadReason(worker.account.Name); reason != "" {
+				bs.log(fmt.Sprintf("🛑 Thread %d stopped: account is dead (%s)", worker.workerID, reason))
 				return
 			}
 
-			bs.performAccountBuy(worker, accountNum)
+			// Paused account waits without making requests
+			if bs.IsAccountPaused(worker.account.Name) {
+				time.Sleep(pausedPollInterval)
+				continue
+			}
+
+			// Standby instance waits until the active one stops renewing lease of account
+			if bs.isStandby(worker.account.Name) {
+				time.Sleep(pausedPollInterval)
+				continue
+			}
+
+			// Requests through dead or banned proxy would fail anyway
+			if bs.proxyUnusable(worker.account) {
+				time.Sleep(pausedPollInterval)
+				continue
+			}
+
+			// Threads above autoscaled count wait idle
+			if scaler := bs.scalers[worker.account.Name]; scaler != nil && !scaler.active(worker.slot) {
+				time.Sleep(pausedPollInterval)
+				continue
+			}
+
+			// Order of the same target created moments ago waits for dedupe window
+			if target, _, ok := worker.targets.Current(); ok {
+				if wait := bs.orderGuard.Cooldown(worker.account, target); wait > 0 {
+					time.Sleep(min(wait, pausedPollInterval))
+					continue
+				}
+			}
+
+			// Orders waiting for payment may use up the rest of transaction limit
+			if worker.limitReserved() {
+				time.Sleep(pausedPollInterval)
+				continue
+			}
+
+			// Purchase runs in queue dispatcher, its panic is handed back to crash this thread
+			var crash *jobPanic
+			submitted := time.Now()
+			<-bs.purchaseQueue.Submit(worker.account, PriorityLoop, func() {
+				bs.bench.recordQueueWait(worker.account.Name, time.Since(submitted))
+				defer func() {
+					if r := recover(); r != nil {
+						crash = &jobPanic{value: r, stack: debug.Stack()}
+					}
+				}()
+				bs.performAccountBuy(worker, accountNum)
+			})
+			if crash != nil {
+				panic(crash)
+			}
 			time.Sleep(100 * time.Millisecond) // Small delay between requests
 		}
 	}
 }
 
+// limitReserved checks if completed and pending payments already reach transaction limit.
+// Target bought until sold out is not limited
+func (worker *AccountWorker) limitReserved() bool {
+	if target, _, ok := worker.targets.Current(); ok && target.UntilSoldOut {
+		return false
+	}
+
+	worker.mu.RLock()
+	defer worker.mu.RUnlock()
+
+	return worker.account.MaxTransactions > 0 && worker.transactionCount+worker.pendingPayments >= worker.account.MaxTransactions
+}
+
 // performAccountBuy executes purchase for a specific account
 func (bs *BuyerService) performAccountBuy(worker *AccountWorker, accountNum int) {
 	// Get cached token (without API check)
 	bearerToken, err := bs.tokenManager.GetValidToken(worker.account.Name)
 	if err != nil {
-		bs.mu.Lock()
-		bs.statistics.FailedRequests++
-		bs.mu.Unlock()
-		bs.logChan <- fmt.Sprintf("❌ Thread %d (Account %d '%s'): Token retrieval error: %v",
-			worker.workerID, accountNum, worker.account.Name, err)
+		bs.addStats(worker.account.Name, failedToken())
+		bs.log(fmt.Sprintf("❌ Thread %d (Account %d '%s'): Token retrieval error: %v",
+			worker.workerID, accountNum, worker.account.Name, err))
 		return
 	}
 
+	// Get current target, all of them may be sold out by other threads
+	target, targetPosition, ok := worker.targets.Current()
+	if !ok {
+		bs.stopSoldOutWorker(worker)
+		return
+	}
+
+	// Order is created only if its payment fits into the wallet backlog
+	slotReserved := false
+	if worker.account.SeedPhrase != "" {
+		if !bs.reservePaymentSlot(worker.account) {
+			return
+		}
+		slotReserved = true
+		defer func() {
+			if slotReserved {
+				bs.releasePaymentSlot(worker.account)
+			}
+		}()
+	}
+
 	// Execute purchase request
-	resp, err := bs.makeOrderRequest(worker.account, bearerToken)
+	started := time.Now()
+	resp, err := bs.makeOrderRequest(worker.account, bearerToken, target)
+	bs.health.RecordWorker(workerName(worker), resp, err, time.Since(started))
 	if err != nil {
-		bs.mu.Lock()
-		bs.statistics.FailedRequests++
-		bs.mu.Unlock()
-		bs.logChan <- fmt.Sprintf("❌ Thread %d (Account %d '%s'): Request error: %v",
-			worker.workerID, accountNum, worker.account.Name, err)
+		bs.addStats(worker.account.Name, failedRequest(resp, err))
+		bs.log(fmt.Sprintf("❌ Thread %d (Account %d '%s'): Request error: %v",
+			worker.workerID, accountNum, worker.account.Name, err))
 		return
 	}
 
 	// Check response status
 	if resp.StatusCode == 401 || resp.StatusCode == 403 {
 		// Token expired, try to refresh and retry request
-		bs.logChan <- fmt.Sprintf("🔄 Thread %d (Account %d '%s'): Token expired (status %d), refreshing...",
-			worker.workerID, accountNum, worker.account.Name, resp.StatusCode)
+		bs.log(fmt.Sprintf("🔄 Thread %d (Account %d '%s'): Token expired (status %d), refreshing...",
+			worker.workerID, accountNum, worker.account.Name, resp.StatusCode))
 
 		newToken, err := bs.tokenManager.RefreshTokenOnError(worker.account.Name, resp.StatusCode)
 		if err != nil {
-			bs.mu.Lock()
-			bs.statistics.FailedRequests++
-			bs.mu.Unlock()
-			bs.logChan <- fmt.Sprintf("❌ Thread %d (Account %d '%s'): Token refresh error: %v",
-				worker.workerID, accountNum, worker.account.Name, err)
+			bs.addStats(worker.account.Name, failedToken())
+			bs.log(fmt.Sprintf("❌ Thread %d (Account %d '%s'): Token refresh error: %v",
+				worker.workerID, accountNum, worker.account.Name, err))
 			return
 		}
 
 		// Retry request with new token
-		resp2, err := bs.makeOrderRequest(worker.account, newToken)
+		resp2, err := bs.makeOrderRequest(worker.account, newToken, target)
 		if err != nil {
-			bs.mu.Lock()
-			bs.statistics.FailedRequests++
-			bs.mu.Unlock()
-			bs.logChan <- fmt.Sprintf("❌ Thread %d (Account %d '%s'): Retry request error: %v",
-				worker.workerID, accountNum, worker.account.Name, err)
+			bs.addStats(worker.account.Name, failedRequest(resp2, err))
+			bs.log(fmt.Sprintf("❌ Thread %d (Account %d '%s'): Retry request error: %v",
+				worker.workerID, accountNum, worker.account.Name, err))
 			return
 		}
 		resp = resp2 // Use new response
 	}
 
 	// Log server response
-	bs.logChan <- fmt.Sprintf("📡 Thread %d (Account %d '%s'): Status %d", worker.workerID, accountNum, worker.account.Name, resp.StatusCode)
-	bs.logChan <- fmt.Sprintf("📄 Thread %d (Account %d '%s'): Response - %s", worker.workerID, accountNum, worker.account.Name, resp.Body)
+	bs.log(fmt.Sprintf("📡 Thread %d (Account %d '%s'): Status %d", worker.workerID, accountNum, worker.account.Name, resp.StatusCode))
+	bs.logResponseBody(fmt.Sprintf("Thread %d (Account %d '%s')", worker.workerID, accountNum, worker.account.Name), resp)
 
 	if resp.IsTokenError {
-		bs.mu.Lock()
-		bs.statistics.FailedRequests++
-		bs.statistics.InvalidTokens++
-		bs.mu.Unlock()
+		bs.addStats(worker.account.Name, types.Counters{FailedRequests: 1, InvalidTokens: 1, Errors: types.ErrorCounters{TokenInvalid: 1}})
 
-		bs.logChan <- fmt.Sprintf("🔑 Thread %d (Account %d '%s'): Invalid authorization token! Refresh attempt...", worker.workerID, accountNum, worker.account.Name)
+		bs.log(fmt.Sprintf("🔑 Thread %d (Account %d '%s'): Invalid authorization token! Refresh attempt...", worker.workerID, accountNum, worker.account.Name))
 
 		// Try to refresh token
 		newToken, err := bs.tokenManager.RefreshTokenOnError(worker.account.Name, resp.StatusCode)
 		if err != nil {
-			bs.logChan <- fmt.Sprintf("❌ Thread %d (Account %d '%s'): Token refresh error: %v", worker.workerID, accountNum, worker.account.Name, err)
+			bs.log(fmt.Sprintf("❌ Thread %d (Account %d '%s'): Token refresh error: %v", worker.workerID, accountNum, worker.account.Name, err))
 			return
 		}
 
-		bs.logChan <- fmt.Sprintf("✅ Thread %d (Account %d '%s'): Token refreshed successfully, retrying request...", worker.workerID, accountNum, worker.account.Name)
+		bs.log(fmt.Sprintf("✅ Thread %d (Account %d '%s'): Token refreshed successfully, retrying request...", worker.workerID, accountNum, worker.account.Name))
 
-		resp2, err := bs.makeOrderRequest(worker.account, newToken)
+		resp2, err := bs.makeOrderRequest(worker.account, newToken, target)
 		if err != nil {
-			bs.logChan <- fmt.Sprintf("❌ Thread %d (Account %d '%s'): Retry request error with new token: %v", worker.workerID, accountNum, worker.account.Name, err)
+			bs.log(fmt.Sprintf("❌ Thread %d (Account %d '%s'): Retry request error with new token: %v", worker.workerID, accountNum, worker.account.Name, err))
 			return
 		}
 
 		resp = resp2 // Use new response
-		bs.logChan <- fmt.Sprintf("🔄 Thread %d (Account %d '%s'): Retry request completed", worker.workerID, accountNum, worker.account.Name)
+		bs.log(fmt.Sprintf("🔄 Thread %d (Account %d '%s'): Retry request completed", worker.workerID, accountNum, worker.account.Name))
 	}
 
 	if !resp.Success {
-		bs.mu.Lock()
-		bs.statistics.FailedRequests++
-		bs.mu.Unlock()
+		bs.addStats(worker.account.Name, failedRequest(resp, nil))
+
+		bs.log(fmt.Sprintf("⚠️ Thread %d (Account %d '%s'): Unsuccessful request (status %d)", worker.workerID, accountNum, worker.account.Name, resp.StatusCode))
 
-		bs.logChan <- fmt.Sprintf("⚠️ Thread %d (Account %d '%s'): Unsuccessful request (status %d)", worker.workerID, accountNum, worker.account.Name, resp.StatusCode)
+		// Switch to the next target instead of ordering sold out character again
+		if resp.IsSoldOut {
+			next, ok := worker.targets.SoldOut(targetPosition)
+			if !ok {
+				bs.stopSoldOutWorker(worker)
+				return
+			}
+			bs.log(fmt.Sprintf("↪️ Thread %d (Account %d '%s'): Collection %d, Character %d sold out, switching to Collection %d, Character %d",
+				worker.workerID, accountNum, worker.account.Name, target.Collection, target.Character, next.Collection, next.Character))
+		}
 	} else {
 		// Successful request
-		bs.mu.Lock()
-		bs.statistics.SuccessRequests++
-		bs.mu.Unlock()
+		bs.addStats(worker.account.Name, types.Counters{SuccessRequests: 1})
 
-		// Process transaction if it was sent
-		if resp.TransactionSent && resp.TransactionResult != nil {
-			// Update global statistics
-			bs.mu.Lock()
-			bs.statistics.SentTransactions++
-			bs.mu.Unlock()
+		label := fmt.Sprintf("Thread %d (Account %d '%s')", worker.workerID, accountNum, worker.account.Name)
 
-			// Update transaction counter for account
+		if resp.OrderID != "" && worker.account.SeedPhrase != "" {
+			// Reserve transaction until payment result is known, so the limit is not exceeded
 			worker.mu.Lock()
-			worker.transactionCount++
-			currentCount := worker.transactionCount
-
-			// Check if account reached transaction limit
-			if worker.account.MaxTransactions > 0 && currentCount >= worker.account.MaxTransactions {
-				worker.isActive = false
-				bs.logChan <- fmt.Sprintf("🛑 Account %d '%s' reached transaction limit (%d/%d) and will be stopped",
-					accountNum, worker.account.Name, currentCount, worker.account.MaxTransactions)
-
-				// Mark account as inactive in the service
-				bs.setAccountInactive(worker.account.Name)
-			}
+			worker.pendingPayments++
 			worker.mu.Unlock()
 
-			// Log transaction information
-			txResult := resp.TransactionResult
-			bs.logChan <- fmt.Sprintf("💰 Thread %d (Account %d '%s'): Transaction sent!", worker.workerID, accountNum, worker.account.Name)
-			bs.logChan <- fmt.Sprintf("   📤 From address: %s", txResult.FromAddress)
-			bs.logChan <- fmt.Sprintf("   📥 To address: %s", txResult.ToAddress)
-			bs.logChan <- fmt.Sprintf("   💰 Amount: %.9f TON", float64(txResult.Amount)/1000000000)
-			bs.logChan <- fmt.Sprintf("   🔗 Order ID: %s", resp.OrderID)
-			bs.logChan <- fmt.Sprintf("   🆔 Transaction ID: %s", txResult.TransactionID)
-			bs.logChan <- fmt.Sprintf("   📊 Account transaction count: %d/%d", currentCount, worker.account.MaxTransactions)
-
-			// Log transaction to file
-			txLog := &types.TransactionLog{
-				Timestamp:     time.Now(),
-				AccountName:   worker.account.Name,
-				OrderID:       resp.OrderID,
-				Amount:        txResult.Amount,
-				Currency:      resp.Currency,
-				FromAddress:   txResult.FromAddress,
-				ToAddress:     txResult.ToAddress,
-				TransactionID: txResult.TransactionID,
-				TestMode:      worker.testMode,
-			}
-			bs.logTransaction(txLog)
+			slotReserved = false // Released by payOrder once the payment is reconciled
+			bs.payOrder(payment{
+				account: worker.account,
+				order:   resp,
+				target:  target,
+				label:   label,
+				done: func(txResult *client.TransactionResult, paid bool) {
+					bs.finishWorkerPayment(worker, accountNum, paid, target.UntilSoldOut)
+				},
+			})
 		} else if resp.OrderID != "" {
-			// Transaction attempt was made but failed
-			bs.logChan <- fmt.Sprintf("✅ Thread %d (Account %d '%s'): Successful purchase! OrderID: %s, but transaction NOT sent",
-				worker.workerID, accountNum, worker.account.Name, resp.OrderID)
+			// Order created, but there is no wallet to pay it
+			bs.log(fmt.Sprintf("✅ %s: Successful purchase! OrderID: %s, but transaction NOT sent", label, resp.OrderID))
 		} else {
 			// Regular successful request without TON
-			bs.logChan <- fmt.Sprintf("✅ Thread %d (Account %d '%s'): Successful request!", worker.workerID, accountNum, worker.account.Name)
+			bs.log(fmt.Sprintf("✅ %s: Successful request!", label))
 		}
 	}
 }
 
-// Stop stops the purchase process
-func (bs *BuyerService) Stop() {
-	bs.mu.Lock()
-	defer bs.mu.Unlock()
+// finishWorkerPayment updates transaction counter of worker after its payment is reconciled.
+// Purchases of target bought until sold out don't count towards transaction limit
+func (bs *BuyerService) finishWorkerPayment(worker *AccountWorker, accountNum int, paid bool, untilSoldOut bool) {
+	worker.mu.Lock()
+	defer worker.mu.Unlock()
 
-	if !bs.isRunning {
+	worker.pendingPayments--
+	if !paid || untilSoldOut {
 		return
 	}
 
-	if bs.cancel != nil {
-		bs.cancel()
+	worker.transactionCount++
+	bs.log(fmt.Sprintf("   📊 Account transaction count: %d/%d", worker.transactionCount, worker.account.MaxTransactions))
+
+	// Check if account reached transaction limit
+	if worker.account.MaxTransactions > 0 && worker.transactionCount >= worker.account.MaxTransactions && worker.isActive {
+		worker.isActive = false
+		bs.log(fmt.Sprintf("🛑 Account %d '%s' reached transaction limit (%d/%d) and will be stopped",
+			accountNum, worker.account.Name, worker.transactionCount, worker.account.MaxTransactions))
+
+		// Mark account as inactive in the service
+		bs.setAccountInactive(worker.account.Name, "transaction limit")
 	}
+}
+
+// stopSoldOutWorker stops worker whose account has no purchase targets left
+func (bs *BuyerService) stopSoldOutWorker(worker *AccountWorker) {
+	worker.mu.Lock()
+	worker.isActive = false
+	worker.mu.Unlock()
+
+	bs.setAccountInactive(worker.account.Name, "all targets sold out")
+}
+
+// Stop stops purchases gracefully: new work is no longer accepted and purchases and
+// payments in progress are waited for up to drain timeout before the run is finished
+func (bs *BuyerService) Stop() {
+	bs.stop(StopRequested)
+}
 
-	// Stop all snipe monitors
+// stop drains and finishes the run for given reason. Only the first of concurrent calls stops the run
+func (bs *BuyerService) stop(reason string) {
+	// Waits for Start in progress, so the run is Running when checked
+	bs.mu.Lock()
+	if _, ok := bs.lifecycle.transition(StateDraining, reason, StateRunning); !ok {
+		bs.mu.Unlock()
+		return
+	}
+
+	// Stop accepting new work
 	for _, monitor := range bs.snipeMonitors {
 		monitor.Stop()
 	}
 	bs.snipeMonitors = nil
+	purchaseQueue := bs.purchaseQueue
+	bs.mu.Unlock()
 
-	// Close transaction log file
-	if bs.transactionLog != nil {
-		bs.transactionLog.Close()
-		bs.transactionLog = nil
+	bs.log("🛑 Stopping sticker purchase, waiting for purchases in progress...")
+	drained := bs.drain(purchaseQueue, drainTimeout(bs.config))
+
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	if bs.cancel != nil {
+		bs.cancel()
 	}
 
+	// Standby instances take over accounts right away
+	bs.releaseLeases()
+
+	bs.finishSession()
+	bs.reportFinalState(drained)
+	if reason != StopRequested {
+		bs.notifyTaskStopped(reason)
+	}
+
+	// Transaction log stays open: payments still pending after drain timeout are reconciled into it
+
 	// Reset active accounts tracking
 	bs.activeAccountsMu.Lock()
 	bs.activeAccounts = make(map[string]bool)
 	bs.totalAccounts = 0
 	bs.activeAccountsMu.Unlock()
 
-	bs.isRunning = false
-	bs.isStopping = false // Reset stopping flag
-	bs.logChan <- "🛑 Stopping sticker purchase..."
+	bs.lifecycle.transition(StateStopped, reason, StateDraining)
+	bs.log(fmt.Sprintf("🛑 Sticker purchase stopped (%s)", reason))
+}
+
+// stopping checks if stop is in progress
+func (bs *BuyerService) stopping() bool {
+	return bs.lifecycle.current() == StateDraining
 }
 
 // IsRunning returns the service status
 func (bs *BuyerService) IsRunning() bool {
-	bs.mu.RLock()
-	defer bs.mu.RUnlock()
-	return bs.isRunning
+	return bs.lifecycle.current().Active()
+}
+
+// NeedsReauth returns why account has to be authorized again before it can get a token,
+// empty if it doesn't
+func (bs *BuyerService) NeedsReauth(accountName string) string {
+	return bs.tokenManager.NeedsReauth(accountName)
 }
 
 // GetStatistics returns current statistics
 func (bs *BuyerService) GetStatistics() *types.Statistics {
-	bs.mu.RLock()
-	defer bs.mu.RUnlock()
-
-	// Create copy of statistics
-	stats := *bs.statistics
-	if bs.isRunning {
-		stats.Duration = time.Since(stats.StartTime)
-		if stats.Duration.Seconds() > 0 {
-			stats.RequestsPerSec = float64(stats.TotalRequests) / stats.Duration.Seconds()
-		}
-	}
+	stats := bs.statistics.snapshot(bs.lifecycle.current().Active())
+	stats.Latencies = bs.latency.Stats()
 	return &stats
 }
 
-// GetLogChannel returns log channel
-func (bs *BuyerService) GetLogChannel() <-chan string {
-	return bs.logChan
+// addStats adds counters to global statistics and statistics of account
+func (bs *BuyerService) addStats(accountName string, delta types.Counters) {
+	bs.statistics.add(accountName, delta)
 }
 
-// updateStatistics updates statistics every second
-func (bs *BuyerService) updateStatistics(ctx context.Context) {
+// Logs returns buffer of latest log lines
+func (bs *BuyerService) Logs() *LogBuffer {
+	return bs.logs
+}
+
+// log adds line to the log. Never blocks: readers that fall behind skip the oldest lines
+func (bs *BuyerService) log(text string) {
+	bs.logs.Add(text)
+}
+
+// updateStatistics updates statistics every second and checks alert rules against them
+func (bs *BuyerService) updateStatistics(ctx context.Context, alerts *alertRules) {
 	ticker := time.NewTicker(1 * time.Second)
 	defer ticker.Stop()
 
@@ -495,7 +857,7 @@ func (bs *BuyerService) updateStatistics(ctx context.Context) {
 		case <-ticker.C:
 			stats := bs.GetStatistics()
 			activeCount, totalAccounts := bs.getActiveAccountsCount()
-			bs.logChan <- fmt.Sprintf("📈 Total: %d | Successful: %d | Failed: %d | InvalidTokens: %d | TON sent: %d | RPS: %.1f | Active accounts: %d/%d | Time: %s",
+			line := fmt.Sprintf("📈 Total: %d | Successful: %d | Failed: %d | InvalidTokens: %d | TON sent: %d | RPS: %.1f | Active accounts: %d/%d | Time: %s",
 				stats.TotalRequests,
 				stats.SuccessRequests,
 				stats.FailedRequests,
@@ -506,42 +868,297 @@ func (bs *BuyerService) updateStatistics(ctx context.Context) {
 				totalAccounts,
 				stats.Duration.Truncate(time.Second),
 			)
+			if classes := stats.Errors.String(); classes != "" {
+				line += " | Errors: " + classes
+			}
+			if healthy, total := bs.monitorsHealthCount(); total > 0 {
+				line += fmt.Sprintf(" | Monitors: %d/%d healthy", healthy, total)
+			}
+			if len(stats.Latencies) > 0 {
+				line += " | Latency: " + formatLatencies(stats.Latencies)
+			}
+			bs.log(line)
+			alerts.evaluate(bs, time.Now(), stats.Counters)
 		}
 	}
 }
 
-// logTransaction logs transaction information to file
-func (bs *BuyerService) logTransaction(txLog *types.TransactionLog) {
-	if bs.transactionLog == nil {
-		return
-	}
+// GetMonitorsHealth returns health state of all snipe monitors
+func (bs *BuyerService) GetMonitorsHealth() []monitor.MonitorHealth {
+	bs.mu.RLock()
+	defer bs.mu.RUnlock()
 
-	// Convert to JSON
-	data, err := json.Marshal(txLog)
-	if err != nil {
-		bs.logChan <- fmt.Sprintf("❌ Transaction log error: %v", err)
-		return
+	health := make([]monitor.MonitorHealth, 0, len(bs.snipeMonitors))
+	for _, snipeMonitor := range bs.snipeMonitors {
+		health = append(health, snipeMonitor.Health())
 	}
+	return health
+}
 
-	// Log to file
-	_, err = bs.transactionLog.WriteString(string(data) + "\n")
-	if err != nil {
-		bs.logChan <- fmt.Sprintf("❌ Transaction log write error: %v", err)
-		return
+// monitorsHealthCount returns number of healthy and total snipe monitors
+func (bs *BuyerService) monitorsHealthCount() (int, int) {
+	health := bs.GetMonitorsHealth()
+
+	healthy := 0
+	for _, h := range health {
+		if h.Healthy {
+			healthy++
+		}
 	}
+	return healthy, len(health)
+}
 
-	// Immediately save to disk
-	bs.transactionLog.Sync()
+// logTransaction saves transaction information to storage
+func (bs *BuyerService) logTransaction(txLog *types.TransactionLog) {
+	if err := bs.storage.AddTransaction(txLog); err != nil {
+		bs.log(fmt.Sprintf("❌ Transaction log write error: %v", err))
+	}
 }
 
 // createPurchaseCallback creates callback function for purchasing stickers
-func (bs *BuyerService) createPurchaseCallback(account *config.Account) monitor.PurchaseCallback {
+func (bs *BuyerService) createPurchaseCallback(detector *config.Account) monitor.PurchaseCallback {
 	return func(request monitor.PurchaseRequest) error {
-		bs.logChan <- fmt.Sprintf("🚀 Snipe purchase: %s (Collection: %d, Character: %d, Price: %d)",
-			request.Name, request.CollectionID, request.CharacterID, request.Price)
+		// New matches are ignored while purchases in progress are drained
+		if bs.stopping() {
+			return nil
+		}
+
+		// Choose account that buys the match
+		account, ok := bs.snipeCoordinator.Assign(detector, request)
+		if !ok {
+			return nil
+		}
+		if account.Name != detector.Name {
+			bs.log(fmt.Sprintf("🤝 Snipe '%s': Collection %d, Character %d assigned to '%s'",
+				detector.Name, request.CollectionID, request.CharacterID, account.Name))
+		}
+
+		if bs.IsAccountPaused(account.Name) {
+			bs.log(fmt.Sprintf("⏸️ Snipe '%s': Account paused, skipping %s", account.Name, request.Name))
+			bs.snipeCoordinator.Unassign(request)
+			return nil
+		}
+
+		if bs.isStandby(account.Name) {
+			bs.log(fmt.Sprintf("💤 Snipe '%s': Another instance is buying, skipping %s", account.Name, request.Name))
+			bs.snipeCoordinator.Unassign(request)
+			return nil
+		}
+
+		// Skip duplicate matches of the same collection:character
+		if !bs.purchaseRegistry.TryAcquire(account.Name, request.CollectionID, request.CharacterID, snipeCooldown(account)) {
+			bs.log(fmt.Sprintf("⏭️ Snipe '%s': Collection %d, Character %d already purchased or in progress, skipping duplicate",
+				account.Name, request.CollectionID, request.CharacterID))
+			return nil
+		}
+
+		// In watch-only mode the match is only reported
+		if account.SnipeMonitor != nil && account.SnipeMonitor.WatchOnly {
+			bs.reportWatchMatch(account, request)
+			bs.purchaseRegistry.Release(account.Name, request.CollectionID, request.CharacterID, true)
+			return nil
+		}
+
+		// Determine how many orders this match may produce
+		orders := bs.snipeOrdersForMatch(account, request.Price)
+		if orders == 0 {
+			bs.log(fmt.Sprintf("🛑 Snipe '%s': Transaction limit or budget exhausted, skipping %s", account.Name, request.Name))
+			bs.purchaseRegistry.Release(account.Name, request.CollectionID, request.CharacterID, false)
+			bs.snipeCoordinator.Unassign(request)
+			return nil
+		}
+
+		bs.log(fmt.Sprintf("🚀 Snipe purchase: %s (Collection: %d, Character: %d, Price: %d, Orders: %d)",
+			request.Name, request.CollectionID, request.CharacterID, request.Price, orders))
+
+		bs.notifySnipeMatch(account, request, orders)
+
+		purchased, err := bs.performSnipeBurst(account, request, orders)
+		bs.purchaseRegistry.Release(account.Name, request.CollectionID, request.CharacterID, purchased)
+		bs.notifySnipePurchase(account, request, purchased, err)
+		if !purchased {
+			// Let the next detection assign the match again
+			bs.snipeCoordinator.Unassign(request)
+		}
+		return err
+	}
+}
+
+// reportWatchMatch logs and notifies about snipe match without purchasing
+func (bs *BuyerService) reportWatchMatch(account *config.Account, request monitor.PurchaseRequest) {
+	bs.log(fmt.Sprintf("👀 Watch '%s': Match %s (Collection: %d, Character: %d, Price: %.2f TON, Supply: %d) - purchase skipped",
+		account.Name, request.Name, request.CollectionID, request.CharacterID, float64(request.Price)/1000000000, request.Supply))
+
+	bs.notifier.Send(notify.Event{
+		Type:     notify.EventSnipeMatch,
+		Severity: notify.SeverityInfo,
+		Account:  account.Name,
+		Title:    "👀 Snipe match (watch mode)",
+		Message: fmt.Sprintf("%s - collection %d, character %d, price %.2f TON, supply %d",
+			request.Name, request.CollectionID, request.CharacterID, float64(request.Price)/1000000000, request.Supply),
+		Fields: snipeEventFields(request, map[string]interface{}{
+			"watch_only": true,
+		}),
+	})
+}
+
+// notifySnipeMatch sends notification about match before purchase
+func (bs *BuyerService) notifySnipeMatch(account *config.Account, request monitor.PurchaseRequest, orders int) {
+	bs.notifier.Send(notify.Event{
+		Type:     notify.EventSnipeMatch,
+		Severity: notify.SeverityInfo,
+		Account:  account.Name,
+		Title:    "🎯 Snipe match",
+		Message: fmt.Sprintf("%s - collection %d, character %d, price %.2f TON, supply %d, orders %d",
+			request.Name, request.CollectionID, request.CharacterID, float64(request.Price)/1000000000, request.Supply, orders),
+		Fields: snipeEventFields(request, map[string]interface{}{
+			"orders": orders,
+		}),
+	})
+}
+
+// notifySnipePurchase sends notification about purchase result of match
+func (bs *BuyerService) notifySnipePurchase(account *config.Account, request monitor.PurchaseRequest, purchased bool, err error) {
+	event := notify.Event{
+		Type:     notify.EventSnipePurchase,
+		Severity: notify.SeverityInfo,
+		Account:  account.Name,
+		Title:    "✅ Snipe purchased",
+		Message: fmt.Sprintf("%s - collection %d, character %d, price %.2f TON",
+			request.Name, request.CollectionID, request.CharacterID, float64(request.Price)/1000000000),
+		Fields: snipeEventFields(request, map[string]interface{}{
+			"purchased": purchased,
+		}),
+	}
+
+	if !purchased {
+		event.Severity = notify.SeverityWarning
+		event.Title = "❌ Snipe purchase failed"
+		if err != nil {
+			event.Message += fmt.Sprintf(": %v", err)
+			event.Fields["error"] = err.Error()
+		}
+	}
+
+	bs.notifier.Send(event)
+}
+
+// snipeEventFields returns notification fields describing the match
+func snipeEventFields(request monitor.PurchaseRequest, extra map[string]interface{}) map[string]interface{} {
+	fields := map[string]interface{}{
+		"collection_id": request.CollectionID,
+		"character_id":  request.CharacterID,
+		"price":         request.Price,
+		"supply":        request.Supply,
+		"name":          request.Name,
+	}
+	for key, value := range extra {
+		fields[key] = value
+	}
+	return fields
+}
+
+// performSnipeBurst fires the given number of purchase requests for one match.
+// Requests are queued with snipe priority, so they overtake routine purchases of the wallet
+func (bs *BuyerService) performSnipeBurst(account *config.Account, request monitor.PurchaseRequest, orders int) (bool, error) {
+	var (
+		mu        sync.Mutex
+		purchased bool
+		lastErr   error
+		done      []<-chan struct{}
+	)
+
+	for i := 0; i < orders; i++ {
+		done = append(done, bs.purchaseQueue.Submit(*account, PrioritySnipe, func() {
+			ok, err := bs.performSnipePurchase(account.Name, request)
+			if errors.Is(err, errSoldOut) {
+				ok, err = bs.performSnipeFallback(account, request)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if ok {
+				purchased = true
+			}
+			if err != nil {
+				lastErr = err
+			}
+		}))
+	}
+
+	for _, ch := range done {
+		<-ch
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	// Error is reported only if no order was created at all
+	if purchased {
+		return true, nil
+	}
+	return false, lastErr
+}
+
+// snipeOrdersForMatch returns number of orders allowed for a match,
+// limited by buy_count_on_match, remaining transactions and remaining budget
+func (bs *BuyerService) snipeOrdersForMatch(account *config.Account, price int) int {
+	bs.snipeCountersMu.RLock()
+	count := bs.snipeTransactionCounters[account.Name] + bs.snipePending[account.Name]
+	spent := bs.snipeSpent[account.Name] + bs.snipePendingNano[account.Name]
+	bs.snipeCountersMu.RUnlock()
+
+	return snipeOrders(account, price, count, spent)
+}
+
+// snipeOrders returns number of orders allowed for a match of account that already
+// made count snipe transactions and spent nanotons on them
+func snipeOrders(account *config.Account, price int, count int, spent int64) int {
+	orders := 1
+	var budget int64
+	if account.SnipeMonitor != nil {
+		if account.SnipeMonitor.BuyCountOnMatch > 1 {
+			orders = account.SnipeMonitor.BuyCountOnMatch
+		}
+		budget = account.SnipeMonitor.BudgetNano
+	}
+
+	if account.MaxTransactions > 0 {
+		if remaining := account.MaxTransactions - count; remaining < orders {
+			orders = remaining
+		}
+	}
+
+	if budget > 0 {
+		if orderCost := snipeOrderCost(account, price); orderCost > 0 {
+			if affordable := int((budget - spent) / orderCost); affordable < orders {
+				orders = affordable
+			}
+		}
+	}
+
+	return max(orders, 0)
+}
+
+// snipeOrderCost returns nanotons of one snipe order: price is multiplied by number of stickers in it
+func snipeOrderCost(account *config.Account, price int) int64 {
+	return int64(price) * int64(max(account.Count, 1))
+}
 
-		return bs.performSnipePurchase(account.Name, request.CollectionID, request.CharacterID)
+// priceTolerance returns percent a snipe order may be quoted above the detected price
+func priceTolerance(account *config.Account) float64 {
+	if account.SnipeMonitor != nil {
+		return account.SnipeMonitor.PriceTolerancePercent
 	}
+	return 0
+}
+
+// snipeCooldown returns cooldown between purchases of the same collection:character
+func snipeCooldown(account *config.Account) time.Duration {
+	if account.SnipeMonitor != nil && account.SnipeMonitor.CooldownSeconds > 0 {
+		return time.Duration(account.SnipeMonitor.CooldownSeconds) * time.Second
+	}
+	return DefaultSnipeCooldown
 }
 
 // checkSnipeTransactionLimit проверяет достигнут ли лимит транзакций для снайп аккаунта
@@ -564,14 +1181,14 @@ func (bs *BuyerService) checkSnipeTransactionLimit(accountName string) bool {
 	}
 
 	bs.snipeCountersMu.RLock()
-	currentCount := bs.snipeTransactionCounters[accountName]
+	currentCount := bs.snipeTransactionCounters[accountName] + bs.snipePending[accountName]
 	bs.snipeCountersMu.RUnlock()
 
 	return currentCount >= account.MaxTransactions
 }
 
-// incrementSnipeTransactionCounter увеличивает счетчик транзакций для снайп аккаунта
-func (bs *BuyerService) incrementSnipeTransactionCounter(accountName string) (int, bool) {
+// incrementSnipeTransactionCounter увеличивает счетчик транзакций и потраченную сумму для снайп аккаунта
+func (bs *BuyerService) incrementSnipeTransactionCounter(accountName string, amount int64) (int, bool) {
 	// Find account in configuration
 	var account *config.Account
 	for _, acc := range bs.config.Accounts {
@@ -586,6 +1203,7 @@ func (bs *BuyerService) incrementSnipeTransactionCounter(accountName string) (in
 
 	bs.snipeCountersMu.Lock()
 	bs.snipeTransactionCounters[accountName]++
+	bs.snipeSpent[accountName] += amount
 	currentCount := bs.snipeTransactionCounters[accountName]
 	bs.snipeCountersMu.Unlock()
 
@@ -595,18 +1213,21 @@ func (bs *BuyerService) incrementSnipeTransactionCounter(accountName string) (in
 	return currentCount, limitReached
 }
 
-// performSnipePurchase executes purchase through snipe monitor
-func (bs *BuyerService) performSnipePurchase(accountName string, collectionID int, characterID int) error {
+// performSnipePurchase executes purchase through snipe monitor.
+// Returns true if the order was successfully created
+func (bs *BuyerService) performSnipePurchase(accountName string, request monitor.PurchaseRequest) (bool, error) {
+	collectionID, characterID := request.CollectionID, request.CharacterID
+
 	// Check if transaction limit is reached
 	if bs.checkSnipeTransactionLimit(accountName) {
-		bs.logChan <- fmt.Sprintf("🛑 Snipe '%s': Transaction limit reached, skipping purchase", accountName)
-		return fmt.Errorf("transaction limit reached for account %s", accountName)
+		bs.log(fmt.Sprintf("🛑 Snipe '%s': Transaction limit reached, skipping purchase", accountName))
+		return false, fmt.Errorf("transaction limit reached for account %s", accountName)
 	}
 
 	// Get cached token (without API check)
 	bearerToken, err := bs.tokenManager.GetValidToken(accountName)
 	if err != nil {
-		return fmt.Errorf("token retrieval error: %v", err)
+		return false, fmt.Errorf("token retrieval error: %v", err)
 	}
 
 	// Find account in configuration
@@ -618,214 +1239,250 @@ func (bs *BuyerService) performSnipePurchase(accountName string, collectionID in
 		}
 	}
 	if account == nil {
-		return fmt.Errorf("account %s not found", accountName)
+		return false, fmt.Errorf("account %s not found", accountName)
+	}
+
+	// Order is created only if its payment fits into the wallet backlog
+	slotReserved := false
+	if account.SeedPhrase != "" {
+		if !bs.reservePaymentSlot(*account) {
+			return false, fmt.Errorf("payment backlog of wallet is full for account %s", accountName)
+		}
+		slotReserved = true
+		defer func() {
+			if slotReserved {
+				bs.releasePaymentSlot(*account)
+			}
+		}()
 	}
 
 	// Execute purchase request
 	resp, err := bs.makeSnipeOrderRequest(*account, bearerToken, collectionID, characterID)
 	if err != nil {
-		return fmt.Errorf("request error: %v", err)
+		bs.addStats(account.Name, failedRequest(resp, err))
+		return false, fmt.Errorf("request error: %v", err)
 	}
 
 	// Check response status
 	if resp.StatusCode == 401 || resp.StatusCode == 403 {
 		// Token expired, try to refresh and retry request
-		bs.logChan <- fmt.Sprintf("🔄 [%s] Token expired at snipe (status %d), refreshing...", accountName, resp.StatusCode)
+		bs.log(fmt.Sprintf("🔄 [%s] Token expired at snipe (status %d), refreshing...", accountName, resp.StatusCode))
 
 		newToken, err := bs.tokenManager.RefreshTokenOnError(accountName, resp.StatusCode)
 		if err != nil {
-			return fmt.Errorf("token refresh error: %v", err)
+			return false, fmt.Errorf("token refresh error: %v", err)
 		}
 
 		// Retry request with new token
 		resp2, err := bs.makeSnipeOrderRequest(*account, newToken, collectionID, characterID)
 		if err != nil {
-			return fmt.Errorf("retry request error: %v", err)
+			return false, fmt.Errorf("retry request error: %v", err)
 		}
 		resp = resp2 // Use new response
 	}
 
 	// Log server response
-	bs.logChan <- fmt.Sprintf("📡 Snipe '%s': Status %d", account.Name, resp.StatusCode)
-	bs.logChan <- fmt.Sprintf("📄 Snipe '%s': Response - %s", account.Name, resp.Body)
+	bs.log(fmt.Sprintf("📡 Snipe '%s': Status %d", account.Name, resp.StatusCode))
+	bs.logResponseBody(fmt.Sprintf("Snipe '%s'", account.Name), resp)
 
 	if resp.IsTokenError {
-		bs.mu.Lock()
-		bs.statistics.FailedRequests++
-		bs.statistics.InvalidTokens++
-		bs.mu.Unlock()
+		bs.addStats(account.Name, types.Counters{FailedRequests: 1, InvalidTokens: 1, Errors: types.ErrorCounters{TokenInvalid: 1}})
 
-		bs.logChan <- fmt.Sprintf("🔑 Snipe '%s': Invalid authorization token! Refresh attempt...", account.Name)
+		bs.log(fmt.Sprintf("🔑 Snipe '%s': Invalid authorization token! Refresh attempt...", account.Name))
 
 		// Try to refresh token
 		newToken, err := bs.tokenManager.RefreshTokenOnError(account.Name, resp.StatusCode)
 		if err != nil {
-			bs.logChan <- fmt.Sprintf("❌ Snipe '%s': Token refresh error: %v", account.Name, err)
-			return nil
+			bs.log(fmt.Sprintf("❌ Snipe '%s': Token refresh error: %v", account.Name, err))
+			return false, nil
 		}
 
-		bs.logChan <- fmt.Sprintf("✅ Snipe '%s': Token refreshed successfully, retrying request...", account.Name)
+		bs.log(fmt.Sprintf("✅ Snipe '%s': Token refreshed successfully, retrying request...", account.Name))
 
 		// Retry request with new token
 		resp2, err := bs.makeSnipeOrderRequest(*account, newToken, collectionID, characterID)
 		if err != nil {
-			bs.logChan <- fmt.Sprintf("❌ Snipe '%s': Retry request error with new token: %v", account.Name, err)
-			return nil
+			bs.log(fmt.Sprintf("❌ Snipe '%s': Retry request error with new token: %v", account.Name, err))
+			return false, nil
 		}
 
 		resp = resp2 // Use new response
-		bs.logChan <- fmt.Sprintf("🔄 Snipe '%s': Retry request completed", account.Name)
+		bs.log(fmt.Sprintf("🔄 Snipe '%s': Retry request completed", account.Name))
 	}
 
 	if !resp.Success {
-		bs.mu.Lock()
-		bs.statistics.FailedRequests++
-		bs.mu.Unlock()
+		bs.addStats(account.Name, failedRequest(resp, nil))
 
-		bs.logChan <- fmt.Sprintf("⚠️ Snipe '%s': Unsuccessful request (status %d)", account.Name, resp.StatusCode)
-		return nil
+		bs.log(fmt.Sprintf("⚠️ Snipe '%s': Unsuccessful request (status %d)", account.Name, resp.StatusCode))
+		if resp.IsSoldOut {
+			return false, errSoldOut
+		}
+		return false, nil
 	}
 
 	// Successful request
-	bs.mu.Lock()
-	bs.statistics.SuccessRequests++
-	bs.mu.Unlock()
+	bs.addStats(account.Name, types.Counters{SuccessRequests: 1})
+
+	if resp.OrderID != "" && account.SeedPhrase != "" {
+		// Reserve transaction and amount until payment result is known
+		bs.reserveSnipePayment(account.Name, resp.TotalAmount, 1)
+
+		slotReserved = false // Released by payOrder once the payment is reconciled
+		bs.payOrder(payment{
+			account: *account,
+			order:   resp,
+			target:  config.PurchaseTarget{Collection: collectionID, Character: characterID},
+			label:   fmt.Sprintf("Snipe '%s'", account.Name),
+
+			detectedAmount:   snipeOrderCost(account, request.Price),
+			tolerancePercent: priceTolerance(account),
+			done: func(txResult *client.TransactionResult, paid bool) {
+				bs.reserveSnipePayment(account.Name, -resp.TotalAmount, -1)
+				if !paid {
+					return
+				}
 
-	// Process transaction if it was sent
-	if resp.TransactionSent && resp.TransactionResult != nil {
-		// Update global statistics
-		bs.mu.Lock()
-		bs.statistics.SentTransactions++
-		bs.mu.Unlock()
+				// Measure detection-to-payment latency
+				resp.TransactionSent = true
+				resp.TransactionResult = txResult
+				bs.latency.RecordPurchase(request, resp)
 
-		// Increment snipe transaction counter
-		currentCount, limitReached := bs.incrementSnipeTransactionCounter(account.Name)
-
-		// Log transaction information
-		txResult := resp.TransactionResult
-		bs.logChan <- fmt.Sprintf("💰 Snipe '%s': Transaction sent!", account.Name)
-		bs.logChan <- fmt.Sprintf("   📤 From address: %s", txResult.FromAddress)
-		bs.logChan <- fmt.Sprintf("   📥 To address: %s", txResult.ToAddress)
-		bs.logChan <- fmt.Sprintf("   💰 Amount: %.9f TON", float64(txResult.Amount)/1000000000)
-		bs.logChan <- fmt.Sprintf("   🔗 Order ID: %s", resp.OrderID)
-		bs.logChan <- fmt.Sprintf("   🆔 Transaction ID: %s", txResult.TransactionID)
-		bs.logChan <- fmt.Sprintf("   📊 Snipe transaction count: %d/%d", currentCount, account.MaxTransactions)
-
-		// Check if limit is reached
-		if limitReached {
-			bs.logChan <- fmt.Sprintf("🛑 Snipe '%s': Transaction limit reached (%d/%d) - stopping snipe monitor",
-				account.Name, currentCount, account.MaxTransactions)
-
-			// Find and stop the snipe monitor for this account
-			for _, monitor := range bs.snipeMonitors {
-				if monitor.GetAccountName() == account.Name {
-					monitor.Stop()
-					break
-				}
-			}
+				bs.finishSnipePayment(account, txResult)
+			},
+		})
+	} else {
+		// Measure detection-to-order latency
+		bs.latency.RecordPurchase(request, resp)
+	}
 
-			// Mark account as inactive in the service
-			bs.setAccountInactive(account.Name)
-		}
+	return true, nil
+}
 
-		// Log transaction to file
-		txLog := &types.TransactionLog{
-			Timestamp:     time.Now(),
-			AccountName:   account.Name,
-			OrderID:       resp.OrderID,
-			Amount:        txResult.Amount,
-			Currency:      resp.Currency,
-			FromAddress:   txResult.FromAddress,
-			ToAddress:     txResult.ToAddress,
-			TransactionID: txResult.TransactionID,
-			TestMode:      bs.config.TestMode,
+// finishSnipePayment updates snipe transaction counter after payment is confirmed
+// and stops snipe monitor of account that reached transaction limit
+func (bs *BuyerService) finishSnipePayment(account *config.Account, txResult *client.TransactionResult) {
+	currentCount, limitReached := bs.incrementSnipeTransactionCounter(account.Name, txResult.Amount)
+	bs.log(fmt.Sprintf("   📊 Snipe transaction count: %d/%d", currentCount, account.MaxTransactions))
+
+	if !limitReached {
+		return
+	}
+
+	bs.log(fmt.Sprintf("🛑 Snipe '%s': Transaction limit reached (%d/%d) - stopping snipe monitor",
+		account.Name, currentCount, account.MaxTransactions))
+
+	// Find and stop the snipe monitor for this account
+	bs.mu.RLock()
+	monitors := bs.snipeMonitors
+	bs.mu.RUnlock()
+	for _, monitor := range monitors {
+		if monitor.GetAccountName() == account.Name {
+			monitor.Stop()
+			break
 		}
-		bs.logTransaction(txLog)
 	}
 
-	return nil
+	// Mark account as inactive in the service
+	bs.setAccountInactive(account.Name, "transaction limit")
 }
 
-// makeOrderRequest executes HTTP request for purchasing
-func (bs *BuyerService) makeOrderRequest(account config.Account, bearerToken string) (*client.BuyStickersResponse, error) {
-	bs.mu.Lock()
-	bs.statistics.TotalRequests++
-	bs.mu.Unlock()
+// reserveSnipePayment adds payment waiting for result to account reservations
+// (negative values release it), so limits count orders that are not paid yet
+func (bs *BuyerService) reserveSnipePayment(accountName string, amount int64, count int) {
+	bs.snipeCountersMu.Lock()
+	defer bs.snipeCountersMu.Unlock()
 
-	// Create HTTP client with account-specific proxy settings
-	httpClient, err := client.NewForAccount(account.UseProxy, account.ProxyURL)
+	bs.snipePending[accountName] += count
+	bs.snipePendingNano[accountName] += amount
+}
+
+// makeOrderRequest executes HTTP request for purchasing, repeating it by account retry policy
+func (bs *BuyerService) makeOrderRequest(account config.Account, bearerToken string, target config.PurchaseTarget) (*client.BuyStickersResponse, error) {
+	return bs.orderWithRetry(account, func() (*client.BuyStickersResponse, error) {
+		return bs.sendOrder(account, bearerToken, target)
+	})
+}
+
+// makeSnipeOrderRequest executes HTTP request for purchasing through snipe monitor
+func (bs *BuyerService) makeSnipeOrderRequest(account config.Account, bearerToken string, collectionID int, characterID int) (*client.BuyStickersResponse, error) {
+	return bs.makeOrderRequest(account, bearerToken, config.PurchaseTarget{Collection: collectionID, Character: characterID})
+}
+
+// sendOrder executes single order request. Payment of created order is queued separately by payOrder
+func (bs *BuyerService) sendOrder(account config.Account, bearerToken string, target config.PurchaseTarget) (*client.BuyStickersResponse, error) {
+	bs.addStats(account.Name, types.Counters{TotalRequests: 1})
+
+	// Account with direct fallback keeps buying while its proxy fails
+	route := proxyName(account)
+	direct := bs.goesDirect(account)
+	httpClient, err := bs.orderClient(account)
+	if direct {
+		route = directConnection
+		httpClient, err = bs.directClient(account)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to create HTTP client for account %s: %v", account.Name, err)
+		return nil, err
 	}
 
-	// Check if seed phrase exists for sending transactions
-	if account.SeedPhrase != "" {
-		// Use new method with TON transaction sending and proxy support
-		return httpClient.BuyStickersAndPayWithProxy(
-			bearerToken,
-			account.Collection,
-			account.Character,
-			account.Currency,
-			account.Count,
-			account.SeedPhrase,
-			bs.config.TestMode,
-			bs.config.TestAddress,
-			account.UseProxy,
-			account.ProxyURL,
-		)
-	} else {
-		// Use regular method without sending transactions
-		return httpClient.BuyStickers(
-			bearerToken,
-			account.Collection,
-			account.Character,
-			account.Currency,
-			account.Count,
-		)
+	if wait := bs.orderGuard.Cooldown(account, target); wait > 0 {
+		return nil, fmt.Errorf("%w: Collection %d, Character %d was ordered moments ago, next order in %s",
+			errDuplicateOrder, target.Collection, target.Character, wait.Round(time.Millisecond))
+	}
+
+	started := time.Now()
+	resp, err := httpClient.BuyStickers(
+		bearerToken,
+		target.Collection,
+		target.Character,
+		account.Currency,
+		account.Count,
+	)
+	elapsed := time.Since(started)
+	bs.bench.recordOrder(account.Name, resp, err, elapsed)
+	bs.health.RecordProxy(route, resp, err, elapsed)
+	bs.proxyScores.record(route, deliveredResponse(resp, err), elapsed)
+	if !direct {
+		bs.recordProxyResult(account, resp, err)
+		bs.recordProxyFailure(account, err)
+	}
+	if scaler := bs.scalers[account.Name]; scaler != nil {
+		scaler.record(resp, err, elapsed)
 	}
+	bs.orderGuard.Record(account, target, resp, err)
+
+	switch {
+	case err != nil:
+		bs.fireError(ErrorEvent{Account: account.Name, Stage: StageOrder, Target: target, Err: err})
+	case !resp.Success:
+		bs.fireError(ErrorEvent{Account: account.Name, Stage: StageOrder, Target: target, Order: resp,
+			Err: fmt.Errorf("unsuccessful request (status %d)", resp.StatusCode)})
+	case resp.OrderID != "":
+		bs.fireOrderCreated(OrderEvent{Account: account.Name, Target: target, Order: resp})
+	}
+	return resp, err
 }
 
-// makeSnipeOrderRequest executes HTTP request for purchasing through snipe monitor
-func (bs *BuyerService) makeSnipeOrderRequest(account config.Account, bearerToken string, collectionID int, characterID int) (*client.BuyStickersResponse, error) {
-	bs.mu.Lock()
-	bs.statistics.TotalRequests++
-	bs.mu.Unlock()
+// orderClient returns HTTP client of account orders. Client is shared by purchases of
+// the account, so its connections are reused and can be opened ahead of time
+func (bs *BuyerService) orderClient(account config.Account) (*client.HTTPClient, error) {
+	bs.orderClientsMu.Lock()
+	defer bs.orderClientsMu.Unlock()
+
+	if httpClient, ok := bs.orderClients[account.Name]; ok {
+		return httpClient, nil
+	}
 
 	// Create HTTP client with account-specific proxy settings
 	httpClient, err := client.NewForAccount(account.UseProxy, account.ProxyURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create HTTP client for account %s: %v", account.Name, err)
 	}
-
-	// Check if seed phrase exists for sending transactions
-	if account.SeedPhrase != "" {
-		// Use new method with TON transaction sending and proxy support
-		return httpClient.BuyStickersAndPayWithProxy(
-			bearerToken,
-			collectionID,
-			characterID,
-			account.Currency,
-			account.Count,
-			account.SeedPhrase,
-			bs.config.TestMode,
-			bs.config.TestAddress,
-			account.UseProxy,
-			account.ProxyURL,
-		)
-	} else {
-		// Use regular method without sending transactions
-		return httpClient.BuyStickers(
-			bearerToken,
-			collectionID,
-			characterID,
-			account.Currency,
-			account.Count,
-		)
-	}
+	bs.orderClients[account.Name] = httpClient
+	return httpClient, nil
 }
 
 // createAccountWorker creates AccountWorker with proxy support
-func createAccountWorker(account config.Account, testMode bool, testAddr string, workerID int) (*AccountWorker, error) {
+func createAccountWorker(account config.Account, targets *TargetList, testMode bool, testAddr string, workerID int) (*AccountWorker, error) {
 	// Create HTTP client with account-specific proxy settings
 	httpClient, err := client.NewForAccount(account.UseProxy, account.ProxyURL)
 	if err != nil {
@@ -838,19 +1495,20 @@ func createAccountWorker(account config.Account, testMode bool, testAddr string,
 		testMode:         testMode,
 		testAddr:         testAddr,
 		workerID:         workerID,
+		targets:          targets,
 		transactionCount: 0,
 		isActive:         true,
 	}, nil
 }
 
 // setAccountInactive помечает аккаунт как неактивный и проверяет нужно ли остановить сервис
-func (bs *BuyerService) setAccountInactive(accountName string) {
+func (bs *BuyerService) setAccountInactive(accountName string, reason string) {
 	bs.activeAccountsMu.Lock()
 	defer bs.activeAccountsMu.Unlock()
 
 	if bs.activeAccounts[accountName] {
 		bs.activeAccounts[accountName] = false
-		bs.logChan <- fmt.Sprintf("🛑 Account '%s' stopped due to transaction limit", accountName)
+		bs.log(fmt.Sprintf("🛑 Account '%s' stopped due to %s", accountName, reason))
 
 		// Check if all accounts are inactive
 		activeCount := 0
@@ -860,29 +1518,14 @@ func (bs *BuyerService) setAccountInactive(accountName string) {
 			}
 		}
 
-		bs.logChan <- fmt.Sprintf("📊 Active accounts: %d/%d", activeCount, bs.totalAccounts)
+		bs.log(fmt.Sprintf("📊 Active accounts: %d/%d", activeCount, bs.totalAccounts))
 
 		if activeCount == 0 {
-			bs.logChan <- "🏁 All accounts reached transaction limits - stopping service"
-
-			// Set stopping flag first to prevent new operations
-			bs.mu.Lock()
-			bs.isStopping = true
-			bs.mu.Unlock()
-
-			// Give time for current transactions to complete
-			go func() {
-				time.Sleep(3 * time.Second) // Wait for current operations to finish
+			bs.log("🏁 All accounts are inactive - stopping service")
+			bs.notifyAllInactive(reason)
 
-				// Stop the service
-				bs.mu.Lock()
-				bs.isRunning = false
-				bs.mu.Unlock()
-
-				if bs.cancel != nil {
-					bs.cancel() // Stop all goroutines
-				}
-			}()
+			// Wait for purchases in progress and finish the run
+			go bs.stop(StopAllInactive)
 		}
 	}
 }
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"stickersbot/internal/config"
+	"stickersbot/internal/monitor"
+	"stickersbot/internal/telegram"
+)
+
+// startChannelWatcher launches watcher of Telegram announcement channels
+// that arms snipe monitors for announced collections
+func (bs *BuyerService) startChannelWatcher(ctx context.Context) {
+	cfg := bs.config.ChannelWatcher
+	if cfg == nil || !cfg.Enabled {
+		return
+	}
+
+	var account *config.Account
+	for i := range bs.config.Accounts {
+		if bs.config.Accounts[i].Name == cfg.Account {
+			account = &bs.config.Accounts[i]
+			break
+		}
+	}
+	if account == nil {
+		bs.log(fmt.Sprintf("❌ Channel watcher: account '%s' not found", cfg.Account))
+		return
+	}
+	if account.PhoneNumber == "" || account.APIId == 0 || account.APIHash == "" {
+		bs.log(fmt.Sprintf("❌ Channel watcher: account '%s' has no Telegram session settings", account.Name))
+		return
+	}
+	if len(bs.snipeMonitors) == 0 {
+		bs.log("⚠️ Channel watcher: no snipe monitors to arm, watcher is not started")
+		return
+	}
+
+	watcher := &telegram.ChannelWatcher{
+		APIId:       account.APIId,
+		APIHash:     account.APIHash,
+		SessionFile: SessionFile(account),
+		UseProxy:    account.UseProxy,
+		ProxyURL:    account.TelegramProxy(),
+		Channels:    cfg.Channels,
+		Interval:    time.Duration(cfg.IntervalSeconds) * time.Second,
+		OnMessage: func(channel string, text string) {
+			bs.handleAnnouncement(channel, text)
+		},
+	}
+
+	bs.log(fmt.Sprintf("📢 Channel watcher: watching %v via account '%s'", cfg.Channels, account.Name))
+
+	go func() {
+		if err := watcher.Run(ctx); err != nil && ctx.Err() == nil {
+			bs.log(fmt.Sprintf("❌ Channel watcher stopped: %v", err))
+		}
+	}()
+}
+
+// handleAnnouncement arms snipe monitors for collections mentioned in channel message
+func (bs *BuyerService) handleAnnouncement(channel string, text string) {
+	ids := monitor.ParseAnnouncementCollections(text)
+	if len(ids) == 0 {
+		return
+	}
+
+	bs.log(fmt.Sprintf("📢 Announcement in @%s mentions collections %v", channel, ids))
+
+	for _, snipeMonitor := range bs.snipeMonitors {
+		for _, id := range ids {
+			if snipeMonitor.ArmCollection(id) {
+				bs.log(fmt.Sprintf("🎯 Snipe '%s': Collection %d armed", snipeMonitor.GetAccountName(), id))
+			}
+		}
+	}
+}
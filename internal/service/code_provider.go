@@ -0,0 +1,78 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"stickersbot/internal/config"
+	"stickersbot/internal/telegram"
+)
+
+// NewCodeProvider returns source of login confirmation code configured for account,
+// nil if the code is typed in the console
+func NewCodeProvider(cfg *config.Config, account config.Account) (telegram.CodeProvider, error) {
+	settings := account.CodeProvider
+	if settings == nil || settings.Type == "" || settings.Type == config.CodeProviderPrompt {
+		return nil, nil
+	}
+
+	wait := telegram.CodeWait{Timeout: time.Duration(settings.TimeoutSeconds) * time.Second}
+	if settings.Pattern != "" {
+		pattern, err := regexp.Compile(settings.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("code_provider.pattern: %v", err)
+		}
+		wait.Pattern = pattern
+	}
+
+	switch settings.Type {
+	case config.CodeProviderFile:
+		if settings.Path == "" {
+			return nil, fmt.Errorf("code_provider.path not specified")
+		}
+		return &telegram.FileCodeProvider{CodeWait: wait, Path: config.StatePath(settings.Path)}, nil
+
+	case config.CodeProviderHTTP:
+		if settings.Listen == "" {
+			return nil, fmt.Errorf("code_provider.listen not specified")
+		}
+		return &telegram.HTTPCodeProvider{Timeout: wait.Timeout, Listen: settings.Listen}, nil
+
+	case config.CodeProviderSMSAPI:
+		if settings.URL == "" {
+			return nil, fmt.Errorf("code_provider.url not specified")
+		}
+		return &telegram.SMSAPICodeProvider{CodeWait: wait, URL: settings.URL}, nil
+
+	case config.CodeProviderTelegram:
+		var reader *config.Account
+		for i := range cfg.Accounts {
+			if cfg.Accounts[i].Name == settings.Account {
+				reader = &cfg.Accounts[i]
+				break
+			}
+		}
+		if reader == nil {
+			return nil, fmt.Errorf("code_provider.account '%s' not found", settings.Account)
+		}
+		if reader.Name == account.Name {
+			return nil, fmt.Errorf("code_provider.account must be another account")
+		}
+		if reader.APIId == 0 || reader.APIHash == "" {
+			return nil, fmt.Errorf("code_provider.account '%s' has no Telegram session settings", reader.Name)
+		}
+		return &telegram.TelegramCodeProvider{
+			CodeWait:    wait,
+			APIId:       reader.APIId,
+			APIHash:     reader.APIHash,
+			SessionFile: SessionFile(reader),
+			UseProxy:    reader.UseProxy,
+			ProxyURL:    reader.TelegramProxy(),
+			From:        settings.From,
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown code_provider.type '%s'", settings.Type)
+	}
+}
@@ -0,0 +1,480 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"stickersbot/internal/config"
+)
+
+// Coordination backends
+const (
+	CoordinationFile  = "file"  // Lease files in directory on shared storage
+	CoordinationRedis = "redis" // Keys with expiry in Redis
+)
+
+const (
+	// DefaultLeaseDuration how long lease of account stays valid without renewal
+	DefaultLeaseDuration = 15 * time.Second
+
+	// DefaultLeaseKey prefix of Redis lease keys
+	DefaultLeaseKey = "stickersbot/leases"
+
+	leaseLockAttempts = 20                    // Tries to get lock of lease file held by another instance
+	leaseLockDelay    = 50 * time.Millisecond // Pause between the tries
+)
+
+// leaseBackend keeps leases of accounts where all instances see them
+type leaseBackend interface {
+	// acquire takes free or expired lease, or renews lease of holder. False if another holder has it
+	acquire(name, holder string, ttl time.Duration) (bool, error)
+	// release gives up lease of holder, so standby doesn't wait for it to expire
+	release(name, holder string) error
+}
+
+// Coordinator decides which of instances running the same configuration buys for an account.
+// Only the instance holding lease of account buys for it, the others keep it hot standby
+// and take over when the lease is not renewed anymore
+type Coordinator struct {
+	backend  leaseBackend
+	instance string
+	ttl      time.Duration
+
+	expires map[string]time.Time // Account name -> end of lease held by this instance
+	stopped bool                 // Leases were released, renewals are skipped until the next run
+	mu      sync.RWMutex
+	renewMu sync.Mutex // Renewal and release don't overlap
+}
+
+// NewCoordinator creates coordinator of configured backend
+func NewCoordinator(cfg *config.CoordinationConfig) (*Coordinator, error) {
+	var backend leaseBackend
+	switch cfg.Type {
+	case CoordinationFile:
+		if cfg.Path == "" {
+			return nil, errors.New("path of lease directory is not set")
+		}
+		if err := os.MkdirAll(cfg.Path, 0755); err != nil {
+			return nil, fmt.Errorf("creating lease directory: %v", err)
+		}
+		backend = &fileLeases{dir: cfg.Path}
+	case CoordinationRedis:
+		server, err := newRedisServer(cfg.Address, cfg.Password, cfg.DB, cfg.TLS)
+		if err != nil {
+			return nil, err
+		}
+		key := cfg.Key
+		if key == "" {
+			key = DefaultLeaseKey
+		}
+		backend = &redisLeases{redisServer: server, key: key}
+	default:
+		return nil, fmt.Errorf("unknown type %q, expected %q or %q", cfg.Type, CoordinationFile, CoordinationRedis)
+	}
+
+	instance := cfg.Instance
+	if instance == "" {
+		host, err := os.Hostname()
+		if err != nil {
+			host = "unknown"
+		}
+		instance = fmt.Sprintf("%s-%d", host, os.Getpid())
+	}
+
+	ttl := DefaultLeaseDuration
+	if cfg.LeaseSeconds > 0 {
+		ttl = time.Duration(cfg.LeaseSeconds) * time.Second
+	}
+
+	return &Coordinator{
+		backend:  backend,
+		instance: instance,
+		ttl:      ttl,
+		expires:  make(map[string]time.Time),
+	}, nil
+}
+
+// Instance returns name of this instance in leases
+func (c *Coordinator) Instance() string {
+	return c.instance
+}
+
+// Holds checks if this instance buys for account. Without coordination it buys for all accounts
+func (c *Coordinator) Holds(accountName string) bool {
+	if c == nil {
+		return true
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return time.Now().Before(c.expires[accountName])
+}
+
+// renewInterval how often leases are renewed, several times per lease so one failed renewal doesn't lose them
+func (c *Coordinator) renewInterval() time.Duration {
+	return c.ttl / 3
+}
+
+// reset allows renewals of a new run
+func (c *Coordinator) reset() {
+	c.renewMu.Lock()
+	defer c.renewMu.Unlock()
+
+	c.stopped = false
+}
+
+// renew acquires free leases of accounts and renews held ones. Lease that can't be renewed
+// because backend is unreachable stays held until it expires, the other instances can't take
+// it before that either. Returns accounts this instance started and stopped buying for
+func (c *Coordinator) renew(accounts []string) (gained, lost []string, err error) {
+	c.renewMu.Lock()
+	defer c.renewMu.Unlock()
+
+	if c.stopped {
+		return nil, nil, nil
+	}
+
+	for _, name := range accounts {
+		// Lease counts from before the request, the backend may have started it a moment later
+		start := time.Now()
+		c.mu.RLock()
+		_, held := c.expires[name]
+		c.mu.RUnlock()
+
+		acquired, acquireErr := c.backend.acquire(name, c.instance, c.ttl)
+		if acquireErr != nil {
+			err = acquireErr
+			c.mu.Lock()
+			if end, ok := c.expires[name]; ok && !time.Now().Before(end) {
+				delete(c.expires, name)
+				lost = append(lost, name)
+			}
+			c.mu.Unlock()
+			continue
+		}
+
+		c.mu.Lock()
+		if acquired {
+			c.expires[name] = start.Add(c.ttl)
+		} else {
+			delete(c.expires, name)
+		}
+		c.mu.Unlock()
+
+		switch {
+		case acquired && !held:
+			gained = append(gained, name)
+		case !acquired && held:
+			lost = append(lost, name)
+		}
+	}
+	return gained, lost, err
+}
+
+// releaseAll gives up all held leases and stops renewals until reset
+func (c *Coordinator) releaseAll() error {
+	c.renewMu.Lock()
+	defer c.renewMu.Unlock()
+
+	c.stopped = true
+
+	c.mu.Lock()
+	expires := c.expires
+	c.expires = make(map[string]time.Time)
+	c.mu.Unlock()
+
+	var err error
+	for name := range expires {
+		if releaseErr := c.backend.release(name, c.instance); releaseErr != nil {
+			err = releaseErr
+		}
+	}
+	return err
+}
+
+// leaseAccounts returns names of accounts instances compete for
+func leaseAccounts(cfg *config.Config) []string {
+	accounts := make([]string, 0, len(cfg.Accounts))
+	for _, account := range cfg.Accounts {
+		accounts = append(accounts, account.Name)
+	}
+	return accounts
+}
+
+// startCoordination takes leases of accounts before the run and keeps renewing them while it works
+func (bs *BuyerService) startCoordination(ctx context.Context) {
+	if bs.coordinator == nil {
+		return
+	}
+
+	bs.coordinator.reset()
+	bs.log(fmt.Sprintf("🤝 Coordination: instance '%s', lease %s", bs.coordinator.Instance(), bs.coordinator.ttl))
+	bs.renewLeases()
+	for _, account := range bs.config.Accounts {
+		if !bs.coordinator.Holds(account.Name) {
+			bs.log(fmt.Sprintf("💤 Account '%s': another instance is buying, standing by", account.Name))
+		}
+	}
+
+	go func() {
+		ticker := time.NewTicker(bs.coordinator.renewInterval())
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				bs.renewLeases()
+			}
+		}
+	}()
+}
+
+// renewLeases renews leases of accounts and reports accounts changing hands
+func (bs *BuyerService) renewLeases() {
+	gained, lost, err := bs.coordinator.renew(leaseAccounts(bs.config))
+	if err != nil {
+		bs.log(fmt.Sprintf("⚠️ Coordination error: %v", err))
+	}
+	for _, name := range gained {
+		bs.log(fmt.Sprintf("🟢 Account '%s': lease acquired, this instance is buying", name))
+	}
+	for _, name := range lost {
+		bs.log(fmt.Sprintf("💤 Account '%s': lease lost, standing by", name))
+	}
+}
+
+// releaseLeases hands accounts over to standby instances when the run stops
+func (bs *BuyerService) releaseLeases() {
+	if bs.coordinator == nil {
+		return
+	}
+	if err := bs.coordinator.releaseAll(); err != nil {
+		bs.log(fmt.Sprintf("⚠️ Coordination: releasing leases failed, standby takes over after %s: %v", bs.coordinator.ttl, err))
+	}
+}
+
+// isStandby checks if another instance holds lease of account and buys for it
+func (bs *BuyerService) isStandby(accountName string) bool {
+	return !bs.coordinator.Holds(accountName)
+}
+
+// fileLeases keeps leases as JSON files in directory on storage shared by instances
+// (NFS, SMB). Expiry is wall clock time, so clocks of the machines must be synchronized
+type fileLeases struct {
+	dir string
+}
+
+// fileLease content of lease file
+type fileLease struct {
+	Holder  string    `json:"holder"`
+	Expires time.Time `json:"expires"`
+}
+
+func (f *fileLeases) acquire(name, holder string, ttl time.Duration) (bool, error) {
+	unlock, err := f.lock(name, ttl)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	lease, err := f.read(name)
+	if err != nil {
+		return false, err
+	}
+	if lease != nil && lease.Holder != holder && time.Now().Before(lease.Expires) {
+		return false, nil
+	}
+
+	data, err := json.Marshal(fileLease{Holder: holder, Expires: time.Now().Add(ttl)})
+	if err != nil {
+		return false, err
+	}
+	if err := config.WriteFileAtomic(f.path(name), data, 0644); err != nil {
+		return false, fmt.Errorf("writing lease of %s: %v", name, err)
+	}
+	return true, nil
+}
+
+func (f *fileLeases) release(name, holder string) error {
+	unlock, err := f.lock(name, DefaultLeaseDuration)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	lease, err := f.read(name)
+	if err != nil || lease == nil || lease.Holder != holder {
+		return err
+	}
+	return os.Remove(f.path(name))
+}
+
+// read returns lease of account, nil if there is none
+func (f *fileLeases) read(name string) (*fileLease, error) {
+	data, err := os.ReadFile(f.path(name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading lease of %s: %v", name, err)
+	}
+
+	var lease fileLease
+	if err := json.Unmarshal(data, &lease); err != nil {
+		// Half-written file of crashed instance holds nothing
+		return nil, nil
+	}
+	return &lease, nil
+}
+
+// lock creates lock file of lease holding unique token, so instances don't update it at
+// the same time. Lock left by crashed instance is taken over once it is older than lease
+func (f *fileLeases) lock(name string, ttl time.Duration) (func(), error) {
+	path := f.path(name) + ".lock"
+	token, err := lockToken()
+	if err != nil {
+		return nil, fmt.Errorf("locking lease of %s: %v", name, err)
+	}
+
+	for attempt := 0; attempt < leaseLockAttempts; attempt++ {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			_, err = file.WriteString(token)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				os.Remove(path)
+				return nil, fmt.Errorf("locking lease of %s: %v", name, err)
+			}
+			return func() { removeLock(path, token) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("locking lease of %s: %v", name, err)
+		}
+		if takeOverLock(path, token, ttl) {
+			continue
+		}
+		time.Sleep(leaseLockDelay)
+	}
+	return nil, fmt.Errorf("lease of %s is locked by another instance", name)
+}
+
+// takeOverLock moves away lock file older than ttl, true if lock may be created again.
+// Rename is atomic, so of instances taking over the same lock only one moves it. Lock
+// that turns out to be replaced by live one since it was checked is put back
+func takeOverLock(path, token string, ttl time.Duration) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return os.IsNotExist(err)
+	}
+	if time.Since(info.ModTime()) <= ttl {
+		return false
+	}
+	stale, err := os.ReadFile(path)
+	if err != nil {
+		return os.IsNotExist(err)
+	}
+
+	moved := path + "." + token
+	if err := os.Rename(path, moved); err != nil {
+		return os.IsNotExist(err)
+	}
+	defer os.Remove(moved)
+
+	data, err := os.ReadFile(moved)
+	if info, statErr := os.Stat(moved); err != nil || statErr != nil ||
+		string(data) != string(stale) || time.Since(info.ModTime()) <= ttl {
+		// Link fails if lock was created again meanwhile, then that one is kept
+		os.Link(moved, path)
+	}
+	return true
+}
+
+// removeLock removes lock file if it still holds token, lock taken over as stale is kept
+func removeLock(path, token string) {
+	data, err := os.ReadFile(path)
+	if err == nil && string(data) == token {
+		os.Remove(path)
+	}
+}
+
+// lockToken returns random token identifying lock file of this call
+func lockToken() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// path returns lease file of account
+func (f *fileLeases) path(name string) string {
+	return filepath.Join(f.dir, url.PathEscape(name)+".lease")
+}
+
+// redisAcquireScript takes free lease or renews lease of holder atomically
+const redisAcquireScript = `local holder = redis.call('GET', KEYS[1])
+if holder == ARGV[1] then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+	return 1
+end
+if holder then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+return 1`
+
+// redisReleaseScript deletes lease only if holder still has it
+const redisReleaseScript = `if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0`
+
+// redisLeases keeps leases as Redis keys expiring with the lease
+type redisLeases struct {
+	redisServer
+	key string
+}
+
+func (r *redisLeases) acquire(name, holder string, ttl time.Duration) (bool, error) {
+	reply, err := r.eval(redisAcquireScript, name, holder, strconv.FormatInt(ttl.Milliseconds(), 10))
+	if err != nil {
+		return false, err
+	}
+	return reply == int64(1), nil
+}
+
+func (r *redisLeases) release(name, holder string) error {
+	_, err := r.eval(redisReleaseScript, name, holder)
+	return err
+}
+
+// eval runs script on lease key of account
+func (r *redisLeases) eval(script, name string, args ...string) (interface{}, error) {
+	conn, err := r.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	command := append([]string{"EVAL", script, "1", r.key + "/" + name}, args...)
+	reply, err := conn.do(command...)
+	if err != nil {
+		return nil, fmt.Errorf("redis lease of %s: %v", name, err)
+	}
+	return reply, nil
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFileLeases(t *testing.T) {
+	const ttl = 100 * time.Millisecond
+
+	tests := []struct {
+		name   string
+		action func(f *fileLeases) (bool, error)
+		want   bool
+	}{
+		{"free lease is taken", func(f *fileLeases) (bool, error) {
+			return f.acquire("main", "a", ttl)
+		}, true},
+		{"lease of other holder is refused", func(f *fileLeases) (bool, error) {
+			f.acquire("main", "a", ttl)
+			return f.acquire("main", "b", ttl)
+		}, false},
+		{"holder renews its lease", func(f *fileLeases) (bool, error) {
+			f.acquire("main", "a", ttl)
+			return f.acquire("main", "a", ttl)
+		}, true},
+		{"expired lease is taken over", func(f *fileLeases) (bool, error) {
+			f.acquire("main", "a", ttl)
+			time.Sleep(2 * ttl)
+			return f.acquire("main", "b", ttl)
+		}, true},
+		{"released lease is taken", func(f *fileLeases) (bool, error) {
+			f.acquire("main", "a", ttl)
+			if err := f.release("main", "a"); err != nil {
+				return false, err
+			}
+			return f.acquire("main", "b", ttl)
+		}, true},
+		{"release by other holder keeps lease", func(f *fileLeases) (bool, error) {
+			f.acquire("main", "a", ttl)
+			if err := f.release("main", "b"); err != nil {
+				return false, err
+			}
+			return f.acquire("main", "b", ttl)
+		}, false},
+		{"leases of accounts are independent", func(f *fileLeases) (bool, error) {
+			f.acquire("main", "a", ttl)
+			return f.acquire("second", "b", ttl)
+		}, true},
+		{"damaged lease file holds nothing", func(f *fileLeases) (bool, error) {
+			if err := os.WriteFile(f.path("main"), []byte(`{"holder":`), 0644); err != nil {
+				return false, err
+			}
+			return f.acquire("main", "b", ttl)
+		}, true},
+		{"stale lock of crashed instance is removed", func(f *fileLeases) (bool, error) {
+			lock := f.path("main") + ".lock"
+			if err := os.WriteFile(lock, nil, 0644); err != nil {
+				return false, err
+			}
+			old := time.Now().Add(-time.Hour)
+			if err := os.Chtimes(lock, old, old); err != nil {
+				return false, err
+			}
+			return f.acquire("main", "b", ttl)
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.action(&fileLeases{dir: t.TempDir()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("acquire() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileLeasesConcurrentTakeover(t *testing.T) {
+	const instances = 2
+
+	for round := 0; round < 20; round++ {
+		f := &fileLeases{dir: t.TempDir()}
+		lock := f.path("main") + ".lock"
+		if err := os.WriteFile(lock, []byte("crashed"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		old := time.Now().Add(-time.Hour)
+		if err := os.Chtimes(lock, old, old); err != nil {
+			t.Fatal(err)
+		}
+
+		// Instances find the same stale lock, only one of them may hold it at a time
+		var holders, maxHolders atomic.Int32
+		var wg sync.WaitGroup
+		start := make(chan struct{})
+		errs := make(chan error, instances)
+		for range instances {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-start
+				unlock, err := f.lock("main", time.Second)
+				if err != nil {
+					errs <- err
+					return
+				}
+				held := holders.Add(1)
+				for {
+					current := maxHolders.Load()
+					if held <= current || maxHolders.CompareAndSwap(current, held) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				holders.Add(-1)
+				unlock()
+			}()
+		}
+		close(start)
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			t.Fatalf("round %d: lock() error = %v", round, err)
+		}
+		if got := maxHolders.Load(); got != 1 {
+			t.Fatalf("round %d: %d instances held the lock at once", round, got)
+		}
+		if _, err := os.Stat(lock); !os.IsNotExist(err) {
+			t.Fatalf("round %d: lock file left after unlock: %v", round, err)
+		}
+	}
+}
+
+func TestFileLeasesUnlockAfterTakeover(t *testing.T) {
+	f := &fileLeases{dir: t.TempDir()}
+	lock := f.path("main") + ".lock"
+
+	unlockA, err := f.lock("main", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Instance A stalls past the lease, so B takes its lock over
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(lock, old, old); err != nil {
+		t.Fatal(err)
+	}
+	unlockB, err := f.lock("main", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unlockA()
+	if _, err := os.Stat(lock); err != nil {
+		t.Fatalf("unlock of taken over lock removed lock of new holder: %v", err)
+	}
+	if _, err := f.lock("main", time.Second); err == nil {
+		t.Fatal("lock() succeeded while lock is held")
+	}
+
+	unlockB()
+	if _, err := os.Stat(lock); !os.IsNotExist(err) {
+		t.Fatalf("lock file left after unlock: %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"stickersbot/internal/client"
+	"stickersbot/internal/config"
+)
+
+const (
+	defaultFallbackFailures = 5               // Consecutive proxy failures that switch account to direct connection
+	defaultFallbackDuration = 5 * time.Minute // How long orders go direct before proxy is tried again
+)
+
+// directFallback accounts whose orders temporarily go without their failing proxy
+type directFallback struct {
+	failures map[string]int       // Account name -> consecutive proxy failures
+	until    map[string]time.Time // Account name -> end of direct connection
+	direct   map[string]bool      // Accounts whose last order went direct
+	clients  map[string]*client.HTTPClient
+	mu       sync.Mutex
+}
+
+// newDirectFallback creates fallback state without accounts
+func newDirectFallback() *directFallback {
+	return &directFallback{
+		failures: make(map[string]int),
+		until:    make(map[string]time.Time),
+		direct:   make(map[string]bool),
+		clients:  make(map[string]*client.HTTPClient),
+	}
+}
+
+// fallbackSettings returns failure count and duration of direct fallback of account
+func fallbackSettings(account config.Account) (int, time.Duration) {
+	failures, duration := defaultFallbackFailures, defaultFallbackDuration
+	if account.DirectFallback.AfterFailures > 0 {
+		failures = account.DirectFallback.AfterFailures
+	}
+	if account.DirectFallback.Minutes > 0 {
+		duration = time.Duration(account.DirectFallback.Minutes) * time.Minute
+	}
+	return failures, duration
+}
+
+// goesDirect checks if order of account should go without its proxy: the proxy failed
+// too many times in a row, is dead or quarantined, and account allows direct fallback
+func (bs *BuyerService) goesDirect(account config.Account) bool {
+	name := proxyName(account)
+	if account.DirectFallback == nil || name == directConnection {
+		return false
+	}
+
+	bs.fallback.mu.Lock()
+	active := time.Now().Before(bs.fallback.until[account.Name])
+	bs.fallback.mu.Unlock()
+
+	direct := active || bs.proxies.dead(name) || !bs.proxyBans.until(name).IsZero()
+	bs.noteRoute(account, direct)
+	return direct
+}
+
+// noteRoute warns loudly when orders of account switch to direct connection and back
+func (bs *BuyerService) noteRoute(account config.Account, direct bool) {
+	bs.fallback.mu.Lock()
+	changed := bs.fallback.direct[account.Name] != direct
+	bs.fallback.direct[account.Name] = direct
+	bs.fallback.mu.Unlock()
+
+	switch {
+	case changed && direct:
+		bs.log(fmt.Sprintf("🚨🚨 Account '%s': proxy %s is failing, orders go DIRECTLY from this server's IP", account.Name, proxyName(account)))
+	case changed:
+		bs.log(fmt.Sprintf("🌐 Account '%s': orders go through proxy %s again", account.Name, proxyName(account)))
+	}
+}
+
+// recordProxyFailure counts consecutive failed orders through proxy of account and
+// starts direct fallback when there are too many of them
+func (bs *BuyerService) recordProxyFailure(account config.Account, err error) {
+	if account.DirectFallback == nil {
+		return
+	}
+
+	bs.fallback.mu.Lock()
+	defer bs.fallback.mu.Unlock()
+
+	if err == nil {
+		delete(bs.fallback.failures, account.Name)
+		return
+	}
+
+	limit, duration := fallbackSettings(account)
+	bs.fallback.failures[account.Name]++
+	if bs.fallback.failures[account.Name] >= limit {
+		delete(bs.fallback.failures, account.Name)
+		bs.fallback.until[account.Name] = time.Now().Add(duration)
+	}
+}
+
+// directClient returns HTTP client of account orders sent without proxy
+func (bs *BuyerService) directClient(account config.Account) (*client.HTTPClient, error) {
+	bs.fallback.mu.Lock()
+	defer bs.fallback.mu.Unlock()
+
+	if httpClient, ok := bs.fallback.clients[account.Name]; ok {
+		return httpClient, nil
+	}
+	httpClient, err := client.New()
+	if err != nil {
+		return nil, err
+	}
+	bs.fallback.clients[account.Name] = httpClient
+	return httpClient, nil
+}
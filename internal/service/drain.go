@@ -0,0 +1,97 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"stickersbot/internal/config"
+)
+
+// DefaultDrainTimeout time stop waits for purchases and payments in progress
+const DefaultDrainTimeout = 90 * time.Second
+
+// drainPollInterval interval of checking pending payments while draining
+const drainPollInterval = 200 * time.Millisecond
+
+// drainTimeout returns drain timeout from configuration
+func drainTimeout(cfg *config.Config) time.Duration {
+	if cfg.DrainTimeoutSeconds > 0 {
+		return time.Duration(cfg.DrainTimeoutSeconds) * time.Second
+	}
+	return DefaultDrainTimeout
+}
+
+// drain drops queued purchases and waits until purchases being executed and their
+// payments finish. Returns false if timeout expired first
+func (bs *BuyerService) drain(queue *PurchaseQueue, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+
+	if queue != nil {
+		queue.Close()
+
+		done := make(chan struct{})
+		go func() {
+			queue.Wait()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(timeout):
+			bs.log(fmt.Sprintf("⏰ Purchases still in progress after %s, stopping anyway", timeout))
+			return false
+		}
+	}
+
+	if !bs.waitPayments(deadline) {
+		bs.log(fmt.Sprintf("⏰ Payments still pending after %s, they will be reconciled in background", timeout))
+		return false
+	}
+	return true
+}
+
+// WaitPayments waits up to drain timeout until payments queued in wallet transaction
+// queues are confirmed and reconciled. Returns false if some of them are still pending
+func (bs *BuyerService) WaitPayments() bool {
+	return bs.waitPayments(time.Now().Add(drainTimeout(bs.config)))
+}
+
+// waitPayments waits until there are no pending payments or deadline passes
+func (bs *BuyerService) waitPayments(deadline time.Time) bool {
+	reported := false
+	for len(bs.pendingPaymentCounts()) > 0 {
+		if time.Now().After(deadline) {
+			return false
+		}
+		if !reported {
+			bs.log("⏳ Waiting for queued payments to be confirmed...")
+			reported = true
+		}
+		time.Sleep(drainPollInterval)
+	}
+	return true
+}
+
+// reportFinalState logs final state of every account of the run. Requires bs.mu to be held
+func (bs *BuyerService) reportFinalState(drained bool) {
+	pending := bs.pendingPaymentCounts()
+
+	bs.log("📋 Final state of accounts:")
+	for _, account := range bs.config.Accounts {
+		name := account.Name
+		line := fmt.Sprintf("   • '%s': no requests", name)
+		if counters := bs.statistics.account(name); counters != nil {
+			line = fmt.Sprintf("   • '%s': requests %d, orders %d, errors %d, TON sent %d (%.4f TON)",
+				name, counters.TotalRequests, counters.SuccessRequests, counters.FailedRequests,
+				counters.SentTransactions, float64(counters.SpentNano)/1000000000)
+		}
+		if count := pending[name]; count > 0 {
+			line += fmt.Sprintf(", ⏳ %d payments pending", count)
+		}
+		bs.log(line)
+	}
+
+	if drained {
+		bs.log("✅ All purchases and payments in progress completed")
+	}
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"stickersbot/internal/config"
+)
+
+// Types of purchase events in the event stream
+const (
+	EventOrderCreated     = "order_created"
+	EventPaymentSent      = "payment_sent"
+	EventPaymentConfirmed = "payment_confirmed"
+	EventError            = "error"
+)
+
+const (
+	eventQueueSize      = 1000            // Events waiting to be written, newer ones are dropped when full
+	eventRedialInterval = 5 * time.Second // Minimal pause between connection attempts to socket
+)
+
+// PurchaseEvent one line of the event stream
+type PurchaseEvent struct {
+	Type          string    `json:"type"`
+	Time          time.Time `json:"time"`
+	Account       string    `json:"account"`
+	CollectionID  int       `json:"collection_id"`
+	CharacterID   int       `json:"character_id"`
+	OrderID       string    `json:"order_id,omitempty"`
+	OrderAmount   int64     `json:"order_amount,omitempty"` // Quoted price in nano units of Currency
+	Currency      string    `json:"currency,omitempty"`
+	StatusCode    int       `json:"status_code,omitempty"`
+	Amount        int64     `json:"amount,omitempty"` // Sent nanoTON
+	TransactionID string    `json:"transaction_id,omitempty"`
+	Stage         string    `json:"stage,omitempty"`
+	Error         string    `json:"error,omitempty"`
+	TestMode      bool      `json:"test_mode,omitempty"`
+}
+
+// EventStream writes purchase events as JSON lines to file or socket. Writing never
+// blocks purchases: events are queued and dropped if the destination can't keep up
+type EventStream struct {
+	output  string
+	events  chan PurchaseEvent
+	dropped atomic.Int64
+
+	dst      io.WriteCloser // Opened by the first write
+	lastDial time.Time
+	log      func(text string)
+}
+
+// NewEventStream creates stream to output: file path (relative to state directory),
+// "tcp://host:port" or "unix:///path/to/socket"
+func NewEventStream(output string, logf func(text string)) *EventStream {
+	return &EventStream{
+		output: output,
+		events: make(chan PurchaseEvent, eventQueueSize),
+		log:    logf,
+	}
+}
+
+// Send queues event for writing
+func (s *EventStream) Send(event PurchaseEvent) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	select {
+	case s.events <- event:
+	default:
+		s.dropped.Add(1)
+	}
+}
+
+// run writes queued events
+func (s *EventStream) run() {
+	for event := range s.events {
+		line, err := json.Marshal(event)
+		if err != nil {
+			continue
+		}
+		line = append(line, '\n')
+
+		if dropped := s.dropped.Swap(0); dropped > 0 {
+			s.log(fmt.Sprintf("⚠️ Event stream: %d events dropped, destination is too slow", dropped))
+		}
+		if err := s.write(line); err != nil {
+			s.log(fmt.Sprintf("⚠️ Event stream: %v", err))
+		}
+	}
+}
+
+// write writes line, opening destination first if needed. Broken socket is reopened
+// on a later event, lines written meanwhile are lost
+func (s *EventStream) write(line []byte) error {
+	if s.dst == nil {
+		if time.Since(s.lastDial) < eventRedialInterval {
+			return nil
+		}
+		s.lastDial = time.Now()
+
+		dst, err := openEventOutput(s.output)
+		if err != nil {
+			return err
+		}
+		s.dst = dst
+	}
+
+	if _, err := s.dst.Write(line); err != nil {
+		s.dst.Close()
+		s.dst = nil
+		return fmt.Errorf("error writing to %s: %v", s.output, err)
+	}
+	return nil
+}
+
+// openEventOutput opens file or connects to socket of event stream
+func openEventOutput(output string) (io.WriteCloser, error) {
+	for _, network := range []string{"tcp", "unix"} {
+		if address, ok := strings.CutPrefix(output, network+"://"); ok {
+			conn, err := net.DialTimeout(network, address, eventRedialInterval)
+			if err != nil {
+				return nil, fmt.Errorf("error connecting to %s: %v", output, err)
+			}
+			return conn, nil
+		}
+	}
+
+	file, err := os.OpenFile(config.StatePath(output), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("error opening %s: %v", output, err)
+	}
+	return file, nil
+}
+
+// startEventStream subscribes event stream of configuration to purchase hooks
+func (bs *BuyerService) startEventStream() {
+	if bs.config.EventStream == nil || bs.config.EventStream.Output == "" {
+		return
+	}
+
+	stream := NewEventStream(bs.config.EventStream.Output, bs.log)
+	go stream.run()
+
+	bs.OnOrderCreated(func(e OrderEvent) {
+		stream.Send(PurchaseEvent{
+			Type: EventOrderCreated, Account: e.Account,
+			CollectionID: e.Target.Collection, CharacterID: e.Target.Character,
+			OrderID: e.Order.OrderID, OrderAmount: e.Order.TotalAmount, Currency: e.Order.Currency,
+			StatusCode: e.Order.StatusCode, TestMode: bs.config.TestMode,
+		})
+	})
+	bs.OnPaymentSent(func(e PaymentEvent) {
+		stream.Send(PurchaseEvent{
+			Type: EventPaymentSent, Account: e.Account,
+			CollectionID: e.Target.Collection, CharacterID: e.Target.Character,
+			OrderID: e.Order.OrderID, OrderAmount: e.Order.TotalAmount, Currency: e.Order.Currency,
+			TestMode: bs.config.TestMode,
+		})
+	})
+	bs.OnPaymentConfirmed(func(e PaymentEvent) {
+		stream.Send(PurchaseEvent{
+			Type: EventPaymentConfirmed, Account: e.Account,
+			CollectionID: e.Target.Collection, CharacterID: e.Target.Character,
+			OrderID: e.Order.OrderID, OrderAmount: e.Order.TotalAmount, Currency: e.Order.Currency,
+			Amount: e.Transaction.Amount, TransactionID: e.Transaction.TransactionID, TestMode: bs.config.TestMode,
+		})
+	})
+	bs.OnError(func(e ErrorEvent) {
+		event := PurchaseEvent{
+			Type: EventError, Account: e.Account, Stage: e.Stage,
+			CollectionID: e.Target.Collection, CharacterID: e.Target.Character,
+			Error: e.Err.Error(), TestMode: bs.config.TestMode,
+		}
+		if e.Order != nil {
+			event.OrderID = e.Order.OrderID
+			event.StatusCode = e.Order.StatusCode
+		}
+		stream.Send(event)
+	})
+
+	bs.log(fmt.Sprintf("📤 Purchase events are written to %s", bs.config.EventStream.Output))
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"stickersbot/internal/client"
+	"stickersbot/internal/types"
+)
+
+// insufficientFundsMarkers error codes and messages of orders rejected for lack of money
+var insufficientFundsMarkers = []string{"insufficient", "not_enough_balance", "not enough balance", "not_enough_funds", "not enough funds", "low_balance"}
+
+// failedRequest returns counters of failed order request classified by its response and error
+func failedRequest(resp *client.BuyStickersResponse, err error) types.Counters {
+	// Order refused as duplicate was never sent
+	if errors.Is(err, errDuplicateOrder) {
+		return types.Counters{}
+	}
+
+	counters := types.Counters{FailedRequests: 1}
+	errors := &counters.Errors
+
+	switch {
+	case err != nil && resp == nil:
+		errors.Network++
+	case err != nil:
+		// Order was created, but its payment failed
+		if containsAny(err.Error(), insufficientFundsMarkers) {
+			errors.InsufficientFunds++
+		} else {
+			errors.PaymentFailed++
+		}
+	case resp.IsTokenError:
+		errors.TokenInvalid++
+	case resp.StatusCode == 429:
+		errors.RateLimited++
+	case resp.IsSoldOut:
+		errors.SoldOut++
+	case containsAny(resp.ErrorCode, insufficientFundsMarkers) || (resp.ErrorCode == "" && containsAny(resp.Body, insufficientFundsMarkers)):
+		errors.InsufficientFunds++
+	default:
+		errors.Other++
+	}
+	return counters
+}
+
+// failedToken returns counters of request failed because of missing or invalid token
+func failedToken() types.Counters {
+	return types.Counters{FailedRequests: 1, Errors: types.ErrorCounters{TokenInvalid: 1}}
+}
+
+// containsAny checks case-insensitively if text contains any of markers
+func containsAny(text string, markers []string) bool {
+	text = strings.ToLower(text)
+	for _, marker := range markers {
+		if strings.Contains(text, marker) {
+			return true
+		}
+	}
+	return false
+}
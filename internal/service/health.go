@@ -0,0 +1,172 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"stickersbot/internal/client"
+	"stickersbot/internal/config"
+)
+
+// directConnection name of requests sent without proxy
+const directConnection = "direct"
+
+// HealthStats request results of one worker thread or proxy
+type HealthStats struct {
+	Name         string        `json:"name"`
+	Requests     int           `json:"requests"`
+	Successes    int           `json:"successes"`
+	Forbidden    int           `json:"forbidden"`    // 403 responses
+	RateLimited  int           `json:"rate_limited"` // 429 responses
+	TotalLatency time.Duration `json:"-"`
+	LastError    string        `json:"last_error,omitempty"`
+	LastErrorAt  time.Time     `json:"last_error_at,omitempty"`
+}
+
+// SuccessRate returns share of successful requests from 0 to 1
+func (h HealthStats) SuccessRate() float64 {
+	if h.Requests == 0 {
+		return 0
+	}
+	return float64(h.Successes) / float64(h.Requests)
+}
+
+// BlockedRate returns share of requests refused with 403 or 429 from 0 to 1
+func (h HealthStats) BlockedRate() float64 {
+	if h.Requests == 0 {
+		return 0
+	}
+	return float64(h.Forbidden+h.RateLimited) / float64(h.Requests)
+}
+
+// AverageLatency returns average request time
+func (h HealthStats) AverageLatency() time.Duration {
+	if h.Requests == 0 {
+		return 0
+	}
+	return h.TotalLatency / time.Duration(h.Requests)
+}
+
+// HealthTracker request results per worker thread and per proxy
+type HealthTracker struct {
+	workers map[string]*HealthStats
+	proxies map[string]*HealthStats
+	mu      sync.Mutex
+}
+
+// NewHealthTracker creates empty health tracker
+func NewHealthTracker() *HealthTracker {
+	return &HealthTracker{
+		workers: make(map[string]*HealthStats),
+		proxies: make(map[string]*HealthStats),
+	}
+}
+
+// Reset forgets results of the previous run
+func (t *HealthTracker) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.workers = make(map[string]*HealthStats)
+	t.proxies = make(map[string]*HealthStats)
+}
+
+// RecordWorker adds result of purchase made by worker thread
+func (t *HealthTracker) RecordWorker(name string, resp *client.BuyStickersResponse, err error, latency time.Duration) {
+	t.record(t.workers, name, resp, err, latency)
+}
+
+// RecordProxy adds result of order request sent through proxy
+func (t *HealthTracker) RecordProxy(name string, resp *client.BuyStickersResponse, err error, latency time.Duration) {
+	t.record(t.proxies, name, resp, err, latency)
+}
+
+// record adds request result to stats of name
+func (t *HealthTracker) record(stats map[string]*HealthStats, name string, resp *client.BuyStickersResponse, err error, latency time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	item, ok := stats[name]
+	if !ok {
+		item = &HealthStats{Name: name}
+		stats[name] = item
+	}
+
+	item.Requests++
+	item.TotalLatency += latency
+	switch {
+	case err != nil:
+		item.LastError = err.Error()
+		item.LastErrorAt = time.Now()
+	case !resp.Success:
+		switch resp.StatusCode {
+		case 403:
+			item.Forbidden++
+		case 429:
+			item.RateLimited++
+		}
+		item.LastError = fmt.Sprintf("status %d", resp.StatusCode)
+		if resp.ErrorCode != "" {
+			item.LastError += " " + resp.ErrorCode
+		}
+		item.LastErrorAt = time.Now()
+	default:
+		item.Successes++
+	}
+}
+
+// Workers returns stats of worker threads, worst first
+func (t *HealthTracker) Workers() []HealthStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return worstFirst(t.workers)
+}
+
+// Proxies returns stats of proxies, worst first
+func (t *HealthTracker) Proxies() []HealthStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return worstFirst(t.proxies)
+}
+
+// worstFirst returns copy of stats ordered by success rate, slower first when equal
+func worstFirst(stats map[string]*HealthStats) []HealthStats {
+	items := make([]HealthStats, 0, len(stats))
+	for _, item := range stats {
+		items = append(items, *item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].SuccessRate() != items[j].SuccessRate() {
+			return items[i].SuccessRate() < items[j].SuccessRate()
+		}
+		return items[i].AverageLatency() > items[j].AverageLatency()
+	})
+	return items
+}
+
+// proxyName returns proxy of account without credentials, the exit of proxy chain.
+// "direct" if proxy is not used
+func proxyName(account config.Account) string {
+	if !account.UseProxy || account.ProxyURL == "" {
+		return directConnection
+	}
+
+	hops, err := config.ParseProxyChain(account.ProxyURL)
+	if err != nil {
+		return strings.Split(account.ProxyURL, ":")[0]
+	}
+	return hops[len(hops)-1].Address()
+}
+
+// workerName returns name of worker thread in health stats
+func workerName(worker *AccountWorker) string {
+	return fmt.Sprintf("thread %d (%s)", worker.workerID, worker.account.Name)
+}
+
+// Health returns request health of worker threads and proxies of the current run
+func (bs *BuyerService) Health() *HealthTracker {
+	return bs.health
+}
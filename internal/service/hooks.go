@@ -0,0 +1,135 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+
+	"stickersbot/internal/client"
+	"stickersbot/internal/config"
+)
+
+// Stages of purchase reported in ErrorEvent
+const (
+	StageOrder   = "order"   // Order request failed or was rejected
+	StagePayment = "payment" // Created order could not be paid
+)
+
+// OrderEvent order created by shop
+type OrderEvent struct {
+	Account string
+	Target  config.PurchaseTarget
+	Order   *client.BuyStickersResponse
+}
+
+// PaymentEvent payment of created order. Transaction is nil until the payment is confirmed
+type PaymentEvent struct {
+	Account     string
+	Target      config.PurchaseTarget
+	Order       *client.BuyStickersResponse
+	Transaction *client.TransactionResult
+}
+
+// ErrorEvent failed purchase step. Order holds shop response if there was any
+type ErrorEvent struct {
+	Account string
+	Stage   string
+	Target  config.PurchaseTarget
+	Order   *client.BuyStickersResponse
+	Err     error
+}
+
+// hooks callbacks registered for purchase lifecycle events
+type hooks struct {
+	orderCreated     []func(OrderEvent)
+	paymentSent      []func(PaymentEvent)
+	paymentConfirmed []func(PaymentEvent)
+	errors           []func(ErrorEvent)
+	mu               sync.RWMutex
+}
+
+// OnOrderCreated registers callback called when shop creates an order.
+// Callbacks run synchronously in purchase goroutines and must return quickly
+func (bs *BuyerService) OnOrderCreated(fn func(OrderEvent)) {
+	bs.hooks.mu.Lock()
+	defer bs.hooks.mu.Unlock()
+
+	bs.hooks.orderCreated = append(bs.hooks.orderCreated, fn)
+}
+
+// OnPaymentSent registers callback called when payment of order is handed to wallet transaction queue
+func (bs *BuyerService) OnPaymentSent(fn func(PaymentEvent)) {
+	bs.hooks.mu.Lock()
+	defer bs.hooks.mu.Unlock()
+
+	bs.hooks.paymentSent = append(bs.hooks.paymentSent, fn)
+}
+
+// OnPaymentConfirmed registers callback called when wallet reports the transaction as sent
+func (bs *BuyerService) OnPaymentConfirmed(fn func(PaymentEvent)) {
+	bs.hooks.mu.Lock()
+	defer bs.hooks.mu.Unlock()
+
+	bs.hooks.paymentConfirmed = append(bs.hooks.paymentConfirmed, fn)
+}
+
+// OnError registers callback called when order request or payment fails
+func (bs *BuyerService) OnError(fn func(ErrorEvent)) {
+	bs.hooks.mu.Lock()
+	defer bs.hooks.mu.Unlock()
+
+	bs.hooks.errors = append(bs.hooks.errors, fn)
+}
+
+// fireOrderCreated calls order created callbacks
+func (bs *BuyerService) fireOrderCreated(event OrderEvent) {
+	bs.hooks.mu.RLock()
+	callbacks := bs.hooks.orderCreated
+	bs.hooks.mu.RUnlock()
+
+	for _, fn := range callbacks {
+		bs.callHook("OnOrderCreated", func() { fn(event) })
+	}
+}
+
+// firePaymentSent calls payment sent callbacks
+func (bs *BuyerService) firePaymentSent(event PaymentEvent) {
+	bs.hooks.mu.RLock()
+	callbacks := bs.hooks.paymentSent
+	bs.hooks.mu.RUnlock()
+
+	for _, fn := range callbacks {
+		bs.callHook("OnPaymentSent", func() { fn(event) })
+	}
+}
+
+// firePaymentConfirmed calls payment confirmed callbacks
+func (bs *BuyerService) firePaymentConfirmed(event PaymentEvent) {
+	bs.hooks.mu.RLock()
+	callbacks := bs.hooks.paymentConfirmed
+	bs.hooks.mu.RUnlock()
+
+	for _, fn := range callbacks {
+		bs.callHook("OnPaymentConfirmed", func() { fn(event) })
+	}
+}
+
+// fireError calls error callbacks
+func (bs *BuyerService) fireError(event ErrorEvent) {
+	bs.hooks.mu.RLock()
+	callbacks := bs.hooks.errors
+	bs.hooks.mu.RUnlock()
+
+	for _, fn := range callbacks {
+		bs.callHook("OnError", func() { fn(event) })
+	}
+}
+
+// callHook runs callback, so a panicking extension doesn't take purchases down
+func (bs *BuyerService) callHook(name string, call func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			bs.log(fmt.Sprintf("⚠️ %s hook panicked: %v", name, r))
+		}
+	}()
+	call()
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LockFile file held by the running instance, so a second copy in the same directory
+// doesn't overwrite tokens, sessions and transaction log of the first one
+const LockFile = "stickersbot.lock"
+
+// InstanceLock lock file of the running instance
+type InstanceLock struct {
+	path string
+}
+
+// ErrAlreadyRunning another instance holds the lock
+var ErrAlreadyRunning = errors.New("another instance is already running in this directory")
+
+// AcquireInstanceLock creates lock file with PID of the process. Lock of a process that
+// no longer runs is stale and taken over
+func AcquireInstanceLock(path string) (*InstanceLock, error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			_, err = fmt.Fprintf(file, "%d\n%s\n", os.Getpid(), time.Now().Format(time.RFC3339))
+			file.Close()
+			if err != nil {
+				os.Remove(path)
+				return nil, fmt.Errorf("writing %s: %v", path, err)
+			}
+			return &InstanceLock{path: path}, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("creating %s: %v", path, err)
+		}
+
+		pid, startedAt := readLockFile(path)
+		if pid > 0 && pid != os.Getpid() && processRunning(pid) {
+			return nil, fmt.Errorf("%w (PID %d, started %s). Stop it first or remove %s if it is not running",
+				ErrAlreadyRunning, pid, startedAt, path)
+		}
+
+		// Stale lock of a crashed run
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("removing stale %s: %v", path, err)
+		}
+	}
+	return nil, fmt.Errorf("%w: %s keeps being recreated", ErrAlreadyRunning, path)
+}
+
+// readLockFile returns PID and start time written to lock file, 0 if it can't be read
+func readLockFile(path string) (int, string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, ""
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	pid, err := strconv.Atoi(strings.TrimSpace(lines[0]))
+	if err != nil {
+		return 0, ""
+	}
+	startedAt := "unknown"
+	if len(lines) > 1 {
+		startedAt = strings.TrimSpace(lines[1])
+	}
+	return pid, startedAt
+}
+
+// Release removes lock file
+func (l *InstanceLock) Release() {
+	if l == nil {
+		return
+	}
+	os.Remove(l.path)
+}
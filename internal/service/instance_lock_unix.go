@@ -0,0 +1,15 @@
+//go:build !windows
+
+package service
+
+import (
+	"errors"
+	"syscall"
+)
+
+// processRunning checks whether process with PID exists
+func processRunning(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	// EPERM: process exists but belongs to another user
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
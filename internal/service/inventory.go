@@ -0,0 +1,89 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"stickersbot/internal/notify"
+	"stickersbot/internal/types"
+)
+
+// Inventory checks of paid orders: the shop credits stickers after the payment is confirmed on chain
+const (
+	creditCheckDelay    = 30 * time.Second
+	creditCheckAttempts = 4
+)
+
+// verifyCredit checks that character of paid order appears in account inventory and
+// records the result in transaction log. Paid but not credited orders are alerted
+func (bs *BuyerService) verifyCredit(p payment) {
+	target := p.target
+	for attempt := 1; attempt <= creditCheckAttempts; attempt++ {
+		time.Sleep(creditCheckDelay)
+
+		owned, err := bs.ownsCharacter(p)
+		if err != nil {
+			bs.log(fmt.Sprintf("⚠️ %s: inventory check %d/%d of order %s failed: %v",
+				p.label, attempt, creditCheckAttempts, p.order.OrderID, err))
+			continue
+		}
+		if owned {
+			bs.log(fmt.Sprintf("📦 %s: Order %s credited, Collection %d, Character %d is in inventory",
+				p.label, p.order.OrderID, target.Collection, target.Character))
+			bs.markCredit(p, types.CreditCredited)
+			return
+		}
+	}
+
+	bs.markCredit(p, types.CreditNotCredited)
+	bs.notifier.Send(notify.Event{
+		Type:     notify.EventNotCredited,
+		Severity: notify.SeverityCritical,
+		Account:  p.account.Name,
+		Title:    "⚠️ Paid order not credited",
+		Message: fmt.Sprintf("Order %s (collection %d, character %d) was paid, but the character is not in inventory after %s",
+			p.order.OrderID, target.Collection, target.Character, creditCheckDelay*creditCheckAttempts),
+		Fields: map[string]interface{}{
+			"order_id":      p.order.OrderID,
+			"collection_id": target.Collection,
+			"character_id":  target.Character,
+			"amount":        p.order.TotalAmount,
+		},
+	})
+}
+
+// ownsCharacter checks if account inventory contains ordered character
+func (bs *BuyerService) ownsCharacter(p payment) (bool, error) {
+	token, err := bs.tokenManager.GetValidToken(p.account.Name)
+	if err != nil {
+		return false, err
+	}
+
+	httpClient, err := bs.orderClient(p.account)
+	if err != nil {
+		return false, err
+	}
+
+	items, err := httpClient.GetInventory(token)
+	if err != nil {
+		return false, err
+	}
+
+	for _, item := range items {
+		if item.Collection == p.target.Collection && item.Character == p.target.Character {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// markCredit records inventory check result of paid order in transaction log
+func (bs *BuyerService) markCredit(p payment, credit string) {
+	bs.logTransaction(&types.TransactionLog{
+		Timestamp:   time.Now(),
+		AccountName: p.account.Name,
+		OrderID:     p.order.OrderID,
+		Credit:      credit,
+		Update:      true,
+	})
+}
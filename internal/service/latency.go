@@ -0,0 +1,130 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"stickersbot/internal/client"
+	"stickersbot/internal/monitor"
+	"stickersbot/internal/types"
+)
+
+// Stages of snipe purchase latency
+const (
+	LatencyScan    = "scan"    // Start of monitoring check -> match detected
+	LatencyOrder   = "order"   // Match detected -> order created
+	LatencyPayment = "payment" // Order created -> TON transaction broadcast
+	LatencyTotal   = "total"   // Match detected -> TON transaction broadcast
+)
+
+// latencyStages stages in reporting order
+var latencyStages = []string{LatencyScan, LatencyOrder, LatencyPayment, LatencyTotal}
+
+// latencySamples number of latest samples kept per stage
+const latencySamples = 1000
+
+// LatencyTracker collects durations of snipe purchase stages
+type LatencyTracker struct {
+	samples map[string][]time.Duration
+	mu      sync.Mutex
+}
+
+// NewLatencyTracker creates empty latency tracker
+func NewLatencyTracker() *LatencyTracker {
+	return &LatencyTracker{
+		samples: make(map[string][]time.Duration),
+	}
+}
+
+// Record adds duration of stage, only latest samples are kept
+func (lt *LatencyTracker) Record(stage string, duration time.Duration) {
+	if duration < 0 {
+		return
+	}
+
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+
+	samples := append(lt.samples[stage], duration)
+	if len(samples) > latencySamples {
+		samples = samples[len(samples)-latencySamples:]
+	}
+	lt.samples[stage] = samples
+}
+
+// RecordPurchase records stage latencies of snipe purchase from its timestamps
+func (lt *LatencyTracker) RecordPurchase(request monitor.PurchaseRequest, resp *client.BuyStickersResponse) {
+	if request.DetectedAt.IsZero() {
+		return
+	}
+
+	if !request.CheckStartedAt.IsZero() {
+		lt.Record(LatencyScan, request.DetectedAt.Sub(request.CheckStartedAt))
+	}
+
+	if resp == nil || !resp.Success || resp.RespondedAt.IsZero() {
+		return
+	}
+	lt.Record(LatencyOrder, resp.RespondedAt.Sub(request.DetectedAt))
+
+	if resp.TransactionResult == nil || resp.TransactionResult.BroadcastAt.IsZero() {
+		return
+	}
+	lt.Record(LatencyPayment, resp.TransactionResult.BroadcastAt.Sub(resp.RespondedAt))
+	lt.Record(LatencyTotal, resp.TransactionResult.BroadcastAt.Sub(request.DetectedAt))
+}
+
+// Stats returns p50/p95 of every stage that has samples
+func (lt *LatencyTracker) Stats() []types.LatencyStats {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+
+	var stats []types.LatencyStats
+	for _, stage := range latencyStages {
+		samples := lt.samples[stage]
+		if len(samples) == 0 {
+			continue
+		}
+
+		sorted := append([]time.Duration{}, samples...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+		stats = append(stats, types.LatencyStats{
+			Stage: stage,
+			Count: len(sorted),
+			P50:   percentile(sorted, 50),
+			P95:   percentile(sorted, 95),
+		})
+	}
+	return stats
+}
+
+// Reset removes all samples
+func (lt *LatencyTracker) Reset() {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+
+	lt.samples = make(map[string][]time.Duration)
+}
+
+// percentile returns p-th percentile of sorted durations (nearest rank)
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// formatLatencies formats latency stats for statistics line
+func formatLatencies(stats []types.LatencyStats) string {
+	parts := make([]string, 0, len(stats))
+	for _, stat := range stats {
+		parts = append(parts, fmt.Sprintf("%s p50 %s / p95 %s",
+			stat.Stage, stat.P50.Round(time.Millisecond), stat.P95.Round(time.Millisecond)))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"stickersbot/internal/logfile"
+	"stickersbot/internal/redact"
+)
+
+// DefaultLogBufferSize number of latest log lines kept in memory
+const DefaultLogBufferSize = 5000
+
+// LogFile file receiving every log line, written in background
+const LogFile = "bot.log"
+
+// LogLine log line with sequence number used for incremental reading
+type LogLine struct {
+	Seq  int64     `json:"seq"`
+	Time time.Time `json:"time"`
+	Text string    `json:"text"`
+}
+
+// LogBuffer non-blocking ring buffer of log lines. Writers never wait for readers:
+// when a reader falls behind, the oldest lines are overwritten
+type LogBuffer struct {
+	lines   []LogLine
+	seq     int64         // Sequence number of the latest line
+	updated chan struct{} // Closed and replaced on every write
+	mu      sync.RWMutex
+
+	toFile  atomic.Bool  // Lines are copied to log file
+	written atomic.Int64 // Sequence number of the latest line written to log file
+}
+
+// NewLogBuffer creates buffer keeping size latest lines
+func NewLogBuffer(size int) *LogBuffer {
+	if size <= 0 {
+		size = DefaultLogBufferSize
+	}
+	return &LogBuffer{
+		lines:   make([]LogLine, size),
+		updated: make(chan struct{}),
+	}
+}
+
+// Add appends log line without blocking. Secrets are masked before the line reaches any reader
+func (b *LogBuffer) Add(text string) {
+	text = redact.String(text)
+
+	b.mu.Lock()
+	b.seq++
+	b.lines[b.seq%int64(len(b.lines))] = LogLine{Seq: b.seq, Time: time.Now(), Text: text}
+	updated := b.updated
+	b.updated = make(chan struct{})
+	b.mu.Unlock()
+
+	close(updated)
+}
+
+// Since returns kept lines with sequence number greater than after, oldest first.
+// Lines overwritten before they were read are counted in skipped
+func (b *LogBuffer) Since(after int64) (lines []LogLine, skipped int64) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	lines = []LogLine{}
+	oldest := b.seq - int64(len(b.lines)) + 1
+	if oldest < 1 {
+		oldest = 1
+	}
+	if after+1 < oldest {
+		skipped = oldest - after - 1
+		after = oldest - 1
+	}
+
+	for seq := after + 1; seq <= b.seq; seq++ {
+		lines = append(lines, b.lines[seq%int64(len(b.lines))])
+	}
+	return lines, skipped
+}
+
+// Updated returns channel closed when the next line is added
+func (b *LogBuffer) Updated() <-chan struct{} {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.updated
+}
+
+// LastSeq returns sequence number of the latest line
+func (b *LogBuffer) LastSeq() int64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.seq
+}
+
+// writeToFile copies every new line to file for the lifetime of the process.
+// Runs in its own goroutine, so slow disk never delays writers
+func (b *LogBuffer) writeToFile(file *logfile.RotatingFile) {
+	last := b.LastSeq()
+	b.written.Store(last)
+	b.toFile.Store(true)
+	for {
+		updated := b.Updated()
+
+		lines, skipped := b.Since(last)
+		if skipped > 0 {
+			file.WriteString(fmt.Sprintf("%s ⚠️ %d log lines skipped by file writer\n", time.Now().Format("2006-01-02 15:04:05.000"), skipped))
+		}
+		for _, line := range lines {
+			file.WriteString(line.Time.Format("2006-01-02 15:04:05.000") + " " + line.Text + "\n")
+			last = line.Seq
+		}
+		b.written.Store(last)
+
+		<-updated
+	}
+}
+
+// Flush waits at most timeout until lines added so far are written to log file, so they are
+// not lost when the process exits. Returns false if they were not written in time
+func (b *LogBuffer) Flush(timeout time.Duration) bool {
+	if !b.toFile.Load() {
+		return true
+	}
+
+	target := b.LastSeq()
+	deadline := time.Now().Add(timeout)
+	for b.written.Load() < target {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return true
+}
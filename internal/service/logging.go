@@ -0,0 +1,87 @@
+package service
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"stickersbot/internal/client"
+	"stickersbot/internal/config"
+	"stickersbot/internal/logfile"
+)
+
+// DefaultLogDir directory of session logs
+const DefaultLogDir = "logs"
+
+// DefaultMaxBodyLength logged response bodies are truncated to this many characters
+const DefaultMaxBodyLength = 500
+
+// verbose logs bodies of all responses, set by --verbose
+var verbose atomic.Bool
+
+// SetVerbose turns logging of all response bodies on or off regardless of configuration
+func SetVerbose(on bool) {
+	verbose.Store(on)
+}
+
+// logResponseBody logs body of order response. Bodies of successful responses flood
+// the console during drops, so they are logged only in verbose mode
+func (bs *BuyerService) logResponseBody(label string, resp *client.BuyStickersResponse) {
+	logging := bs.config.Logging
+	if resp.Success && !resp.IsTokenError && !verbose.Load() && (logging == nil || !logging.Verbose) {
+		return
+	}
+
+	limit := DefaultMaxBodyLength
+	if logging != nil && logging.MaxBodyLength != 0 {
+		limit = logging.MaxBodyLength
+	}
+	bs.log(fmt.Sprintf("📄 %s: Response - %s", label, truncateBody(resp.Body, limit)))
+}
+
+// truncateBody shortens body to limit characters, negative limit keeps it whole
+func truncateBody(body string, limit int) string {
+	runes := []rune(body)
+	if limit < 0 || len(runes) <= limit {
+		return body
+	}
+	return fmt.Sprintf("%s... (%d more characters)", string(runes[:limit]), len(runes)-limit)
+}
+
+// LogFileOptions returns rotation settings of log files from configuration
+func LogFileOptions(cfg *config.Config) logfile.Options {
+	if cfg.Logging == nil {
+		return logfile.Options{}
+	}
+
+	return logfile.Options{
+		MaxSize: int64(cfg.Logging.MaxSizeMB) * 1024 * 1024,
+		MaxAge:  time.Duration(cfg.Logging.MaxAgeHours) * time.Hour,
+		Backups: cfg.Logging.Backups,
+	}
+}
+
+// OpenSessionLog opens console log file of a run started at given time.
+// Returns nil if session logs are disabled
+func OpenSessionLog(cfg *config.Config, startedAt time.Time) (*logfile.RotatingFile, error) {
+	if cfg.Logging == nil || !cfg.Logging.SessionLog {
+		return nil, nil
+	}
+
+	path := filepath.Join(logDir(cfg), "session_"+startedAt.Format("20060102_150405")+".log")
+	return logfile.Open(path, LogFileOptions(cfg))
+}
+
+// openBotLog opens file receiving all log lines of the bot
+func openBotLog(cfg *config.Config) (*logfile.RotatingFile, error) {
+	return logfile.Open(filepath.Join(logDir(cfg), LogFile), LogFileOptions(cfg))
+}
+
+// logDir returns directory of log files
+func logDir(cfg *config.Config) string {
+	if cfg.Logging == nil || cfg.Logging.Dir == "" {
+		return config.StatePath(DefaultLogDir)
+	}
+	return config.StatePath(cfg.Logging.Dir)
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"stickersbot/internal/client"
+	"stickersbot/internal/config"
+)
+
+// errDuplicateOrder returned instead of sending order that would duplicate a recent one
+var errDuplicateOrder = errors.New("duplicate order")
+
+// OrderGuard protects against paying the same order twice and, when account has
+// dedupe window, against creating near-duplicate orders of the same target
+type OrderGuard struct {
+	paid   map[string]bool      // Order IDs handed over to payment
+	recent map[string]time.Time // Account and target -> last created or possibly created order
+	mu     sync.Mutex
+}
+
+// NewOrderGuard creates empty order guard
+func NewOrderGuard() *OrderGuard {
+	return &OrderGuard{
+		paid:   make(map[string]bool),
+		recent: make(map[string]time.Time),
+	}
+}
+
+// Reset forgets orders of the previous run
+func (g *OrderGuard) Reset() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.paid = make(map[string]bool)
+	g.recent = make(map[string]time.Time)
+}
+
+// ClaimPayment marks order as being paid. Returns false if it was already claimed
+func (g *OrderGuard) ClaimPayment(orderID string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.paid[orderID] {
+		return false
+	}
+	g.paid[orderID] = true
+	return true
+}
+
+// Cooldown returns how long new order of target must wait for dedupe window of account
+func (g *OrderGuard) Cooldown(account config.Account, target config.PurchaseTarget) time.Duration {
+	window := dedupeWindow(account)
+	if window <= 0 {
+		return 0
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	last, ok := g.recent[orderKey(account, target)]
+	if !ok {
+		return 0
+	}
+	return max(0, window-time.Since(last))
+}
+
+// Record remembers order request of target that created an order or may have created it:
+// a request without response could have reached the shop before the connection failed
+func (g *OrderGuard) Record(account config.Account, target config.PurchaseTarget, resp *client.BuyStickersResponse, err error) {
+	if dedupeWindow(account) <= 0 {
+		return
+	}
+	ambiguous := err != nil && resp == nil
+	created := resp != nil && resp.OrderID != ""
+	if !ambiguous && !created {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.recent[orderKey(account, target)] = time.Now()
+}
+
+// orderKey returns key of orders of account for target
+func orderKey(account config.Account, target config.PurchaseTarget) string {
+	return fmt.Sprintf("%s|%d|%d", account.Name, target.Collection, target.Character)
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"stickersbot/internal/client"
+	"stickersbot/internal/logfile"
+	"stickersbot/internal/redact"
+	"stickersbot/internal/types"
+)
+
+// OrderLogFile file where raw order records are kept as JSON lines
+const OrderLogFile = "orders.log"
+
+// recordOrder saves raw order and its payment, so disputed purchases can be investigated later
+func (bs *BuyerService) recordOrder(p payment, txResult *client.TransactionResult, result string, err error) {
+	record := types.OrderRecord{
+		Timestamp:    time.Now(),
+		AccountName:  p.account.Name,
+		OrderID:      p.order.OrderID,
+		Collection:   p.target.Collection,
+		Character:    p.target.Character,
+		Request:      p.order.Request,
+		StatusCode:   p.order.StatusCode,
+		ResponseBody: redact.JSONFields(p.order.Body),
+		RespondedAt:  p.order.RespondedAt,
+		Result:       result,
+	}
+	if txResult != nil {
+		record.PaymentTo = txResult.ToAddress
+		record.PaymentAmount = txResult.Amount
+		record.PaymentComment = txResult.Comment
+		record.TransactionID = txResult.TransactionID
+		record.BroadcastAt = txResult.BroadcastAt
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+
+	if err := bs.storage.AddOrderRecord(record); err != nil {
+		bs.log(fmt.Sprintf("⚠️ Error saving record of order %s: %v", p.order.OrderID, err))
+	}
+}
+
+// findOrderRecords reads records of the order from order log and its rotated files
+func findOrderRecords(path string, orderID string) ([]types.OrderRecord, error) {
+	var records []types.OrderRecord
+	for _, filename := range logfile.Files(path) {
+		file, err := os.Open(filename)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("error opening order log: %v", err)
+		}
+
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+		for scanner.Scan() {
+			var record types.OrderRecord
+			if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+				continue // Skip damaged lines
+			}
+			if record.OrderID == orderID {
+				records = append(records, record)
+			}
+		}
+		err = scanner.Err()
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error reading order log: %v", err)
+		}
+	}
+	return records, nil
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"stickersbot/internal/config"
+	"stickersbot/internal/notify"
+	"stickersbot/internal/telegram"
+)
+
+// defaultOwnerEvents events accounts send to their owner unless owner_notify.events is set
+var defaultOwnerEvents = []string{
+	string(notify.EventPurchase),
+	string(notify.EventPaymentFailed),
+	string(notify.EventTokenExpired),
+}
+
+// ownerNotifier sends events of account through the account's own Telegram session
+type ownerNotifier struct {
+	sender *telegram.MessageSender
+	events map[notify.EventType]bool
+}
+
+// registerOwnerNotify registers notifier delivering account events to the owner's
+// Saved Messages, or another chat, of accounts with owner_notify enabled
+func (bs *BuyerService) registerOwnerNotify() {
+	owners := make(map[string]*ownerNotifier)
+	for i := range bs.config.Accounts {
+		account := &bs.config.Accounts[i]
+		if account.OwnerNotify == nil || !account.OwnerNotify.Enabled {
+			continue
+		}
+		if account.PhoneNumber == "" || account.APIId == 0 || account.APIHash == "" {
+			bs.log(fmt.Sprintf("⚠️ owner_notify of '%s' needs Telegram authorization settings, skipped", account.Name))
+			continue
+		}
+		owners[account.Name] = newOwnerNotifier(account)
+	}
+	if len(owners) == 0 {
+		return
+	}
+
+	bs.notifier.Register("owner", notify.NotifierFunc(func(ctx context.Context, event notify.Event) error {
+		owner, ok := owners[event.Account]
+		if !ok || !owner.events[event.Type] {
+			return nil
+		}
+		return owner.sender.Send(ctx, event.Text())
+	}))
+}
+
+// newOwnerNotifier creates notifier of account
+func newOwnerNotifier(account *config.Account) *ownerNotifier {
+	events := account.OwnerNotify.Events
+	if len(events) == 0 {
+		events = defaultOwnerEvents
+	}
+
+	owner := &ownerNotifier{
+		sender: &telegram.MessageSender{
+			APIId:       account.APIId,
+			APIHash:     account.APIHash,
+			SessionFile: SessionFile(account),
+			UseProxy:    account.UseProxy,
+			ProxyURL:    account.TelegramProxy(),
+			To:          account.OwnerNotify.Chat,
+		},
+		events: make(map[notify.EventType]bool),
+	}
+	for _, event := range events {
+		owner.events[notify.EventType(event)] = true
+	}
+	return owner
+}
+
+// handleTokenExpired notifies that account lost its token, failures - failed refreshes in a row
+func (bs *BuyerService) handleTokenExpired(accountName, reason string, failures int) {
+	bs.notifier.Send(notify.Event{
+		Type:     notify.EventTokenExpired,
+		Severity: notify.SeverityWarning,
+		Account:  accountName,
+		Title:    "🔑 Token expired",
+		Message:  fmt.Sprintf("Token could not be refreshed: %s", reason),
+		Fields: map[string]interface{}{
+			"reason": reason,
+		},
+	})
+	bs.notifyAuthFailures(accountName, reason, failures)
+}
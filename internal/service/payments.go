@@ -0,0 +1,266 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"stickersbot/internal/client"
+	"stickersbot/internal/config"
+	"stickersbot/internal/notify"
+	"stickersbot/internal/types"
+)
+
+// DefaultMaxPendingPayments payments queued per wallet by default
+const DefaultMaxPendingPayments = 3
+
+// DefaultPaymentValidity time a created order can be paid by default
+const DefaultPaymentValidity = 5 * time.Minute
+
+// maxPendingPayments returns number of payments queued per wallet at most
+func maxPendingPayments(cfg *config.Config) int {
+	if cfg.MaxPendingPayments > 0 {
+		return cfg.MaxPendingPayments
+	}
+	return DefaultMaxPendingPayments
+}
+
+// paymentValidity returns time a created order can still be paid
+func paymentValidity(cfg *config.Config) time.Duration {
+	if cfg.PaymentValiditySeconds > 0 {
+		return time.Duration(cfg.PaymentValiditySeconds) * time.Second
+	}
+	return DefaultPaymentValidity
+}
+
+// payment created order handed over to wallet transaction queue
+type payment struct {
+	account config.Account
+	order   *client.BuyStickersResponse
+	target  config.PurchaseTarget // Ordered collection and character
+	label   string                // Log prefix of the purchase, e.g. "Snipe 'main'"
+
+	// Order amount expected from detected price and its allowed rise in percent (0 - not checked)
+	detectedAmount   int64
+	tolerancePercent float64
+
+	// done is called after the transaction result is reconciled (optional)
+	done func(txResult *client.TransactionResult, paid bool)
+}
+
+// payOrder queues payment of created order and reconciles its result in background,
+// so the caller can create the next order while the transaction confirms.
+// Caller reserves payment slot of the account wallet, it is released once the payment is reconciled
+func (bs *BuyerService) payOrder(p payment) {
+	done := p.done
+	p.done = func(txResult *client.TransactionResult, paid bool) {
+		bs.releasePaymentSlot(p.account)
+		if done != nil {
+			done(txResult, paid)
+		}
+	}
+
+	// Order waiting longer than its payment validity is dropped instead of being paid late
+	if !p.order.RespondedAt.IsZero() {
+		p.order.PayBefore = p.order.RespondedAt.Add(paymentValidity(bs.config))
+	}
+
+	// Price may change between detection and order creation, quote above limit is not paid
+	if err := quoteRefusal(p); err != nil {
+		bs.addStats(p.account.Name, types.Counters{FailedRequests: 1, Errors: types.ErrorCounters{PriceTooHigh: 1}})
+		bs.log(fmt.Sprintf("🚫 %s: Order %s refused: %v", p.label, p.order.OrderID, err))
+		bs.fireError(ErrorEvent{Account: p.account.Name, Stage: StagePayment, Target: p.target, Order: p.order, Err: err})
+		bs.recordOrder(p, nil, types.OrderRefused, err)
+		p.done(nil, false)
+		return
+	}
+
+	// The same order must never be paid twice, whatever path handed it over
+	if !bs.orderGuard.ClaimPayment(p.order.OrderID) {
+		bs.log(fmt.Sprintf("🛡️ %s: Order %s is already being paid, duplicate payment refused", p.label, p.order.OrderID))
+		p.done(nil, false)
+		return
+	}
+
+	if !p.order.PayBefore.IsZero() && time.Now().After(p.order.PayBefore) {
+		bs.reconcilePayment(p, nil, fmt.Errorf("order payment validity expired before transfer"))
+		return
+	}
+
+	results, err := bs.client.PayOrderAsync(p.order, p.account.SeedPhrase, bs.config.TestMode, bs.config.TestAddress,
+		p.account.UseProxy, p.account.TONProxyURL)
+	if err != nil {
+		bs.reconcilePayment(p, nil, err)
+		return
+	}
+
+	bs.log(fmt.Sprintf("🧾 %s: Order %s created, payment queued", p.label, p.order.OrderID))
+	bs.firePaymentSent(PaymentEvent{Account: p.account.Name, Target: p.target, Order: p.order})
+
+	bs.trackPayment(p.account.Name, 1)
+	go func() {
+		defer bs.trackPayment(p.account.Name, -1)
+
+		txResult := <-results
+		if txResult.Expired {
+			bs.reconcilePayment(p, txResult, fmt.Errorf("order payment validity expired before transfer"))
+			return
+		}
+		if !txResult.Success {
+			bs.reconcilePayment(p, txResult, fmt.Errorf("transaction failed"))
+			return
+		}
+		bs.reconcilePayment(p, txResult, nil)
+	}()
+}
+
+// quoteRefusal checks order quote against max price of target and against price seen at
+// detection with its tolerance. Returns why the order must not be paid, nil if it may be
+func quoteRefusal(p payment) error {
+	if maxPrice := p.account.MaxOrderPrice(p.target.Collection, p.target.Character); maxPrice > 0 && p.order.TotalAmount > maxPrice {
+		return fmt.Errorf("order quoted %.4f TON, above max price %.4f TON",
+			float64(p.order.TotalAmount)/1000000000, float64(maxPrice)/1000000000)
+	}
+	if p.detectedAmount > 0 {
+		limit := p.detectedAmount + int64(float64(p.detectedAmount)*p.tolerancePercent/100)
+		if p.order.TotalAmount > limit {
+			return fmt.Errorf("order quoted %.4f TON, above detected price %.4f TON (tolerance %g%%)",
+				float64(p.order.TotalAmount)/1000000000, float64(p.detectedAmount)/1000000000, p.tolerancePercent)
+		}
+	}
+	return nil
+}
+
+// trackPayment adds payment waiting for result (negative delta removes it)
+func (bs *BuyerService) trackPayment(accountName string, delta int) {
+	bs.paymentsMu.Lock()
+	defer bs.paymentsMu.Unlock()
+
+	bs.paymentsPending[accountName] += delta
+	if bs.paymentsPending[accountName] <= 0 {
+		delete(bs.paymentsPending, accountName)
+	}
+}
+
+// reservePaymentSlot reserves place for order payment in the backlog of account wallet.
+// Returns false if the backlog is full and no order should be created
+func (bs *BuyerService) reservePaymentSlot(account config.Account) bool {
+	bs.paymentsMu.Lock()
+	defer bs.paymentsMu.Unlock()
+
+	limit := maxPendingPayments(bs.config)
+	if bs.walletPayments[account.SeedPhrase] >= limit {
+		if !bs.walletFull[account.SeedPhrase] {
+			bs.walletFull[account.SeedPhrase] = true
+			bs.log(fmt.Sprintf("⏸️ '%s': %d payments of wallet are pending, new orders wait for them", account.Name, limit))
+		}
+		return false
+	}
+
+	bs.walletPayments[account.SeedPhrase]++
+	return true
+}
+
+// releasePaymentSlot frees place reserved in the backlog of account wallet
+func (bs *BuyerService) releasePaymentSlot(account config.Account) {
+	bs.paymentsMu.Lock()
+	defer bs.paymentsMu.Unlock()
+
+	bs.walletPayments[account.SeedPhrase]--
+	if bs.walletPayments[account.SeedPhrase] <= 0 {
+		delete(bs.walletPayments, account.SeedPhrase)
+	}
+	if bs.walletFull[account.SeedPhrase] {
+		delete(bs.walletFull, account.SeedPhrase)
+		bs.log(fmt.Sprintf("▶️ '%s': Wallet payment backlog freed, orders are created again", account.Name))
+	}
+}
+
+// pendingPaymentCounts returns number of payments waiting for result per account
+func (bs *BuyerService) pendingPaymentCounts() map[string]int {
+	bs.paymentsMu.Lock()
+	defer bs.paymentsMu.Unlock()
+
+	counts := make(map[string]int, len(bs.paymentsPending))
+	for name, count := range bs.paymentsPending {
+		counts[name] = count
+	}
+	return counts
+}
+
+// reconcilePayment updates statistics and transaction log with payment result
+func (bs *BuyerService) reconcilePayment(p payment, txResult *client.TransactionResult, err error) {
+	if err != nil {
+		bs.addStats(p.account.Name, failedRequest(p.order, err))
+		bs.log(fmt.Sprintf("❌ %s: Payment of order %s failed: %v", p.label, p.order.OrderID, err))
+		bs.recordOrder(p, txResult, types.OrderPaymentFailed, err)
+		bs.fireError(ErrorEvent{Account: p.account.Name, Stage: StagePayment, Target: p.target, Order: p.order, Err: err})
+		bs.notifier.Send(notify.Event{
+			Type:     notify.EventPaymentFailed,
+			Severity: notify.SeverityWarning,
+			Account:  p.account.Name,
+			Title:    "❌ Payment failed",
+			Message:  fmt.Sprintf("Order %s (%d:%d): %v", p.order.OrderID, p.target.Collection, p.target.Character, err),
+			Fields: map[string]interface{}{
+				"order_id":      p.order.OrderID,
+				"collection_id": p.target.Collection,
+				"character_id":  p.target.Character,
+			},
+		})
+		if p.done != nil {
+			p.done(txResult, false)
+		}
+		return
+	}
+
+	bs.addStats(p.account.Name, types.Counters{SentTransactions: 1, SpentNano: txResult.Amount})
+
+	bs.log(fmt.Sprintf("💰 %s: Transaction sent!", p.label))
+	bs.log(fmt.Sprintf("   📤 From address: %s", txResult.FromAddress))
+	bs.log(fmt.Sprintf("   📥 To address: %s", txResult.ToAddress))
+	bs.log(fmt.Sprintf("   💰 Amount: %.9f TON", float64(txResult.Amount)/1000000000))
+	bs.log(fmt.Sprintf("   🔗 Order ID: %s", p.order.OrderID))
+	bs.log(fmt.Sprintf("   🆔 Transaction ID: %s", txResult.TransactionID))
+
+	bs.logTransaction(&types.TransactionLog{
+		Timestamp:     time.Now(),
+		AccountName:   p.account.Name,
+		OrderID:       p.order.OrderID,
+		Collection:    p.target.Collection,
+		Character:     p.target.Character,
+		Items:         p.account.Count,
+		OrderAmount:   p.order.TotalAmount,
+		Amount:        txResult.Amount,
+		Currency:      p.order.Currency,
+		FromAddress:   txResult.FromAddress,
+		ToAddress:     txResult.ToAddress,
+		TransactionID: txResult.TransactionID,
+		TestMode:      bs.config.TestMode,
+	})
+	bs.recordOrder(p, txResult, types.OrderPaid, nil)
+
+	bs.firePaymentConfirmed(PaymentEvent{Account: p.account.Name, Target: p.target, Order: p.order, Transaction: txResult})
+	bs.notifier.Send(notify.Event{
+		Type:     notify.EventPurchase,
+		Severity: notify.SeverityInfo,
+		Account:  p.account.Name,
+		Title:    "💰 Purchase paid",
+		Message: fmt.Sprintf("Order %s (%d:%d), %.4f TON, transaction %s",
+			p.order.OrderID, p.target.Collection, p.target.Character, float64(txResult.Amount)/1000000000, txResult.TransactionID),
+		Fields: map[string]interface{}{
+			"order_id":       p.order.OrderID,
+			"collection_id":  p.target.Collection,
+			"character_id":   p.target.Character,
+			"amount_nano":    txResult.Amount,
+			"transaction_id": txResult.TransactionID,
+		},
+	})
+
+	// Test payments go to test address, nothing is credited for them
+	if bs.config.VerifyInventory && !bs.config.TestMode {
+		go bs.verifyCredit(p)
+	}
+
+	if p.done != nil {
+		p.done(txResult, true)
+	}
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"stickersbot/internal/client"
+	"stickersbot/internal/config"
+)
+
+func TestQuoteRefusal(t *testing.T) {
+	const ton = 1000000000
+
+	tests := []struct {
+		name      string
+		maxPrice  int64
+		detected  int64
+		tolerance float64
+		quoted    int64
+		wantErr   string
+	}{
+		{"no limits", 0, 0, 0, 10 * ton, ""},
+		{"below max price", 5 * ton, 0, 0, 4 * ton, ""},
+		{"above max price", 5 * ton, 0, 0, 6 * ton, "above max price"},
+		{"at detected price", 0, 2 * ton, 0, 2 * ton, ""},
+		{"price rose after detection", 0, 2 * ton, 0, 2*ton + 1, "above detected price"},
+		{"rise within tolerance", 0, 2 * ton, 10, 2.2 * ton, ""},
+		{"rise above tolerance", 0, 2 * ton, 10, 2.2*ton + 1, "above detected price"},
+		{"max price checked first", 3 * ton, 2 * ton, 100, 3.5 * ton, "above max price"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := payment{
+				account:          config.Account{MaxPriceNano: tt.maxPrice},
+				order:            &client.BuyStickersResponse{OrderID: "order", TotalAmount: tt.quoted},
+				detectedAmount:   tt.detected,
+				tolerancePercent: tt.tolerance,
+			}
+			err := quoteRefusal(p)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("quoteRefusal() = %v, want nil", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("quoteRefusal() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSnipeDetectedAmount(t *testing.T) {
+	account := &config.Account{Count: 3, SnipeMonitor: &config.SnipeMonitorConfig{PriceTolerancePercent: 5}}
+
+	if got, want := snipeOrderCost(account, 2000), int64(6000); got != want {
+		t.Errorf("snipeOrderCost() = %d, want %d", got, want)
+	}
+	if got := priceTolerance(account); got != 5 {
+		t.Errorf("priceTolerance() = %g, want 5", got)
+	}
+	if got := priceTolerance(&config.Account{}); got != 0 {
+		t.Errorf("priceTolerance() without snipe monitor = %g, want 0", got)
+	}
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"stickersbot/internal/client"
+	"stickersbot/internal/config"
+)
+
+const (
+	// DefaultProxyQuarantine how long proxy that looks banned gets no requests
+	DefaultProxyQuarantine = 10 * time.Minute
+
+	proxyBanWindow      = 50  // Latest requests of proxy ban detection looks at
+	proxyBanMinRequests = 20  // Fewer requests are not enough to judge
+	proxyBanRatio       = 0.6 // Share of 403 and 429 responses of banned proxy
+)
+
+// proxyWindow results of the latest requests through proxy, true - blocked with 403 or 429
+type proxyWindow struct {
+	results []bool
+	next    int
+	blocked int
+}
+
+// add adds request result and returns share of blocked requests and number of requests in window
+func (w *proxyWindow) add(blocked bool) (float64, int) {
+	if len(w.results) < proxyBanWindow {
+		w.results = append(w.results, blocked)
+	} else {
+		if w.results[w.next] {
+			w.blocked--
+		}
+		w.results[w.next] = blocked
+		w.next = (w.next + 1) % proxyBanWindow
+	}
+	if blocked {
+		w.blocked++
+	}
+	return float64(w.blocked) / float64(len(w.results)), len(w.results)
+}
+
+// ProxyBanDetector quarantines proxies whose latest requests are mostly refused with
+// 403 or 429, the shop has most likely banned or rate limited their IP
+type ProxyBanDetector struct {
+	cooldown    time.Duration
+	windows     map[string]*proxyWindow
+	quarantined map[string]time.Time // Proxy name -> end of quarantine
+	mu          sync.Mutex
+}
+
+// NewProxyBanDetector creates detector quarantining proxies for cooldown, 0 disables quarantine
+func NewProxyBanDetector(cooldown time.Duration) *ProxyBanDetector {
+	return &ProxyBanDetector{
+		cooldown:    cooldown,
+		windows:     make(map[string]*proxyWindow),
+		quarantined: make(map[string]time.Time),
+	}
+}
+
+// record adds result of request through proxy. Returns true if proxy was quarantined by it
+func (d *ProxyBanDetector) record(name string, resp *client.BuyStickersResponse, err error) bool {
+	if d.cooldown <= 0 || name == directConnection || err != nil {
+		return false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.quarantined[name]; ok {
+		return false
+	}
+
+	window, ok := d.windows[name]
+	if !ok {
+		window = &proxyWindow{}
+		d.windows[name] = window
+	}
+
+	ratio, requests := window.add(isBlockedResponse(resp))
+	if requests < proxyBanMinRequests || ratio < proxyBanRatio {
+		return false
+	}
+
+	d.quarantined[name] = time.Now().Add(d.cooldown)
+	delete(d.windows, name)
+	return true
+}
+
+// until returns end of quarantine of proxy, zero time if it is not quarantined
+func (d *ProxyBanDetector) until(name string) time.Time {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	end, ok := d.quarantined[name]
+	if !ok {
+		return time.Time{}
+	}
+	if time.Now().After(end) {
+		// Cooldown is over, proxy starts with clean window
+		delete(d.quarantined, name)
+		return time.Time{}
+	}
+	return end
+}
+
+// isBlockedResponse checks if shop refused request as forbidden or rate limited
+func isBlockedResponse(resp *client.BuyStickersResponse) bool {
+	return resp != nil && (resp.StatusCode == 403 || resp.StatusCode == 429)
+}
+
+// proxyQuarantine returns quarantine of banned proxies, 0 if it is disabled
+func proxyQuarantine(cfg *config.Config) time.Duration {
+	switch {
+	case cfg.ProxyQuarantineMinutes < 0:
+		return 0
+	case cfg.ProxyQuarantineMinutes == 0:
+		return DefaultProxyQuarantine
+	default:
+		return time.Duration(cfg.ProxyQuarantineMinutes) * time.Minute
+	}
+}
+
+// recordProxyResult feeds order result to proxy ban detection
+func (bs *BuyerService) recordProxyResult(account config.Account, resp *client.BuyStickersResponse, err error) {
+	name := proxyName(account)
+	if bs.proxyBans.record(name, resp, err) {
+		bs.log(fmt.Sprintf("🚷 Proxy %s looks banned (most of its latest requests got 403/429), no requests through it for %s",
+			name, bs.proxyBans.cooldown))
+	}
+}
+
+// ProxyQuarantinedUntil returns end of quarantine of proxy, zero time if it is not quarantined
+func (bs *BuyerService) ProxyQuarantinedUntil(name string) time.Time {
+	return bs.proxyBans.until(name)
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"stickersbot/internal/client"
+	"stickersbot/internal/config"
+)
+
+const (
+	// DefaultProxyCheckInterval how often proxies of accounts are checked during run
+	DefaultProxyCheckInterval = 5 * time.Minute
+
+	proxyDialTimeout = 10 * time.Second // Connection to proxy itself
+)
+
+// ProxyStatus result of the last health check of proxy
+type ProxyStatus struct {
+	Name      string        `json:"name"`     // host:port without credentials
+	Accounts  []string      `json:"accounts"` // Accounts using the proxy
+	Alive     bool          `json:"alive"`
+	Latency   time.Duration `json:"latency"` // Time of request through proxy
+	LastError string        `json:"last_error,omitempty"`
+	CheckedAt time.Time     `json:"checked_at"`
+	DeadSince time.Time     `json:"dead_since,omitempty"`
+}
+
+// ProxyChecker checks proxies of accounts by connecting to them and sending a request
+// to the shop API through them. Accounts of dead proxies get no requests until it recovers
+type ProxyChecker struct {
+	statuses map[string]*ProxyStatus
+	mu       sync.RWMutex
+}
+
+// NewProxyChecker creates checker without results
+func NewProxyChecker() *ProxyChecker {
+	return &ProxyChecker{statuses: make(map[string]*ProxyStatus)}
+}
+
+// Statuses returns results of the last checks, dead proxies first
+func (c *ProxyChecker) Statuses() []ProxyStatus {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	items := make([]ProxyStatus, 0, len(c.statuses))
+	for _, status := range c.statuses {
+		items = append(items, *status)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Alive != items[j].Alive {
+			return !items[i].Alive
+		}
+		return items[i].Name < items[j].Name
+	})
+	return items
+}
+
+// dead checks if the last check of proxy failed. Unchecked proxies are not dead
+func (c *ProxyChecker) dead(name string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	status, ok := c.statuses[name]
+	return ok && !status.Alive
+}
+
+// set stores check result and returns status before it (nil on the first check)
+func (c *ProxyChecker) set(status ProxyStatus) *ProxyStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	previous := c.statuses[status.Name]
+	if !status.Alive {
+		status.DeadSince = status.CheckedAt
+		if previous != nil && !previous.Alive {
+			status.DeadSince = previous.DeadSince
+		}
+	}
+	c.statuses[status.Name] = &status
+	return previous
+}
+
+// checkProxy connects to proxy (the first hop of chain) and sends request to the shop API through it
+func checkProxy(proxyURL string) (time.Duration, error) {
+	hops, err := config.ParseProxyChain(proxyURL)
+	if err != nil {
+		return 0, err
+	}
+	conn, err := net.DialTimeout("tcp", hops[0].Address(), proxyDialTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("proxy unreachable: %v", err)
+	}
+	conn.Close()
+
+	httpClient, err := client.NewWithProxy(proxyURL)
+	if err != nil {
+		return 0, err
+	}
+	started := time.Now()
+	if err := httpClient.Warmup(); err != nil {
+		return 0, fmt.Errorf("request through proxy failed: %v", err)
+	}
+	return time.Since(started), nil
+}
+
+// accountProxies returns proxy URLs of accounts and names of accounts using each of them
+func accountProxies(accounts []config.Account) map[string][]string {
+	proxies := make(map[string][]string)
+	for _, account := range accounts {
+		if account.UseProxy && account.ProxyURL != "" {
+			proxies[account.ProxyURL] = append(proxies[account.ProxyURL], account.Name)
+		}
+	}
+	return proxies
+}
+
+// checkProxies checks all proxies of accounts in parallel and logs proxies that died or recovered
+func (bs *BuyerService) checkProxies() {
+	var wg sync.WaitGroup
+	for proxyURL, accounts := range accountProxies(bs.config.Accounts) {
+		wg.Add(1)
+		go func(proxyURL string, accounts []string) {
+			defer wg.Done()
+
+			latency, err := checkProxy(proxyURL)
+			status := ProxyStatus{
+				Name:      proxyName(config.Account{UseProxy: true, ProxyURL: proxyURL}),
+				Accounts:  accounts,
+				Alive:     err == nil,
+				Latency:   latency,
+				CheckedAt: time.Now(),
+			}
+			if err != nil {
+				status.LastError = err.Error()
+			}
+
+			previous := bs.proxies.set(status)
+			bs.proxyScores.record(status.Name, status.Alive, latency)
+			switch {
+			case !status.Alive && (previous == nil || previous.Alive):
+				bs.log(fmt.Sprintf("💀 Proxy %s is dead, accounts %s get no requests until it recovers: %v",
+					status.Name, strings.Join(accounts, ", "), err))
+			case status.Alive && previous != nil && !previous.Alive:
+				bs.log(fmt.Sprintf("💚 Proxy %s recovered (%s)", status.Name, latency.Round(time.Millisecond)))
+			}
+		}(proxyURL, accounts)
+	}
+	wg.Wait()
+}
+
+// runProxyChecks checks proxies periodically until the run stops
+func (bs *BuyerService) runProxyChecks(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			bs.checkProxies()
+		}
+	}
+}
+
+// proxyCheckInterval returns interval of proxy checks, 0 if they are disabled
+func proxyCheckInterval(cfg *config.Config) time.Duration {
+	switch {
+	case cfg.ProxyCheckMinutes < 0:
+		return 0
+	case cfg.ProxyCheckMinutes == 0:
+		return DefaultProxyCheckInterval
+	default:
+		return time.Duration(cfg.ProxyCheckMinutes) * time.Minute
+	}
+}
+
+// startProxyChecks checks proxies before the run and keeps checking them while it works
+func (bs *BuyerService) startProxyChecks(ctx context.Context) {
+	interval := proxyCheckInterval(bs.config)
+	if interval == 0 || len(accountProxies(bs.config.Accounts)) == 0 {
+		return
+	}
+
+	bs.log("🌐 Checking proxies...")
+	bs.checkProxies()
+	alive := 0
+	statuses := bs.proxies.Statuses()
+	for _, status := range statuses {
+		if status.Alive {
+			alive++
+		}
+	}
+	bs.log(fmt.Sprintf("🌐 Proxies alive: %d/%d", alive, len(statuses)))
+
+	go bs.runProxyChecks(ctx, interval)
+}
+
+// proxyUnusable checks if proxy of account failed its last health check or is quarantined as banned
+func (bs *BuyerService) proxyUnusable(account config.Account) bool {
+	name := proxyName(account)
+	if name == directConnection || account.DirectFallback != nil {
+		// Account with direct fallback keeps buying without its proxy
+		return false
+	}
+	return bs.proxies.dead(name) || !bs.proxyBans.until(name).IsZero()
+}
+
+// ProxyHealth returns results of the last proxy checks
+func (bs *BuyerService) ProxyHealth() []ProxyStatus {
+	return bs.proxies.Statuses()
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"stickersbot/internal/client"
+	"stickersbot/internal/config"
+)
+
+const (
+	proxyScoreDecay      = 0.2  // Weight of the newest result, older results fade away
+	proxyScoreMinSuccess = 0.05 // Floor of success rate, so a failing proxy gets a large but finite score
+)
+
+// proxyScore decaying averages of request latency and success of one proxy
+type proxyScore struct {
+	latency float64 // Seconds
+	success float64 // 0..1
+}
+
+// ProxyScores scores proxies by decaying averages of their latency and success.
+// Lower score is better: score is average latency divided by success rate
+type ProxyScores struct {
+	scores map[string]*proxyScore
+	mu     sync.Mutex
+}
+
+// NewProxyScores creates scores without results
+func NewProxyScores() *ProxyScores {
+	return &ProxyScores{scores: make(map[string]*proxyScore)}
+}
+
+// record adds result of request through proxy
+func (s *ProxyScores) record(name string, delivered bool, latency time.Duration) {
+	success := 0.0
+	if delivered {
+		success = 1
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	score, ok := s.scores[name]
+	if !ok {
+		s.scores[name] = &proxyScore{latency: latency.Seconds(), success: success}
+		return
+	}
+	score.latency += proxyScoreDecay * (latency.Seconds() - score.latency)
+	score.success += proxyScoreDecay * (success - score.success)
+}
+
+// Score returns score of proxy, false if it has no results yet
+func (s *ProxyScores) Score(name string) (float64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	score, ok := s.scores[name]
+	if !ok {
+		return 0, false
+	}
+	return score.latency / math.Max(score.success, proxyScoreMinSuccess), true
+}
+
+// deliveredResponse checks if order request went through proxy. Sold out and other
+// refusals of the shop still mean the proxy delivered it
+func deliveredResponse(resp *client.BuyStickersResponse, err error) bool {
+	return err == nil && resp != nil && resp.StatusCode < 500 && !isBlockedResponse(resp)
+}
+
+// accountScore returns score of account proxy for snipe coordinator. Proxy without
+// results scores best, so it gets a request and is measured
+func (bs *BuyerService) accountScore(account *config.Account) float64 {
+	score, ok := bs.proxyScores.Score(proxyName(*account))
+	if !ok {
+		return 0
+	}
+	return score
+}
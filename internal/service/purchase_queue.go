@@ -0,0 +1,181 @@
+package service
+
+import (
+	"container/heap"
+	"fmt"
+	"runtime/debug"
+	"sync"
+
+	"stickersbot/internal/config"
+)
+
+// Priority of purchase job, jobs with higher priority are dispatched first
+type Priority int
+
+// Purchase job priorities
+const (
+	PriorityLoop  Priority = iota // Routine purchases of account threads
+	PrioritySnipe                 // Snipe hits
+)
+
+// purchaseJob purchase waiting in queue
+type purchaseJob struct {
+	priority Priority
+	seq      uint64 // Submission order, keeps jobs of equal priority FIFO
+	run      func()
+	done     chan struct{}
+}
+
+// jobHeap priority queue of purchase jobs
+type jobHeap []*purchaseJob
+
+func (h jobHeap) Len() int { return len(h) }
+func (h jobHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	return h[i].seq < h[j].seq
+}
+func (h jobHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *jobHeap) Push(x interface{}) { *h = append(*h, x.(*purchaseJob)) }
+func (h *jobHeap) Pop() interface{} {
+	old := *h
+	job := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return job
+}
+
+// walletLane jobs of one wallet and their dispatchers
+type walletLane struct {
+	jobs jobHeap
+	cond *sync.Cond
+}
+
+// PurchaseQueue central queue of purchases. Every wallet has its own lane served
+// by a fixed number of dispatchers, so snipe hits overtake routine purchases
+// waiting for the same wallet and a busy wallet never delays other wallets
+type PurchaseQueue struct {
+	lanes   map[string]*walletLane
+	seq     uint64
+	closed  bool
+	running sync.WaitGroup // Jobs being executed by dispatchers
+	mu      sync.Mutex
+
+	// OnPanic is called when a job panics, the dispatcher goes on with next jobs (optional)
+	OnPanic func(value interface{}, stack []byte)
+}
+
+// NewPurchaseQueue creates queue with lanes for wallets of accounts.
+// Dispatchers of a wallet match the concurrency its accounts had before:
+// threads of regular accounts (upper bound of autoscaled ones) plus parallel orders of snipe accounts
+func NewPurchaseQueue(accounts []config.Account) *PurchaseQueue {
+	dispatchers := make(map[string]int)
+	for _, account := range accounts {
+		count := maxThreads(account)
+		if account.SnipeMonitor != nil && account.SnipeMonitor.Enabled {
+			count = max(1, account.SnipeMonitor.ParallelOrders)
+		}
+		dispatchers[walletKey(account)] += count
+	}
+
+	q := &PurchaseQueue{lanes: make(map[string]*walletLane)}
+	for wallet, count := range dispatchers {
+		lane := &walletLane{cond: sync.NewCond(&q.mu)}
+		q.lanes[wallet] = lane
+		for i := 0; i < max(1, count); i++ {
+			go q.dispatch(lane)
+		}
+	}
+	return q
+}
+
+// newPurchaseQueue creates queue for accounts of configuration that logs crashed jobs
+func (bs *BuyerService) newPurchaseQueue() *PurchaseQueue {
+	q := NewPurchaseQueue(bs.config.Accounts)
+	q.OnPanic = func(value interface{}, stack []byte) {
+		bs.log(fmt.Sprintf("💥 Purchase job crashed: %v\n%s", value, stack))
+	}
+	return q
+}
+
+// walletKey returns lane of account: accounts sharing seed phrase pay from one wallet
+func walletKey(account config.Account) string {
+	if account.SeedPhrase != "" {
+		return "seed:" + account.SeedPhrase
+	}
+	return "account:" + account.Name
+}
+
+// Submit enqueues purchase of account. Returned channel is closed when the purchase
+// has finished or was dropped because the queue is closed
+func (q *PurchaseQueue) Submit(account config.Account, priority Priority, run func()) <-chan struct{} {
+	job := &purchaseJob{priority: priority, run: run, done: make(chan struct{})}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	lane, ok := q.lanes[walletKey(account)]
+	if q.closed || !ok {
+		close(job.done)
+		return job.done
+	}
+
+	q.seq++
+	job.seq = q.seq
+	heap.Push(&lane.jobs, job)
+	lane.cond.Signal()
+	return job.done
+}
+
+// Close stops dispatchers, jobs still waiting are dropped
+func (q *PurchaseQueue) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.closed = true
+	for _, lane := range q.lanes {
+		for _, job := range lane.jobs {
+			close(job.done)
+		}
+		lane.jobs = nil
+		lane.cond.Broadcast()
+	}
+}
+
+// Wait waits until jobs already being executed finish. Call after Close
+func (q *PurchaseQueue) Wait() {
+	q.running.Wait()
+}
+
+// dispatch runs jobs of lane, highest priority first
+func (q *PurchaseQueue) dispatch(lane *walletLane) {
+	for {
+		q.mu.Lock()
+		for len(lane.jobs) == 0 && !q.closed {
+			lane.cond.Wait()
+		}
+		if q.closed {
+			q.mu.Unlock()
+			return
+		}
+		job := heap.Pop(&lane.jobs).(*purchaseJob)
+		q.running.Add(1)
+		q.mu.Unlock()
+
+		q.runJob(job)
+	}
+}
+
+// runJob runs job and marks it done even if it panics, so its waiters never hang
+func (q *PurchaseQueue) runJob(job *purchaseJob) {
+	defer q.running.Done()
+	defer close(job.done)
+	defer func() {
+		if r := recover(); r != nil && q.OnPanic != nil {
+			q.OnPanic(r, debug.Stack())
+		}
+	}()
+
+	job.run()
+}
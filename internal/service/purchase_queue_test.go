@@ -0,0 +1,125 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"stickersbot/internal/config"
+)
+
+// waitDone fails test if done is not closed in time
+func waitDone(t *testing.T, done <-chan struct{}, what string) {
+	t.Helper()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("%s did not finish", what)
+	}
+}
+
+func TestPurchaseQueueOrder(t *testing.T) {
+	account := config.Account{Name: "main", Threads: 1}
+
+	tests := []struct {
+		name       string
+		priorities []Priority
+		want       []string
+	}{
+		{"fifo within priority", []Priority{PriorityLoop, PriorityLoop, PriorityLoop}, []string{"0", "1", "2"}},
+		{"snipe overtakes loop", []Priority{PriorityLoop, PrioritySnipe, PriorityLoop, PrioritySnipe}, []string{"1", "3", "0", "2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := NewPurchaseQueue([]config.Account{account})
+			defer q.Close()
+
+			// The only dispatcher is busy until all jobs are queued
+			release := make(chan struct{})
+			started := make(chan struct{})
+			blocker := q.Submit(account, PriorityLoop, func() {
+				close(started)
+				<-release
+			})
+			<-started
+
+			var mu sync.Mutex
+			var order []string
+			var dones []<-chan struct{}
+			for i, priority := range tt.priorities {
+				id := fmt.Sprint(i)
+				dones = append(dones, q.Submit(account, priority, func() {
+					mu.Lock()
+					order = append(order, id)
+					mu.Unlock()
+				}))
+			}
+			close(release)
+
+			waitDone(t, blocker, "blocking job")
+			for i, done := range dones {
+				waitDone(t, done, fmt.Sprintf("job %d", i))
+			}
+			if fmt.Sprint(order) != fmt.Sprint(tt.want) {
+				t.Errorf("jobs ran in order %v, want %v", order, tt.want)
+			}
+		})
+	}
+}
+
+func TestPurchaseQueueRecoversPanic(t *testing.T) {
+	account := config.Account{Name: "main", Threads: 1}
+	q := NewPurchaseQueue([]config.Account{account})
+	defer q.Close()
+
+	panics := make(chan interface{}, 1)
+	q.OnPanic = func(value interface{}, stack []byte) {
+		panics <- value
+	}
+
+	waitDone(t, q.Submit(account, PrioritySnipe, func() { panic("boom") }), "panicking job")
+	select {
+	case value := <-panics:
+		if value != "boom" {
+			t.Errorf("OnPanic got %v, want boom", value)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("OnPanic was not called")
+	}
+
+	// Dispatcher survives the panic
+	ran := false
+	waitDone(t, q.Submit(account, PriorityLoop, func() { ran = true }), "job after panic")
+	if !ran {
+		t.Error("job after panic did not run")
+	}
+}
+
+func TestPurchaseQueueClose(t *testing.T) {
+	account := config.Account{Name: "main", Threads: 1}
+	q := NewPurchaseQueue([]config.Account{account})
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	running := q.Submit(account, PriorityLoop, func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	ran := false
+	waiting := q.Submit(account, PriorityLoop, func() { ran = true })
+	q.Close()
+	waitDone(t, waiting, "dropped job")
+
+	waitDone(t, q.Submit(account, PriorityLoop, func() { ran = true }), "job submitted after close")
+	waitDone(t, q.Submit(config.Account{Name: "unknown"}, PriorityLoop, func() { ran = true }), "job of unknown account")
+
+	close(release)
+	waitDone(t, running, "running job")
+	q.Wait()
+	if ran {
+		t.Error("dropped job ran")
+	}
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultSnipeCooldown is used when snipe_monitor.cooldown_seconds is not set
+const DefaultSnipeCooldown = 10 * time.Minute
+
+// purchaseEntry state of a single collection:character purchase for one account
+type purchaseEntry struct {
+	inFlight    bool      // Purchase is being executed right now
+	purchasedAt time.Time // Time of the last successful purchase
+}
+
+// PurchaseRegistry remembers which collection:character pairs were already bought
+// (or are being bought) by each account, so that duplicate snipe matches coming
+// from different detection paths don't produce extra orders
+type PurchaseRegistry struct {
+	entries map[string]*purchaseEntry // key - "account:collectionID:characterID"
+	mu      sync.Mutex
+}
+
+// NewPurchaseRegistry creates an empty purchase registry
+func NewPurchaseRegistry() *PurchaseRegistry {
+	return &PurchaseRegistry{
+		entries: make(map[string]*purchaseEntry),
+	}
+}
+
+// registryKey builds registry key for account and collection:character pair
+func registryKey(accountName string, collectionID, characterID int) string {
+	return fmt.Sprintf("%s:%d:%d", accountName, collectionID, characterID)
+}
+
+// TryAcquire reserves collection:character for the account.
+// Returns false if the pair is already being purchased or was purchased within cooldown
+func (r *PurchaseRegistry) TryAcquire(accountName string, collectionID, characterID int, cooldown time.Duration) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := registryKey(accountName, collectionID, characterID)
+	if entry, exists := r.entries[key]; exists {
+		if entry.inFlight {
+			return false
+		}
+		if !entry.purchasedAt.IsZero() && time.Since(entry.purchasedAt) < cooldown {
+			return false
+		}
+	}
+
+	r.entries[key] = &purchaseEntry{inFlight: true}
+	return true
+}
+
+// Release finishes reservation made by TryAcquire.
+// Successful purchases start the cooldown, failed ones free the pair for the next match
+func (r *PurchaseRegistry) Release(accountName string, collectionID, characterID int, purchased bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := registryKey(accountName, collectionID, characterID)
+	if !purchased {
+		delete(r.entries, key)
+		return
+	}
+
+	r.entries[key] = &purchaseEntry{purchasedAt: time.Now()}
+}
+
+// purchased returns purchase times by key. Purchases in flight are included
+// as bought now, their result is not known yet
+func (r *PurchaseRegistry) purchased() map[string]time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := make(map[string]time.Time, len(r.entries))
+	for key, entry := range r.entries {
+		if entry.inFlight {
+			result[key] = time.Now()
+		} else {
+			result[key] = entry.purchasedAt
+		}
+	}
+	return result
+}
+
+// restore adds purchases saved by previous run
+func (r *PurchaseRegistry) restore(purchased map[string]time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, purchasedAt := range purchased {
+		r.entries[key] = &purchaseEntry{purchasedAt: purchasedAt}
+	}
+}
+
+// Reset clears all registry entries
+func (r *PurchaseRegistry) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = make(map[string]*purchaseEntry)
+}
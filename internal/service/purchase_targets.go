@@ -0,0 +1,108 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"stickersbot/internal/config"
+	"stickersbot/internal/monitor"
+)
+
+// errSoldOut returned when ordered character is sold out
+var errSoldOut = errors.New("character is sold out")
+
+// TargetList ordered purchase targets of account shared by its threads.
+// When current target is sold out, the next one is used
+type TargetList struct {
+	targets []config.PurchaseTarget
+	index   int
+	mu      sync.Mutex
+}
+
+// NewTargetList creates target list starting from the first target
+func NewTargetList(targets []config.PurchaseTarget) *TargetList {
+	return &TargetList{
+		targets: targets,
+	}
+}
+
+// Current returns current target and its position. Returns false if all targets are sold out
+func (tl *TargetList) Current() (config.PurchaseTarget, int, bool) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	if tl.index >= len(tl.targets) {
+		return config.PurchaseTarget{}, tl.index, false
+	}
+	return tl.targets[tl.index], tl.index, true
+}
+
+// setPosition moves list to target at position, used when resuming a run
+func (tl *TargetList) setPosition(position int) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	tl.index = position
+}
+
+// SoldOut marks target at position as sold out and returns the target to use next.
+// Threads reporting the same target switch the list only once
+func (tl *TargetList) SoldOut(position int) (config.PurchaseTarget, bool) {
+	tl.mu.Lock()
+	if position == tl.index {
+		tl.index++
+	}
+	tl.mu.Unlock()
+
+	target, _, ok := tl.Current()
+	return target, ok
+}
+
+// newPurchaseTargets creates target lists of all accounts: primary target with fallbacks
+// for direct purchase, only fallbacks for snipe monitor
+func newPurchaseTargets(cfg *config.Config) map[string]*TargetList {
+	targets := make(map[string]*TargetList)
+	for i := range cfg.Accounts {
+		account := &cfg.Accounts[i]
+		if account.SnipeMonitor != nil && account.SnipeMonitor.Enabled {
+			targets[account.Name] = NewTargetList(account.Fallbacks)
+		} else {
+			targets[account.Name] = NewTargetList(account.PurchaseTargets())
+		}
+	}
+	return targets
+}
+
+// performSnipeFallback buys fallback targets of account in order after the matched
+// character turned out to be sold out
+func (bs *BuyerService) performSnipeFallback(account *config.Account, request monitor.PurchaseRequest) (bool, error) {
+	targets := bs.purchaseTargets[account.Name]
+	if targets == nil {
+		return false, errSoldOut
+	}
+
+	soldOut := request
+	for {
+		target, position, ok := targets.Current()
+		if !ok {
+			return false, errSoldOut
+		}
+
+		bs.log(fmt.Sprintf("↪️ Snipe '%s': Collection %d, Character %d sold out, buying fallback Collection %d, Character %d",
+			account.Name, soldOut.CollectionID, soldOut.CharacterID, target.Collection, target.Character))
+
+		fallback := request
+		fallback.CollectionID = target.Collection
+		fallback.CharacterID = target.Character
+		fallback.Name = fmt.Sprintf("fallback %d:%d", target.Collection, target.Character)
+		fallback.Price = 0 // Price of fallback is not known, its own max_price_nano limits it
+
+		purchased, err := bs.performSnipePurchase(account.Name, fallback)
+		if !errors.Is(err, errSoldOut) {
+			return purchased, err
+		}
+		targets.SoldOut(position)
+		soldOut = fallback
+	}
+}
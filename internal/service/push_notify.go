@@ -0,0 +1,59 @@
+package service
+
+import (
+	"fmt"
+
+	"stickersbot/internal/config"
+	"stickersbot/internal/notify"
+)
+
+// registerPush registers ntfy and Pushover notifiers of notifications, each receiving
+// events of its types from its minimal severity up
+func (bs *BuyerService) registerPush() {
+	if bs.config.Notifications == nil {
+		return
+	}
+
+	if settings := bs.config.Notifications.Ntfy; settings != nil && settings.Enabled {
+		secrets, err := resolveSecrets(settings.Topic, settings.Token)
+		if err != nil {
+			bs.log(fmt.Sprintf("⚠️ notifications.ntfy: %v, skipped", err))
+		} else {
+			bs.registerSeverity("ntfy", settings.MinSeverity, settings.Events,
+				notify.NewNtfy(settings.Server, secrets[0], secrets[1]))
+		}
+	}
+
+	if settings := bs.config.Notifications.Pushover; settings != nil && settings.Enabled {
+		secrets, err := resolveSecrets(settings.AppToken, settings.UserKey)
+		if err != nil {
+			bs.log(fmt.Sprintf("⚠️ notifications.pushover: %v, skipped", err))
+		} else {
+			bs.registerSeverity("pushover", settings.MinSeverity, settings.Events,
+				notify.NewPushover(secrets[0], secrets[1]))
+		}
+	}
+}
+
+// registerSeverity registers notifier receiving events of types from severity up
+func (bs *BuyerService) registerSeverity(name, minSeverity string, events []string, notifier notify.Notifier) {
+	severity, err := notify.ParseSeverity(minSeverity)
+	if err != nil {
+		bs.log(fmt.Sprintf("⚠️ notifications.%s: %v, skipped", name, err))
+		return
+	}
+	bs.notifier.Register(name, notify.Only(events, notify.AtLeast(severity, notifier)))
+}
+
+// resolveSecrets returns values of secret references in the same order
+func resolveSecrets(values ...string) ([]string, error) {
+	resolved := make([]string, len(values))
+	for i, value := range values {
+		secret, err := config.ResolveSecret(value)
+		if err != nil {
+			return nil, err
+		}
+		resolved[i] = secret
+	}
+	return resolved, nil
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"stickersbot/internal/client"
+	"stickersbot/internal/config"
+)
+
+// Retry defaults
+const (
+	defaultRetryBackoff    = 200 * time.Millisecond
+	defaultRetryMaxBackoff = 5 * time.Second
+
+	// Dedupe window of account retrying network errors without its own window
+	defaultNetworkRetryDedupe = 5 * time.Second
+)
+
+// retryPolicy retry settings of account purchase requests
+type retryPolicy struct {
+	attempts   int
+	backoff    time.Duration
+	maxBackoff time.Duration
+	retryOn    map[string]bool
+}
+
+// newRetryPolicy creates policy from account configuration, nil disables retries
+func newRetryPolicy(cfg *config.RetryConfig) retryPolicy {
+	if cfg == nil || cfg.MaxAttempts <= 1 {
+		return retryPolicy{attempts: 1}
+	}
+
+	policy := retryPolicy{
+		attempts:   cfg.MaxAttempts,
+		backoff:    time.Duration(cfg.BackoffMs) * time.Millisecond,
+		maxBackoff: time.Duration(cfg.MaxBackoffMs) * time.Millisecond,
+		retryOn:    make(map[string]bool),
+	}
+	if policy.backoff <= 0 {
+		policy.backoff = defaultRetryBackoff
+	}
+	if policy.maxBackoff <= 0 {
+		policy.maxBackoff = defaultRetryMaxBackoff
+	}
+
+	// Network errors are not retried by default: the shop may have created the order
+	// before the connection failed, and the retry would create a second one
+	classes := cfg.RetryOn
+	if len(classes) == 0 {
+		classes = []string{config.RetryOnRateLimit, config.RetryOnServer}
+	}
+	for _, class := range classes {
+		policy.retryOn[class] = true
+	}
+	return policy
+}
+
+// retryClass returns class of failed order request that may be repeated,
+// empty string if the request succeeded or must not be repeated
+func retryClass(resp *client.BuyStickersResponse, err error) string {
+	if errors.Is(err, errDuplicateOrder) {
+		return ""
+	}
+	if err != nil {
+		// Request without response may still have created the order, it is retried only
+		// when account opted in and then the dedupe window guards it
+		if resp == nil {
+			return config.RetryOnNetwork
+		}
+		return ""
+	}
+
+	switch {
+	case resp.StatusCode == 429:
+		return config.RetryOnRateLimit
+	case resp.StatusCode >= 500:
+		return config.RetryOnServer
+	}
+	return ""
+}
+
+// dedupeWindow returns minimum time between orders of the same target by account. Account
+// retrying network errors always has a window, so an ambiguous failure is not ordered again at once
+func dedupeWindow(account config.Account) time.Duration {
+	if account.OrderDedupeWindowMs > 0 {
+		return time.Duration(account.OrderDedupeWindowMs) * time.Millisecond
+	}
+	if newRetryPolicy(account.Retry).retryOn[config.RetryOnNetwork] {
+		return defaultNetworkRetryDedupe
+	}
+	return 0
+}
+
+// orderWithRetry executes order request repeating it on retryable errors by account retry policy
+func (bs *BuyerService) orderWithRetry(account config.Account, request func() (*client.BuyStickersResponse, error)) (*client.BuyStickersResponse, error) {
+	policy := newRetryPolicy(account.Retry)
+	delay := policy.backoff
+
+	for attempt := 1; ; attempt++ {
+		resp, err := request()
+
+		class := retryClass(resp, err)
+		if class == "" || !policy.retryOn[class] || attempt >= policy.attempts {
+			return resp, err
+		}
+
+		bs.addStats(account.Name, failedRequest(resp, err))
+		bs.log(fmt.Sprintf("🔁 '%s': %s error, retry %d/%d in %s", account.Name, class, attempt, policy.attempts-1, delay))
+
+		time.Sleep(delay)
+		delay = min(delay*2, policy.maxBackoff)
+	}
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"stickersbot/internal/config"
+)
+
+func TestDedupeWindow(t *testing.T) {
+	tests := []struct {
+		name    string
+		account config.Account
+		want    time.Duration
+	}{
+		{"no retry", config.Account{}, 0},
+		{"default classes", config.Account{Retry: &config.RetryConfig{MaxAttempts: 3}}, 0},
+		{"network retried", config.Account{Retry: &config.RetryConfig{MaxAttempts: 3, RetryOn: []string{config.RetryOnNetwork}}}, defaultNetworkRetryDedupe},
+		{"network without attempts", config.Account{Retry: &config.RetryConfig{MaxAttempts: 1, RetryOn: []string{config.RetryOnNetwork}}}, 0},
+		{"own window", config.Account{OrderDedupeWindowMs: 800, Retry: &config.RetryConfig{MaxAttempts: 3, RetryOn: []string{config.RetryOnNetwork}}}, 800 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dedupeWindow(tt.account); got != tt.want {
+				t.Errorf("dedupeWindow() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultRetryClassesSkipNetwork(t *testing.T) {
+	policy := newRetryPolicy(&config.RetryConfig{MaxAttempts: 3})
+	if policy.retryOn[config.RetryOnNetwork] {
+		t.Error("network errors are retried by default")
+	}
+	if !policy.retryOn[config.RetryOnRateLimit] || !policy.retryOn[config.RetryOnServer] {
+		t.Errorf("default classes = %v, want 429 and 5xx", policy.retryOn)
+	}
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"stickersbot/internal/config"
+	"stickersbot/internal/monitor"
+	"stickersbot/internal/types"
+)
+
+// RunSnapshotFile file with state of the running task, removed when the task stops normally.
+// Its presence at startup means the previous run crashed
+const RunSnapshotFile = "run_snapshot.json"
+
+// snapshotInterval how often state of running task is saved
+const snapshotInterval = 10 * time.Second
+
+// RunSnapshot state of running task needed to continue it after a crash
+type RunSnapshot struct {
+	SavedAt    time.Time        `json:"saved_at"`
+	Statistics types.Statistics `json:"statistics"`
+
+	// Snipe purchases per account, payments waiting for result are counted as done
+	SnipeTransactions map[string]int   `json:"snipe_transactions"`
+	SnipeSpentNano    map[string]int64 `json:"snipe_spent_nano"`
+
+	// Transactions of purchase threads by "account/slot", pending payments included
+	WorkerTransactions map[string]int `json:"worker_transactions"`
+
+	// Position in primary and fallback targets per account
+	TargetPositions map[string]int `json:"target_positions"`
+
+	// Bought snipe targets by "account:collection:character"
+	Purchased map[string]time.Time `json:"purchased"`
+
+	// Payments whose result was not known when the snapshot was taken, per account
+	PendingPayments map[string]int `json:"pending_payments,omitempty"`
+
+	// Known collections of snipe monitors per account
+	Monitors map[string]monitor.KnownState `json:"monitors,omitempty"`
+}
+
+// workerKey key of purchase thread in snapshot
+func workerKey(accountName string, slot int) string {
+	return fmt.Sprintf("%s/%d", accountName, slot)
+}
+
+// LoadRunSnapshot returns snapshot left by a run that didn't stop normally, nil if there is none
+func LoadRunSnapshot() (*RunSnapshot, error) {
+	data, err := os.ReadFile(config.StatePath(RunSnapshotFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot RunSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("parsing %s: %v", RunSnapshotFile, err)
+	}
+	return &snapshot, nil
+}
+
+// DiscardRunSnapshot removes snapshot of previous run
+func DiscardRunSnapshot() error {
+	err := os.Remove(config.StatePath(RunSnapshotFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// ResumeRun makes the next Start continue the run of snapshot: counters, targets,
+// bought items and known collections are restored instead of starting from zero
+func (bs *BuyerService) ResumeRun(snapshot *RunSnapshot) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	bs.resume = snapshot
+}
+
+// applyResume restores state of snapshot passed to ResumeRun. Called by Start after
+// the state of a new run is initialized, must be called with bs.mu held
+func (bs *BuyerService) applyResume() *RunSnapshot {
+	snapshot := bs.resume
+	bs.resume = nil
+	if snapshot == nil {
+		return nil
+	}
+
+	bs.statistics.restore(snapshot.Statistics)
+
+	bs.snipeCountersMu.Lock()
+	for name, count := range snapshot.SnipeTransactions {
+		bs.snipeTransactionCounters[name] = count
+	}
+	for name, spent := range snapshot.SnipeSpentNano {
+		bs.snipeSpent[name] = spent
+	}
+	bs.snipeCountersMu.Unlock()
+
+	for name, position := range snapshot.TargetPositions {
+		if targets := bs.purchaseTargets[name]; targets != nil {
+			targets.setPosition(position)
+		}
+	}
+	bs.purchaseRegistry.restore(snapshot.Purchased)
+
+	bs.log(fmt.Sprintf("♻️ Resuming run started %s (snapshot of %s)",
+		snapshot.Statistics.StartTime.Format("2006-01-02 15:04:05"), snapshot.SavedAt.Format("15:04:05")))
+	for name, count := range snapshot.PendingPayments {
+		bs.log(fmt.Sprintf("⚠️ Account '%s': %d payments were in progress at crash, counted as done. Check transaction history", name, count))
+	}
+	return snapshot
+}
+
+// takeSnapshot collects state of running task
+func (bs *BuyerService) takeSnapshot() *RunSnapshot {
+	snapshot := &RunSnapshot{
+		SavedAt:            time.Now(),
+		Statistics:         *bs.GetStatistics(),
+		SnipeTransactions:  make(map[string]int),
+		SnipeSpentNano:     make(map[string]int64),
+		WorkerTransactions: make(map[string]int),
+		TargetPositions:    make(map[string]int),
+		Purchased:          bs.purchaseRegistry.purchased(),
+		PendingPayments:    bs.pendingPaymentCounts(),
+		Monitors:           make(map[string]monitor.KnownState),
+	}
+
+	bs.snipeCountersMu.RLock()
+	for name, count := range bs.snipeTransactionCounters {
+		snapshot.SnipeTransactions[name] = count + bs.snipePending[name]
+	}
+	for name, spent := range bs.snipeSpent {
+		snapshot.SnipeSpentNano[name] = spent + bs.snipePendingNano[name]
+	}
+	for name, count := range bs.snipePending {
+		if _, ok := bs.snipeTransactionCounters[name]; !ok {
+			snapshot.SnipeTransactions[name] = count
+			snapshot.SnipeSpentNano[name] = bs.snipePendingNano[name]
+		}
+	}
+	bs.snipeCountersMu.RUnlock()
+
+	bs.mu.RLock()
+	for name, targets := range bs.purchaseTargets {
+		_, position, _ := targets.Current()
+		snapshot.TargetPositions[name] = position
+	}
+	for _, worker := range bs.workers {
+		worker.mu.RLock()
+		snapshot.WorkerTransactions[workerKey(worker.account.Name, worker.slot)] = worker.transactionCount + worker.pendingPayments
+		worker.mu.RUnlock()
+	}
+	for _, snipeMonitor := range bs.snipeMonitors {
+		snapshot.Monitors[snipeMonitor.GetAccountName()] = snipeMonitor.KnownState()
+	}
+	bs.mu.RUnlock()
+
+	return snapshot
+}
+
+// saveSnapshots saves state of running task until the run stops
+func (bs *BuyerService) saveSnapshots(ctx context.Context) {
+	ticker := time.NewTicker(snapshotInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if bs.State() != StateRunning {
+				continue
+			}
+			data, err := json.Marshal(bs.takeSnapshot())
+			if err == nil {
+				err = config.WriteFileAtomic(config.StatePath(RunSnapshotFile), data, 0644)
+			}
+			if err != nil {
+				bs.log(fmt.Sprintf("⚠️ Failed to save run snapshot: %v", err))
+			}
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"stickersbot/internal/types"
+)
+
+// runStats statistics of current run. Has its own lock, so counting requests of
+// every worker never waits for Start, Stop or other users of bs.mu
+type runStats struct {
+	mu    sync.Mutex
+	stats types.Statistics
+}
+
+// newRunStats creates empty statistics
+func newRunStats() *runStats {
+	return &runStats{stats: types.Statistics{Accounts: make(map[string]*types.Counters)}}
+}
+
+// reset starts statistics of new run
+func (r *runStats) reset(startTime time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.stats = types.Statistics{
+		StartTime: startTime,
+		Accounts:  make(map[string]*types.Counters),
+	}
+}
+
+// restore replaces statistics with those of resumed run
+func (r *runStats) restore(stats types.Statistics) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.stats = copyStatistics(stats)
+}
+
+// add adds counters to global statistics and statistics of account
+func (r *runStats) add(accountName string, delta types.Counters) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.stats.Add(delta)
+
+	account, ok := r.stats.Accounts[accountName]
+	if !ok {
+		account = &types.Counters{}
+		r.stats.Accounts[accountName] = account
+	}
+	account.Add(delta)
+}
+
+// account returns counters of account, nil if it made no requests
+func (r *runStats) account(accountName string) *types.Counters {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	counters, ok := r.stats.Accounts[accountName]
+	if !ok {
+		return nil
+	}
+	copied := *counters
+	return &copied
+}
+
+// snapshot returns copy of statistics. Duration and rate are calculated up to now
+// if the run is still active
+func (r *runStats) snapshot(active bool) types.Statistics {
+	r.mu.Lock()
+	stats := copyStatistics(r.stats)
+	r.mu.Unlock()
+
+	if active {
+		setDuration(&stats, time.Since(stats.StartTime))
+	}
+	return stats
+}
+
+// finish fixes duration and rate of finished run and returns its statistics
+func (r *runStats) finish(latencies []types.LatencyStats) types.Statistics {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	setDuration(&r.stats, time.Since(r.stats.StartTime))
+	r.stats.Latencies = latencies
+	return copyStatistics(r.stats)
+}
+
+// copyStatistics returns copy of statistics not sharing account counters
+func copyStatistics(stats types.Statistics) types.Statistics {
+	accounts := make(map[string]*types.Counters, len(stats.Accounts))
+	for name, counters := range stats.Accounts {
+		copied := *counters
+		accounts[name] = &copied
+	}
+	stats.Accounts = accounts
+	return stats
+}
+
+// setDuration sets duration of run and its request rate
+func setDuration(stats *types.Statistics, duration time.Duration) {
+	stats.Duration = duration
+	if duration.Seconds() > 0 {
+		stats.RequestsPerSec = float64(stats.TotalRequests) / duration.Seconds()
+	}
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+
+	"stickersbot/internal/config"
+	"stickersbot/internal/monitor"
+)
+
+// Snipe strategies for accounts with enabled snipe monitor
+const (
+	StrategyIndependent     = "independent"      // Every account buys every match on its own
+	StrategyRoundRobin      = "round_robin"      // Each match is bought by the next account in turn
+	StrategySplitCharacters = "split_characters" // Characters of one collection are spread across accounts
+	StrategyFastestProxy    = "fastest_proxy"    // Each match is bought by the account with the fastest healthy proxy
+)
+
+// SnipeCoordinator distributes snipe matches across accounts so that
+// accounts don't all hammer the same item
+type SnipeCoordinator struct {
+	strategy string
+	accounts []*config.Account // Accounts taking part in coordinated sniping
+	canBuy   func(account *config.Account, request monitor.PurchaseRequest) bool
+	score    func(account *config.Account) float64 // Proxy score of account, lower is better
+
+	claimed         map[string]string // "collectionID:characterID" -> assigned account
+	nextAccount     int               // Round robin position
+	collectionTurns map[int]int       // Collection ID -> number of characters already assigned
+	mu              sync.Mutex
+}
+
+// NewSnipeCoordinator creates coordinator for snipe accounts of configuration
+func NewSnipeCoordinator(cfg *config.Config, canBuy func(account *config.Account, request monitor.PurchaseRequest) bool, score func(account *config.Account) float64) *SnipeCoordinator {
+	strategy := cfg.SnipeStrategy
+	if strategy == "" {
+		strategy = StrategyIndependent
+	}
+
+	var accounts []*config.Account
+	for i := range cfg.Accounts {
+		account := &cfg.Accounts[i]
+		if account.SnipeMonitor != nil && account.SnipeMonitor.Enabled && !account.SnipeMonitor.WatchOnly {
+			accounts = append(accounts, account)
+		}
+	}
+
+	return &SnipeCoordinator{
+		strategy:        strategy,
+		accounts:        accounts,
+		canBuy:          canBuy,
+		score:           score,
+		claimed:         make(map[string]string),
+		collectionTurns: make(map[int]int),
+	}
+}
+
+// ValidateStrategy checks that snipe strategy name is known
+func ValidateStrategy(strategy string) error {
+	switch strategy {
+	case "", StrategyIndependent, StrategyRoundRobin, StrategySplitCharacters, StrategyFastestProxy:
+		return nil
+	}
+	return fmt.Errorf("unknown snipe_strategy %q (expected %s, %s, %s or %s)",
+		strategy, StrategyIndependent, StrategyRoundRobin, StrategySplitCharacters, StrategyFastestProxy)
+}
+
+// Strategy returns used strategy
+func (c *SnipeCoordinator) Strategy() string {
+	return c.strategy
+}
+
+// Assign decides which account buys the match reported by detector account.
+// Returns false if the match was already assigned after detection by another account
+func (c *SnipeCoordinator) Assign(detector *config.Account, request monitor.PurchaseRequest) (*config.Account, bool) {
+	if c.strategy == StrategyIndependent || detector.SnipeMonitor.WatchOnly {
+		return detector, true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := fmt.Sprintf("%d:%d", request.CollectionID, request.CharacterID)
+	if _, exists := c.claimed[key]; exists {
+		return nil, false
+	}
+
+	candidates := c.candidates(request)
+	if len(candidates) == 0 {
+		return detector, true
+	}
+
+	var assigned *config.Account
+	switch c.strategy {
+	case StrategyRoundRobin:
+		assigned = candidates[c.nextAccount%len(candidates)]
+		c.nextAccount++
+	case StrategySplitCharacters:
+		turn := c.collectionTurns[request.CollectionID]
+		assigned = candidates[turn%len(candidates)]
+		c.collectionTurns[request.CollectionID] = turn + 1
+	case StrategyFastestProxy:
+		assigned = c.fastest(candidates)
+	default:
+		assigned = detector
+	}
+
+	c.claimed[key] = assigned.Name
+	return assigned, true
+}
+
+// fastest returns candidate with the best proxy score. Candidates with equal score
+// (e.g. not measured yet) take turns, so every proxy gets measured. Must be called with c.mu held
+func (c *SnipeCoordinator) fastest(candidates []*config.Account) *config.Account {
+	start := c.nextAccount % len(candidates)
+	c.nextAccount++
+
+	best := candidates[start]
+	if c.score == nil {
+		return best
+	}
+	bestScore := c.score(best)
+	for i := 1; i < len(candidates); i++ {
+		account := candidates[(start+i)%len(candidates)]
+		if score := c.score(account); score < bestScore {
+			best, bestScore = account, score
+		}
+	}
+	return best
+}
+
+// Unassign frees the match so it can be assigned again (after failed purchase)
+func (c *SnipeCoordinator) Unassign(request monitor.PurchaseRequest) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.claimed, fmt.Sprintf("%d:%d", request.CollectionID, request.CharacterID))
+}
+
+// candidates returns accounts that can still buy the match (transaction limit
+// and budget are not exhausted). Must be called with c.mu held
+func (c *SnipeCoordinator) candidates(request monitor.PurchaseRequest) []*config.Account {
+	var candidates []*config.Account
+	for _, account := range c.accounts {
+		if c.canBuy == nil || c.canBuy(account, request) {
+			candidates = append(candidates, account)
+		}
+	}
+	return candidates
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"fmt"
+
+	"stickersbot/internal/monitor"
+)
+
+// GetSnipeFilters returns current filters of account snipe monitor
+func (bs *BuyerService) GetSnipeFilters(accountName string) (monitor.FilterSettings, error) {
+	if snipeMonitor := bs.findSnipeMonitor(accountName); snipeMonitor != nil {
+		return snipeMonitor.Filters(), nil
+	}
+
+	for _, account := range bs.config.Accounts {
+		if account.Name == accountName && account.SnipeMonitor != nil {
+			return monitor.FilterSettingsFromConfig(account.SnipeMonitor), nil
+		}
+	}
+
+	return monitor.FilterSettings{}, fmt.Errorf("account '%s' has no snipe monitor", accountName)
+}
+
+// UpdateSnipeFilters changes filters of account snipe monitor. Running monitor
+// uses new filters from its next check, configuration is updated as well
+func (bs *BuyerService) UpdateSnipeFilters(accountName string, settings monitor.FilterSettings) error {
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+
+	if snipeMonitor := bs.findSnipeMonitor(accountName); snipeMonitor != nil {
+		if err := snipeMonitor.UpdateFilters(settings); err != nil {
+			return err
+		}
+		bs.log(fmt.Sprintf("✏️ Snipe '%s': Filters updated (%s)", accountName, settings))
+		return nil
+	}
+
+	for i := range bs.config.Accounts {
+		account := &bs.config.Accounts[i]
+		if account.Name == accountName && account.SnipeMonitor != nil {
+			settings.Apply(account.SnipeMonitor)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("account '%s' has no snipe monitor", accountName)
+}
+
+// findSnipeMonitor returns running snipe monitor of account
+func (bs *BuyerService) findSnipeMonitor(accountName string) *monitor.SnipeMonitor {
+	bs.mu.RLock()
+	defer bs.mu.RUnlock()
+
+	for _, snipeMonitor := range bs.snipeMonitors {
+		if snipeMonitor.GetAccountName() == accountName {
+			return snipeMonitor
+		}
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+
+	"stickersbot/internal/types"
+)
+
+// SpendReportFile file spend report is exported to
+const SpendReportFile = "spend_report.csv"
+
+// SpendLine spend of one account or collection
+type SpendLine struct {
+	Name         string
+	Transactions int
+	Items        int
+	SpentNano    int64 // Sent by transactions, fees included
+	FeesNano     int64 // Sent above order prices
+
+	pricedNano  int64 // Prices of orders logged with price and item count
+	pricedItems int
+}
+
+// add adds transaction to line
+func (l *SpendLine) add(tx types.TransactionLog) {
+	l.Transactions++
+	l.Items += tx.Items
+	l.SpentNano += tx.Amount
+
+	// Transactions logged by older versions have no order price
+	if tx.OrderAmount > 0 {
+		l.FeesNano += tx.Amount - tx.OrderAmount
+		if tx.Items > 0 {
+			l.pricedNano += tx.OrderAmount
+			l.pricedItems += tx.Items
+		}
+	}
+}
+
+// AveragePriceNano returns average price of one item without fees, 0 if unknown
+func (l SpendLine) AveragePriceNano() int64 {
+	if l.pricedItems == 0 {
+		return 0
+	}
+	return l.pricedNano / int64(l.pricedItems)
+}
+
+// SpendReport spend of logged transactions per account and per collection
+type SpendReport struct {
+	Total            SpendLine
+	Accounts         []SpendLine // Ordered by account name
+	Collections      []SpendLine // Ordered by collection ID, unknown collection last
+	TestTransactions int         // Transactions sent in test mode, included in the report
+}
+
+// BuildSpendReport aggregates transactions into spend report
+func BuildSpendReport(transactions []types.TransactionLog) SpendReport {
+	report := SpendReport{Total: SpendLine{Name: "total"}}
+	accounts := make(map[string]*SpendLine)
+	collections := make(map[int]*SpendLine)
+
+	for _, tx := range transactions {
+		report.Total.add(tx)
+		if tx.TestMode {
+			report.TestTransactions++
+		}
+
+		account, ok := accounts[tx.AccountName]
+		if !ok {
+			account = &SpendLine{Name: tx.AccountName}
+			accounts[tx.AccountName] = account
+		}
+		account.add(tx)
+
+		collection, ok := collections[tx.Collection]
+		if !ok {
+			collection = &SpendLine{Name: collectionName(tx.Collection)}
+			collections[tx.Collection] = collection
+		}
+		collection.add(tx)
+	}
+
+	for _, line := range accounts {
+		report.Accounts = append(report.Accounts, *line)
+	}
+	sort.Slice(report.Accounts, func(i, j int) bool {
+		return report.Accounts[i].Name < report.Accounts[j].Name
+	})
+
+	ids := make([]int, 0, len(collections))
+	for id := range collections {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		// Transactions without collection (older logs) go last
+		if ids[i] == 0 || ids[j] == 0 {
+			return ids[j] == 0 && ids[i] != 0
+		}
+		return ids[i] < ids[j]
+	})
+	for _, id := range ids {
+		report.Collections = append(report.Collections, *collections[id])
+	}
+
+	return report
+}
+
+// collectionName returns report name of collection, 0 means it was not logged
+func collectionName(id int) string {
+	if id == 0 {
+		return "unknown"
+	}
+	return fmt.Sprintf("collection %d", id)
+}
+
+// WriteCSV writes report as CSV: total line, then accounts and collections
+func (r SpendReport) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"group", "name", "transactions", "items", "spent_ton", "fees_ton", "avg_price_ton"}); err != nil {
+		return err
+	}
+
+	rows := []struct {
+		group string
+		lines []SpendLine
+	}{
+		{"total", []SpendLine{r.Total}},
+		{"account", r.Accounts},
+		{"collection", r.Collections},
+	}
+	for _, row := range rows {
+		for _, line := range row.lines {
+			record := []string{
+				row.group,
+				line.Name,
+				strconv.Itoa(line.Transactions),
+				strconv.Itoa(line.Items),
+				formatNanoTON(line.SpentNano),
+				formatNanoTON(line.FeesNano),
+				formatNanoTON(line.AveragePriceNano()),
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// ExportSpendReport writes report to CSV file
+func ExportSpendReport(path string, report SpendReport) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating spend report: %v", err)
+	}
+	defer file.Close()
+
+	if err := report.WriteCSV(file); err != nil {
+		return fmt.Errorf("error writing spend report: %v", err)
+	}
+	return nil
+}
+
+// formatNanoTON formats nanotons as TON with full precision
+func formatNanoTON(nano int64) string {
+	return fmt.Sprintf("%.9f", float64(nano)/1000000000)
+}
@@ -0,0 +1,257 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"stickersbot/internal/monitor"
+	"stickersbot/internal/types"
+
+	_ "modernc.org/sqlite"
+)
+
+// sqliteSchema tables of sqlite storage. Transactions and found collections keep queried
+// fields in columns and the whole record as JSON
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS tokens (
+	key          TEXT PRIMARY KEY,
+	token        TEXT NOT NULL,
+	expires_at   INTEGER NOT NULL,
+	is_valid     INTEGER NOT NULL,
+	last_check   INTEGER NOT NULL,
+	refreshed_at INTEGER NOT NULL,
+	app          TEXT NOT NULL DEFAULT ''
+);
+CREATE TABLE IF NOT EXISTS transactions (
+	id             INTEGER PRIMARY KEY AUTOINCREMENT,
+	timestamp      INTEGER NOT NULL,
+	account_name   TEXT NOT NULL,
+	order_id       TEXT NOT NULL,
+	collection     INTEGER NOT NULL,
+	character      INTEGER NOT NULL,
+	amount         INTEGER NOT NULL,
+	transaction_id TEXT NOT NULL,
+	test_mode      INTEGER NOT NULL,
+	credit         TEXT NOT NULL DEFAULT '',
+	data           TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS transactions_account ON transactions (account_name, timestamp);
+CREATE INDEX IF NOT EXISTS transactions_order ON transactions (order_id);
+CREATE TABLE IF NOT EXISTS order_records (
+	id           INTEGER PRIMARY KEY AUTOINCREMENT,
+	timestamp    INTEGER NOT NULL,
+	account_name TEXT NOT NULL,
+	order_id     TEXT NOT NULL,
+	result       TEXT NOT NULL,
+	data         TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS order_records_order ON order_records (order_id);
+CREATE TABLE IF NOT EXISTS found_collections (
+	id            INTEGER PRIMARY KEY AUTOINCREMENT,
+	found_at      INTEGER NOT NULL,
+	account_name  TEXT NOT NULL,
+	collection_id INTEGER NOT NULL,
+	character_id  INTEGER NOT NULL,
+	price_nano    INTEGER NOT NULL,
+	data          TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS found_collections_account ON found_collections (account_name, found_at);
+`
+
+// sqliteStorage keeps all data in one SQLite database. Writes are transactional,
+// so a crash never leaves a half-written record
+type sqliteStorage struct {
+	db *sql.DB
+}
+
+// openSQLiteStorage opens database at path, creating it and its tables if needed
+func openSQLiteStorage(path string) (*sqliteStorage, error) {
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %v", path, err)
+	}
+	// One connection serializes writers of the process
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("creating tables in %s: %v", path, err)
+	}
+	return &sqliteStorage{db: db}, nil
+}
+
+func (s *sqliteStorage) LoadTokens() (map[string]*TokenInfo, error) {
+	rows, err := s.db.Query(`SELECT key, token, expires_at, is_valid, last_check, refreshed_at, app FROM tokens`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := make(map[string]*TokenInfo)
+	for rows.Next() {
+		var key string
+		var info TokenInfo
+		var expiresAt, lastCheck, refreshedAt int64
+		if err := rows.Scan(&key, &info.Token, &expiresAt, &info.IsValid, &lastCheck, &refreshedAt, &info.App); err != nil {
+			return nil, err
+		}
+		info.ExpiresAt = unixTime(expiresAt)
+		info.LastCheck = unixTime(lastCheck)
+		info.RefreshedAt = unixTime(refreshedAt)
+		tokens[key] = &info
+	}
+	return tokens, rows.Err()
+}
+
+func (s *sqliteStorage) SaveTokens(tokens map[string]*TokenInfo, changed map[string]bool) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM tokens`); err != nil {
+		return err
+	}
+	for key, info := range tokens {
+		_, err := tx.Exec(`INSERT INTO tokens (key, token, expires_at, is_valid, last_check, refreshed_at, app) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			key, info.Token, unixMilli(info.ExpiresAt), info.IsValid, unixMilli(info.LastCheck), unixMilli(info.RefreshedAt), info.App)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *sqliteStorage) AddTransaction(txLog *types.TransactionLog) error {
+	if txLog.Update {
+		_, err := s.db.Exec(`UPDATE transactions SET credit = ? WHERE order_id = ?`, txLog.Credit, txLog.OrderID)
+		return err
+	}
+
+	data, err := json.Marshal(txLog)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`INSERT INTO transactions (timestamp, account_name, order_id, collection, character, amount, transaction_id, test_mode, credit, data)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		unixMilli(txLog.Timestamp), txLog.AccountName, txLog.OrderID, txLog.Collection, txLog.Character,
+		txLog.Amount, txLog.TransactionID, txLog.TestMode, txLog.Credit, string(data))
+	return err
+}
+
+func (s *sqliteStorage) LoadTransactions(limit int) ([]types.TransactionLog, error) {
+	query := `SELECT credit, data FROM transactions ORDER BY id DESC`
+	var args []interface{}
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transactions []types.TransactionLog
+	for rows.Next() {
+		var credit, data string
+		if err := rows.Scan(&credit, &data); err != nil {
+			return nil, err
+		}
+		var tx types.TransactionLog
+		if err := json.Unmarshal([]byte(data), &tx); err != nil {
+			continue // Skip damaged records
+		}
+		tx.Credit = credit
+		transactions = append(transactions, tx)
+	}
+	return transactions, rows.Err()
+}
+
+func (s *sqliteStorage) AddOrderRecord(record types.OrderRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`INSERT INTO order_records (timestamp, account_name, order_id, result, data) VALUES (?, ?, ?, ?, ?)`,
+		unixMilli(record.Timestamp), record.AccountName, record.OrderID, record.Result, string(data))
+	return err
+}
+
+func (s *sqliteStorage) FindOrderRecords(orderID string) ([]types.OrderRecord, error) {
+	rows, err := s.db.Query(`SELECT data FROM order_records WHERE order_id = ? ORDER BY id`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []types.OrderRecord
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var record types.OrderRecord
+		if err := json.Unmarshal([]byte(data), &record); err != nil {
+			continue
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+func (s *sqliteStorage) AddFoundCollection(item monitor.FoundCollection) error {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`INSERT INTO found_collections (found_at, account_name, collection_id, character_id, price_nano, data) VALUES (?, ?, ?, ?, ?, ?)`,
+		unixMilli(item.FoundAt), item.AccountName, item.ID, item.CharacterID, item.PriceNano, string(data))
+	return err
+}
+
+func (s *sqliteStorage) LoadFoundCollections() ([]monitor.FoundCollection, error) {
+	rows, err := s.db.Query(`SELECT data FROM found_collections ORDER BY found_at, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var collections []monitor.FoundCollection
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var item monitor.FoundCollection
+		if err := json.Unmarshal([]byte(data), &item); err != nil {
+			continue
+		}
+		collections = append(collections, item)
+	}
+	return collections, rows.Err()
+}
+
+func (s *sqliteStorage) Close() error {
+	return s.db.Close()
+}
+
+// unixMilli returns time as Unix milliseconds, 0 for zero time
+func unixMilli(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
+
+// unixTime returns time of Unix milliseconds, zero time for 0
+func unixTime(ms int64) time.Time {
+	if ms == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"stickersbot/internal/client"
+
+	"stickersbot/internal/config"
+)
+
+// DefaultStartLead how long before start_at the token is refreshed and connection opened
+const DefaultStartLead = 30 * time.Second
+
+// paymentWarmupTimeout limits connecting to TON network before start
+const paymentWarmupTimeout = 20 * time.Second
+
+// countdownMarks remaining times logged before scheduled start
+var countdownMarks = []time.Duration{
+	10 * time.Minute, 5 * time.Minute, time.Minute, 30 * time.Second, 10 * time.Second,
+	5 * time.Second, 4 * time.Second, 3 * time.Second, 2 * time.Second, time.Second,
+}
+
+// accountStartTime returns scheduled start of account purchases, zero if it is not in the future
+func accountStartTime(account config.Account) time.Time {
+	if account.StartAt == "" {
+		return time.Time{}
+	}
+
+	// Format is checked when configuration is loaded
+	startAt, err := config.ParseTime(account.StartAt)
+	if err != nil || !startAt.After(time.Now()) {
+		return time.Time{}
+	}
+	return startAt
+}
+
+// scheduleAccountStart logs countdown to scheduled start of account and prepares
+// the account shortly before it: refreshes token and opens connection to the API
+func (bs *BuyerService) scheduleAccountStart(ctx context.Context, account config.Account, startAt time.Time) {
+	bs.log(fmt.Sprintf("⏰ Account '%s': purchases start at %s (in %s)",
+		account.Name, startAt.Format("2006-01-02 15:04:05"), time.Until(startAt).Truncate(time.Second)))
+
+	prepared := false
+	for _, mark := range countdownMarks {
+		if mark < DefaultStartLead && !prepared {
+			if !sleepUntil(ctx, startAt.Add(-DefaultStartLead)) {
+				return
+			}
+			bs.prepareAccountStart(account)
+			prepared = true
+		}
+
+		if time.Until(startAt) <= mark {
+			continue
+		}
+		if !sleepUntil(ctx, startAt.Add(-mark)) {
+			return
+		}
+		bs.log(fmt.Sprintf("⏳ Account '%s': start in %s", account.Name, mark))
+	}
+
+	if sleepUntil(ctx, startAt) {
+		bs.log(fmt.Sprintf("🚀 Account '%s': scheduled start", account.Name))
+	}
+}
+
+// prepareAccountStart refreshes token and opens connections of account before its start
+func (bs *BuyerService) prepareAccountStart(account config.Account) {
+	// Token checked within refresh cooldown is kept as is
+	if _, err := bs.tokenManager.RefreshTokenOnError(account.Name, 0); err != nil {
+		bs.log(fmt.Sprintf("⚠️ Account '%s': token refresh before start failed: %v", account.Name, err))
+	} else {
+		bs.log(fmt.Sprintf("🔑 Account '%s': token ready for start", account.Name))
+	}
+
+	bs.warmupConnections(account)
+}
+
+// warmupConnections opens order connection of account to the API through its proxy and
+// connects its wallet to TON network in parallel, so the first purchase and its payment
+// don't pay for TLS, proxy and liteserver handshakes
+func (bs *BuyerService) warmupConnections(account config.Account) {
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		started := time.Now()
+		httpClient, err := bs.orderClient(account)
+		if err == nil {
+			err = httpClient.Warmup()
+		}
+		if err != nil {
+			bs.log(fmt.Sprintf("⚠️ Account '%s': connection warmup failed: %v", account.Name, err))
+			return
+		}
+		bs.log(fmt.Sprintf("🔌 Account '%s': connection to API opened (%s)", account.Name, time.Since(started).Round(time.Millisecond)))
+	}()
+
+	if account.SeedPhrase != "" {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			started := time.Now()
+			// Same wallet manager and queue are used by payments of account
+			tonClient, err := client.NewTONClientWithProxy(account.SeedPhrase, account.UseProxy, account.TONProxyURL)
+			if err == nil {
+				ctx, cancel := context.WithTimeout(context.Background(), paymentWarmupTimeout)
+				err = tonClient.Warmup(ctx)
+				cancel()
+			}
+			if err != nil {
+				bs.log(fmt.Sprintf("⚠️ Account '%s': TON connection warmup failed: %v", account.Name, err))
+				return
+			}
+			bs.log(fmt.Sprintf("💎 Account '%s': TON connection ready for payments (%s)", account.Name, time.Since(started).Round(time.Millisecond)))
+		}()
+	}
+
+	wg.Wait()
+}
+
+// sleepUntil waits until given time. Returns false if context was cancelled first
+func sleepUntil(ctx context.Context, t time.Time) bool {
+	timer := time.NewTimer(time.Until(t))
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
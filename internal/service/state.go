@@ -0,0 +1,140 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// State lifecycle state of purchase service
+type State int
+
+// Lifecycle states. Service goes Idle -> Starting -> Running -> Draining -> Stopped
+// and may be started again from Stopped
+const (
+	StateIdle     State = iota // Never started
+	StateStarting              // Start in progress
+	StateRunning               // Purchasing and monitoring
+	StateDraining              // Stop in progress, purchases and payments in flight are waited for
+	StateStopped               // Run finished
+)
+
+// Stop reasons reported in StateChange
+const (
+	StopRequested   = "stop requested"
+	StopAllInactive = "all accounts inactive"
+	StopThreadsDone = "all threads completed"
+	StopDeadline    = "run time is over"
+)
+
+// stopStartFailed reason of returning to previous state when start is rejected
+const stopStartFailed = "start failed"
+
+// String returns state name
+func (s State) String() string {
+	switch s {
+	case StateStarting:
+		return "starting"
+	case StateRunning:
+		return "running"
+	case StateDraining:
+		return "draining"
+	case StateStopped:
+		return "stopped"
+	default:
+		return "idle"
+	}
+}
+
+// MarshalText encodes state as its name
+func (s State) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Active checks if a run is in progress in this state
+func (s State) Active() bool {
+	return s == StateStarting || s == StateRunning || s == StateDraining
+}
+
+// StateChange transition of service state
+type StateChange struct {
+	From   State
+	To     State
+	Reason string // Why the run is stopping, set for Draining and Stopped
+	Time   time.Time
+}
+
+// stateSubscriberBuffer changes buffered per subscriber, further changes are dropped for slow subscribers
+const stateSubscriberBuffer = 16
+
+// lifecycle state machine of service. All state changes go through transition,
+// so concurrent start and stop requests can't leave the service in a mixed state
+type lifecycle struct {
+	state       State
+	subscribers map[chan StateChange]struct{}
+	mu          sync.Mutex
+}
+
+// current returns current state
+func (l *lifecycle) current() State {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.state
+}
+
+// transition moves to state if current state is one of from. Returns state before the call
+// and whether the transition happened
+func (l *lifecycle) transition(to State, reason string, from ...State) (State, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	previous := l.state
+	allowed := false
+	for _, state := range from {
+		if state == previous {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return previous, false
+	}
+
+	l.state = to
+	change := StateChange{From: previous, To: to, Reason: reason, Time: time.Now()}
+	for ch := range l.subscribers {
+		select {
+		case ch <- change:
+		default:
+		}
+	}
+	return previous, true
+}
+
+// subscribe returns channel of state changes and function removing the subscription
+func (l *lifecycle) subscribe() (<-chan StateChange, func()) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.subscribers == nil {
+		l.subscribers = make(map[chan StateChange]struct{})
+	}
+	ch := make(chan StateChange, stateSubscriberBuffer)
+	l.subscribers[ch] = struct{}{}
+
+	return ch, func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		delete(l.subscribers, ch)
+	}
+}
+
+// State returns current lifecycle state of the service
+func (bs *BuyerService) State() State {
+	return bs.lifecycle.current()
+}
+
+// SubscribeState returns channel receiving state changes of the service and function
+// cancelling the subscription. Changes are dropped if the channel is not read
+func (bs *BuyerService) SubscribeState() (<-chan StateChange, func()) {
+	return bs.lifecycle.subscribe()
+}
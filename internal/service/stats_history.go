@@ -0,0 +1,110 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"stickersbot/internal/config"
+	"stickersbot/internal/types"
+)
+
+// StatsHistoryFile file where statistics of finished runs are kept
+const StatsHistoryFile = "stats_history.json"
+
+// LoadStatsHistory loads statistics of previous runs, oldest first
+func LoadStatsHistory(path string) ([]types.SessionStatistics, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading statistics history: %v", err)
+	}
+
+	var sessions []types.SessionStatistics
+	if err := json.Unmarshal(data, &sessions); err != nil {
+		return nil, fmt.Errorf("error parsing statistics history: %v", err)
+	}
+	return sessions, nil
+}
+
+// AppendStatsHistory adds statistics of finished run to history file
+func AppendStatsHistory(path string, session types.SessionStatistics) error {
+	sessions, err := LoadStatsHistory(path)
+	if err != nil {
+		return err
+	}
+	sessions = append(sessions, session)
+
+	data, err := json.MarshalIndent(sessions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding statistics history: %v", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("error writing statistics history: %v", err)
+	}
+	return nil
+}
+
+// StatsTotals cumulative statistics of all runs
+type StatsTotals struct {
+	types.Counters
+	Sessions int                        `json:"sessions"`
+	Duration time.Duration              `json:"duration"`
+	FirstRun time.Time                  `json:"first_run"`
+	Accounts map[string]*types.Counters `json:"accounts"`
+}
+
+// AggregateStats sums statistics of all runs
+func AggregateStats(sessions []types.SessionStatistics) StatsTotals {
+	totals := StatsTotals{
+		Sessions: len(sessions),
+		Accounts: make(map[string]*types.Counters),
+	}
+
+	for _, session := range sessions {
+		totals.Add(session.Counters)
+		totals.Duration += session.Duration
+		if totals.FirstRun.IsZero() || session.StartTime.Before(totals.FirstRun) {
+			totals.FirstRun = session.StartTime
+		}
+
+		for name, counters := range session.Accounts {
+			account, ok := totals.Accounts[name]
+			if !ok {
+				account = &types.Counters{}
+				totals.Accounts[name] = account
+			}
+			account.Add(*counters)
+		}
+	}
+
+	return totals
+}
+
+// finishSession fixes duration of current run and saves its statistics to history.
+// Must be called with bs.mu held
+func (bs *BuyerService) finishSession() {
+	if bs.statsSaved || bs.statistics.snapshot(false).StartTime.IsZero() {
+		return
+	}
+	bs.statsSaved = true
+
+	// Run stopped normally, nothing to resume
+	if err := DiscardRunSnapshot(); err != nil {
+		bs.log(fmt.Sprintf("⚠️ Failed to remove run snapshot: %v", err))
+	}
+
+	session := types.SessionStatistics{
+		Statistics: bs.statistics.finish(bs.latency.Stats()),
+		EndTime:    time.Now(),
+	}
+	if err := AppendStatsHistory(config.StatePath(StatsHistoryFile), session); err != nil {
+		bs.log(fmt.Sprintf("⚠️ Failed to save statistics: %v", err))
+		return
+	}
+	bs.log(fmt.Sprintf("💾 Statistics of this run saved to %s", StatsHistoryFile))
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"stickersbot/internal/config"
+	"stickersbot/internal/monitor"
+)
+
+// stockPollInterval how often remaining stock of target bought until sold out is checked
+const stockPollInterval = 5 * time.Second
+
+// trackStock follows remaining stock (Left) of current target of account while it is
+// bought until sold out. When nothing is left, threads switch to the next target
+// without waiting for a sold out order response
+func (bs *BuyerService) trackStock(ctx context.Context, account config.Account) {
+	targets := bs.purchaseTargets[account.Name]
+	if targets == nil {
+		return
+	}
+
+	ticker := time.NewTicker(stockPollInterval)
+	defer ticker.Stop()
+
+	lastPosition, lastLeft := -1, -1
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		target, position, ok := targets.Current()
+		if !ok {
+			return
+		}
+		if !target.UntilSoldOut || bs.IsAccountPaused(account.Name) {
+			continue
+		}
+		if position != lastPosition {
+			lastPosition, lastLeft = position, -1
+		}
+
+		left, err := bs.characterLeft(account, target)
+		if err != nil {
+			bs.log(fmt.Sprintf("⚠️ Account '%s': stock check of Collection %d, Character %d failed: %v",
+				account.Name, target.Collection, target.Character, err))
+			continue
+		}
+		if left != lastLeft {
+			bs.log(fmt.Sprintf("📦 Account '%s': Collection %d, Character %d: %d left",
+				account.Name, target.Collection, target.Character, left))
+			lastLeft = left
+		}
+		if left > 0 {
+			continue
+		}
+
+		next, ok := targets.SoldOut(position)
+		if !ok {
+			bs.log(fmt.Sprintf("🏁 Account '%s': Collection %d, Character %d sold out, no targets left",
+				account.Name, target.Collection, target.Character))
+			return
+		}
+		bs.log(fmt.Sprintf("↪️ Account '%s': Collection %d, Character %d sold out, switching to Collection %d, Character %d",
+			account.Name, target.Collection, target.Character, next.Collection, next.Character))
+	}
+}
+
+// characterLeft returns remaining stock of target character from collection details
+func (bs *BuyerService) characterLeft(account config.Account, target config.PurchaseTarget) (int, error) {
+	token, err := bs.tokenManager.GetValidToken(account.Name)
+	if err != nil {
+		return 0, err
+	}
+
+	httpClient, err := bs.orderClient(account)
+	if err != nil {
+		return 0, err
+	}
+
+	details, err := monitor.NewAPIClient(httpClient).GetCollectionDetails(token, target.Collection)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, character := range details.Data.Characters {
+		if character.ID == target.Character {
+			return character.Left, nil
+		}
+	}
+	return 0, fmt.Errorf("character not found in collection")
+}
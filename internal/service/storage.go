@@ -0,0 +1,239 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"stickersbot/internal/config"
+	"stickersbot/internal/logfile"
+	"stickersbot/internal/monitor"
+	"stickersbot/internal/types"
+)
+
+// TokenStore keeps token cache between runs
+type TokenStore interface {
+	// LoadTokens returns saved token cache by cache key, empty if nothing is saved yet
+	LoadTokens() (map[string]*TokenInfo, error)
+	// SaveTokens saves token cache. changed lists keys replaced or removed (missing in tokens)
+	// since the last save. Shared stores only write changed keys, keeping tokens of other instances
+	SaveTokens(tokens map[string]*TokenInfo, changed map[string]bool) error
+}
+
+// Storage keeps tokens, transactions and found collections between runs
+type Storage interface {
+	TokenStore
+
+	// AddTransaction saves sent transaction. Update records only change Credit of the
+	// transaction with the same OrderID
+	AddTransaction(tx *types.TransactionLog) error
+	// LoadTransactions returns latest transactions, newest first. limit <= 0 returns all
+	LoadTransactions(limit int) ([]types.TransactionLog, error)
+
+	// AddOrderRecord saves raw order request and responses of a purchase
+	AddOrderRecord(record types.OrderRecord) error
+	// FindOrderRecords returns saved records of the order, oldest first
+	FindOrderRecords(orderID string) ([]types.OrderRecord, error)
+
+	// AddFoundCollection saves collection found by snipe monitor
+	AddFoundCollection(item monitor.FoundCollection) error
+	// LoadFoundCollections returns found collections of all accounts, oldest first
+	LoadFoundCollections() ([]monitor.FoundCollection, error)
+
+	Close() error
+}
+
+// Storage types
+const (
+	StorageFiles  = "files"  // tokens.json, transactions.log and found_collections_*.jsonl
+	StorageSQLite = "sqlite" // Single SQLite database
+)
+
+// DefaultSQLitePath database file of sqlite storage
+const DefaultSQLitePath = "stickersbot.db"
+
+// Shared token store types
+const (
+	TokenStoreRedis = "redis" // Hash in Redis
+	TokenStoreVault = "vault" // Secret of HashiCorp Vault KV v2 engine
+)
+
+// DefaultTokenStoreKey Redis hash and Vault secret path of shared tokens
+const DefaultTokenStoreKey = "stickersbot/tokens"
+
+// OpenStorage opens storage selected in config, files unless storage.type is "sqlite".
+// storage.tokens moves tokens to a shared store
+func OpenStorage(cfg *config.Config) (Storage, error) {
+	storage, err := openBaseStorage(cfg)
+	if err != nil || cfg.Storage == nil || cfg.Storage.Tokens == nil {
+		return storage, err
+	}
+
+	tokens, err := openTokenStore(cfg.Storage.Tokens)
+	if err != nil {
+		storage.Close()
+		return nil, fmt.Errorf("token store: %v", err)
+	}
+	return &sharedTokensStorage{Storage: storage, tokens: tokens}, nil
+}
+
+// openBaseStorage opens storage of storage.type
+func openBaseStorage(cfg *config.Config) (Storage, error) {
+	if cfg.Storage == nil || cfg.Storage.Type == "" || cfg.Storage.Type == StorageFiles {
+		return newFileStorage(cfg), nil
+	}
+	if cfg.Storage.Type != StorageSQLite {
+		return nil, fmt.Errorf("unknown storage type %q", cfg.Storage.Type)
+	}
+
+	path := cfg.Storage.Path
+	if path == "" {
+		path = DefaultSQLitePath
+	}
+	return openSQLiteStorage(config.StatePath(path))
+}
+
+// openTokenStore creates shared token store of config
+func openTokenStore(cfg *config.TokenStoreConfig) (TokenStore, error) {
+	key := cfg.Key
+	if key == "" {
+		key = DefaultTokenStoreKey
+	}
+
+	switch cfg.Type {
+	case TokenStoreRedis:
+		return newRedisTokenStore(cfg, key)
+	case TokenStoreVault:
+		return newVaultTokenStore(cfg, key)
+	default:
+		return nil, fmt.Errorf("unknown type %q, expected %q or %q", cfg.Type, TokenStoreRedis, TokenStoreVault)
+	}
+}
+
+// sharedTokensStorage keeps tokens in shared store and everything else in its storage
+type sharedTokensStorage struct {
+	Storage
+	tokens TokenStore
+}
+
+func (s *sharedTokensStorage) LoadTokens() (map[string]*TokenInfo, error) {
+	return s.tokens.LoadTokens()
+}
+
+func (s *sharedTokensStorage) SaveTokens(tokens map[string]*TokenInfo, changed map[string]bool) error {
+	return s.tokens.SaveTokens(tokens, changed)
+}
+
+// fileStorage keeps data in JSON files next to the binary
+type fileStorage struct {
+	options logfile.Options
+
+	mu             sync.Mutex
+	transactionLog *logfile.RotatingFile
+	orderLog       *logfile.RotatingFile
+	collectionLogs map[string]*monitor.CollectionLogger // By account name
+}
+
+// newFileStorage creates file storage, files are opened on first write
+func newFileStorage(cfg *config.Config) *fileStorage {
+	return &fileStorage{
+		options:        LogFileOptions(cfg),
+		collectionLogs: make(map[string]*monitor.CollectionLogger),
+	}
+}
+
+func (s *fileStorage) LoadTokens() (map[string]*TokenInfo, error) {
+	return loadTokenInfo(config.StatePath(TokensFile))
+}
+
+func (s *fileStorage) SaveTokens(tokens map[string]*TokenInfo, changed map[string]bool) error {
+	data, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding token cache: %v", err)
+	}
+	return writeTokensFile(config.StatePath(TokensFile), data)
+}
+
+func (s *fileStorage) AddTransaction(tx *types.TransactionLog) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// File that failed to open before is retried with every transaction
+	if s.transactionLog == nil {
+		logFile, err := logfile.Open(config.StatePath(TransactionLogFile), s.options)
+		if err != nil {
+			return fmt.Errorf("opening transaction log: %v", err)
+		}
+		s.transactionLog = logFile
+	}
+
+	data, err := json.Marshal(tx)
+	if err != nil {
+		return err
+	}
+	if _, err := s.transactionLog.WriteString(string(data) + "\n"); err != nil {
+		return err
+	}
+
+	// Immediately save to disk
+	return s.transactionLog.Sync()
+}
+
+func (s *fileStorage) LoadTransactions(limit int) ([]types.TransactionLog, error) {
+	return LoadTransactions(config.StatePath(TransactionLogFile), limit)
+}
+
+func (s *fileStorage) AddOrderRecord(record types.OrderRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.orderLog == nil {
+		logFile, err := logfile.Open(config.StatePath(OrderLogFile), s.options)
+		if err != nil {
+			return fmt.Errorf("opening order log: %v", err)
+		}
+		s.orderLog = logFile
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if _, err := s.orderLog.WriteString(string(data) + "\n"); err != nil {
+		return err
+	}
+	return s.orderLog.Sync()
+}
+
+func (s *fileStorage) FindOrderRecords(orderID string) ([]types.OrderRecord, error) {
+	return findOrderRecords(config.StatePath(OrderLogFile), orderID)
+}
+
+func (s *fileStorage) AddFoundCollection(item monitor.FoundCollection) error {
+	s.mu.Lock()
+	logger, ok := s.collectionLogs[item.AccountName]
+	if !ok {
+		logger = monitor.NewCollectionLogger(monitor.FoundCollectionsFile(item.AccountName))
+		s.collectionLogs[item.AccountName] = logger
+	}
+	s.mu.Unlock()
+
+	return logger.AddFoundCollection(item)
+}
+
+func (s *fileStorage) LoadFoundCollections() ([]monitor.FoundCollection, error) {
+	return monitor.LoadAllFoundCollections(config.StateDir())
+}
+
+func (s *fileStorage) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.orderLog != nil {
+		s.orderLog.Close()
+	}
+	if s.transactionLog == nil {
+		return nil
+	}
+	return s.transactionLog.Close()
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// Restart budget of crashed worker threads
+const (
+	maxWorkerRestarts  = 5               // Restarts of one thread before it is given up
+	workerRestartDelay = 1 * time.Second // Pause before restart, so a crash loop doesn't spin
+)
+
+// jobPanic panic of purchase job with stack of the goroutine it happened in
+type jobPanic struct {
+	value interface{}
+	stack []byte
+}
+
+// superviseWorker runs worker thread and restarts it after panic until restart budget is used up
+func (bs *BuyerService) superviseWorker(ctx context.Context, wg *sync.WaitGroup, worker *AccountWorker, accountNum int) {
+	defer wg.Done()
+
+	for restarts := 0; ; restarts++ {
+		if !bs.runWorker(ctx, worker, accountNum) {
+			return
+		}
+
+		if restarts >= maxWorkerRestarts {
+			bs.log(fmt.Sprintf("☠️ Thread %d (Account %d '%s'): crashed %d times, not restarted anymore",
+				worker.workerID, accountNum, worker.account.Name, restarts+1))
+			return
+		}
+
+		if !sleepUntil(ctx, time.Now().Add(workerRestartDelay)) || bs.stopping() {
+			return
+		}
+		bs.log(fmt.Sprintf("♻️ Thread %d (Account %d '%s'): restarting after crash (%d/%d)",
+			worker.workerID, accountNum, worker.account.Name, restarts+1, maxWorkerRestarts))
+	}
+}
+
+// runWorker runs worker thread until it finishes. Returns true if it crashed with panic
+func (bs *BuyerService) runWorker(ctx context.Context, worker *AccountWorker, accountNum int) (crashed bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			crashed = true
+			stack := debug.Stack()
+			if job, ok := r.(*jobPanic); ok {
+				r, stack = job.value, job.stack
+			}
+			bs.log(fmt.Sprintf("💥 Thread %d (Account %d '%s') crashed: %v\n%s",
+				worker.workerID, accountNum, worker.account.Name, r, stack))
+		}
+	}()
+
+	bs.accountWorker(ctx, worker, accountNum)
+	return false
+}
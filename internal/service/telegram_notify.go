@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"stickersbot/internal/config"
+	"stickersbot/internal/notify"
+	"stickersbot/internal/telegram"
+)
+
+// defaultTelegramNotifyEvents events sent to notifications.telegram chat unless its events are set
+var defaultTelegramNotifyEvents = []string{
+	string(notify.EventSnipePurchase),
+	string(notify.EventPurchase),
+	string(notify.EventPaymentFailed),
+	string(notify.EventTokenExpired),
+	string(notify.EventAccountDead),
+	string(notify.EventNotCredited),
+}
+
+// registerTelegramNotify registers notifier sending events to chat of notifications.telegram
+// through Bot API, so it works even when Telegram sessions of accounts are broken
+func (bs *BuyerService) registerTelegramNotify() {
+	if bs.config.Notifications == nil {
+		return
+	}
+	settings := bs.config.Notifications.Telegram
+	if settings == nil || !settings.Enabled {
+		return
+	}
+
+	token, err := config.ResolveSecret(settings.BotToken)
+	if err != nil {
+		bs.log(fmt.Sprintf("⚠️ notifications.telegram: bot token: %v, skipped", err))
+		return
+	}
+
+	events := settings.Events
+	if len(events) == 0 {
+		events = defaultTelegramNotifyEvents
+	}
+
+	bot := telegram.NewBotAPI(token)
+	bs.notifier.Register("telegram", notify.Only(events, notify.NotifierFunc(func(ctx context.Context, event notify.Event) error {
+		return bot.SendMessageToChat(ctx, settings.ChatID, event.Severity.Icon()+" "+event.Text())
+	})))
+}
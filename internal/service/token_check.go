@@ -0,0 +1,94 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+
+	"stickersbot/internal/client"
+	"stickersbot/internal/config"
+)
+
+// TokenValidity result of checking account token against the API
+type TokenValidity string
+
+const (
+	TokenValid       TokenValidity = "valid"   // API accepts the token
+	TokenExpired     TokenValidity = "expired" // API rejects the token, it has to be refreshed
+	TokenBanned      TokenValidity = "banned"  // Telegram account is banned or API forbids access
+	TokenMissing     TokenValidity = "missing" // Account has no usable token
+	TokenCheckFailed TokenValidity = "error"   // API could not be reached
+)
+
+// TokenCheck validity of account token
+type TokenCheck struct {
+	Account    string
+	Validity   TokenValidity
+	StatusCode int    // HTTP status of the check request, 0 if it wasn't sent
+	Detail     string // Why token is not valid
+}
+
+// CheckTokens checks token of every account with a cheap authenticated request, so broken
+// authorization is found before the drop. Rejected tokens are marked stale for PreventiveRefresh
+func (bs *BuyerService) CheckTokens() []TokenCheck {
+	checks := make([]TokenCheck, len(bs.config.Accounts))
+
+	var wg sync.WaitGroup
+	for i, account := range bs.config.Accounts {
+		wg.Add(1)
+		go func(i int, account config.Account) {
+			defer wg.Done()
+			checks[i] = bs.checkToken(account)
+		}(i, account)
+	}
+	wg.Wait()
+
+	return checks
+}
+
+// checkToken checks token of one account
+func (bs *BuyerService) checkToken(account config.Account) TokenCheck {
+	check := TokenCheck{Account: account.Name}
+
+	if reason := bs.DeadReason(account.Name); reason != "" {
+		check.Validity = TokenBanned
+		check.Detail = reason
+		return check
+	}
+
+	token, err := bs.tokenManager.GetValidToken(account.Name)
+	if err != nil || token == "" {
+		check.Validity = TokenMissing
+		if err != nil {
+			check.Detail = err.Error()
+		}
+		return check
+	}
+
+	httpClient, err := client.NewForAccount(account.UseProxy, account.ProxyURL)
+	if err != nil {
+		check.Validity = TokenCheckFailed
+		check.Detail = fmt.Sprintf("failed to create HTTP client: %v", err)
+		return check
+	}
+
+	statusCode, accepted, err := httpClient.CheckToken(token)
+	check.StatusCode = statusCode
+	switch {
+	case err != nil:
+		check.Validity = TokenCheckFailed
+		check.Detail = err.Error()
+	case statusCode == 403:
+		check.Validity = TokenBanned
+		check.Detail = "API forbids access to the account"
+	case !accepted:
+		check.Validity = TokenExpired
+		check.Detail = fmt.Sprintf("token rejected with status %d", statusCode)
+		bs.tokenManager.MarkStale(account.Name)
+	case statusCode != 200:
+		check.Validity = TokenCheckFailed
+		check.Detail = fmt.Sprintf("unexpected status code %d", statusCode)
+	default:
+		check.Validity = TokenValid
+	}
+	return check
+}
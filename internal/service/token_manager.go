@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"maps"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -19,6 +21,20 @@ type TokenInfo struct {
 	ExpiresAt time.Time `json:"expires_at"`
 	IsValid   bool      `json:"is_valid"`
 	LastCheck time.Time `json:"last_check"`
+
+	RefreshedAt time.Time `json:"refreshed_at,omitempty"` // When the token was obtained through Telegram
+	App         string    `json:"app,omitempty"`          // Bot of the token if it's not the account's own bot
+}
+
+// refreshReuseWindow time a refreshed token is handed to workers reporting token errors
+// instead of starting another refresh, their requests were sent with the old token
+const refreshReuseWindow = 5 * time.Second
+
+// tokenRefresh token refresh of account in progress, waited for by concurrent callers
+type tokenRefresh struct {
+	done  chan struct{}
+	token string
+	err   error
 }
 
 // TokenManager manages Bearer tokens for accounts with caching
@@ -28,6 +44,32 @@ type TokenManager struct {
 	tokens      map[string]*TokenInfo // key - account name
 	mutex       sync.RWMutex
 	authService *AuthIntegration
+	reauth      map[string]string        // Accounts needing re-authorization, with reason
+	refreshes   map[string]*tokenRefresh // Refreshes in progress by account name
+	storage     Storage                  // Where the cache is persisted
+	dead        map[string]string        // Banned or deactivated accounts, with reason
+	failures    map[string]int           // Failed token refreshes in a row by account name
+
+	// Token cache saves, one writer at a time, guarded by the mutex
+	tokenSaving  bool            // Writer is running
+	tokenChanges map[string]bool // Keys changed since the last save
+
+	// Configuration saves with refreshed tokens, one writer at a time
+	saveMu      sync.Mutex
+	saving      bool // Writer is running
+	savePending bool // Writer has to save once more
+
+	// OnAccountDead is called once when Telegram reports account banned or deactivated
+	OnAccountDead func(accountName, reason string)
+
+	// Per-token refresh timers, armed once StartRefreshScheduler runs
+	schedulerCtx context.Context
+	isIdle       func(accountName string) bool
+	timers       map[string]*time.Timer
+
+	// OnTokenExpired is called when token of account could not be refreshed,
+	// failures - failed refreshes of the account in a row
+	OnTokenExpired func(accountName, reason string, failures int)
 
 	// Cache settings
 	tokenTTL      time.Duration // Token lifetime (default 40 minutes)
@@ -35,21 +77,37 @@ type TokenManager struct {
 }
 
 // NewTokenManager creates a new token manager
-func NewTokenManager(cfg *config.Config) *TokenManager {
+func NewTokenManager(cfg *config.Config, storage Storage) (*TokenManager, error) {
+	httpClient, err := client.New()
+	if err != nil {
+		return nil, err
+	}
+
 	return &TokenManager{
 		config:        cfg,
-		httpClient:    client.New(),
+		httpClient:    httpClient,
 		tokens:        make(map[string]*TokenInfo),
+		reauth:        make(map[string]string),
+		refreshes:     make(map[string]*tokenRefresh),
+		storage:       storage,
+		dead:          make(map[string]string),
+		failures:      make(map[string]int),
+		tokenChanges:  make(map[string]bool),
 		authService:   NewAuthIntegration(cfg),
 		tokenTTL:      40 * time.Minute, // Tokens live ~45 minutes, refresh 5 minutes before expiration
 		checkCooldown: 1 * time.Minute,  // Don't check more often than once per minute
-	}
+	}, nil
 }
 
-// GetCachedToken returns cached token without API check
+// GetCachedToken returns cached token without API check. Valid cached token is read under
+// read lock, so purchase threads don't wait for each other
 func (tm *TokenManager) GetCachedToken(accountName string) (string, error) {
-	tm.mutex.RLock()
-	defer tm.mutex.RUnlock()
+	if token, ok := tm.cachedToken(accountName); ok {
+		return token, nil
+	}
+
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
 
 	// Find account in configuration
 	var account *config.Account
@@ -63,6 +121,9 @@ func (tm *TokenManager) GetCachedToken(accountName string) (string, error) {
 	if account == nil {
 		return "", fmt.Errorf("account %s not found", accountName)
 	}
+	if reason, dead := tm.dead[accountName]; dead {
+		return "", fmt.Errorf("account %s is dead: %s", accountName, reason)
+	}
 
 	// Check cached token
 	if tokenInfo, exists := tm.tokens[accountName]; exists {
@@ -70,9 +131,17 @@ func (tm *TokenManager) GetCachedToken(accountName string) (string, error) {
 		if time.Now().Before(tokenInfo.ExpiresAt) {
 			return tokenInfo.Token, nil
 		}
+		// Expired token is used until the refresh scheduler replaces it, its expiry is kept
+		// so it stays marked stale
+		if tokenInfo.Token == account.AuthToken {
+			return tokenInfo.Token, nil
+		}
 	}
 
 	// If no cache or token expired, return token from configuration
+	if tm.rejectTempToken(accountName, account.AuthToken) {
+		return "", fmt.Errorf("account %s needs re-authorization: %s", accountName, tm.reauth[accountName])
+	}
 	if account.AuthToken != "" {
 		// Update cache with current token
 		tm.tokens[accountName] = &TokenInfo{
@@ -81,53 +150,136 @@ func (tm *TokenManager) GetCachedToken(accountName string) (string, error) {
 			IsValid:   true,
 			LastCheck: time.Now(),
 		}
+		tm.scheduleRefresh(accountName)
 		return account.AuthToken, nil
 	}
 
 	return "", fmt.Errorf("token for account %s is missing", accountName)
 }
 
+// cachedToken returns token of account cached and not expired yet
+func (tm *TokenManager) cachedToken(accountName string) (string, bool) {
+	tm.mutex.RLock()
+	defer tm.mutex.RUnlock()
+
+	if _, dead := tm.dead[accountName]; dead {
+		return "", false
+	}
+	tokenInfo, exists := tm.tokens[accountName]
+	if !exists || !time.Now().Before(tokenInfo.ExpiresAt) {
+		return "", false
+	}
+	return tokenInfo.Token, true
+}
+
+// saveConfig writes configuration with refreshed tokens in background. Saves run one at
+// a time; saves requested meanwhile are merged into one that follows
+func (tm *TokenManager) saveConfig() {
+	tm.saveMu.Lock()
+	tm.savePending = true
+	start := !tm.saving
+	tm.saving = true
+	tm.saveMu.Unlock()
+
+	if start {
+		go tm.writeConfig()
+	}
+}
+
+// writeConfig saves configuration until no save is pending. Configuration is encoded
+// under read lock, so tokens changed meanwhile are never written half way
+func (tm *TokenManager) writeConfig() {
+	for {
+		tm.saveMu.Lock()
+		if !tm.savePending {
+			tm.saving = false
+			tm.saveMu.Unlock()
+			return
+		}
+		tm.savePending = false
+		tm.saveMu.Unlock()
+
+		tm.mutex.RLock()
+		data, err := tm.config.Encode()
+		tm.mutex.RUnlock()
+		if err == nil {
+			err = config.WriteFileAtomic(tm.config.Path(), data, 0644)
+		}
+		if err != nil {
+			log.Printf("⚠️ Failed to save configuration: %v", err)
+		}
+	}
+}
+
 // RefreshTokenOnError refreshes token only when receiving authorization error
 func (tm *TokenManager) RefreshTokenOnError(accountName string, statusCode int) (string, error) {
 	tm.mutex.Lock()
-	defer tm.mutex.Unlock()
+
+	// Banned account is not authorized again and again
+	if reason, dead := tm.dead[accountName]; dead {
+		tm.mutex.Unlock()
+		return "", fmt.Errorf("account %s is dead: %s", accountName, reason)
+	}
 
 	log.Printf("🔄 Refreshing token for %s due to error %d", accountName, statusCode)
 
 	// Check cooldown - don't update too often, BUT ignore cooldown for critical token errors
 	isTokenError := statusCode == 401 || statusCode == 403 || statusCode == 200 // 200 may contain JSON token error
+	if tokenInfo, exists := tm.tokens[accountName]; exists && isTokenError && time.Since(tokenInfo.RefreshedAt) >= refreshReuseWindow {
+		tokenInfo.IsValid = false
+	}
 	if tokenInfo, exists := tm.tokens[accountName]; exists && !isTokenError {
 		if time.Since(tokenInfo.LastCheck) < tm.checkCooldown {
+			tm.mutex.Unlock()
 			log.Printf("⏳ Token refresh too frequent for %s, using cached", accountName)
 			return tokenInfo.Token, nil
 		}
 	}
 
+	// Workers that sent requests with the old token report errors after the refresh finished
+	if tokenInfo, exists := tm.tokens[accountName]; exists && time.Since(tokenInfo.RefreshedAt) < refreshReuseWindow {
+		tm.mutex.Unlock()
+		log.Printf("♻️ Token for %s was refreshed just now, using it", accountName)
+		return tokenInfo.Token, nil
+	}
+	tm.mutex.Unlock()
+
 	// For token errors, always try to refresh
 	if isTokenError {
 		log.Printf("🔑 Critical token error for %s (status %d), forced refresh", accountName, statusCode)
 	}
 
-	// Find account in configuration
-	var account *config.Account
-	var accountIndex int
-	for i, acc := range tm.config.Accounts {
-		if acc.Name == accountName {
-			account = &acc
-			accountIndex = i
-			break
-		}
-	}
+	return tm.refreshOnce(accountName, func() (string, error) {
+		return tm.refreshAfterError(accountName)
+	})
+}
 
-	if account == nil {
-		return "", fmt.Errorf("account %s not found", accountName)
+// refreshAfterError refreshes token through Telegram, falling back to old token
+// if refresh fails and strict auth is off
+func (tm *TokenManager) refreshAfterError(accountName string) (string, error) {
+	account, accountIndex, err := tm.findAccount(accountName)
+	if err != nil {
+		return "", err
 	}
 
-	// Refresh token through Telegram authentication
+	// Refresh token through Telegram authentication, other accounts are not blocked meanwhile
 	log.Printf("🔄 Starting Telegram authentication for %s...", accountName)
-	newToken, err := tm.refreshTokenViaTelegram(account)
+	newToken, err := tm.refreshTokenViaTelegram(&account)
+
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+
 	if err != nil {
 		log.Printf("❌ Error refreshing token for %s: %v", accountName, err)
+		if reason := telegram.DeadAccountReason(err); reason != "" {
+			tm.markDead(accountName, reason)
+			return "", fmt.Errorf("account %s is dead: %s", accountName, reason)
+		}
+		tm.tokenExpired(accountName, err)
+		if tm.config.StrictAuthEnabled() {
+			tm.markNeedsReauth(accountName, err.Error())
+			return "", fmt.Errorf("error refreshing token for %s: %v", accountName, err)
+		}
 		// Return old token if refresh failed
 		if account.AuthToken != "" {
 			log.Printf("🔄 Using old token for %s", accountName)
@@ -158,64 +310,40 @@ func (tm *TokenManager) RefreshTokenOnError(accountName string, statusCode int)
 	tm.config.Accounts[accountIndex].AuthToken = newToken
 
 	// Save configuration in background (don't block main thread)
-	go func() {
-		if err := tm.config.Save("config.json"); err != nil {
-			log.Printf("⚠️ Failed to save configuration: %v", err)
-		}
-	}()
+	tm.saveConfig()
 
 	// Update cache
 	tm.tokens[accountName] = &TokenInfo{
-		Token:     newToken,
-		ExpiresAt: time.Now().Add(tm.tokenTTL),
-		IsValid:   true,
-		LastCheck: time.Now(),
+		Token:       newToken,
+		ExpiresAt:   time.Now().Add(tm.tokenTTL),
+		IsValid:     true,
+		LastCheck:   time.Now(),
+		RefreshedAt: time.Now(),
 	}
 
+	delete(tm.reauth, accountName)
+	delete(tm.failures, accountName)
+	tm.saveTokens(accountName)
+	tm.scheduleRefresh(accountName)
 	log.Printf("✅ Token for account %s successfully updated", accountName)
 	return newToken, nil
 }
 
 // refreshTokenViaTelegram refreshes token through Telegram authentication
 func (tm *TokenManager) refreshTokenViaTelegram(account *config.Account) (string, error) {
-	if account.PhoneNumber == "" {
-		return "", fmt.Errorf("phone number not specified for account %s", account.Name)
-	}
-
-	// Determine session file path
-	sessionFile := account.SessionFile
-	if sessionFile == "" {
-		cleanPhone := strings.ReplaceAll(account.PhoneNumber, "+", "")
-		sessionFile = fmt.Sprintf("sessions/%s.session", cleanPhone)
-	}
-
-	// Validate account API credentials
-	if account.APIId == 0 {
-		return "", fmt.Errorf("API ID not specified for account %s", account.Name)
-	}
-
-	if account.APIHash == "" {
-		return "", fmt.Errorf("API Hash not specified for account %s", account.Name)
+	// Authorization uses credentials, session and proxy of this account only
+	authService, err := NewAccountAuthService(tm.config, account)
+	if err != nil {
+		return "", err
 	}
 
-	// Create authentication service with proxy support using account's individual API credentials
-	authService := telegram.NewAuthServiceWithProxy(
-		account.APIId,
-		account.APIHash,
-		account.PhoneNumber,
-		sessionFile,
-		account.TwoFactorPassword,
-		account.UseProxy,
-		account.ProxyURL,
-	)
-
 	// Execute authentication with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
 	bearerToken, err := authService.AuthorizeAndGetToken(ctx)
 	if err != nil {
-		return "", fmt.Errorf("Telegram authentication error: %v", err)
+		return "", fmt.Errorf("Telegram authentication error: %w", err)
 	}
 
 	return bearerToken, nil
@@ -228,18 +356,13 @@ func (tm *TokenManager) PreventiveRefresh() {
 
 	log.Printf("🔄 Proactively refreshing tokens...")
 
-	for accountName, tokenInfo := range tm.tokens {
-		// Refresh tokens that will expire in the next 5 minutes
-		if time.Until(tokenInfo.ExpiresAt) < 5*time.Minute {
-			log.Printf("⏰ Token for %s is about to expire, refreshing proactively", accountName)
+	for key, tokenInfo := range tm.tokens {
+		// Refresh tokens that will expire soon
+		if time.Until(tokenInfo.ExpiresAt) < refreshLead {
+			log.Printf("⏰ Token for %s is about to expire, refreshing proactively", key)
 
 			// Start refresh in separate goroutine to not block
-			go func(name string) {
-				_, err := tm.RefreshTokenOnError(name, 401) // Forced refresh
-				if err != nil {
-					log.Printf("❌ Error proactively refreshing token for %s: %v", name, err)
-				}
-			}(accountName)
+			go tm.refreshKey(key, tokenInfo.App)
 		}
 	}
 }
@@ -249,24 +372,75 @@ func (tm *TokenManager) GetValidToken(accountName string) (string, error) {
 	return tm.GetCachedToken(accountName)
 }
 
-// InitializeTokens initializes token cache from configuration
+// InitializeTokens initializes token cache from configuration. Expiry of tokens saved by
+// the previous run is restored, so stale tokens are refreshed by PreventiveRefresh
 func (tm *TokenManager) InitializeTokens() {
 	tm.mutex.Lock()
 	defer tm.mutex.Unlock()
 
 	log.Printf("🔧 Initializing token cache...")
 
+	// Every run starts from config and the saved cache, accounts may have been
+	// authorized again since the previous one
+	tm.tokens = make(map[string]*TokenInfo)
+	tm.reauth = make(map[string]string)
+	tm.dead = make(map[string]string)
+
+	saved, err := tm.storage.LoadTokens()
+	if err != nil {
+		log.Printf("⚠️ Failed to load token cache: %v", err)
+		saved = map[string]*TokenInfo{}
+	}
+
 	for _, account := range tm.config.Accounts {
-		if account.AuthToken != "" {
-			tm.tokens[account.Name] = &TokenInfo{
-				Token:     account.AuthToken,
-				ExpiresAt: time.Now().Add(tm.tokenTTL),
-				IsValid:   true,
-				LastCheck: time.Now(),
+		if tm.rejectTempToken(account.Name, account.AuthToken) {
+			log.Printf("⚠️ Token for %s is temporary, account needs re-authorization", account.Name)
+			continue
+		}
+		if account.AuthToken == "" {
+			continue
+		}
+
+		// Saved metadata applies only while config holds the same token
+		if info, ok := saved[account.Name]; ok && info.Token == account.AuthToken {
+			if !info.IsValid {
+				// Token was rejected by the API, refresh it right away
+				info.ExpiresAt = time.Now()
+			}
+			tm.tokens[account.Name] = info
+			if time.Now().Before(info.ExpiresAt) {
+				log.Printf("📋 Token for %s restored, valid until %s", account.Name, info.ExpiresAt.Format("15:04:05"))
+			} else {
+				log.Printf("⌛ Token for %s is stale (expired %s), it will be refreshed", account.Name, info.ExpiresAt.Format("2006-01-02 15:04:05"))
+			}
+			continue
+		}
+
+		tm.tokens[account.Name] = &TokenInfo{
+			Token:     account.AuthToken,
+			ExpiresAt: time.Now().Add(tm.tokenTTL),
+			IsValid:   true,
+			LastCheck: time.Now(),
+		}
+		log.Printf("📋 Token for %s added to cache", account.Name)
+	}
+
+	// Tokens of other bots exist only in the saved cache
+	for _, account := range tm.config.Accounts {
+		for _, app := range account.WebApps {
+			key := appTokenKey(account.Name, app.BotUsername)
+			if info, ok := saved[key]; ok && info.Token != "" {
+				if !info.IsValid {
+					info.ExpiresAt = time.Now()
+				}
+				info.App = app.BotUsername
+				tm.tokens[key] = info
+				log.Printf("📋 Token of %s for bot %s restored", account.Name, app.BotUsername)
 			}
-			log.Printf("📋 Token for %s added to cache", account.Name)
 		}
 	}
+
+	tm.saveTokens(slices.Collect(maps.Keys(tm.tokens))...)
 }
 
 // RefreshTokenOnJSONError refreshes token when receiving JSON token error
@@ -275,32 +449,51 @@ func (tm *TokenManager) RefreshTokenOnJSONError(accountName string) (string, err
 	return tm.RefreshTokenOnError(accountName, 200) // Use status 200 for JSON errors
 }
 
-// ForceRefreshToken forcibly refreshes token (ignoring cache and cooldown)
-func (tm *TokenManager) ForceRefreshToken(accountName string) (string, error) {
+// MarkStale marks cached token of account rejected by the API, so PreventiveRefresh replaces it
+func (tm *TokenManager) MarkStale(accountName string) {
 	tm.mutex.Lock()
 	defer tm.mutex.Unlock()
 
+	if tokenInfo, ok := tm.tokens[accountName]; ok {
+		tokenInfo.IsValid = false
+		tokenInfo.ExpiresAt = time.Now()
+		tm.saveTokens(accountName)
+		tm.scheduleRefresh(accountName)
+	}
+}
+
+// ForceRefreshToken forcibly refreshes token (ignoring cache and cooldown)
+func (tm *TokenManager) ForceRefreshToken(accountName string) (string, error) {
 	log.Printf("🔄 Forcibly refreshing token for %s", accountName)
 
-	// Find account in configuration
-	var account *config.Account
-	var accountIndex int
-	for i, acc := range tm.config.Accounts {
-		if acc.Name == accountName {
-			account = &acc
-			accountIndex = i
-			break
-		}
-	}
+	return tm.refreshOnce(accountName, func() (string, error) {
+		return tm.forceRefresh(accountName)
+	})
+}
 
-	if account == nil {
-		return "", fmt.Errorf("account %s not found", accountName)
+// forceRefresh refreshes token through Telegram without fallback to old token
+func (tm *TokenManager) forceRefresh(accountName string) (string, error) {
+	account, accountIndex, err := tm.findAccount(accountName)
+	if err != nil {
+		return "", err
 	}
 
 	// Refresh token through Telegram authentication
-	newToken, err := tm.refreshTokenViaTelegram(account)
+	newToken, err := tm.refreshTokenViaTelegram(&account)
+
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+
 	if err != nil {
 		log.Printf("❌ Error forcibly refreshing token for %s: %v", accountName, err)
+		if reason := telegram.DeadAccountReason(err); reason != "" {
+			tm.markDead(accountName, reason)
+			return "", fmt.Errorf("account %s is dead: %s", accountName, reason)
+		}
+		tm.tokenExpired(accountName, err)
+		if tm.config.StrictAuthEnabled() {
+			tm.markNeedsReauth(accountName, err.Error())
+		}
 		return "", fmt.Errorf("error refreshing token for %s: %v", accountName, err)
 	}
 
@@ -308,18 +501,21 @@ func (tm *TokenManager) ForceRefreshToken(accountName string) (string, error) {
 	tm.config.Accounts[accountIndex].AuthToken = newToken
 
 	// Save configuration
-	if err := tm.config.Save("config.json"); err != nil {
-		log.Printf("⚠️ Failed to save configuration: %v", err)
-	}
+	tm.saveConfig()
 
 	// Update cache
 	tm.tokens[accountName] = &TokenInfo{
-		Token:     newToken,
-		ExpiresAt: time.Now().Add(tm.tokenTTL),
-		IsValid:   true,
-		LastCheck: time.Now(),
+		Token:       newToken,
+		ExpiresAt:   time.Now().Add(tm.tokenTTL),
+		IsValid:     true,
+		LastCheck:   time.Now(),
+		RefreshedAt: time.Now(),
 	}
 
+	delete(tm.reauth, accountName)
+	delete(tm.failures, accountName)
+	tm.saveTokens(accountName)
+	tm.scheduleRefresh(accountName)
 	log.Printf("✅ Token for account %s forcibly updated", accountName)
 	return newToken, nil
 }
@@ -354,6 +550,9 @@ func (tm *TokenManager) ReloadTokenFromConfig(accountName string) error {
 	if account.AuthToken == "" {
 		return fmt.Errorf("token for account %s is missing in configuration", accountName)
 	}
+	if tm.rejectTempToken(accountName, account.AuthToken) {
+		return fmt.Errorf("token for account %s is temporary, re-authorization required", accountName)
+	}
 
 	// Update cache with token from configuration
 	tm.tokens[accountName] = &TokenInfo{
@@ -363,6 +562,107 @@ func (tm *TokenManager) ReloadTokenFromConfig(accountName string) error {
 		LastCheck: time.Now(),
 	}
 
+	tm.scheduleRefresh(accountName)
 	log.Printf("🔄 Token for %s reloaded from configuration", accountName)
 	return nil
 }
+
+// NeedsReauth returns why account has to be authorized again, empty if it doesn't
+func (tm *TokenManager) NeedsReauth(accountName string) string {
+	tm.mutex.RLock()
+	defer tm.mutex.RUnlock()
+	return tm.reauth[accountName]
+}
+
+// markNeedsReauth remembers that account can't get a token without new authorization.
+// Cached token is dropped. Caller must hold the mutex
+func (tm *TokenManager) markNeedsReauth(accountName, reason string) {
+	tm.reauth[accountName] = reason
+	delete(tm.tokens, accountName)
+	tm.saveTokens(accountName)
+	log.Printf("🔐 Account %s needs re-authorization: %s", accountName, reason)
+}
+
+// rejectTempToken checks if token is a temporary token refused in strict mode and marks
+// the account for re-authorization. Caller must hold the mutex
+func (tm *TokenManager) rejectTempToken(accountName, token string) bool {
+	if !tm.config.StrictAuthEnabled() || !telegram.IsTempToken(token) {
+		return false
+	}
+	if _, marked := tm.reauth[accountName]; !marked {
+		tm.markNeedsReauth(accountName, "saved token is a temporary token")
+	}
+	return true
+}
+
+// refreshOnce runs refresh unless a refresh of the account is already in progress,
+// then its result is waited for. Only one Telegram authorization per account runs at a time
+func (tm *TokenManager) refreshOnce(accountName string, refresh func() (string, error)) (string, error) {
+	tm.mutex.Lock()
+	if flight, ok := tm.refreshes[accountName]; ok {
+		tm.mutex.Unlock()
+		log.Printf("⏳ Token refresh for %s already in progress, waiting for its result", accountName)
+		<-flight.done
+		return flight.token, flight.err
+	}
+	flight := &tokenRefresh{
+		done: make(chan struct{}),
+		err:  fmt.Errorf("token refresh of %s was interrupted", accountName),
+	}
+	tm.refreshes[accountName] = flight
+	tm.mutex.Unlock()
+
+	defer func() {
+		tm.mutex.Lock()
+		delete(tm.refreshes, accountName)
+		tm.mutex.Unlock()
+		close(flight.done)
+	}()
+
+	flight.token, flight.err = refresh()
+	return flight.token, flight.err
+}
+
+// findAccount returns copy of account configuration and its index
+func (tm *TokenManager) findAccount(accountName string) (config.Account, int, error) {
+	tm.mutex.RLock()
+	defer tm.mutex.RUnlock()
+
+	for i, acc := range tm.config.Accounts {
+		if acc.Name == accountName {
+			return acc, i, nil
+		}
+	}
+	return config.Account{}, 0, fmt.Errorf("account %s not found", accountName)
+}
+
+// DeadReason returns why account is banned or deactivated, empty if it isn't
+func (tm *TokenManager) DeadReason(accountName string) string {
+	tm.mutex.RLock()
+	defer tm.mutex.RUnlock()
+	return tm.dead[accountName]
+}
+
+// tokenExpired reports failed refresh of account token. Caller must hold the mutex
+func (tm *TokenManager) tokenExpired(accountName string, err error) {
+	tm.failures[accountName]++
+	if tm.OnTokenExpired != nil {
+		go tm.OnTokenExpired(accountName, err.Error(), tm.failures[accountName])
+	}
+}
+
+// markDead remembers that Telegram banned or deactivated account, its token is dropped and
+// it is not refreshed anymore. Caller must hold the mutex
+func (tm *TokenManager) markDead(accountName, reason string) {
+	if _, dead := tm.dead[accountName]; dead {
+		return
+	}
+	tm.dead[accountName] = reason
+	delete(tm.tokens, accountName)
+	tm.saveTokens(accountName)
+	log.Printf("💀 Account %s is dead: %s", accountName, reason)
+
+	if tm.OnAccountDead != nil {
+		go tm.OnAccountDead(accountName, reason)
+	}
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+)
+
+// refreshLead how long before its expiry a token is refreshed
+const refreshLead = 5 * time.Minute
+
+// idleRecheckInterval how often refresh of idle account's token is reconsidered
+const idleRecheckInterval = time.Minute
+
+// StartRefreshScheduler refreshes every token refreshLead before its own expiry until ctx
+// is cancelled. Tokens of accounts idle reports are not refreshed while they stay idle
+func (tm *TokenManager) StartRefreshScheduler(ctx context.Context, idle func(accountName string) bool) {
+	tm.mutex.Lock()
+	tm.stopTimers()
+	tm.schedulerCtx = ctx
+	tm.isIdle = idle
+	tm.timers = make(map[string]*time.Timer)
+	for key := range tm.tokens {
+		tm.scheduleRefresh(key)
+	}
+	tm.mutex.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		tm.mutex.Lock()
+		defer tm.mutex.Unlock()
+		// Scheduler of the next run may already be started
+		if tm.schedulerCtx == ctx {
+			tm.stopTimers()
+		}
+	}()
+}
+
+// stopTimers cancels all scheduled refreshes. Caller must hold the mutex
+func (tm *TokenManager) stopTimers() {
+	for _, timer := range tm.timers {
+		timer.Stop()
+	}
+	tm.timers = nil
+}
+
+// scheduleRefresh arms refresh of token for its current expiry, replacing previous timer.
+// Caller must hold the mutex
+func (tm *TokenManager) scheduleRefresh(key string) {
+	if tm.schedulerCtx == nil || tm.schedulerCtx.Err() != nil || tm.timers == nil {
+		return
+	}
+	tokenInfo, ok := tm.tokens[key]
+	if !ok {
+		return
+	}
+
+	if timer, ok := tm.timers[key]; ok {
+		timer.Stop()
+	}
+	expiresAt := tokenInfo.ExpiresAt
+	tm.timers[key] = time.AfterFunc(time.Until(expiresAt.Add(-refreshLead)), func() {
+		tm.scheduledRefresh(key, expiresAt)
+	})
+}
+
+// scheduledRefresh refreshes token whose timer fired, unless it was replaced meanwhile
+func (tm *TokenManager) scheduledRefresh(key string, expiresAt time.Time) {
+	current := func() (*TokenInfo, bool) {
+		tokenInfo, ok := tm.tokens[key]
+		if !ok || !tokenInfo.ExpiresAt.Equal(expiresAt) || tm.schedulerCtx.Err() != nil {
+			return nil, false
+		}
+		return tokenInfo, true
+	}
+
+	tm.mutex.RLock()
+	tokenInfo, ok := current()
+	tm.mutex.RUnlock()
+	if !ok {
+		return
+	}
+
+	// Idle check takes locks of the service, so it is done without the mutex
+	if tm.isIdle != nil && tm.isIdle(tokenAccount(key, tokenInfo)) {
+		tm.mutex.Lock()
+		defer tm.mutex.Unlock()
+		// Token is refreshed when the account works again
+		if _, ok := current(); ok && tm.timers != nil {
+			tm.timers[key] = time.AfterFunc(idleRecheckInterval, func() {
+				tm.scheduledRefresh(key, expiresAt)
+			})
+		}
+		return
+	}
+
+	log.Printf("⏰ Token for %s expires at %s, refreshing proactively", key, expiresAt.Format("15:04:05"))
+	tm.refreshKey(key, tokenInfo.App)
+}
+
+// refreshKey refreshes token of cache entry, main or Web App one
+func (tm *TokenManager) refreshKey(key, app string) {
+	if app != "" {
+		name := strings.TrimSuffix(key, "@"+app)
+		if _, err := tm.RefreshAppToken(name, app); err != nil {
+			log.Printf("❌ Error proactively refreshing token of %s for bot %s: %v", name, app, err)
+		}
+		return
+	}
+
+	if _, err := tm.RefreshTokenOnError(key, 401); err != nil { // Forced refresh
+		log.Printf("❌ Error proactively refreshing token for %s: %v", key, err)
+	}
+}
+
+// tokenAccount returns name of account owning token cache entry
+func tokenAccount(key string, tokenInfo *TokenInfo) string {
+	if tokenInfo.App != "" {
+		return strings.TrimSuffix(key, "@"+tokenInfo.App)
+	}
+	return key
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"stickersbot/internal/config"
+	"stickersbot/internal/redact"
+)
+
+// TokensFile file keeping cached tokens with their expiry between runs
+const TokensFile = "tokens.json"
+
+// loadTokenInfo reads saved tokens by account name, empty if the file doesn't exist yet
+func loadTokenInfo(path string) (map[string]*TokenInfo, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]*TokenInfo{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make(map[string]*TokenInfo)
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		// Broken file is kept aside, otherwise the next save would overwrite it
+		backup := path + ".broken"
+		if renameErr := os.Rename(path, backup); renameErr != nil {
+			return nil, fmt.Errorf("parsing %s: %v", path, err)
+		}
+		return nil, fmt.Errorf("parsing %s: %v (moved to %s)", path, err, backup)
+	}
+	return tokens, nil
+}
+
+// writeTokensFile writes encoded token cache, replacing the file atomically
+func writeTokensFile(path string, data []byte) error {
+	// Tokens are credentials, only the owner may read them
+	if err := config.WriteFileAtomic(path, data, 0600); err != nil {
+		return fmt.Errorf("writing %s: %v", path, err)
+	}
+	return nil
+}
+
+// saveTokens writes token cache with changed keys in background, so the next run knows
+// which tokens are stale. Saves run one at a time, keys changed meanwhile are written by
+// the save that follows. Caller must hold the mutex
+func (tm *TokenManager) saveTokens(keys ...string) {
+	for _, key := range keys {
+		tm.tokenChanges[key] = true
+
+		// New tokens are masked in logs from now on
+		if info, ok := tm.tokens[key]; ok {
+			redact.AddSecret(info.Token)
+		}
+	}
+
+	if !tm.tokenSaving && len(tm.tokenChanges) > 0 {
+		tm.tokenSaving = true
+		go tm.writeTokens()
+	}
+}
+
+// writeTokens saves token cache until no key is left changed. Cache is copied under the
+// mutex and written after it is released, so slow stores don't block token readers
+func (tm *TokenManager) writeTokens() {
+	for {
+		tm.mutex.Lock()
+		if len(tm.tokenChanges) == 0 {
+			tm.tokenSaving = false
+			tm.mutex.Unlock()
+			return
+		}
+		tokens := make(map[string]*TokenInfo, len(tm.tokens))
+		for key, info := range tm.tokens {
+			snapshot := *info
+			tokens[key] = &snapshot
+		}
+		changed := tm.tokenChanges
+		tm.tokenChanges = make(map[string]bool)
+		tm.mutex.Unlock()
+
+		if err := tm.storage.SaveTokens(tokens, changed); err != nil {
+			log.Printf("⚠️ Failed to save token cache: %v", err)
+		}
+	}
+}
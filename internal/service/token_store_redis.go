@@ -0,0 +1,223 @@
+package service
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+
+	"stickersbot/internal/config"
+)
+
+// redisTimeout timeout of connection and one exchange with Redis
+const redisTimeout = 5 * time.Second
+
+// redisTokenStore keeps tokens as JSON fields of a Redis hash, so instances sharing
+// the hash see each other's tokens
+type redisTokenStore struct {
+	redisServer
+	key string
+}
+
+// newRedisTokenStore creates Redis token store, connection is opened per operation
+func newRedisTokenStore(cfg *config.TokenStoreConfig, key string) (*redisTokenStore, error) {
+	server, err := newRedisServer(cfg.Address, cfg.Password, cfg.DB, cfg.TLS)
+	if err != nil {
+		return nil, err
+	}
+	return &redisTokenStore{redisServer: server, key: key}, nil
+}
+
+func (s *redisTokenStore) LoadTokens() (map[string]*TokenInfo, error) {
+	conn, err := s.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	reply, err := conn.do("HGETALL", s.key)
+	if err != nil {
+		return nil, err
+	}
+	fields, ok := reply.([]interface{})
+	if !ok || len(fields)%2 != 0 {
+		return nil, fmt.Errorf("unexpected HGETALL reply %v", reply)
+	}
+
+	tokens := make(map[string]*TokenInfo)
+	for i := 0; i < len(fields); i += 2 {
+		key, _ := fields[i].(string)
+		value, _ := fields[i+1].(string)
+		var info TokenInfo
+		if err := json.Unmarshal([]byte(value), &info); err != nil {
+			return nil, fmt.Errorf("parsing token %s: %v", key, err)
+		}
+		tokens[key] = &info
+	}
+	return tokens, nil
+}
+
+func (s *redisTokenStore) SaveTokens(tokens map[string]*TokenInfo, changed map[string]bool) error {
+	set := []string{"HSET", s.key}
+	removed := []string{"HDEL", s.key}
+	for key := range changed {
+		info, ok := tokens[key]
+		if !ok {
+			removed = append(removed, key)
+			continue
+		}
+		data, err := json.Marshal(info)
+		if err != nil {
+			return err
+		}
+		set = append(set, key, string(data))
+	}
+	if len(set) == 2 && len(removed) == 2 {
+		return nil
+	}
+
+	conn, err := s.connect()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	for _, args := range [][]string{set, removed} {
+		if len(args) == 2 {
+			continue
+		}
+		if _, err := conn.do(args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// redisServer address and credentials of Redis server
+type redisServer struct {
+	address  string
+	password string
+	db       int
+	tls      bool
+}
+
+// newRedisServer checks address and resolves password reference of Redis server
+func newRedisServer(address, password string, db int, useTLS bool) (redisServer, error) {
+	if address == "" {
+		return redisServer{}, errors.New("redis address is not set")
+	}
+	password, err := config.ResolveSecret(password)
+	if err != nil {
+		return redisServer{}, err
+	}
+	return redisServer{address: address, password: password, db: db, tls: useTLS}, nil
+}
+
+// connect opens connection, authenticates and selects database
+func (s redisServer) connect() (*redisConn, error) {
+	dialer := &net.Dialer{Timeout: redisTimeout}
+	var netConn net.Conn
+	var err error
+	if s.tls {
+		netConn, err = tls.DialWithDialer(dialer, "tcp", s.address, &tls.Config{})
+	} else {
+		netConn, err = dialer.Dial("tcp", s.address)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("connecting to redis %s: %v", s.address, err)
+	}
+
+	conn := &redisConn{conn: netConn, reader: bufio.NewReader(netConn)}
+	if s.password != "" {
+		if _, err := conn.do("AUTH", s.password); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("redis AUTH: %v", err)
+		}
+	}
+	if s.db != 0 {
+		if _, err := conn.do("SELECT", strconv.Itoa(s.db)); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("redis SELECT: %v", err)
+		}
+	}
+	return conn, nil
+}
+
+// redisConn connection speaking RESP protocol
+type redisConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// do sends command and reads its reply: string, int64, nil or []interface{}
+func (c *redisConn) do(args ...string) (interface{}, error) {
+	c.conn.SetDeadline(time.Now().Add(redisTimeout))
+
+	command := "*" + strconv.Itoa(len(args)) + "\r\n"
+	for _, arg := range args {
+		command += "$" + strconv.Itoa(len(arg)) + "\r\n" + arg + "\r\n"
+	}
+	if _, err := io.WriteString(c.conn, command); err != nil {
+		return nil, err
+	}
+	return c.readReply()
+}
+
+func (c *redisConn) readReply() (interface{}, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 {
+		return nil, fmt.Errorf("malformed redis reply %q", line)
+	}
+	kind, body := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, errors.New(body)
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		size, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(c.reader, data); err != nil {
+			return nil, err
+		}
+		return string(data[:size]), nil
+	case '*':
+		count, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, err
+		}
+		if count < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, count)
+		for i := range items {
+			if items[i], err = c.readReply(); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("unknown redis reply type %q", kind)
+	}
+}
+
+func (c *redisConn) Close() error {
+	return c.conn.Close()
+}
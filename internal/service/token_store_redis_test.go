@@ -0,0 +1,111 @@
+package service
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// fakeRedis records commands sent to it and answers each with OK
+func fakeRedis(t *testing.T) (string, <-chan []string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	commands := make(chan []string, 10)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				for {
+					command, err := readCommand(reader)
+					if err != nil {
+						return
+					}
+					commands <- command
+					fmt.Fprint(conn, "+OK\r\n")
+				}
+			}()
+		}
+	}()
+	return listener.Addr().String(), commands
+}
+
+// readCommand reads one RESP array of bulk strings
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	count, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+	args := make([]string, count)
+	for i := range args {
+		if _, err := reader.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		arg, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		args[i] = strings.TrimSuffix(arg, "\r\n")
+	}
+	return args, nil
+}
+
+func TestRedisSaveTokensWritesChangedKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		tokens  map[string]*TokenInfo
+		changed map[string]bool
+		want    []string // Command and keys, values are not compared
+	}{
+		{"changed token only", map[string]*TokenInfo{"a": {Token: "1"}, "b": {Token: "2"}}, map[string]bool{"b": true},
+			[]string{"HSET tokens b"}},
+		{"removed token", map[string]*TokenInfo{"a": {Token: "1"}}, map[string]bool{"gone": true},
+			[]string{"HDEL tokens gone"}},
+		{"changed and removed", map[string]*TokenInfo{"a": {Token: "1"}}, map[string]bool{"a": true, "gone": true},
+			[]string{"HSET tokens a", "HDEL tokens gone"}},
+		{"nothing changed", map[string]*TokenInfo{"a": {Token: "1"}}, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr, commands := fakeRedis(t)
+			store := &redisTokenStore{redisServer: redisServer{address: addr}, key: "tokens"}
+
+			if err := store.SaveTokens(tt.tokens, tt.changed); err != nil {
+				t.Fatal(err)
+			}
+
+			// Every command is recorded before it is answered
+			var got []string
+			for len(commands) > 0 {
+				command := <-commands
+				keys := []string{command[0], command[1]}
+				if command[0] == "HSET" {
+					for i := 2; i < len(command); i += 2 {
+						keys = append(keys, command[i])
+					}
+				} else {
+					keys = append(keys, command[2:]...)
+				}
+				sort.Strings(keys[2:])
+				got = append(got, strings.Join(keys, " "))
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("commands %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"stickersbot/internal/config"
+)
+
+// DefaultVaultMount mount of KV v2 secrets engine
+const DefaultVaultMount = "secret"
+
+// vaultSaveAttempts attempts to save tokens when another instance saved at the same time
+const vaultSaveAttempts = 3
+
+// errVaultConflict secret was changed since it was read
+var errVaultConflict = errors.New("secret was changed by another instance")
+
+// vaultTokenStore keeps tokens as fields of a HashiCorp Vault KV v2 secret.
+// Saves are merged into the secret with check-and-set, so instances don't lose each other's tokens
+type vaultTokenStore struct {
+	url    string // Data endpoint of the secret
+	token  string
+	client *http.Client
+}
+
+// newVaultTokenStore creates Vault token store
+func newVaultTokenStore(cfg *config.TokenStoreConfig, key string) (*vaultTokenStore, error) {
+	if cfg.Address == "" {
+		return nil, errors.New("vault address is not set")
+	}
+
+	token := os.Getenv("VAULT_TOKEN")
+	if cfg.Token != "" {
+		resolved, err := config.ResolveSecret(cfg.Token)
+		if err != nil {
+			return nil, err
+		}
+		token = resolved
+	}
+	if token == "" {
+		return nil, errors.New("vault token is not set (token or VAULT_TOKEN variable)")
+	}
+
+	mount := cfg.Mount
+	if mount == "" {
+		mount = DefaultVaultMount
+	}
+
+	return &vaultTokenStore{
+		url:    strings.TrimRight(cfg.Address, "/") + "/v1/" + strings.Trim(mount, "/") + "/data/" + strings.Trim(key, "/"),
+		token:  token,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (s *vaultTokenStore) LoadTokens() (map[string]*TokenInfo, error) {
+	fields, _, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make(map[string]*TokenInfo)
+	for key, value := range fields {
+		var info TokenInfo
+		if err := json.Unmarshal([]byte(value), &info); err != nil {
+			return nil, fmt.Errorf("parsing token %s: %v", key, err)
+		}
+		tokens[key] = &info
+	}
+	return tokens, nil
+}
+
+func (s *vaultTokenStore) SaveTokens(tokens map[string]*TokenInfo, changed map[string]bool) error {
+	if len(changed) == 0 {
+		return nil
+	}
+
+	for attempt := 1; ; attempt++ {
+		fields, version, err := s.read()
+		if err != nil {
+			return err
+		}
+		for key := range changed {
+			info, ok := tokens[key]
+			if !ok {
+				delete(fields, key)
+				continue
+			}
+			data, err := json.Marshal(info)
+			if err != nil {
+				return err
+			}
+			fields[key] = string(data)
+		}
+
+		err = s.write(fields, version)
+		if !errors.Is(err, errVaultConflict) || attempt == vaultSaveAttempts {
+			return err
+		}
+	}
+}
+
+// read returns fields of the secret and its version, empty if the secret doesn't exist
+func (s *vaultTokenStore) read() (map[string]string, int, error) {
+	var response struct {
+		Data struct {
+			Data     map[string]string `json:"data"`
+			Metadata struct {
+				Version int `json:"version"`
+			} `json:"metadata"`
+		} `json:"data"`
+	}
+
+	status, err := s.request(http.MethodGet, nil, &response)
+	if status == http.StatusNotFound {
+		return map[string]string{}, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	if response.Data.Data == nil {
+		// Latest version was deleted
+		response.Data.Data = map[string]string{}
+	}
+	return response.Data.Data, response.Data.Metadata.Version, nil
+}
+
+// write saves new version of the secret if its current version is still version
+func (s *vaultTokenStore) write(fields map[string]string, version int) error {
+	body := map[string]interface{}{
+		"options": map[string]int{"cas": version},
+		"data":    fields,
+	}
+	status, err := s.request(http.MethodPost, body, nil)
+	if status == http.StatusBadRequest && err != nil && strings.Contains(err.Error(), "check-and-set") {
+		return errVaultConflict
+	}
+	return err
+}
+
+// request calls secret endpoint, decoding response into result. Returns HTTP status
+func (s *vaultTokenStore) request(method string, body interface{}, result interface{}) (int, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, s.url, reader)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("X-Vault-Token", s.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("vault request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("vault returned HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if result != nil {
+		if err := json.Unmarshal(data, result); err != nil {
+			return resp.StatusCode, fmt.Errorf("parsing vault response: %v", err)
+		}
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"strings"
+	"time"
+
+	"stickersbot/internal/types"
+)
+
+// Transaction statuses used by history filter
+const (
+	TxStatusCredited    = types.CreditCredited    // Character appeared in inventory
+	TxStatusNotCredited = types.CreditNotCredited // Paid, character didn't appear
+	TxStatusUnchecked   = "unchecked"             // Inventory wasn't checked
+	TxStatusTest        = "test"                  // Sent in test mode
+)
+
+// TransactionStatus returns status of sent transaction
+func TransactionStatus(tx types.TransactionLog) string {
+	if tx.TestMode {
+		return TxStatusTest
+	}
+	if tx.Credit == "" {
+		return TxStatusUnchecked
+	}
+	return tx.Credit
+}
+
+// TransactionFilter selects transactions of history. Empty fields match everything
+type TransactionFilter struct {
+	Account string    // Account name, case insensitive substring
+	From    time.Time // Sent at or after
+	To      time.Time // Sent before
+	Status  string    // One of TxStatus constants
+}
+
+// Matches checks whether transaction passes the filter
+func (f TransactionFilter) Matches(tx types.TransactionLog) bool {
+	if f.Account != "" && !strings.Contains(strings.ToLower(tx.AccountName), strings.ToLower(f.Account)) {
+		return false
+	}
+	if !f.From.IsZero() && tx.Timestamp.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !tx.Timestamp.Before(f.To) {
+		return false
+	}
+	if f.Status != "" && TransactionStatus(tx) != f.Status {
+		return false
+	}
+	return true
+}
+
+// FilterTransactions returns transactions passing the filter, keeping their order
+func FilterTransactions(transactions []types.TransactionLog, filter TransactionFilter) []types.TransactionLog {
+	var result []types.TransactionLog
+	for _, tx := range transactions {
+		if filter.Matches(tx) {
+			result = append(result, tx)
+		}
+	}
+	return result
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"stickersbot/internal/logfile"
+	"stickersbot/internal/types"
+)
+
+// TransactionLogFile file where sent transactions are logged as JSON lines
+const TransactionLogFile = "transactions.log"
+
+// LoadTransactions reads latest transactions from transaction log and its rotated
+// files, newest first. limit <= 0 returns all transactions
+func LoadTransactions(path string, limit int) ([]types.TransactionLog, error) {
+	var transactions []types.TransactionLog
+	byOrder := make(map[string]int) // Order ID -> index of its transaction
+	for _, filename := range logfile.Files(path) {
+		items, err := readTransactions(filename)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tx := range items {
+			// Update lines are applied to the transaction they refer to
+			if tx.Update {
+				if i, ok := byOrder[tx.OrderID]; ok {
+					transactions[i].Credit = tx.Credit
+				}
+				continue
+			}
+			byOrder[tx.OrderID] = len(transactions)
+			transactions = append(transactions, tx)
+		}
+	}
+
+	if limit > 0 && len(transactions) > limit {
+		transactions = transactions[len(transactions)-limit:]
+	}
+
+	// Newest first
+	for i, j := 0, len(transactions)-1; i < j; i, j = i+1, j-1 {
+		transactions[i], transactions[j] = transactions[j], transactions[i]
+	}
+	return transactions, nil
+}
+
+// readTransactions reads transactions of one log file
+func readTransactions(filename string) ([]types.TransactionLog, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error opening transaction log: %v", err)
+	}
+	defer file.Close()
+
+	var transactions []types.TransactionLog
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var tx types.TransactionLog
+		if err := json.Unmarshal(scanner.Bytes(), &tx); err != nil {
+			continue // Skip damaged lines
+		}
+		transactions = append(transactions, tx)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading transaction log: %v", err)
+	}
+	return transactions, nil
+}
@@ -65,7 +65,7 @@ func (w *WalletService) getAccountBalance(ctx context.Context, account config.Ac
 	}
 
 	// Create TON client from seed phrase
-	tonClient, err := client.NewTONClient(account.SeedPhrase)
+	tonClient, err := client.NewTONClientWithProxy(account.SeedPhrase, account.UseProxy, account.TONProxyURL)
 	if err != nil {
 		wallet.Error = fmt.Sprintf("Error creating TON client: %v", err)
 		return wallet
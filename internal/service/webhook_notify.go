@@ -0,0 +1,48 @@
+package service
+
+import (
+	"fmt"
+
+	"stickersbot/internal/config"
+	"stickersbot/internal/notify"
+)
+
+// registerWebhooks registers webhooks of notifications.webhooks, each receiving its event types
+func (bs *BuyerService) registerWebhooks() {
+	if bs.config.Notifications == nil {
+		return
+	}
+
+	for i, webhook := range bs.config.Notifications.Webhooks {
+		name := webhook.WebhookName(i)
+		notifier, err := newTemplateWebhook(webhook)
+		if err != nil {
+			bs.log(fmt.Sprintf("⚠️ Webhook '%s': %v, skipped", name, err))
+			continue
+		}
+		bs.notifier.Register(name, notify.Only(webhook.Events, notifier))
+	}
+}
+
+// newTemplateWebhook creates webhook notifier with resolved secrets of URL and headers
+func newTemplateWebhook(webhook config.WebhookConfig) (*notify.Webhook, error) {
+	url, err := config.ResolveSecret(webhook.URL)
+	if err != nil {
+		return nil, fmt.Errorf("url: %v", err)
+	}
+	headers := make(map[string]string, len(webhook.Headers))
+	for name, value := range webhook.Headers {
+		resolved, err := config.ResolveSecret(value)
+		if err != nil {
+			return nil, fmt.Errorf("header %s: %v", name, err)
+		}
+		headers[name] = resolved
+	}
+
+	return notify.NewTemplateWebhook(url, notify.WebhookOptions{
+		Method:      webhook.Method,
+		Headers:     headers,
+		Template:    webhook.Template,
+		ContentType: webhook.ContentType,
+	})
+}
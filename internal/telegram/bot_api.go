@@ -0,0 +1,121 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// botAPIURL base URL of Telegram Bot API
+const botAPIURL = "https://api.telegram.org/bot%s/%s"
+
+// BotUpdate incoming update of Telegram bot (only messages are used)
+type BotUpdate struct {
+	UpdateID int64       `json:"update_id"`
+	Message  *BotMessage `json:"message,omitempty"`
+}
+
+// BotMessage message received by Telegram bot
+type BotMessage struct {
+	MessageID int64 `json:"message_id"`
+	From      *struct {
+		ID       int64  `json:"id"`
+		Username string `json:"username,omitempty"`
+	} `json:"from,omitempty"`
+	Chat struct {
+		ID int64 `json:"id"`
+	} `json:"chat"`
+	Text string `json:"text,omitempty"`
+}
+
+// BotAPI minimal client of Telegram Bot API
+type BotAPI struct {
+	token  string
+	client *http.Client
+}
+
+// NewBotAPI creates Bot API client for bot token from @BotFather
+func NewBotAPI(token string) *BotAPI {
+	return &BotAPI{
+		token:  token,
+		client: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// GetUpdates long-polls updates after offset, waiting up to timeout for new ones
+func (b *BotAPI) GetUpdates(ctx context.Context, offset int64, timeout time.Duration) ([]BotUpdate, error) {
+	var updates []BotUpdate
+	err := b.call(ctx, "getUpdates", map[string]interface{}{
+		"offset":          offset,
+		"timeout":         int(timeout.Seconds()),
+		"allowed_updates": []string{"message"},
+	}, &updates)
+	return updates, err
+}
+
+// SendMessage sends text message to chat
+func (b *BotAPI) SendMessage(ctx context.Context, chatID int64, text string) error {
+	return b.call(ctx, "sendMessage", map[string]interface{}{
+		"chat_id":                  chatID,
+		"text":                     text,
+		"disable_web_page_preview": true,
+	}, nil)
+}
+
+// SendMessageToChat sends text message to chat given by numeric ID or @username of a channel
+func (b *BotAPI) SendMessageToChat(ctx context.Context, chat string, text string) error {
+	return b.call(ctx, "sendMessage", map[string]interface{}{
+		"chat_id":                  chat,
+		"text":                     text,
+		"disable_web_page_preview": true,
+	}, nil)
+}
+
+// call calls Bot API method and decodes its result
+func (b *BotAPI) call(ctx context.Context, method string, params map[string]interface{}, result interface{}) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("error encoding request: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf(botAPIURL, b.token, method), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error creating request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		// Hide bot token contained in request URL
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("%s request error: %v", method, err)
+	}
+	defer resp.Body.Close()
+
+	var apiResp struct {
+		OK          bool            `json:"ok"`
+		Description string          `json:"description"`
+		Result      json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return fmt.Errorf("%s response decoding error: %v", method, err)
+	}
+	if !apiResp.OK {
+		return fmt.Errorf("%s failed: %s", method, apiResp.Description)
+	}
+
+	if result != nil {
+		if err := json.Unmarshal(apiResp.Result, result); err != nil {
+			return fmt.Errorf("%s result decoding error: %v", method, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gotd/td/session"
+	"github.com/gotd/td/telegram"
+	"github.com/gotd/td/telegram/dcs"
+	"github.com/gotd/td/tg"
+)
+
+// DefaultChannelPollInterval interval between checks of watched channels
+const DefaultChannelPollInterval = 5 * time.Second
+
+// ChannelMessageHandler is called for every new message of watched channel
+type ChannelMessageHandler func(channel string, text string)
+
+// ChannelWatcher polls Telegram channels using existing session
+type ChannelWatcher struct {
+	APIId       int
+	APIHash     string
+	SessionFile string
+	UseProxy    bool
+	ProxyURL    string
+
+	Channels  []string      // Channel usernames (with or without @)
+	Interval  time.Duration // Poll interval (default 5s)
+	OnMessage ChannelMessageHandler
+}
+
+// watchedChannel resolved channel with last seen message
+type watchedChannel struct {
+	username string
+	peer     tg.InputPeerClass
+	lastID   int
+}
+
+// Run watches channels until context is cancelled.
+// Session must already be authorized, watcher never asks for login code
+func (w *ChannelWatcher) Run(ctx context.Context) error {
+	clientOptions := telegram.Options{
+		SessionStorage: &session.FileStorage{
+			Path: w.SessionFile,
+		},
+		Middlewares: []telegram.Middleware{floodWaitMiddleware("Channel watcher")},
+	}
+
+	if w.UseProxy && w.ProxyURL != "" {
+		dialFunc, err := createProxyDialFunc(w.ProxyURL)
+		if err != nil {
+			return fmt.Errorf("invalid proxy URL: %v", err)
+		}
+		clientOptions.Resolver = dcs.Plain(dcs.PlainOptions{
+			Dial: dialFunc,
+		})
+	}
+
+	client := telegram.NewClient(w.APIId, w.APIHash, clientOptions)
+
+	return client.Run(ctx, func(ctx context.Context) error {
+		status, err := client.Auth().Status(ctx)
+		if err != nil {
+			return fmt.Errorf("authorization status check: %w", err)
+		}
+		if !status.Authorized {
+			return fmt.Errorf("session %s is not authorized", w.SessionFile)
+		}
+
+		api := client.API()
+
+		var channels []*watchedChannel
+		for _, username := range w.Channels {
+			channel, err := w.resolveChannel(ctx, api, username)
+			if err != nil {
+				log.Printf("⚠️ Channel watcher: %v", err)
+				continue
+			}
+			channels = append(channels, channel)
+		}
+		if len(channels) == 0 {
+			return fmt.Errorf("no channels to watch")
+		}
+
+		interval := w.Interval
+		if interval <= 0 {
+			interval = DefaultChannelPollInterval
+		}
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			for _, channel := range channels {
+				if err := w.poll(ctx, api, channel); err != nil {
+					log.Printf("⚠️ Channel watcher: error reading @%s: %v", channel.username, err)
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// resolveChannel resolves channel username and remembers its latest message
+func (w *ChannelWatcher) resolveChannel(ctx context.Context, api *tg.Client, username string) (*watchedChannel, error) {
+	username = strings.TrimPrefix(strings.TrimSpace(username), "@")
+
+	resolved, err := api.ContactsResolveUsername(ctx, &tg.ContactsResolveUsernameRequest{Username: username})
+	if err != nil {
+		return nil, fmt.Errorf("error resolving @%s: %v", username, err)
+	}
+
+	for _, chat := range resolved.Chats {
+		if channel, ok := chat.(*tg.Channel); ok {
+			watched := &watchedChannel{
+				username: username,
+				peer: &tg.InputPeerChannel{
+					ChannelID:  channel.ID,
+					AccessHash: channel.AccessHash,
+				},
+			}
+
+			// Skip messages published before the watcher started
+			messages, err := w.history(ctx, api, watched.peer, 0, 1)
+			if err != nil {
+				return nil, fmt.Errorf("error reading @%s: %v", username, err)
+			}
+			for _, message := range messages {
+				watched.lastID = max(watched.lastID, message.ID)
+			}
+
+			log.Printf("📢 Channel watcher: watching @%s", username)
+			return watched, nil
+		}
+	}
+
+	return nil, fmt.Errorf("@%s is not a channel", username)
+}
+
+// poll delivers messages newer than the last seen one
+func (w *ChannelWatcher) poll(ctx context.Context, api *tg.Client, channel *watchedChannel) error {
+	messages, err := w.history(ctx, api, channel.peer, channel.lastID, 20)
+	if err != nil {
+		return err
+	}
+
+	// History is returned newest first
+	for i := len(messages) - 1; i >= 0; i-- {
+		message := messages[i]
+		if message.ID <= channel.lastID {
+			continue
+		}
+		channel.lastID = message.ID
+
+		if w.OnMessage != nil && message.Message != "" {
+			w.OnMessage(channel.username, message.Message)
+		}
+	}
+
+	return nil
+}
+
+// history returns up to limit messages with ID greater than minID
+func (w *ChannelWatcher) history(ctx context.Context, api *tg.Client, peer tg.InputPeerClass, minID int, limit int) ([]*tg.Message, error) {
+	result, err := api.MessagesGetHistory(ctx, &tg.MessagesGetHistoryRequest{
+		Peer:  peer,
+		MinID: minID,
+		Limit: limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	modified, ok := result.AsModified()
+	if !ok {
+		return nil, nil
+	}
+
+	var messages []*tg.Message
+	for _, item := range modified.GetMessages() {
+		if message, ok := item.(*tg.Message); ok {
+			messages = append(messages, message)
+		}
+	}
+	return messages, nil
+}
@@ -0,0 +1,344 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gotd/td/session"
+	"github.com/gotd/td/telegram"
+	"github.com/gotd/td/telegram/dcs"
+	"github.com/gotd/td/tg"
+)
+
+// CodeProvider supplies login confirmation code without the console
+type CodeProvider interface {
+	// Code waits for confirmation code just sent to phone
+	Code(ctx context.Context, phone string) (string, error)
+}
+
+// DefaultCodeTimeout time code providers wait for the code
+const DefaultCodeTimeout = 5 * time.Minute
+
+// codePollInterval interval between checks of polling code providers
+const codePollInterval = 2 * time.Second
+
+// serviceNotificationsID user ID of Telegram service notifications, the chat login codes are sent to
+const serviceNotificationsID = 777000
+
+// DefaultCodePattern matches confirmation code of 5-6 digits
+var DefaultCodePattern = regexp.MustCompile(`\b(\d{5,6})\b`)
+
+// CodeWait how code is recognized in text and how long it is waited for
+type CodeWait struct {
+	Pattern *regexp.Regexp // Code in text, first group if any (default DefaultCodePattern)
+	Timeout time.Duration  // Default DefaultCodeTimeout
+}
+
+// find returns code found in text, empty if there is none
+func (w CodeWait) find(text string) string {
+	pattern := w.Pattern
+	if pattern == nil {
+		pattern = DefaultCodePattern
+	}
+
+	match := pattern.FindStringSubmatch(text)
+	switch {
+	case len(match) > 1:
+		return match[1]
+	case len(match) == 1:
+		return match[0]
+	default:
+		return ""
+	}
+}
+
+// poll calls fetch until it finds code in returned text or timeout expires
+func (w CodeWait) poll(ctx context.Context, fetch func(ctx context.Context) (string, error)) (string, error) {
+	timeout := w.Timeout
+	if timeout <= 0 {
+		timeout = DefaultCodeTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(codePollInterval)
+	defer ticker.Stop()
+
+	for {
+		text, err := fetch(ctx)
+		if err != nil {
+			log.Printf("⚠️ Waiting for confirmation code: %v", err)
+		} else if code := w.find(text); code != "" {
+			return code, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("confirmation code not received in %s", timeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+// FileCodeProvider reads code from file written after the code was requested
+type FileCodeProvider struct {
+	CodeWait
+	Path string
+}
+
+// Code waits until the file is written
+func (p *FileCodeProvider) Code(ctx context.Context, phone string) (string, error) {
+	since := time.Now()
+	log.Printf("📄 Waiting for confirmation code of %s in file %s", phone, p.Path)
+
+	return p.poll(ctx, func(ctx context.Context) (string, error) {
+		info, err := os.Stat(p.Path)
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		if err != nil {
+			return "", err
+		}
+		// Code left from the previous login is not used
+		if info.ModTime().Before(since) {
+			return "", nil
+		}
+
+		data, err := os.ReadFile(p.Path)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	})
+}
+
+// HTTPCodeProvider receives code by request to POST /code on Listen address,
+// e.g. curl -d code=12345 http://127.0.0.1:8091/code
+type HTTPCodeProvider struct {
+	Timeout time.Duration // Default DefaultCodeTimeout
+	Listen  string
+}
+
+// Code serves /code until the code is received
+func (p *HTTPCodeProvider) Code(ctx context.Context, phone string) (string, error) {
+	listener, err := net.Listen("tcp", p.Listen)
+	if err != nil {
+		return "", fmt.Errorf("listening on %s: %w", p.Listen, err)
+	}
+
+	codes := make(chan string, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/code", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		code := r.FormValue("code")
+		if code == "" {
+			body, _ := io.ReadAll(io.LimitReader(r.Body, 1024))
+			code = string(body)
+		}
+		code = strings.TrimSpace(code)
+		if code == "" {
+			http.Error(w, "code is empty", http.StatusBadRequest)
+			return
+		}
+
+		select {
+		case codes <- code:
+			fmt.Fprintln(w, "ok")
+		default:
+			http.Error(w, "code already received", http.StatusConflict)
+		}
+	})
+
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go server.Serve(listener)
+	defer server.Close()
+
+	log.Printf("🌐 Waiting for confirmation code of %s: POST http://%s/code", phone, listener.Addr())
+
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = DefaultCodeTimeout
+	}
+
+	select {
+	case code := <-codes:
+		return code, nil
+	case <-time.After(timeout):
+		return "", fmt.Errorf("confirmation code not received in %s", timeout)
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// SMSAPICodeProvider polls SMS service for the received message. "{phone}" in URL
+// is replaced with the phone number without +
+type SMSAPICodeProvider struct {
+	CodeWait
+	URL string
+}
+
+// Code polls URL until its response contains the code
+func (p *SMSAPICodeProvider) Code(ctx context.Context, phone string) (string, error) {
+	url := strings.ReplaceAll(p.URL, "{phone}", strings.TrimPrefix(phone, "+"))
+	log.Printf("📨 Waiting for confirmation code of %s from SMS service", phone)
+
+	return p.poll(ctx, func(ctx context.Context) (string, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return "", err
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		if err != nil {
+			return "", err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("SMS service responded with status %d", resp.StatusCode)
+		}
+		return string(body), nil
+	})
+}
+
+// TelegramCodeProvider reads code from messages received by another authorized session,
+// e.g. the same number logged in on a second session or an account the code is forwarded to
+type TelegramCodeProvider struct {
+	CodeWait
+	APIId       int
+	APIHash     string
+	SessionFile string
+	UseProxy    bool
+	ProxyURL    string
+	From        string // Username of chat with the code, Telegram service notifications if empty
+}
+
+// Code polls chat history until a message with the code arrives
+func (p *TelegramCodeProvider) Code(ctx context.Context, phone string) (string, error) {
+	// Message may arrive before the provider connects
+	since := time.Now().Add(-time.Minute)
+
+	clientOptions := telegram.Options{
+		SessionStorage: &session.FileStorage{
+			Path: p.SessionFile,
+		},
+		Middlewares: []telegram.Middleware{floodWaitMiddleware("Code reader")},
+	}
+
+	if p.UseProxy && p.ProxyURL != "" {
+		dialFunc, err := createProxyDialFunc(p.ProxyURL)
+		if err != nil {
+			return "", fmt.Errorf("invalid proxy URL: %v", err)
+		}
+		clientOptions.Resolver = dcs.Plain(dcs.PlainOptions{
+			Dial: dialFunc,
+		})
+	}
+
+	client := telegram.NewClient(p.APIId, p.APIHash, clientOptions)
+
+	var code string
+	err := client.Run(ctx, func(ctx context.Context) error {
+		status, err := client.Auth().Status(ctx)
+		if err != nil {
+			return fmt.Errorf("authorization status check: %w", err)
+		}
+		if !status.Authorized {
+			return fmt.Errorf("session %s is not authorized", p.SessionFile)
+		}
+
+		api := client.API()
+		peer, err := p.resolvePeer(ctx, api)
+		if err != nil {
+			return err
+		}
+
+		log.Printf("💬 Waiting for confirmation code of %s in Telegram messages", phone)
+
+		code, err = p.poll(ctx, func(ctx context.Context) (string, error) {
+			result, err := api.MessagesGetHistory(ctx, &tg.MessagesGetHistoryRequest{Peer: peer, Limit: 5})
+			if err != nil {
+				return "", err
+			}
+			modified, ok := result.AsModified()
+			if !ok {
+				return "", nil
+			}
+
+			// History is returned newest first
+			for _, item := range modified.GetMessages() {
+				message, ok := item.(*tg.Message)
+				if !ok || int64(message.Date) < since.Unix() {
+					continue
+				}
+				if p.find(message.Message) != "" {
+					return message.Message, nil
+				}
+			}
+			return "", nil
+		})
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return code, nil
+}
+
+// resolvePeer returns chat the code is read from
+func (p *TelegramCodeProvider) resolvePeer(ctx context.Context, api *tg.Client) (tg.InputPeerClass, error) {
+	if p.From != "" {
+		username := strings.TrimPrefix(strings.TrimSpace(p.From), "@")
+		resolved, err := api.ContactsResolveUsername(ctx, &tg.ContactsResolveUsernameRequest{Username: username})
+		if err != nil {
+			return nil, fmt.Errorf("error resolving @%s: %v", username, err)
+		}
+		for _, user := range resolved.Users {
+			if user, ok := user.(*tg.User); ok {
+				return user.AsInputPeer(), nil
+			}
+		}
+		for _, chat := range resolved.Chats {
+			if channel, ok := chat.(*tg.Channel); ok {
+				return channel.AsInputPeer(), nil
+			}
+		}
+		return nil, fmt.Errorf("@%s not found", username)
+	}
+
+	// Access hash of service notifications is taken from dialogs
+	dialogs, err := api.MessagesGetDialogs(ctx, &tg.MessagesGetDialogsRequest{
+		OffsetPeer: &tg.InputPeerEmpty{},
+		Limit:      50,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getting dialogs: %w", err)
+	}
+	modified, ok := dialogs.AsModified()
+	if ok {
+		for _, user := range modified.GetUsers() {
+			if user, ok := user.(*tg.User); ok && user.ID == serviceNotificationsID {
+				return user.AsInputPeer(), nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("no chat with Telegram service notifications")
+}
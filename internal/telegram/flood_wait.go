@@ -0,0 +1,81 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gotd/td/bin"
+	"github.com/gotd/td/telegram"
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+)
+
+// MaxFloodWait longest FLOOD_WAIT slept through, calls throttled for longer fail right away
+const MaxFloodWait = 10 * time.Minute
+
+// floodWaitRetries times one call is repeated after FLOOD_WAIT
+const floodWaitRetries = 3
+
+// floodWaitReportInterval how often remaining time of long FLOOD_WAIT is logged
+const floodWaitReportInterval = 30 * time.Second
+
+// floodWaitMiddleware sleeps through FLOOD_WAIT errors of Telegram and repeats the call,
+// so a throttled farm waits instead of failing authorization. Name identifies the client in logs
+func floodWaitMiddleware(name string) telegram.Middleware {
+	return telegram.MiddlewareFunc(func(next tg.Invoker) telegram.InvokeFunc {
+		return func(ctx context.Context, input bin.Encoder, output bin.Decoder) error {
+			for attempt := 1; ; attempt++ {
+				err := next.Invoke(ctx, input, output)
+				wait, ok := tgerr.AsFloodWait(err)
+				if !ok {
+					return err
+				}
+
+				method := methodName(input)
+				if wait > MaxFloodWait {
+					return fmt.Errorf("%s: Telegram asks to wait %s (longer than %s): %w", method, wait, MaxFloodWait, err)
+				}
+				if attempt > floodWaitRetries {
+					return fmt.Errorf("%s: still throttled after %d waits: %w", method, floodWaitRetries, err)
+				}
+
+				log.Printf("⏳ %s: Telegram FLOOD_WAIT on %s, waiting %s (attempt %d/%d)", name, method, wait, attempt, floodWaitRetries)
+				if err := sleepFloodWait(ctx, name, method, wait); err != nil {
+					return err
+				}
+			}
+		}
+	})
+}
+
+// sleepFloodWait waits out FLOOD_WAIT, logging remaining time of long waits
+func sleepFloodWait(ctx context.Context, name, method string, wait time.Duration) error {
+	// Telegram rounds the wait down to seconds
+	deadline := time.Now().Add(wait + time.Second)
+
+	ticker := time.NewTicker(floodWaitReportInterval)
+	defer ticker.Stop()
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			return nil
+		case <-ticker.C:
+			log.Printf("⏳ %s: %s left of FLOOD_WAIT on %s", name, time.Until(deadline).Round(time.Second), method)
+		}
+	}
+}
+
+// methodName returns TL name of request, e.g. "auth.sendCode"
+func methodName(input bin.Encoder) string {
+	if named, ok := input.(interface{ TypeName() string }); ok {
+		return named.TypeName()
+	}
+	return fmt.Sprintf("%T", input)
+}
@@ -0,0 +1,109 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Default login limits, they keep a batch of logins below Telegram's limits
+const (
+	DefaultLoginGap      = 30 * time.Second // Between logins through the same IP or proxy
+	DefaultLoginAttempts = 3                // Login attempts of one phone number within the cool-down
+	DefaultLoginCooldown = time.Hour        // Window the attempts are counted in
+)
+
+// directLoginRoute route of logins without proxy
+const directLoginRoute = "direct"
+
+// LoginLimiter spaces out logins sharing an IP or proxy and limits login attempts of every
+// phone number. Each attempt requests a confirmation code and may try the 2FA password, and
+// too many of them in a row make Telegram refuse codes for hours
+type LoginLimiter struct {
+	mu          sync.Mutex
+	gap         time.Duration
+	maxAttempts int
+	cooldown    time.Duration
+
+	nextLogin map[string]time.Time   // Earliest next login by route
+	attempts  map[string][]time.Time // Recent attempts by phone number
+}
+
+// NewLoginLimiter creates limiter with default limits
+func NewLoginLimiter() *LoginLimiter {
+	return &LoginLimiter{
+		gap:         DefaultLoginGap,
+		maxAttempts: DefaultLoginAttempts,
+		cooldown:    DefaultLoginCooldown,
+		nextLogin:   make(map[string]time.Time),
+		attempts:    make(map[string][]time.Time),
+	}
+}
+
+// SetLimits changes limits, zero values keep defaults. Negative gap disables the delay
+func (l *LoginLimiter) SetLimits(gap time.Duration, maxAttempts int, cooldown time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.gap, l.maxAttempts, l.cooldown = DefaultLoginGap, DefaultLoginAttempts, DefaultLoginCooldown
+	if gap != 0 {
+		l.gap = max(gap, 0)
+	}
+	if maxAttempts > 0 {
+		l.maxAttempts = maxAttempts
+	}
+	if cooldown > 0 {
+		l.cooldown = cooldown
+	}
+}
+
+// Wait takes login attempt of phone: fails if its attempts are used up, otherwise waits
+// until the previous login through the route (proxy URL, empty for direct connection) is far enough
+func (l *LoginLimiter) Wait(ctx context.Context, phone, route string) error {
+	if route == "" {
+		route = directLoginRoute
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+
+	var recent []time.Time
+	for _, at := range l.attempts[phone] {
+		if now.Sub(at) < l.cooldown {
+			recent = append(recent, at)
+		}
+	}
+	if len(recent) >= l.maxAttempts {
+		l.attempts[phone] = recent
+		l.mu.Unlock()
+		retryIn := recent[0].Add(l.cooldown).Sub(now).Round(time.Second)
+		return fmt.Errorf("%d login attempts of %s in the last %s, next attempt allowed in %s",
+			len(recent), phone, l.cooldown, retryIn)
+	}
+	l.attempts[phone] = append(recent, now)
+
+	// Logins through one route are queued one gap apart
+	start := l.nextLogin[route]
+	if start.Before(now) {
+		start = now
+	}
+	l.nextLogin[route] = start.Add(l.gap)
+	l.mu.Unlock()
+
+	wait := start.Sub(now)
+	if wait <= 0 {
+		return nil
+	}
+
+	log.Printf("⏳ Waiting %s before login of %s to stay within Telegram login limits", wait.Round(time.Second), phone)
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
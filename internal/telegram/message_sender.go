@@ -0,0 +1,64 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gotd/td/session"
+	"github.com/gotd/td/telegram"
+	"github.com/gotd/td/telegram/dcs"
+	"github.com/gotd/td/telegram/message"
+)
+
+// MessageSender sends text messages on behalf of account using its existing session
+type MessageSender struct {
+	APIId       int
+	APIHash     string
+	SessionFile string
+	UseProxy    bool
+	ProxyURL    string
+	To          string // Username of receiving chat, Saved Messages of the account if empty
+}
+
+// Send connects with the session and sends text. Session must already be authorized
+func (s *MessageSender) Send(ctx context.Context, text string) error {
+	clientOptions := telegram.Options{
+		SessionStorage: &session.FileStorage{
+			Path: s.SessionFile,
+		},
+		Middlewares: []telegram.Middleware{floodWaitMiddleware("Message sender")},
+	}
+
+	if s.UseProxy && s.ProxyURL != "" {
+		dialFunc, err := createProxyDialFunc(s.ProxyURL)
+		if err != nil {
+			return fmt.Errorf("invalid proxy URL: %v", err)
+		}
+		clientOptions.Resolver = dcs.Plain(dcs.PlainOptions{
+			Dial: dialFunc,
+		})
+	}
+
+	client := telegram.NewClient(s.APIId, s.APIHash, clientOptions)
+
+	return client.Run(ctx, func(ctx context.Context) error {
+		status, err := client.Auth().Status(ctx)
+		if err != nil {
+			return fmt.Errorf("authorization status check: %w", err)
+		}
+		if !status.Authorized {
+			return fmt.Errorf("session %s is not authorized", s.SessionFile)
+		}
+
+		sender := message.NewSender(client.API())
+		builder := sender.Self()
+		if s.To != "" {
+			builder = sender.Resolve(s.To)
+		}
+
+		if _, err := builder.Text(ctx, text); err != nil {
+			return fmt.Errorf("sending message: %w", err)
+		}
+		return nil
+	})
+}
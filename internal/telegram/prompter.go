@@ -0,0 +1,61 @@
+package telegram
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrNoPrompt login needs input of user, but the program can't ask for it
+var ErrNoPrompt = errors.New("login needs user input, but prompting is disabled")
+
+// Prompter asks user for login secrets that have no other source
+type Prompter interface {
+	CodeProvider
+	// Password returns 2FA password of account with phone
+	Password(ctx context.Context, phone string) (string, error)
+}
+
+// ConsolePrompter reads confirmation code and 2FA password from standard input
+type ConsolePrompter struct{}
+
+// Code reads confirmation code from standard input
+func (ConsolePrompter) Code(ctx context.Context, phone string) (string, error) {
+	fmt.Print("Enter code: ")
+	return readLine()
+}
+
+// Password reads 2FA password from standard input
+func (ConsolePrompter) Password(ctx context.Context, phone string) (string, error) {
+	fmt.Print("Enter your 2FA password: ")
+	password, err := readLine()
+	if err != nil {
+		return "", fmt.Errorf("error reading password: %w", err)
+	}
+	return password, nil
+}
+
+// readLine reads one line of standard input without line break
+func readLine() (string, error) {
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// NoPrompter fails every prompt, for programs without user at the console
+type NoPrompter struct{}
+
+// Code returns ErrNoPrompt
+func (NoPrompter) Code(ctx context.Context, phone string) (string, error) {
+	return "", fmt.Errorf("confirmation code for %s: %w", phone, ErrNoPrompt)
+}
+
+// Password returns ErrNoPrompt
+func (NoPrompter) Password(ctx context.Context, phone string) (string, error) {
+	return "", fmt.Errorf("2FA password for %s: %w", phone, ErrNoPrompt)
+}
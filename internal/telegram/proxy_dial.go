@@ -0,0 +1,25 @@
+package telegram
+
+import (
+	"context"
+	"net"
+
+	"stickersbot/internal/config"
+	"stickersbot/internal/proxydial"
+)
+
+// createProxyDialFunc creates dial function for proxy connection. Proxy without
+// scheme (host:port:user:pass) is used as SOCKS5, http:// and https:// proxies are
+// used through HTTP CONNECT. Chain "first > exit" reaches the exit through first
+func createProxyDialFunc(proxyURL string) (func(ctx context.Context, network, addr string) (net.Conn, error), error) {
+	hops, err := config.ParseProxyChain(proxyURL)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer, err := proxydial.Chain(hops, config.ProxySchemeSOCKS5)
+	if err != nil {
+		return nil, err
+	}
+	return dialer.DialContext, nil
+}
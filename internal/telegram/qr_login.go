@@ -0,0 +1,75 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gotd/td/telegram/auth/qrlogin"
+	"github.com/gotd/td/tgerr"
+	"rsc.io/qr"
+)
+
+// performQRAuth authorizes by login token scanned as QR code by a device where
+// the account is already logged in, no confirmation code is needed
+func (a *AuthService) performQRAuth(ctx context.Context, loggedIn qrlogin.LoggedIn) error {
+	_, err := a.client.QR().Auth(ctx, loggedIn, a.showQR)
+	if tgerr.Is(err, "SESSION_PASSWORD_NEEDED") {
+		password, err := a.passwordPrompt(ctx)
+		if err != nil {
+			return err
+		}
+		if _, err := a.client.Auth().Password(ctx, password); err != nil {
+			return fmt.Errorf("2FA password: %w", err)
+		}
+		return nil
+	}
+	return err
+}
+
+// showQR prints login token as QR code. Called again with a new token when the previous one expires
+func (a *AuthService) showQR(ctx context.Context, token qrlogin.Token) error {
+	code, err := qr.Encode(token.URL(), qr.L)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n📷 QR login for number: %s\n", a.PhoneNumber)
+	fmt.Println("   On a device where this account is logged in open Telegram: Settings > Devices > Link Desktop Device, and scan:")
+	fmt.Println(renderQR(code))
+	fmt.Printf("   Or open the link on that device: %s\n", token.URL())
+	fmt.Printf("   ⏳ Code is valid until %s, a new one is shown after that\n", token.Expires().Format(time.TimeOnly))
+	return nil
+}
+
+// renderQR renders QR code for terminal: two rows of modules per line using half blocks,
+// with a quiet zone around the code so phone cameras recognize it
+func renderQR(code *qr.Code) string {
+	const quiet = 2
+	size := code.Size + 2*quiet
+	dark := func(x, y int) bool {
+		x, y = x-quiet, y-quiet
+		return x >= 0 && y >= 0 && x < code.Size && y < code.Size && code.Black(x, y)
+	}
+
+	var sb strings.Builder
+	for y := 0; y < size; y += 2 {
+		for x := 0; x < size; x++ {
+			// Light text on dark terminal: blocks are drawn where modules are light
+			top, bottom := !dark(x, y), !dark(x, y+1) && y+1 < size
+			switch {
+			case top && bottom:
+				sb.WriteString("█")
+			case top:
+				sb.WriteString("▀")
+			case bottom:
+				sb.WriteString("▄")
+			default:
+				sb.WriteString(" ")
+			}
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
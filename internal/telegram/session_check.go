@@ -0,0 +1,98 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/gotd/td/telegram"
+	"github.com/gotd/td/telegram/auth"
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+)
+
+// SessionStatus result of checking session with Telegram
+type SessionStatus string
+
+// Session statuses
+const (
+	SessionMissing     SessionStatus = "missing"     // No session file
+	SessionAuthorized  SessionStatus = "authorized"  // Session is valid
+	SessionRevoked     SessionStatus = "revoked"     // Logged out, terminated from another device or expired
+	SessionDeactivated SessionStatus = "deactivated" // Account deleted or banned by Telegram
+	SessionUnknown     SessionStatus = "unknown"     // Telegram could not be asked, e.g. network error
+)
+
+// NeedsReauth checks if account has to log in again to use Telegram
+func (s SessionStatus) NeedsReauth() bool {
+	return s == SessionMissing || s == SessionRevoked
+}
+
+// CheckSession opens session file and asks Telegram whether it is still authorized.
+// Nothing is prompted: a session that is not authorized is reported, not repaired.
+// Error is returned together with SessionUnknown
+func (a *AuthService) CheckSession(ctx context.Context) (SessionStatus, error) {
+	if _, err := os.Stat(a.SessionFile); errors.Is(err, os.ErrNotExist) {
+		return SessionMissing, nil
+	}
+
+	clientOptions, err := a.clientOptions()
+	if err != nil {
+		return SessionUnknown, err
+	}
+	a.client = telegram.NewClient(a.APIId, a.APIHash, clientOptions)
+
+	status := SessionUnknown
+	err = a.client.Run(ctx, func(ctx context.Context) error {
+		var err error
+		status, err = a.selfStatus(ctx)
+		if status == SessionUnknown {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return SessionUnknown, err
+	}
+
+	return status, nil
+}
+
+// selfStatus requests own user to learn session status, auth.Status hides why session is
+// unauthorized. Error of Telegram is returned with every status except SessionAuthorized
+func (a *AuthService) selfStatus(ctx context.Context) (SessionStatus, error) {
+	_, err := a.client.API().UsersGetUsers(ctx, []tg.InputUserClass{&tg.InputUserSelf{}})
+	switch {
+	case err == nil:
+		return SessionAuthorized, nil
+	case tgerr.Is(err, "USER_DEACTIVATED", "USER_DEACTIVATED_BAN"):
+		return SessionDeactivated, err
+	case auth.IsUnauthorized(err):
+		return SessionRevoked, err
+	default:
+		return SessionUnknown, fmt.Errorf("getting self: %w", err)
+	}
+}
+
+// deadAccountErrors Telegram errors after which the account can't get tokens anymore
+var deadAccountErrors = []struct {
+	code   string
+	reason string
+}{
+	{"USER_DEACTIVATED_BAN", "account is banned"},
+	{"USER_DEACTIVATED", "account is deleted"},
+	{"PHONE_NUMBER_BANNED", "phone number is banned"},
+	{"AUTH_KEY_UNREGISTERED", "session was terminated"},
+}
+
+// DeadAccountReason returns why account is unusable if err is a ban or deactivation
+// error of Telegram, empty otherwise
+func DeadAccountReason(err error) string {
+	for _, dead := range deadAccountErrors {
+		if tgerr.Is(err, dead.code) {
+			return fmt.Sprintf("%s (%s)", dead.reason, dead.code)
+		}
+	}
+	return ""
+}
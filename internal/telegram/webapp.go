@@ -23,16 +23,16 @@ type WebAppService struct {
 }
 
 // NewWebAppService creates a new Web App service
-func NewWebAppService(api *tg.Client, botUsername, webAppURL string) *WebAppService {
+func NewWebAppService(api *tg.Client, botUsername, webAppURL string) (*WebAppService, error) {
 	return NewWebAppServiceWithProxy(api, botUsername, webAppURL, false, "")
 }
 
-// NewWebAppServiceWithProxy creates a new Web App service with proxy support
-func NewWebAppServiceWithProxy(api *tg.Client, botUsername, webAppURL string, useProxy bool, proxyURL string) *WebAppService {
+// NewWebAppServiceWithProxy creates a new Web App service whose HTTP requests go through
+// the proxy. Invalid proxy is an error, requests never fall back to the server's own IP
+func NewWebAppServiceWithProxy(api *tg.Client, botUsername, webAppURL string, useProxy bool, proxyURL string) (*WebAppService, error) {
 	httpClient, err := client.NewForAccount(useProxy, proxyURL)
 	if err != nil {
-		// Fallback to regular client if proxy fails
-		httpClient = client.New()
+		return nil, fmt.Errorf("creating HTTP client with proxy: %v", err)
 	}
 
 	return &WebAppService{
@@ -40,7 +40,12 @@ func NewWebAppServiceWithProxy(api *tg.Client, botUsername, webAppURL string, us
 		botUsername: botUsername,
 		webAppURL:   webAppURL,
 		httpClient:  httpClient,
-	}
+	}, nil
+}
+
+// Authenticate exchanges Web App auth data for Bearer token at apiURL
+func (w *WebAppService) Authenticate(apiURL string, authData *client.AuthData) (*client.TelegramAuthResponse, error) {
+	return w.httpClient.AuthenticateWithTelegramData(apiURL, authData)
 }
 
 // GetBearerTokenFromWebApp gets Bearer token through Web App
@@ -1,6 +1,10 @@
 package types
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 // Sticker represents a sticker
 type Sticker struct {
@@ -54,16 +58,102 @@ type BuyRequest struct {
 	Count      int    `json:"count"`
 }
 
+// Counters request and transaction counters
+type Counters struct {
+	TotalRequests    int   `json:"total_requests"`
+	SuccessRequests  int   `json:"success_requests"`
+	FailedRequests   int   `json:"failed_requests"`
+	InvalidTokens    int   `json:"invalid_tokens"`
+	SentTransactions int   `json:"sent_transactions"`
+	SpentNano        int64 `json:"spent_nano"` // Amount of sent transactions in nanotons
+
+	Errors ErrorCounters `json:"errors"` // Failed requests by error class
+}
+
+// Add adds other counters to counters
+func (c *Counters) Add(other Counters) {
+	c.TotalRequests += other.TotalRequests
+	c.SuccessRequests += other.SuccessRequests
+	c.FailedRequests += other.FailedRequests
+	c.InvalidTokens += other.InvalidTokens
+	c.SentTransactions += other.SentTransactions
+	c.SpentNano += other.SpentNano
+	c.Errors.Add(other.Errors)
+}
+
+// ErrorCounters failed requests by error class
+type ErrorCounters struct {
+	RateLimited       int `json:"rate_limited"`       // HTTP 429
+	SoldOut           int `json:"sold_out"`           // Character is sold out
+	InsufficientFunds int `json:"insufficient_funds"` // Not enough money for the order
+	TokenInvalid      int `json:"token_invalid"`      // Token missing, expired or rejected
+	Network           int `json:"network"`            // No response received
+	PaymentFailed     int `json:"payment_failed"`     // Order created but TON transaction failed
+	PriceTooHigh      int `json:"price_too_high"`     // Order quoted above max price, not paid
+	Other             int `json:"other"`              // Any other unsuccessful response
+}
+
+// Add adds other counters to counters
+func (e *ErrorCounters) Add(other ErrorCounters) {
+	e.RateLimited += other.RateLimited
+	e.SoldOut += other.SoldOut
+	e.InsufficientFunds += other.InsufficientFunds
+	e.TokenInvalid += other.TokenInvalid
+	e.Network += other.Network
+	e.PaymentFailed += other.PaymentFailed
+	e.PriceTooHigh += other.PriceTooHigh
+	e.Other += other.Other
+}
+
+// String formats non-zero counters as "rate_limited 3, sold_out 1"
+func (e ErrorCounters) String() string {
+	var parts []string
+	for _, counter := range []struct {
+		name  string
+		value int
+	}{
+		{"rate_limited", e.RateLimited},
+		{"sold_out", e.SoldOut},
+		{"insufficient_funds", e.InsufficientFunds},
+		{"token_invalid", e.TokenInvalid},
+		{"network", e.Network},
+		{"payment_failed", e.PaymentFailed},
+		{"price_too_high", e.PriceTooHigh},
+		{"other", e.Other},
+	} {
+		if counter.value > 0 {
+			parts = append(parts, fmt.Sprintf("%s %d", counter.name, counter.value))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
 // Statistics purchase statistics
 type Statistics struct {
-	TotalRequests    int           `json:"total_requests"`
-	SuccessRequests  int           `json:"success_requests"`
-	FailedRequests   int           `json:"failed_requests"`
-	InvalidTokens    int           `json:"invalid_tokens"`
-	SentTransactions int           `json:"sent_transactions"`
-	StartTime        time.Time     `json:"start_time"`
-	Duration         time.Duration `json:"duration"`
-	RequestsPerSec   float64       `json:"requests_per_sec"`
+	Counters
+	StartTime      time.Time     `json:"start_time"`
+	Duration       time.Duration `json:"duration"`
+	RequestsPerSec float64       `json:"requests_per_sec"`
+
+	// Counters of every account (account name -> counters)
+	Accounts map[string]*Counters `json:"accounts,omitempty"`
+
+	// Snipe purchase latencies (p50/p95 per stage)
+	Latencies []LatencyStats `json:"latencies,omitempty"`
+}
+
+// SessionStatistics statistics of one finished run
+type SessionStatistics struct {
+	Statistics
+	EndTime time.Time `json:"end_time"`
+}
+
+// LatencyStats latency percentiles of one purchase stage
+type LatencyStats struct {
+	Stage string        `json:"stage"`
+	Count int           `json:"count"`
+	P50   time.Duration `json:"p50"`
+	P95   time.Duration `json:"p95"`
 }
 
 // AppState application state
@@ -82,10 +172,56 @@ type TransactionLog struct {
 	Timestamp     time.Time `json:"timestamp"`
 	AccountName   string    `json:"account_name"`
 	OrderID       string    `json:"order_id"`
+	Collection    int       `json:"collection,omitempty"`   // Collection ID of the order
+	Character     int       `json:"character,omitempty"`    // Character ID of the order
+	Items         int       `json:"items,omitempty"`        // Number of stickers ordered
+	OrderAmount   int64     `json:"order_amount,omitempty"` // Price of the order, Amount minus fee
 	Amount        int64     `json:"amount"`
 	Currency      string    `json:"currency"`
 	FromAddress   string    `json:"from_address"`
 	ToAddress     string    `json:"to_address"`
 	TransactionID string    `json:"transaction_id"`
 	TestMode      bool      `json:"test_mode"`
+
+	// Inventory check result: CreditCredited or CreditNotCredited, empty if not checked
+	Credit string `json:"credit,omitempty"`
+	// Line only updates Credit of earlier logged transaction with the same OrderID
+	Update bool `json:"update,omitempty"`
 }
+
+// OrderRecord raw order request and responses of a purchase, kept to investigate
+// disputed purchases. Secrets are removed from bodies before saving
+type OrderRecord struct {
+	Timestamp    time.Time `json:"timestamp"`
+	AccountName  string    `json:"account_name"`
+	OrderID      string    `json:"order_id"`
+	Collection   int       `json:"collection"`
+	Character    int       `json:"character"`
+	Request      string    `json:"request"` // Method and URL of the order request
+	StatusCode   int       `json:"status_code"`
+	ResponseBody string    `json:"response_body"`
+	RespondedAt  time.Time `json:"responded_at"`
+
+	// Payment of the order, empty if it was not sent
+	PaymentTo      string    `json:"payment_to,omitempty"`
+	PaymentAmount  int64     `json:"payment_amount,omitempty"`
+	PaymentComment string    `json:"payment_comment,omitempty"`
+	TransactionID  string    `json:"transaction_id,omitempty"`
+	BroadcastAt    time.Time `json:"broadcast_at,omitempty"`
+
+	Result string `json:"result"`          // OrderPaid, OrderPaymentFailed or OrderRefused
+	Error  string `json:"error,omitempty"` // Why payment failed or was refused
+}
+
+// Results of created orders
+const (
+	OrderPaid          = "paid"           // Payment transaction was sent
+	OrderPaymentFailed = "payment_failed" // Payment transaction failed
+	OrderRefused       = "refused"        // Order was not paid, e.g. quoted above max price
+)
+
+// Inventory check results of paid orders
+const (
+	CreditCredited    = "credited"     // Purchased character appeared in account inventory
+	CreditNotCredited = "not_credited" // Paid, but the character didn't appear in time
+)
@@ -0,0 +1,321 @@
+// Package update finds newer signed releases of the bot and replaces the running binary with them
+package update
+
+import (
+	"cmp"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ReleaseKey hex ed25519 public key release signatures are checked against.
+// Set at build time: -ldflags "-X stickersbot/internal/update.ReleaseKey=<hex>"
+var ReleaseKey = ""
+
+// Release channels from the most to the least stable. Build of a channel is offered
+// releases of its own and more stable channels
+const (
+	ChannelStable = "stable"
+	ChannelRC     = "rc"
+	ChannelBeta   = "beta"
+	ChannelDev    = "dev"
+)
+
+var channels = []string{ChannelStable, ChannelRC, ChannelBeta, ChannelDev}
+
+const requestTimeout = 5 * time.Minute // Release list and binary download
+
+// Asset binary of release for one platform
+type Asset struct {
+	URL       string `json:"url"`
+	SHA256    string `json:"sha256"`    // Hex digest of binary
+	Signature string `json:"signature"` // Base64 ed25519 signature of SignedMessage
+}
+
+// Release one published version of the bot
+type Release struct {
+	Version    string           `json:"version"`              // "1.2.0"
+	Prerelease string           `json:"prerelease,omitempty"` // "", "rc1", "beta", "dev"
+	Required   bool             `json:"required,omitempty"`   // Older versions no longer work with the shop or license server
+	Notes      string           `json:"notes,omitempty"`
+	Assets     map[string]Asset `json:"assets"` // "linux-amd64", "windows-amd64", ...
+}
+
+// String returns version with pre-release marker
+func (r Release) String() string {
+	if r.Prerelease == "" {
+		return r.Version
+	}
+	return r.Version + "-" + r.Prerelease
+}
+
+// ParseVersion splits "1.2.0-beta" into version and pre-release marker
+func ParseVersion(s string) (string, string) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	version, prerelease, _ := strings.Cut(s, "-")
+	return version, prerelease
+}
+
+// Platform returns asset key of the running binary
+func Platform() string {
+	return runtime.GOOS + "-" + runtime.GOARCH
+}
+
+// Channel returns release channel of pre-release marker
+func Channel(prerelease string) string {
+	switch p := strings.ToLower(prerelease); {
+	case p == "":
+		return ChannelStable
+	case strings.HasPrefix(p, "rc"):
+		return ChannelRC
+	case strings.HasPrefix(p, "beta"), strings.HasPrefix(p, "alpha"):
+		return ChannelBeta
+	default:
+		return ChannelDev
+	}
+}
+
+// ValidateChannel checks that channel name is known
+func ValidateChannel(channel string) error {
+	for _, c := range channels {
+		if c == channel {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown channel %q, expected %s", channel, strings.Join(channels, ", "))
+}
+
+// channelRank returns position of channel, lower is more stable
+func channelRank(channel string) int {
+	for i, c := range channels {
+		if c == channel {
+			return i
+		}
+	}
+	return len(channels)
+}
+
+// Compare compares versions with pre-release markers: -1 if a is older than b, 0 if equal, 1 if newer.
+// Final release is newer than pre-releases of the same version
+func Compare(a, aPrerelease, b, bPrerelease string) int {
+	aParts, bParts := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < max(len(aParts), len(bParts)); i++ {
+		var x, y int
+		if i < len(aParts) {
+			x, _ = strconv.Atoi(aParts[i])
+		}
+		if i < len(bParts) {
+			y, _ = strconv.Atoi(bParts[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	switch {
+	case aPrerelease == bPrerelease:
+		return 0
+	case aPrerelease == "":
+		return 1
+	case bPrerelease == "":
+		return -1
+	default:
+		return comparePrerelease(aPrerelease, bPrerelease)
+	}
+}
+
+// comparePrerelease compares pre-release markers by runs of digits and other characters,
+// numbers by value: "rc2" is older than "rc10". A marker extending the other is newer
+func comparePrerelease(a, b string) int {
+	aParts, bParts := prereleaseParts(a), prereleaseParts(b)
+	for i := 0; i < min(len(aParts), len(bParts)); i++ {
+		x, xErr := strconv.Atoi(aParts[i])
+		y, yErr := strconv.Atoi(bParts[i])
+		switch {
+		case xErr == nil && yErr == nil:
+			if x != y {
+				return cmp.Compare(x, y)
+			}
+		case xErr == nil:
+			// Numbers are older than words, like in semantic versioning
+			return -1
+		case yErr == nil:
+			return 1
+		case aParts[i] != bParts[i]:
+			return strings.Compare(aParts[i], bParts[i])
+		}
+	}
+	return cmp.Compare(len(aParts), len(bParts))
+}
+
+// prereleaseParts splits pre-release marker into runs of digits and letters, dots and dashes
+// only separate them: "rc.10" and "rc10" are both "rc", "10"
+func prereleaseParts(prerelease string) []string {
+	var parts []string
+	start := -1
+	for i, r := range prerelease + "." {
+		isDigit := r >= '0' && r <= '9'
+		if start >= 0 && (r == '.' || r == '-' || isDigit != (prerelease[start] >= '0' && prerelease[start] <= '9')) {
+			parts = append(parts, strings.ToLower(prerelease[start:i]))
+			start = -1
+		}
+		if start < 0 && r != '.' && r != '-' {
+			start = i
+		}
+	}
+	return parts
+}
+
+// FetchReleases downloads list of releases from release endpoint
+func FetchReleases(url string) ([]Release, error) {
+	httpClient := &http.Client{Timeout: requestTimeout}
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("fetching releases: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching releases: status %d", resp.StatusCode)
+	}
+
+	var releases []Release
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return nil, fmt.Errorf("parsing releases: %v", err)
+	}
+	return releases, nil
+}
+
+// Latest returns the newest release of channel (or more stable ones) that is newer than
+// current version and has binary for this platform. False if current version is up to date
+func Latest(releases []Release, channel, version, prerelease string) (Release, bool) {
+	var latest Release
+	found := false
+	for _, release := range releases {
+		if channelRank(Channel(release.Prerelease)) > channelRank(channel) {
+			continue
+		}
+		if _, ok := release.Assets[Platform()]; !ok {
+			continue
+		}
+		if Compare(release.Version, release.Prerelease, version, prerelease) <= 0 {
+			continue
+		}
+		if !found || Compare(release.Version, release.Prerelease, latest.Version, latest.Prerelease) > 0 {
+			latest, found = release, true
+		}
+	}
+	return latest, found
+}
+
+// SignedMessage returns text signed by release signature. Version and platform are part of it,
+// so a signed binary can't be offered as another version or for another platform
+func SignedMessage(release Release, platform, digest string) []byte {
+	return []byte(fmt.Sprintf("stickersbot %s %s %s", release.String(), platform, strings.ToLower(digest)))
+}
+
+// verify checks digest of downloaded binary and signature of release over it
+func verify(release Release, asset Asset, digest string) error {
+	if ReleaseKey == "" {
+		return fmt.Errorf("this build has no release signing key, updates can't be verified")
+	}
+	key, err := hex.DecodeString(ReleaseKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid release signing key of this build")
+	}
+
+	if !strings.EqualFold(digest, asset.SHA256) {
+		return fmt.Errorf("checksum mismatch: downloaded %s, release lists %s", digest, asset.SHA256)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(asset.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid release signature: %v", err)
+	}
+	if !ed25519.Verify(key, SignedMessage(release, Platform(), digest), signature) {
+		return fmt.Errorf("release signature is not valid")
+	}
+	return nil
+}
+
+// Apply downloads binary of release, verifies it and replaces the running executable.
+// The previous binary is kept next to it with ".old" suffix. Returns path of replaced executable
+func Apply(release Release) (string, error) {
+	asset, ok := release.Assets[Platform()]
+	if !ok {
+		return "", fmt.Errorf("release %s has no binary for %s", release, Platform())
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("locating executable: %v", err)
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return "", fmt.Errorf("locating executable: %v", err)
+	}
+
+	// New binary is written next to the old one, so it can be renamed over it
+	tmp, err := os.CreateTemp(filepath.Dir(exe), filepath.Base(exe)+".new-*")
+	if err != nil {
+		return "", fmt.Errorf("creating update file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	digest, err := download(asset.URL, tmp)
+	tmp.Close()
+	if err != nil {
+		return "", err
+	}
+	if err := verify(release, asset, digest); err != nil {
+		return "", err
+	}
+	if err := os.Chmod(tmp.Name(), 0755); err != nil {
+		return "", err
+	}
+
+	// Running binary can't be overwritten on Windows, but it can be renamed
+	old := exe + ".old"
+	os.Remove(old)
+	if err := os.Rename(exe, old); err != nil {
+		return "", fmt.Errorf("moving current binary: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), exe); err != nil {
+		os.Rename(old, exe)
+		return "", fmt.Errorf("installing new binary: %v", err)
+	}
+	return exe, nil
+}
+
+// download writes binary from url to file and returns its hex SHA-256 digest
+func download(url string, file *os.File) (string, error) {
+	httpClient := &http.Client{Timeout: requestTimeout}
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return "", fmt.Errorf("downloading release: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("downloading release: status %d", resp.StatusCode)
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(file, hash), resp.Body); err != nil {
+		return "", fmt.Errorf("downloading release: %v", err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
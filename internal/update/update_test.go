@@ -0,0 +1,161 @@
+package update
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		in              string
+		version, marker string
+	}{
+		{"1.2.0", "1.2.0", ""},
+		{"v1.2.0", "1.2.0", ""},
+		{" 1.2.0-beta ", "1.2.0", "beta"},
+		{"1.2.0-rc.1", "1.2.0", "rc.1"},
+		{"1.2.0-rc-2", "1.2.0", "rc-2"},
+	}
+	for _, tt := range tests {
+		version, marker := ParseVersion(tt.in)
+		if version != tt.version || marker != tt.marker {
+			t.Errorf("ParseVersion(%q) = %q, %q, want %q, %q", tt.in, version, marker, tt.version, tt.marker)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.0", "1.2.0", 0},
+		{"1.2.0", "1.10.0", -1},
+		{"1.2", "1.2.0", 0},
+		{"2.0.0", "1.9.9", 1},
+		{"1.2.0", "1.2.0-rc1", 1},
+		{"1.2.0-rc2", "1.2.0-rc10", -1},
+		{"1.2.0-rc.2", "1.2.0-rc.10", -1},
+		{"1.2.0-rc10", "1.2.0-rc.10", 0},
+		{"1.2.0-beta", "1.2.0-rc1", -1},
+		{"1.2.0-rc", "1.2.0-rc1", -1},
+		{"1.2.0-1", "1.2.0-rc", -1},
+		{"1.2.0-RC2", "1.2.0-rc2", 0},
+	}
+	for _, tt := range tests {
+		a, aPrerelease := ParseVersion(tt.a)
+		b, bPrerelease := ParseVersion(tt.b)
+		if got := Compare(a, aPrerelease, b, bPrerelease); got != tt.want {
+			t.Errorf("Compare(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := Compare(b, bPrerelease, a, aPrerelease); got != -tt.want {
+			t.Errorf("Compare(%q, %q) = %d, want %d", tt.b, tt.a, got, -tt.want)
+		}
+	}
+}
+
+func TestChannel(t *testing.T) {
+	tests := map[string]string{
+		"":        ChannelStable,
+		"rc1":     ChannelRC,
+		"beta.2":  ChannelBeta,
+		"alpha":   ChannelBeta,
+		"nightly": ChannelDev,
+	}
+	for marker, want := range tests {
+		if got := Channel(marker); got != want {
+			t.Errorf("Channel(%q) = %q, want %q", marker, got, want)
+		}
+	}
+}
+
+func TestLatest(t *testing.T) {
+	assets := map[string]Asset{Platform(): {}}
+	releases := []Release{
+		{Version: "1.1.0", Assets: assets},
+		{Version: "1.2.0", Prerelease: "rc10", Assets: assets},
+		{Version: "1.2.0", Prerelease: "rc2", Assets: assets},
+		{Version: "1.3.0", Prerelease: "beta", Assets: assets},
+		{Version: "9.0.0", Assets: map[string]Asset{"other-platform": {}}},
+	}
+
+	tests := []struct {
+		channel string
+		current string
+		want    string
+		found   bool
+	}{
+		{ChannelStable, "1.0.0", "1.1.0", true},
+		{ChannelStable, "1.1.0", "", false},
+		{ChannelRC, "1.1.0", "1.2.0-rc10", true},
+		{ChannelBeta, "1.1.0", "1.3.0-beta", true},
+	}
+	for _, tt := range tests {
+		version, prerelease := ParseVersion(tt.current)
+		got, found := Latest(releases, tt.channel, version, prerelease)
+		if found != tt.found || (found && got.String() != tt.want) {
+			t.Errorf("Latest(%s, %s) = %s, %v, want %s, %v", tt.channel, tt.current, got, found, tt.want, tt.found)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func(key string) { ReleaseKey = key }(ReleaseKey)
+	ReleaseKey = hex.EncodeToString(public)
+
+	release := Release{Version: "1.2.0", Prerelease: "rc1"}
+	digest := strings.Repeat("ab", 32)
+	sign := func(key ed25519.PrivateKey, release Release, platform string) string {
+		return base64.StdEncoding.EncodeToString(ed25519.Sign(key, SignedMessage(release, platform, digest)))
+	}
+
+	tests := []struct {
+		name    string
+		asset   Asset
+		digest  string
+		wantErr string
+	}{
+		{"valid", Asset{SHA256: digest, Signature: sign(private, release, Platform())}, digest, ""},
+		{"digest in upper case", Asset{SHA256: strings.ToUpper(digest), Signature: sign(private, release, Platform())}, digest, ""},
+		{"checksum mismatch", Asset{SHA256: digest, Signature: sign(private, release, Platform())}, strings.Repeat("cd", 32), "checksum mismatch"},
+		{"other key", Asset{SHA256: digest, Signature: sign(otherPrivate, release, Platform())}, digest, "not valid"},
+		{"other version", Asset{SHA256: digest, Signature: sign(private, Release{Version: "1.1.0"}, Platform())}, digest, "not valid"},
+		{"other platform", Asset{SHA256: digest, Signature: sign(private, release, "plan9-arm")}, digest, "not valid"},
+		{"malformed signature", Asset{SHA256: digest, Signature: "%%%"}, digest, "invalid release signature"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verify(release, tt.asset, tt.digest)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("verify() = %v, want nil", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("verify() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyWithoutKey(t *testing.T) {
+	defer func(key string) { ReleaseKey = key }(ReleaseKey)
+
+	for _, key := range []string{"", "not-hex", hex.EncodeToString([]byte("short"))} {
+		ReleaseKey = key
+		if err := verify(Release{Version: "1.0.0"}, Asset{}, ""); err == nil {
+			t.Errorf("verify() with release key %q = nil, want error", key)
+		}
+	}
+}
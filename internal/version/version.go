@@ -13,3 +13,11 @@ var Version = "1.0.0"
 var Prerelease = "beta"
 
 var Production = true
+
+// Commit and BuildDate identify the build. Set at build time:
+// -ldflags "-X stickersbot/internal/version.Commit=<sha> -X stickersbot/internal/version.BuildDate=<RFC3339>".
+// Empty values are filled from VCS information Go embeds into the binary
+var (
+	Commit    = ""
+	BuildDate = ""
+)
@@ -0,0 +1,184 @@
+// Package stickersbot is the programmatic API of the bot for Go programs that embed it
+// instead of running the console application.
+//
+// Nothing in this package reads standard input or panics: constructors return errors,
+// and Telegram logins ask for confirmation codes and 2FA passwords through the Prompter
+// given in Options. Without one, logins that need user input fail with telegram.ErrNoPrompt.
+//
+// A typical program loads the configuration and runs purchases:
+//
+//	cfg, err := stickersbot.LoadConfig("config.json")
+//	if err != nil { ... }
+//	buyer, err := stickersbot.NewBuyer(cfg, stickersbot.Options{})
+//	if err != nil { ... }
+//	defer buyer.Close()
+//	buyer.OnPaymentConfirmed(func(e stickersbot.PaymentEvent) { ... })
+//	err = buyer.Start()
+//
+// Runtime files (tokens, sessions, logs) are resolved against SetStateDir, the current
+// directory by default, the same way as for the console application.
+package stickersbot
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"stickersbot/internal/client"
+	"stickersbot/internal/config"
+	"stickersbot/internal/monitor"
+	"stickersbot/internal/service"
+	"stickersbot/internal/telegram"
+	"stickersbot/internal/types"
+)
+
+// Types shared with the bot
+type (
+	Config            = config.Config
+	Account           = config.Account
+	Statistics        = types.Statistics
+	PurchaseRequest   = monitor.PurchaseRequest
+	FilterSettings    = monitor.FilterSettings
+	TokenProvider     = monitor.TokenProvider
+	OrderEvent        = service.OrderEvent
+	PaymentEvent      = service.PaymentEvent
+	ErrorEvent        = service.ErrorEvent
+	TransactionResult = client.TransactionResult
+
+	// Prompter asks user for login confirmation code and 2FA password
+	Prompter = telegram.Prompter
+	// ConsolePrompter reads code and password from standard input, like the console application
+	ConsolePrompter = telegram.ConsolePrompter
+)
+
+// Options settings of the programmatic API
+type Options struct {
+	Prompter Prompter // Asks for login code and 2FA password, nil - logins needing input fail
+}
+
+// prompter returns prompter of options, NoPrompter if none is set
+func (o Options) prompter() Prompter {
+	if o.Prompter == nil {
+		return telegram.NoPrompter{}
+	}
+	return o.Prompter
+}
+
+// LoadConfig loads configuration from file, missing file gives default configuration
+func LoadConfig(path string) (*Config, error) {
+	return config.Load(path)
+}
+
+// SetStateDir sets directory runtime files are kept in, empty - current directory
+func SetStateDir(dir string) {
+	config.SetStateDir(dir)
+}
+
+// Buyer runs purchases of all accounts of configuration: workers, snipe monitors and payments
+type Buyer struct {
+	*service.BuyerService
+	tokens  *service.TokenManager
+	storage service.Storage
+}
+
+// NewBuyer creates buyer of configuration. Close releases its storage
+func NewBuyer(cfg *Config, opts Options) (*Buyer, error) {
+	service.SetLoginPrompter(opts.prompter())
+
+	storage, err := service.OpenStorage(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("opening storage: %w", err)
+	}
+	tokens, err := service.NewTokenManager(cfg, storage)
+	if err != nil {
+		storage.Close()
+		return nil, fmt.Errorf("creating token manager: %w", err)
+	}
+	buyer, err := service.NewBuyerService(cfg, tokens, storage)
+	if err != nil {
+		storage.Close()
+		return nil, fmt.Errorf("creating buyer service: %w", err)
+	}
+
+	return &Buyer{BuyerService: buyer, tokens: tokens, storage: storage}, nil
+}
+
+// Tokens returns token provider of the buyer, e.g. for a Monitor of the same accounts
+func (b *Buyer) Tokens() TokenProvider {
+	return b.tokens
+}
+
+// Close stops purchases and closes storage
+func (b *Buyer) Close() error {
+	if b.IsRunning() {
+		b.Stop()
+	}
+	return b.storage.Close()
+}
+
+// Monitor watches the shop catalog for an account and reports matches of its snipe filters
+type Monitor struct {
+	*monitor.SnipeMonitor
+}
+
+// NewMonitor creates monitor of account whose snipe_monitor settings select matches.
+// onMatch is called for every match instead of buying it
+func NewMonitor(account *Account, tokens TokenProvider, onMatch func(PurchaseRequest) error) (*Monitor, error) {
+	if account.SnipeMonitor == nil {
+		return nil, fmt.Errorf("account %s has no snipe_monitor settings", account.Name)
+	}
+	httpClient, err := client.NewForAccount(account.UseProxy, account.ProxyURL)
+	if err != nil {
+		return nil, err
+	}
+	return &Monitor{monitor.NewSnipeMonitor(account, httpClient, onMatch, tokens)}, nil
+}
+
+// Wallet TON wallet of a seed phrase. Wallets of the same seed phrase share one transaction queue
+type Wallet struct {
+	client *client.TONClient
+}
+
+// OpenWallet connects to TON network and opens wallet of seed phrase
+func OpenWallet(seedPhrase string) (*Wallet, error) {
+	tonClient, err := client.NewTONClient(seedPhrase)
+	if err != nil {
+		return nil, err
+	}
+	return &Wallet{client: tonClient}, nil
+}
+
+// Address returns wallet address
+func (w *Wallet) Address() string {
+	return w.client.GetAddress().String()
+}
+
+// Balance returns wallet balance in nanoTON
+func (w *Wallet) Balance(ctx context.Context) (*big.Int, error) {
+	return w.client.GetBalance(ctx)
+}
+
+// Send sends nanoTON with comment and waits until the transaction is sent
+func (w *Wallet) Send(ctx context.Context, to string, nanoTON int64, comment string) (*TransactionResult, error) {
+	return w.client.SendTON(ctx, to, nanoTON, comment, false, "")
+}
+
+// Auth Telegram login of an account and retrieval of its shop token
+type Auth struct {
+	service *telegram.AuthService
+}
+
+// NewAuth creates login of account with its phone, API credentials, proxies and code provider
+func NewAuth(cfg *Config, account *Account, opts Options) (*Auth, error) {
+	authService, err := service.NewAccountAuthService(cfg, account)
+	if err != nil {
+		return nil, err
+	}
+	authService.Prompter = opts.prompter()
+	return &Auth{service: authService}, nil
+}
+
+// Token logs in if the session is not authorized yet and returns shop Bearer token
+func (a *Auth) Token(ctx context.Context) (string, error) {
+	return a.service.AuthorizeAndGetToken(ctx)
+}